@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+)
+
+// aiUsageRow はAI呼び出しの集計の1行
+type aiUsageRow struct {
+	Key          string  `json:"key"`
+	Calls        int     `json:"calls"`
+	Errors       int     `json:"errors"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+	LatencyMS    int64   `json:"-"` // 合計（表示は平均）
+}
+
+// AvgLatencyMS は1回あたりの平均レイテンシを返す
+func (r aiUsageRow) AvgLatencyMS() int64 {
+	if r.Calls == 0 {
+		return 0
+	}
+	return r.LatencyMS / int64(r.Calls)
+}
+
+// runAIUsage は storage.ai_ledger に記録したAI呼び出しを取得元・モデル・日・結果ごとに集計して表示する
+//
+//	x-crawler ai-usage [-by source|model|day|outcome] [-days 7] [-since 2024-01-01] [-until 2024-01-31] [-calls] [-format table|csv|jsonl]
+func runAIUsage(args []string) error {
+	fs := flag.NewFlagSet("ai-usage", flag.ExitOnError)
+	configPath := fs.String("config", defaultConfigPath, "設定ファイルのパス")
+	by := fs.String("by", "source", "集計の軸: source (トレーダー・キーワード), model, day, outcome")
+	days := fs.Int("days", 7, "直近の日数（-since を指定した場合は無視）")
+	sinceFlag := fs.String("since", "", "この日時以降 (2006-01-02 または RFC3339)")
+	untilFlag := fs.String("until", "", "この日時より前 (日付のみの場合はその日を含む)")
+	calls := fs.Bool("calls", false, "集計せずに呼び出しを1件ずつ出力する")
+	limit := fs.Int("limit", 20, "表示する件数（0で全て、-calls の場合は無視）")
+	format := fs.String("format", "table", "出力形式: table, csv, jsonl")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	switch *by {
+	case "source", "model", "day", "outcome":
+	default:
+		return fmt.Errorf("invalid -by %q (expected source, model, day or outcome)", *by)
+	}
+	switch *format {
+	case "table", "csv", "jsonl":
+	default:
+		return fmt.Errorf("invalid -format %q (expected table, csv or jsonl)", *format)
+	}
+	since, err := parseExportTime(*sinceFlag, false)
+	if err != nil {
+		return fmt.Errorf("invalid -since: %w", err)
+	}
+	until, err := parseExportTime(*untilFlag, true)
+	if err != nil {
+		return fmt.Errorf("invalid -until: %w", err)
+	}
+	if since.IsZero() && *days > 0 {
+		now := time.Now()
+		since = time.Date(now.Year(), now.Month(), now.Day()-(*days-1), 0, 0, 0, 0, now.Location())
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := setupEncryption(cfg); err != nil {
+		return err
+	}
+	ledger, closeLedger, err := openUsageLedger(cfg, nil)
+	if err != nil {
+		return err
+	}
+	if ledger == nil {
+		return fmt.Errorf("AI usage ledger is disabled (set storage.ai_ledger.enabled: true)")
+	}
+	defer closeLedger()
+
+	records, err := ledger.AICalls(since, until)
+	if err != nil {
+		return fmt.Errorf("failed to read AI usage ledger: %w", err)
+	}
+	if *calls {
+		return writeAICalls(os.Stdout, *format, records)
+	}
+
+	rows := summarizeAICalls(records, *by)
+	if *limit > 0 && len(rows) > *limit {
+		rows = rows[:*limit]
+	}
+	return writeAIUsage(os.Stdout, *format, rows)
+}
+
+// summarizeAICalls はbyごとに集計し、コストの高い順（dayの場合は日付順）に返す
+func summarizeAICalls(calls []ai.Call, by string) []aiUsageRow {
+	rows := make(map[string]*aiUsageRow)
+	for _, c := range calls {
+		var key string
+		switch by {
+		case "model":
+			key = c.Model
+		case "day":
+			key = c.At.Local().Format("2006-01-02")
+		case "outcome":
+			key = c.Outcome
+		default:
+			key = c.Source
+		}
+		if key == "" {
+			key = "-"
+		}
+		r, ok := rows[key]
+		if !ok {
+			r = &aiUsageRow{Key: key}
+			rows[key] = r
+		}
+		r.Calls++
+		if c.Outcome == ai.CallError {
+			r.Errors++
+		}
+		r.InputTokens += c.InputTokens
+		r.OutputTokens += c.OutputTokens
+		r.CostUSD += c.CostUSD
+		r.LatencyMS += c.LatencyMS
+	}
+
+	out := make([]aiUsageRow, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if by != "day" && out[i].CostUSD != out[j].CostUSD {
+			return out[i].CostUSD > out[j].CostUSD
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+// writeAIUsage は集計を表・CSV・JSONLで書き込む
+func writeAIUsage(w io.Writer, format string, rows []aiUsageRow) error {
+	switch format {
+	case "jsonl":
+		return writeJSONLines(w, len(rows), func(i int) interface{} {
+			return struct {
+				aiUsageRow
+				AvgLatencyMS int64 `json:"avg_latency_ms"`
+			}{rows[i], rows[i].AvgLatencyMS()}
+		})
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"key", "calls", "errors", "input_tokens", "output_tokens", "cost_usd", "avg_latency_ms"})
+		for _, r := range rows {
+			cw.Write([]string{
+				r.Key,
+				strconv.Itoa(r.Calls),
+				strconv.Itoa(r.Errors),
+				strconv.Itoa(r.InputTokens),
+				strconv.Itoa(r.OutputTokens),
+				strconv.FormatFloat(r.CostUSD, 'f', 4, 64),
+				strconv.FormatInt(r.AvgLatencyMS(), 10),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "KEY\tCALLS\tERRORS\tINPUT\tOUTPUT\tCOST\tAVG LATENCY\t")
+	var total aiUsageRow
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t$%.4f\t%dms\t\n", r.Key, r.Calls, r.Errors, r.InputTokens, r.OutputTokens, r.CostUSD, r.AvgLatencyMS())
+		total.Calls += r.Calls
+		total.Errors += r.Errors
+		total.InputTokens += r.InputTokens
+		total.OutputTokens += r.OutputTokens
+		total.CostUSD += r.CostUSD
+		total.LatencyMS += r.LatencyMS
+	}
+	fmt.Fprintf(tw, "TOTAL\t%d\t%d\t%d\t%d\t$%.4f\t%dms\t\n", total.Calls, total.Errors, total.InputTokens, total.OutputTokens, total.CostUSD, total.AvgLatencyMS())
+	return tw.Flush()
+}
+
+// writeAICalls は呼び出しを1件ずつCSV・JSONLで書き込む（tableの場合はCSV）
+func writeAICalls(w io.Writer, format string, calls []ai.Call) error {
+	if format == "jsonl" {
+		return writeJSONLines(w, len(calls), func(i int) interface{} { return calls[i] })
+	}
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"at", "source", "tweet_ids", "model", "input_tokens", "output_tokens", "cost_usd", "latency_ms", "outcome", "error"})
+	for _, c := range calls {
+		cw.Write([]string{
+			formatExportTime(c.At),
+			c.Source,
+			strings.Join(c.TweetIDs, " "),
+			c.Model,
+			strconv.Itoa(c.InputTokens),
+			strconv.Itoa(c.OutputTokens),
+			strconv.FormatFloat(c.CostUSD, 'f', 6, 64),
+			strconv.FormatInt(c.LatencyMS, 10),
+			c.Outcome,
+			c.Error,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
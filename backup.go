@@ -0,0 +1,458 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/Minatonton/x-crawler/internal/config"
+	"github.com/Minatonton/x-crawler/internal/storage"
+)
+
+// snapshotVersion はスナップショットの形式のバージョン
+const snapshotVersion = 1
+
+// snapshotManifest はスナップショットの manifest.json
+type snapshotManifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Backend   string    `json:"backend"` // 作成時の storage.backend（復元先は別のバックエンドでもよい）
+}
+
+// snapshotSeen は state/seen.jsonl の1行
+type snapshotSeen struct {
+	TweetID string `json:"tweet_id"`
+	SeenAt  int64  `json:"seen_at"`
+}
+
+// snapshotCheckpoint は state/checkpoints.jsonl の1行
+type snapshotCheckpoint struct {
+	Source  string `json:"source"`
+	TweetID string `json:"tweet_id"`
+}
+
+// snapshotFiles はスナップショットに含める状態ファイル（files/{名前} -> 設定のパス）
+func snapshotFiles(cfg *config.Config) map[string]string {
+	return map[string]string{
+		"feedback.json":    cfg.Feedback.File,
+		"sentiment.json":   cfg.Sentiment.File,
+		"stats.json":       cfg.Stats.File,
+		"analyses.json":    cfg.Analyses.File,
+		"commands.json":    cfg.Commands.File,
+		"slack_queue.json": cfg.Slack.QueueFile,
+	}
+}
+
+// runBackup は全ての状態（既読ID・チェックポイント・アーカイブ・フィードバックなど）を1つの圧縮ファイルに保存する
+// 既読IDなどは保存先のバックエンドに関係ない形式で保存するため、別のバックエンドにも復元できる
+//
+//	x-crawler backup -out x-crawler-20240101.tar.gz
+func runBackup(args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	configPath := fs.String("config", defaultConfigPath, "設定ファイルのパス")
+	seenPath := fs.String("seen", defaultSeenTweetsPath, "既読ツイートファイルのパス（storage.backend: json の場合）")
+	outPath := fs.String("out", "", "スナップショットの保存先（省略時は x-crawler-YYYYMMDD-HHMMSS.tar.gz）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *outPath == "" {
+		*outPath = "x-crawler-" + time.Now().Format("20060102-150405") + ".tar.gz"
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := setupEncryption(cfg); err != nil {
+		return err
+	}
+	state, err := openStorage(cfg, *seenPath)
+	if err != nil {
+		return err
+	}
+	defer state.Close()
+
+	f, err := os.Create(*outPath)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	manifest, _ := json.MarshalIndent(snapshotManifest{Version: snapshotVersion, CreatedAt: time.Now(), Backend: cfg.Storage.Backend}, "", "  ")
+	if err := writeTarFile(tw, "manifest.json", manifest); err != nil {
+		return err
+	}
+
+	// 既読ID
+	seen, ok := state.(storage.SeenMigrator)
+	if !ok {
+		return fmt.Errorf("storage backend %s does not support backup", cfg.Storage.Backend)
+	}
+	entries, err := seen.SeenEntries()
+	if err != nil {
+		return fmt.Errorf("failed to read seen tweets: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return storage.NewerTweetID(entries[j].TweetID, entries[i].TweetID) })
+	if err := writeTarLines(tw, "state/seen.jsonl", len(entries), func(i int) interface{} {
+		return snapshotSeen{TweetID: entries[i].TweetID, SeenAt: entries[i].SeenAt.Unix()}
+	}); err != nil {
+		return err
+	}
+
+	// チェックポイント
+	var checkpoints []storage.Checkpoint
+	if lister, ok := state.(storage.CheckpointLister); ok {
+		if checkpoints, err = lister.Checkpoints(); err != nil {
+			return fmt.Errorf("failed to read checkpoints: %w", err)
+		}
+	}
+	if err := writeTarLines(tw, "state/checkpoints.jsonl", len(checkpoints), func(i int) interface{} {
+		return snapshotCheckpoint{Source: checkpoints[i].Source, TweetID: checkpoints[i].TweetID}
+	}); err != nil {
+		return err
+	}
+
+	// 保存先に記録されたツイート・AI分析結果（SQLite）
+	var tweets []storage.ArchivedTweet
+	var records []storage.AnalysisRecord
+	if reader, ok := state.(storage.ArchiveReader); ok {
+		if tweets, err = reader.Tweets(time.Time{}, time.Time{}); err != nil {
+			return fmt.Errorf("failed to read tweets: %w", err)
+		}
+		if records, err = reader.Analyses(time.Time{}, time.Time{}); err != nil {
+			return fmt.Errorf("failed to read analyses: %w", err)
+		}
+	}
+	if err := writeTarLines(tw, "state/tweets.jsonl", len(tweets), func(i int) interface{} { return tweets[i] }); err != nil {
+		return err
+	}
+	if err := writeTarLines(tw, "state/analyses.jsonl", len(records), func(i int) interface{} { return records[i] }); err != nil {
+		return err
+	}
+
+	// 保存先に記録された通知・フィードバック・事後の値動き（SQLite）
+	var notifications []storage.NotificationRecord
+	if backend, ok := state.(storage.FeedbackBackend); ok {
+		if notifications, err = backend.Notifications(time.Time{}, time.Time{}); err != nil {
+			return fmt.Errorf("failed to read feedback: %w", err)
+		}
+	}
+	if err := writeTarLines(tw, "state/notifications.jsonl", len(notifications), func(i int) interface{} { return notifications[i] }); err != nil {
+		return err
+	}
+
+	// 状態ファイル
+	files := 0
+	for name, p := range snapshotFiles(cfg) {
+		if p == "" {
+			continue
+		}
+		data, err := os.ReadFile(p)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		if err := writeTarFile(tw, "files/"+name, data); err != nil {
+			return err
+		}
+		files++
+	}
+
+	// アーカイブ (storage.archive) とAI呼び出しの記録 (storage.ai_ledger)
+	for _, d := range []struct{ prefix, dir string }{
+		{"archive/", cfg.Storage.Archive.Dir},
+		{"ai_calls/", cfg.Storage.AILedger.Dir},
+	} {
+		if d.dir == "" {
+			continue
+		}
+		matches, _ := filepath.Glob(filepath.Join(d.dir, "*.jsonl"))
+		for _, m := range matches {
+			data, err := os.ReadFile(m)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", m, err)
+			}
+			if err := writeTarFile(tw, d.prefix+filepath.Base(m), data); err != nil {
+				return err
+			}
+			files++
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	log.Printf("Backed up %d seen tweets, %d checkpoints, %d tweets, %d analyses and %d files to %s",
+		len(entries), len(checkpoints), len(tweets), len(records), files, *outPath)
+	return nil
+}
+
+// runRestore はbackupで作成したスナップショットを設定の保存先に復元する
+// 既読ID・チェックポイント・記録は現在の保存先に追加し、状態ファイルは -force の場合だけ上書きする
+//
+//	x-crawler restore -in x-crawler-20240101.tar.gz [-force]
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	configPath := fs.String("config", defaultConfigPath, "設定ファイルのパス")
+	seenPath := fs.String("seen", defaultSeenTweetsPath, "既読ツイートファイルのパス（storage.backend: json の場合）")
+	inPath := fs.String("in", "", "復元するスナップショット")
+	force := fs.Bool("force", false, "既存の状態ファイル・アーカイブを上書きする")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *inPath == "" {
+		return fmt.Errorf("-in is required")
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := setupEncryption(cfg); err != nil {
+		return err
+	}
+
+	contents, err := readSnapshot(*inPath)
+	if err != nil {
+		return err
+	}
+	var manifest snapshotManifest
+	if err := json.Unmarshal(contents["manifest.json"], &manifest); err != nil {
+		return fmt.Errorf("invalid snapshot: missing manifest.json")
+	}
+	if manifest.Version > snapshotVersion {
+		return fmt.Errorf("snapshot version %d is not supported (expected %d or earlier)", manifest.Version, snapshotVersion)
+	}
+
+	// 上書きする状態ファイルを先に確認（途中まで復元した状態で止まらないようにする）
+	files := snapshotFiles(cfg)
+	writes := make(map[string][]byte)
+	for name, data := range contents {
+		var dst string
+		switch {
+		case strings.HasPrefix(name, "files/"):
+			dst = files[strings.TrimPrefix(name, "files/")]
+		case strings.HasPrefix(name, "archive/"):
+			dst = filepath.Join(cfg.Storage.Archive.Dir, path.Base(name))
+		case strings.HasPrefix(name, "ai_calls/"):
+			dst = filepath.Join(cfg.Storage.AILedger.Dir, path.Base(name))
+		default:
+			continue
+		}
+		if dst == "" {
+			log.Printf("Skipping %s (no path configured)", name)
+			continue
+		}
+		if _, err := os.Stat(dst); err == nil && !*force {
+			return fmt.Errorf("%s already exists (use -force to overwrite)", dst)
+		}
+		writes[dst] = data
+	}
+
+	state, err := openStorage(cfg, *seenPath)
+	if err != nil {
+		return err
+	}
+	defer state.Close()
+
+	var seen []storage.SeenEntry
+	if err := readLines(contents["state/seen.jsonl"], func(line []byte) error {
+		var s snapshotSeen
+		if err := json.Unmarshal(line, &s); err != nil {
+			return err
+		}
+		seen = append(seen, storage.SeenEntry{TweetID: s.TweetID, SeenAt: time.Unix(s.SeenAt, 0)})
+		return nil
+	}); err != nil {
+		return fmt.Errorf("invalid state/seen.jsonl: %w", err)
+	}
+	importer, ok := state.(storage.SeenMigrator)
+	if !ok {
+		return fmt.Errorf("storage backend %s does not support restore", cfg.Storage.Backend)
+	}
+	if err := importer.ImportSeen(seen); err != nil {
+		return fmt.Errorf("failed to restore seen tweets: %w", err)
+	}
+
+	checkpoints := 0
+	if cs, ok := state.(storage.CheckpointStore); ok {
+		if err := readLines(contents["state/checkpoints.jsonl"], func(line []byte) error {
+			var cp snapshotCheckpoint
+			if err := json.Unmarshal(line, &cp); err != nil {
+				return err
+			}
+			checkpoints++
+			return cs.SetCheckpoint(cp.Source, cp.TweetID)
+		}); err != nil {
+			return fmt.Errorf("failed to restore checkpoints: %w", err)
+		}
+	}
+
+	records := 0
+	if archive, ok := state.(storage.ArchiveStore); ok {
+		if err := readLines(contents["state/tweets.jsonl"], func(line []byte) error {
+			var t storage.ArchivedTweet
+			if err := json.Unmarshal(line, &t); err != nil {
+				return err
+			}
+			t.Tweet.Username = t.Username
+			return archive.RecordTweet(t.Tweet, t.Source)
+		}); err != nil {
+			return fmt.Errorf("failed to restore tweets: %w", err)
+		}
+		if err := readLines(contents["state/analyses.jsonl"], func(line []byte) error {
+			var rec storage.AnalysisRecord
+			if err := json.Unmarshal(line, &rec); err != nil {
+				return err
+			}
+			records++
+			return archive.RecordAnalysis(rec)
+		}); err != nil {
+			return fmt.Errorf("failed to restore analyses: %w", err)
+		}
+	}
+	if backend, ok := state.(storage.FeedbackBackend); ok {
+		var notifications []storage.NotificationRecord
+		if err := readLines(contents["state/notifications.jsonl"], func(line []byte) error {
+			var rec storage.NotificationRecord
+			if err := json.Unmarshal(line, &rec); err != nil {
+				return err
+			}
+			notifications = append(notifications, rec)
+			return nil
+		}); err != nil {
+			return fmt.Errorf("invalid state/notifications.jsonl: %w", err)
+		}
+		if err := backend.SaveNotifications(notifications); err != nil {
+			return fmt.Errorf("failed to restore feedback: %w", err)
+		}
+	}
+
+	for dst, data := range writes {
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, data, 0644); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", dst, err)
+		}
+	}
+
+	if err := state.Save(); err != nil {
+		return fmt.Errorf("failed to save %s storage: %w", cfg.Storage.Backend, err)
+	}
+	log.Printf("Restored %d seen tweets, %d checkpoints, %d analyses and %d files from %s (created %s)",
+		len(seen), checkpoints, records, len(writes), *inPath, manifest.CreatedAt.Format(time.RFC3339))
+	return nil
+}
+
+// writeTarFile はスナップショットに1つのファイルを書き込む
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// writeTarLines はn件を1行ずつJSONにしたファイルをスナップショットに書き込む
+func writeTarLines(tw *tar.Writer, name string, n int, item func(i int) interface{}) error {
+	var buf bytes.Buffer
+	if err := writeJSONLines(&buf, n, item); err != nil {
+		return err
+	}
+	// 暗号化の鍵が設定されていれば状態ファイルと同じく暗号化する
+	data, err := storage.EncryptFile(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to encrypt %s: %w", name, err)
+	}
+	return writeTarFile(tw, name, data)
+}
+
+// readSnapshot はスナップショットの全てのファイルを読み込む（ファイル名 -> 内容）
+func readSnapshot(p string) (map[string][]byte, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	defer gz.Close()
+
+	contents := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot: %w", err)
+		}
+		name := path.Clean(hdr.Name)
+		// state/ は復元時に読むため復号する（files/, archive/ は暗号化されたまま書き戻す）
+		if strings.HasPrefix(name, "state/") {
+			if data, err = storage.DecryptFile(data); err != nil {
+				return nil, fmt.Errorf("failed to decrypt %s: %w", name, err)
+			}
+		}
+		contents[name] = data
+	}
+	return contents, nil
+}
+
+// readLines はJSONLの空でない行ごとにfnを呼ぶ
+func readLines(data []byte, fn func(line []byte) error) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Bytes(); len(line) > 0 {
+			if err := fn(line); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+
+	"github.com/Minatonton/x-crawler/internal/evaluate"
+)
+
+// runEvaluate は保存済みツイートを現在のプロンプト・モデルで再分析し、ベースラインとの差分レポートを出力する
+//
+//	x-crawler evaluate -corpus analyses.json [-baseline prev.json] [-out results.json] [-report report.md]
+func runEvaluate(args []string) error {
+	fs := flag.NewFlagSet("evaluate", flag.ExitOnError)
+	configPath := fs.String("config", defaultConfigPath, "設定ファイルのパス")
+	corpusPath := fs.String("corpus", "", "評価用ツイート（analyses.json、アーカイブのディレクトリ、または期待ラベル付きのJSON配列）")
+	baselinePath := fs.String("baseline", "", "比較するベースラインの評価結果（省略時はコーパスに保存された分析）")
+	outPath := fs.String("out", "", "今回の評価結果の保存先（次回のベースラインに使える）")
+	reportPath := fs.String("report", "", "差分レポート (Markdown) の保存先（省略時は標準出力）")
+	scoreDelta := fs.Int("score-delta", 20, "食い違いとみなすスコア差")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *corpusPath == "" {
+		return fmt.Errorf("-corpus is required")
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := setupEncryption(cfg); err != nil {
+		return err
+	}
+	if !cfg.AI.Enabled {
+		return fmt.Errorf("ai.enabled must be true to run an evaluation")
+	}
+
+	cases, baseline, err := evaluate.LoadCorpus(*corpusPath)
+	if err != nil {
+		return err
+	}
+	if *baselinePath != "" {
+		baseline, err = evaluate.LoadResults(*baselinePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := resolveModels(cfg); err != nil {
+		return fmt.Errorf("invalid AI model: %w", err)
+	}
+	usage := newUsageTracker(cfg)
+	analyzer, err := newAnalyzer(cfg, usage, nil)
+	if err != nil {
+		return fmt.Errorf("failed to initialize AI analyzer: %w", err)
+	}
+	if analyzer == nil {
+		return fmt.Errorf("no AI provider is available")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Evaluating %d tweets...", len(cases))
+	results := evaluate.Run(ctx, analyzer, cases, cfg.AI.BatchSize)
+	logUsage(usage)
+
+	if *outPath != "" {
+		if err := evaluate.SaveResults(*outPath, results); err != nil {
+			return err
+		}
+		log.Printf("Saved evaluation results to %s", *outPath)
+	}
+
+	report := evaluate.BuildReport(cases, results, baseline, cfg.AI.MinScore, *scoreDelta).Markdown()
+	if *reportPath == "" {
+		fmt.Print(report)
+		return nil
+	}
+	if err := os.WriteFile(*reportPath, []byte(report), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	log.Printf("Saved evaluation report to %s", *reportPath)
+	return nil
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/config"
+	"github.com/Minatonton/x-crawler/internal/storage"
+)
+
+// notifiedDecisions は -type notifications で出力する処理（通知したもの）
+var notifiedDecisions = map[string]bool{
+	storage.DecisionNotified: true,
+	storage.DecisionMaybe:    true,
+	storage.DecisionDigest:   true,
+	storage.DecisionUpdated:  true,
+}
+
+// runExport は保存済みのツイート・AI分析結果・通知・フィードバックをCSVまたはJSONLで出力する
+//
+//	x-crawler export [-type tweets|analyses|notifications|feedback] [-format csv|jsonl] [-since 2024-01-01] [-until 2024-01-31] [-ticker NVDA] [-out file]
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	configPath := fs.String("config", defaultConfigPath, "設定ファイルのパス")
+	kind := fs.String("type", "analyses", "出力する記録: tweets, analyses (通知しなかったものも含む), notifications, feedback (評価と事後の値動き)")
+	format := fs.String("format", "csv", "出力形式: csv, jsonl")
+	sinceFlag := fs.String("since", "", "この日時以降 (2006-01-02 または RFC3339)")
+	untilFlag := fs.String("until", "", "この日時より前 (日付のみの場合はその日を含む)")
+	tickerFlag := fs.String("ticker", "", "銘柄で絞り込む（カンマ区切りで複数指定）")
+	archiveDir := fs.String("archive", "", "読み込むアーカイブのディレクトリ（省略時は設定の storage.archive または SQLite）")
+	outPath := fs.String("out", "", "出力先のファイル（省略時は標準出力）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	switch *kind {
+	case "tweets", "analyses", "notifications", "feedback":
+	default:
+		return fmt.Errorf("invalid -type %q (expected tweets, analyses, notifications or feedback)", *kind)
+	}
+	switch *format {
+	case "csv", "jsonl":
+	default:
+		return fmt.Errorf("invalid -format %q (expected csv or jsonl)", *format)
+	}
+	since, err := parseExportTime(*sinceFlag, false)
+	if err != nil {
+		return fmt.Errorf("invalid -since: %w", err)
+	}
+	until, err := parseExportTime(*untilFlag, true)
+	if err != nil {
+		return fmt.Errorf("invalid -until: %w", err)
+	}
+	tickers := make(map[string]bool)
+	for _, t := range strings.Split(*tickerFlag, ",") {
+		if t = strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(t), "$")); t != "" {
+			tickers[t] = true
+		}
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := setupEncryption(cfg); err != nil {
+		return err
+	}
+	var reader storage.ArchiveReader
+	var feedbackRecords []storage.NotificationRecord
+	if *kind == "feedback" {
+		if feedbackRecords, err = readFeedback(cfg, since, until); err != nil {
+			return err
+		}
+	} else {
+		var closeReader func()
+		if reader, closeReader, err = openArchiveReader(cfg, *archiveDir); err != nil {
+			return err
+		}
+		defer closeReader()
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+
+	var n int
+	switch *kind {
+	case "feedback":
+		var filtered []storage.NotificationRecord
+		for _, rec := range feedbackRecords {
+			if matchTickers(tickers, rec.Tickers) {
+				filtered = append(filtered, rec)
+			}
+		}
+		n = len(filtered)
+		if err := writeExportFeedback(w, *format, filtered, cfg.Feedback.Outcomes.Hours); err != nil {
+			return err
+		}
+	case "tweets":
+		tweets, err := reader.Tweets(since, until)
+		if err != nil {
+			return fmt.Errorf("failed to read archived tweets: %w", err)
+		}
+		var filtered []storage.ArchivedTweet
+		for _, t := range tweets {
+			if matchTickers(tickers, ai.ExtractCashtags(t.Tweet.Text)) {
+				filtered = append(filtered, t)
+			}
+		}
+		n = len(filtered)
+		if err := writeExportTweets(w, *format, filtered); err != nil {
+			return err
+		}
+	default:
+		records, err := reader.Analyses(since, until)
+		if err != nil {
+			return fmt.Errorf("failed to read archived analyses: %w", err)
+		}
+		var filtered []storage.AnalysisRecord
+		for _, rec := range records {
+			if *kind == "notifications" && !notifiedDecisions[rec.Decision] {
+				continue
+			}
+			if matchTickers(tickers, rec.Analysis.Tickers) {
+				filtered = append(filtered, rec)
+			}
+		}
+		n = len(filtered)
+		if err := writeExportAnalyses(w, *format, filtered); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	if *outPath != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d %s to %s\n", n, *kind, *outPath)
+	}
+	return nil
+}
+
+// openArchiveReader は保存済みのツイート・分析結果の読み込み元を開く
+// dirの指定、storage.archive、SQLiteの保存先の順に使う
+func openArchiveReader(cfg *config.Config, dir string) (storage.ArchiveReader, func(), error) {
+	if dir == "" && cfg.Storage.Archive.Enabled {
+		dir = cfg.Storage.Archive.Dir
+	}
+	if dir != "" {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, nil, fmt.Errorf("failed to open archive: %w", err)
+		}
+		archive, err := storage.NewArchive(dir, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		return archive, func() {}, nil
+	}
+	if cfg.Storage.Backend == "sqlite" {
+		db, err := storage.OpenSQLite(cfg.Storage.SQLite.File, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, func() { db.Close() }, nil
+	}
+	return nil, nil, fmt.Errorf("no archive to export (enable storage.archive, use storage.backend: sqlite, or pass -archive)")
+}
+
+// readFeedback は保存先（SQLite）または feedback.file から[since, until)に通知した記録を読み込む
+func readFeedback(cfg *config.Config, since, until time.Time) ([]storage.NotificationRecord, error) {
+	if cfg.Storage.Backend == "sqlite" {
+		db, err := storage.OpenSQLite(cfg.Storage.SQLite.File, 0)
+		if err != nil {
+			return nil, err
+		}
+		defer db.Close()
+		return db.Notifications(since, until)
+	}
+	if _, err := os.Stat(cfg.Feedback.File); err != nil {
+		return nil, fmt.Errorf("no feedback to export (enable feedback and run the crawler first): %w", err)
+	}
+	store, err := storage.NewFeedbackStore(cfg.Feedback.File)
+	if err != nil {
+		return nil, err
+	}
+	return store.Records(since, until)
+}
+
+// parseExportTime は日付 (2006-01-02) またはRFC3339の日時を解釈する（空の場合はゼロ値）
+// endOfDayがtrueで日付のみの場合は翌日の0時を返す（その日を含める）
+func parseExportTime(s string, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// matchTickers はtickersのいずれかを含むかを返す（絞り込みがない場合はtrue）
+func matchTickers(filter map[string]bool, tickers []string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, t := range tickers {
+		if filter[strings.ToUpper(strings.TrimPrefix(t, "$"))] {
+			return true
+		}
+	}
+	return false
+}
+
+// writeExportTweets はツイートをCSVまたはJSONLで書き込む
+func writeExportTweets(w io.Writer, format string, tweets []storage.ArchivedTweet) error {
+	if format == "jsonl" {
+		return writeJSONLines(w, len(tweets), func(i int) interface{} { return tweets[i] })
+	}
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"tweet_id", "username", "source", "created_at", "fetched_at", "cashtags", "text", "url"})
+	for _, t := range tweets {
+		cw.Write([]string{
+			t.Tweet.ID,
+			t.Username,
+			t.Source,
+			formatExportTime(t.Tweet.CreatedAt),
+			formatExportTime(t.FetchedAt),
+			strings.Join(ai.ExtractCashtags(t.Tweet.Text), " "),
+			t.Tweet.Text,
+			fmt.Sprintf("https://x.com/%s/status/%s", t.Username, t.Tweet.ID),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeExportAnalyses は分析結果をCSVまたはJSONLで書き込む
+func writeExportAnalyses(w io.Writer, format string, records []storage.AnalysisRecord) error {
+	if format == "jsonl" {
+		return writeJSONLines(w, len(records), func(i int) interface{} { return records[i] })
+	}
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"tweet_id", "username", "source", "created_at", "analyzed_at", "decision",
+		"score", "confidence", "category", "urgency", "sentiment", "tickers", "summary", "text", "url"})
+	for _, rec := range records {
+		a := rec.Analysis
+		cw.Write([]string{
+			rec.TweetID,
+			rec.Username,
+			rec.Source,
+			formatExportTime(rec.CreatedAt),
+			formatExportTime(rec.AnalyzedAt),
+			rec.Decision,
+			strconv.Itoa(a.Score),
+			strconv.Itoa(a.Confidence),
+			a.Category,
+			a.Urgency,
+			a.Sentiment,
+			strings.Join(a.Tickers, " "),
+			a.Summary,
+			rec.Text,
+			fmt.Sprintf("https://x.com/%s/status/%s", rec.Username, rec.TweetID),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeExportFeedback は通知記録・評価・事後の値動きをCSVまたはJSONLで書き込む
+// CSVの値動きは hours ごとの銘柄の平均変化率 (%)
+func writeExportFeedback(w io.Writer, format string, records []storage.NotificationRecord, hours []int) error {
+	if format == "jsonl" {
+		return writeJSONLines(w, len(records), func(i int) interface{} { return records[i] })
+	}
+	cw := csv.NewWriter(w)
+	header := []string{"tweet_id", "username", "source", "notified_at", "score", "category", "tickers", "label", "labeled_at", "labeled_by"}
+	for _, h := range hours {
+		header = append(header, fmt.Sprintf("change_pct_%dh", h))
+	}
+	cw.Write(append(header, "text", "url"))
+	for _, rec := range records {
+		row := []string{
+			rec.TweetID,
+			rec.Username,
+			rec.Source,
+			formatExportTime(rec.NotifiedAt),
+			strconv.Itoa(rec.Score),
+			rec.Category,
+			strings.Join(rec.Tickers, " "),
+			rec.Label,
+			formatExportTime(rec.LabeledAt),
+			rec.LabeledBy,
+		}
+		for _, h := range hours {
+			var sum float64
+			var count int
+			for _, o := range rec.Outcomes {
+				if o.Hours == h {
+					sum += o.ChangePct
+					count++
+				}
+			}
+			var change string
+			if count > 0 {
+				change = strconv.FormatFloat(sum/float64(count), 'f', 2, 64)
+			}
+			row = append(row, change)
+		}
+		cw.Write(append(row, rec.Text, fmt.Sprintf("https://x.com/%s/status/%s", rec.Username, rec.TweetID)))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeJSONLines はn件を1行ずつJSONで書き込む
+func writeJSONLines(w io.Writer, n int, item func(i int) interface{}) error {
+	enc := json.NewEncoder(w)
+	for i := 0; i < n; i++ {
+		if err := enc.Encode(item(i)); err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+	}
+	return nil
+}
+
+// formatExportTime はスプレッドシートで扱いやすいRFC3339で出力（ゼロ値は空）
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,44 @@
+package ai
+
+import (
+	"context"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// Analyzer はツイートを分析するバックエンドの共通インターフェース
+type Analyzer interface {
+	// Analyze はツイートを分析する。traderInfo には投稿者やキーワードなど取得元の情報を渡す
+	Analyze(ctx context.Context, tweet twitter.Tweet, traderInfo string) (*Analysis, error)
+}
+
+// Analysis はAI分析結果
+type Analysis struct {
+	Score      int      `json:"score"`
+	Confidence int      `json:"confidence"` // 分析自体の確信度 (0-100)
+	Category   string   `json:"category"`
+	Sentiment  string   `json:"sentiment"`
+	Tickers    []string `json:"tickers"`
+	Summary    string   `json:"summary"`
+	KeyPoints  []string `json:"key_points"`
+	Urgency    string   `json:"urgency"`
+	Reasoning  string   `json:"reasoning"`
+	Variant    string   `json:"variant,omitempty"`  // A/Bテストのグループ名（実験時のみ）
+	CostUSD    float64  `json:"cost_usd,omitempty"` // 推定AIコスト（バッチで分析した場合は件数で按分）
+	Cached     bool     `json:"cached,omitempty"`   // 同一本文の分析結果を再利用した（AIを呼んでいない）
+}
+
+// clone はスライスを含めたAnalysisのコピーを返す
+func (a Analysis) clone() Analysis {
+	a.Tickers = append([]string(nil), a.Tickers...)
+	a.KeyPoints = append([]string(nil), a.KeyPoints...)
+	return a
+}
+
+// 対応しているAIプロバイダー
+const (
+	ProviderAnthropic = "anthropic"
+	ProviderOpenAI    = "openai"
+)
+
+var _ Analyzer = (*Filter)(nil)
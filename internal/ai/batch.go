@@ -0,0 +1,82 @@
+package ai
+
+import (
+	"context"
+	"log"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// Item は分析対象のツイートと取得元情報
+type Item struct {
+	Tweet      twitter.Tweet
+	TraderInfo string
+}
+
+// Result は1件分の分析結果
+type Result struct {
+	Analysis *Analysis
+	Err      error
+}
+
+// BatchAnalyzer は複数ツイートを1回のリクエストで分析できるAnalyzer
+type BatchAnalyzer interface {
+	Analyzer
+	// AnalyzeBatch はitemsと同じ順序で分析結果を返す
+	// 応答に含まれなかった項目はnilになる（エラーを返す場合も分析できた項目は返してよい）
+	AnalyzeBatch(ctx context.Context, items []Item) ([]*Analysis, error)
+}
+
+// AnalyzeAll はitemsを分析する
+// analyzerがBatchAnalyzerでbatchSizeが2以上の場合はまとめて分析し、
+// バッチ応答が不正だった項目は1件ずつの分析にフォールバックする
+func AnalyzeAll(ctx context.Context, analyzer Analyzer, items []Item, batchSize int) []Result {
+	results := make([]Result, len(items))
+
+	ba, ok := analyzer.(BatchAnalyzer)
+	if !ok || batchSize < 2 {
+		for i, item := range items {
+			analysis, err := analyzer.Analyze(WithCallTweets(ctx, item.Tweet.ID), item.Tweet, item.TraderInfo)
+			results[i] = Result{Analysis: analysis, Err: err}
+		}
+		return results
+	}
+
+	for start := 0; start < len(items); start += batchSize {
+		end := start + batchSize
+		if end > len(items) {
+			end = len(items)
+		}
+		chunk := items[start:end]
+
+		var analyses []*Analysis
+		if len(chunk) > 1 {
+			var err error
+			analyses, err = ba.AnalyzeBatch(WithCallTweets(ctx, itemIDs(chunk)...), chunk)
+			if err != nil {
+				// 分析できた項目は使い、残りを1件ずつ分析する
+				log.Printf("Batch AI analysis failed for %d tweets, falling back to per-tweet analysis: %v", len(chunk), err)
+			}
+		}
+
+		for i, item := range chunk {
+			if i < len(analyses) && analyses[i] != nil {
+				results[start+i] = Result{Analysis: analyses[i]}
+				continue
+			}
+			analysis, err := analyzer.Analyze(WithCallTweets(ctx, item.Tweet.ID), item.Tweet, item.TraderInfo)
+			results[start+i] = Result{Analysis: analysis, Err: err}
+		}
+	}
+
+	return results
+}
+
+// itemIDs はitemsのツイートIDを返す
+func itemIDs(items []Item) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.Tweet.ID
+	}
+	return ids
+}
@@ -0,0 +1,160 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// Cache は正規化した本文のハッシュをキーに分析結果を再利用するAnalyzer
+type Cache struct {
+	analyzer Analyzer
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// cacheEntry はキャッシュされた分析結果
+type cacheEntry struct {
+	analysis  Analysis
+	expiresAt time.Time
+}
+
+// NewCache は新しい分析キャッシュを作成
+func NewCache(analyzer Analyzer, ttl time.Duration) *Cache {
+	return &Cache{
+		analyzer: analyzer,
+		ttl:      ttl,
+		entries:  make(map[string]cacheEntry),
+	}
+}
+
+// Analyze はキャッシュにヒットした場合は保存済みの結果を、そうでなければ分析して結果を保存
+func (c *Cache) Analyze(ctx context.Context, tweet twitter.Tweet, traderInfo string) (*Analysis, error) {
+	key := cacheKey(tweet, traderInfo)
+	if key == "" {
+		return c.analyzer.Analyze(ctx, tweet, traderInfo)
+	}
+
+	if analysis, ok := c.get(key); ok {
+		log.Printf("AI analysis cache hit for tweet %s", tweet.ID)
+		return analysis, nil
+	}
+
+	analysis, err := c.analyzer.Analyze(ctx, tweet, traderInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(key, analysis)
+	return analysis, nil
+}
+
+// AnalyzeBatch はキャッシュにない項目だけをまとめて分析
+func (c *Cache) AnalyzeBatch(ctx context.Context, items []Item) ([]*Analysis, error) {
+	analyses := make([]*Analysis, len(items))
+	keys := make([]string, len(items))
+
+	var missIdx []int
+	var misses []Item
+	for i, item := range items {
+		keys[i] = cacheKey(item.Tweet, item.TraderInfo)
+		if analysis, ok := c.get(keys[i]); ok {
+			analyses[i] = analysis
+			continue
+		}
+		missIdx = append(missIdx, i)
+		misses = append(misses, item)
+	}
+
+	ba, ok := c.analyzer.(BatchAnalyzer)
+	if len(misses) == 0 || !ok {
+		return analyses, nil
+	}
+
+	// エラーの場合も分析できた項目は使う
+	results, err := ba.AnalyzeBatch(ctx, misses)
+	for j, analysis := range results {
+		if j >= len(missIdx) || analysis == nil {
+			continue
+		}
+		i := missIdx[j]
+		if keys[i] != "" {
+			c.put(keys[i], analysis)
+		}
+		analyses[i] = analysis
+	}
+
+	return analyses, err
+}
+
+// StartCycle はラップしたAnalyzerにサイクル開始を伝え、期限切れエントリを削除
+func (c *Cache) StartCycle() {
+	if ca, ok := c.analyzer.(CycleAware); ok {
+		ca.StartCycle()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// get は有効期限内のキャッシュを取得（呼び出し側で変更できるようコピーを返す）
+// 再利用した結果はAIを呼んでいないため、コストとA/Bテストのグループを空にして再利用済みと記録
+func (c *Cache) get(key string) (*Analysis, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	analysis := entry.analysis.clone()
+	analysis.CostUSD = 0
+	analysis.Variant = ""
+	analysis.Cached = true
+	return &analysis, true
+}
+
+// put は分析結果をキャッシュに保存
+func (c *Cache) put(key string, analysis *Analysis) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := analysis.clone()
+	entry.Cached = false
+	c.entries[key] = cacheEntry{
+		analysis:  entry,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
+
+// cacheKey はツイートのキャッシュのキーを返す（キャッシュしない場合は空文字）
+// 本文が短い・URLだけのツイートや、画像・リンク先の本文も分析するツイートは本文だけでは同じ内容か判定できない
+// 優先度などの取得元情報もプロンプトに含まれるため、同じ本文でも取得元が違えば別のキーにする
+func cacheKey(tweet twitter.Tweet, traderInfo string) string {
+	if len(tweet.Media) > 0 || (tweet.Attachments != nil && len(tweet.Attachments.MediaKeys) > 0) || len(tweet.Articles) > 0 {
+		return ""
+	}
+	hash := twitter.ContentHash(tweet.Text)
+	if hash == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(traderInfo))
+	return hash + ":" + hex.EncodeToString(sum[:8])
+}
+
+var (
+	_ BatchAnalyzer = (*Cache)(nil)
+	_ CycleAware    = (*Cache)(nil)
+)
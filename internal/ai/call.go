@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"context"
+	"time"
+)
+
+// 呼び出しの結果
+const (
+	CallOK    = "ok"
+	CallError = "error"
+)
+
+// Call は1回のAI API呼び出しの記録（使用量の監査・予算の集計用）
+type Call struct {
+	At           time.Time `json:"at"`
+	TweetIDs     []string  `json:"tweet_ids,omitempty"` // 分析したツイート（バッチの場合は複数）
+	Source       string    `json:"source,omitempty"`    // 取得元（トレーダー・キーワード）
+	Model        string    `json:"model"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens"`
+	CostUSD      float64   `json:"cost_usd"`
+	LatencyMS    int64     `json:"latency_ms"`
+	Outcome      string    `json:"outcome"` // ok, error
+	Error        string    `json:"error,omitempty"`
+}
+
+// callInfoKey はcontextに呼び出し元の情報を保存するキー
+type callInfoKey struct{}
+
+// callInfo は呼び出し元の取得元と分析するツイート
+type callInfo struct {
+	source   string
+	tweetIDs []string
+}
+
+// WithCallSource は以降のAI呼び出しの記録に取得元を付ける
+func WithCallSource(ctx context.Context, source string) context.Context {
+	info := callInfoFrom(ctx)
+	info.source = source
+	return context.WithValue(ctx, callInfoKey{}, info)
+}
+
+// WithCallTweets は以降のAI呼び出しの記録に分析するツイートIDを付ける
+func WithCallTweets(ctx context.Context, tweetIDs ...string) context.Context {
+	info := callInfoFrom(ctx)
+	info.tweetIDs = tweetIDs
+	return context.WithValue(ctx, callInfoKey{}, info)
+}
+
+// callInfoFrom はcontextの呼び出し元の情報を返す
+func callInfoFrom(ctx context.Context) callInfo {
+	if ctx == nil {
+		return callInfo{}
+	}
+	info, _ := ctx.Value(callInfoKey{}).(callInfo)
+	return info
+}
@@ -0,0 +1,47 @@
+package ai
+
+// Category は分析カテゴリの定義
+type Category struct {
+	Name        string
+	Description string // プロンプトでモデルに伝える定義（空の場合は名前のみ）
+}
+
+// OtherCategory はどのカテゴリにも当てはまらない場合のカテゴリ名
+const OtherCategory = "other"
+
+// DefaultCategories は組み込みのカテゴリ体系
+var DefaultCategories = []Category{
+	{Name: "buy_signal"},
+	{Name: "sell_signal"},
+	{Name: "earnings_beat"},
+	{Name: "earnings_miss"},
+	{Name: "sec_filing"},
+	{Name: "merger_acquisition"},
+	{Name: "analyst_upgrade"},
+	{Name: "analyst_downgrade"},
+	{Name: "market_news"},
+	{Name: "executive_trade"},
+	{Name: OtherCategory},
+}
+
+// normalizeCategories は空の場合にデフォルトを使い、otherが含まれない場合は追加
+func normalizeCategories(categories []Category) []Category {
+	if len(categories) == 0 {
+		return DefaultCategories
+	}
+	for _, c := range categories {
+		if c.Name == OtherCategory {
+			return categories
+		}
+	}
+	return append(append([]Category(nil), categories...), Category{Name: OtherCategory})
+}
+
+// categoryNames はカテゴリ名の一覧を返す
+func categoryNames(categories []Category) []string {
+	names := make([]string, len(categories))
+	for i, c := range categories {
+		names[i] = c.Name
+	}
+	return names
+}
@@ -0,0 +1,103 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// ChainEntry はフォールバックチェーンの1プロバイダー
+type ChainEntry struct {
+	Name     string
+	Analyzer Analyzer
+}
+
+// Chain は複数のAnalyzerを順に試すフォールバックチェーン
+type Chain struct {
+	entries []ChainEntry
+	timeout time.Duration // プロバイダーごとのタイムアウト（0の場合は無制限）
+}
+
+// NewChain は新しいフォールバックチェーンを作成
+func NewChain(entries []ChainEntry, timeout time.Duration) *Chain {
+	return &Chain{
+		entries: entries,
+		timeout: timeout,
+	}
+}
+
+// Analyze は先頭のプロバイダーから順に分析を試み、最初に成功した結果を返す
+func (c *Chain) Analyze(ctx context.Context, tweet twitter.Tweet, traderInfo string) (*Analysis, error) {
+	var errs []error
+
+	for i, entry := range c.entries {
+		analysis, err := c.analyzeWith(ctx, entry, tweet, traderInfo)
+		if err == nil {
+			if i > 0 {
+				log.Printf("AI analysis for tweet %s succeeded with fallback provider %s", tweet.ID, entry.Name)
+			}
+			return analysis, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", entry.Name, err))
+
+		// 呼び出し元がキャンセルされた場合は後続のプロバイダーも試さない
+		if ctx.Err() != nil {
+			break
+		}
+		if i < len(c.entries)-1 {
+			log.Printf("AI provider %s failed for tweet %s, trying %s: %v",
+				entry.Name, tweet.ID, c.entries[i+1].Name, err)
+		}
+	}
+
+	return nil, fmt.Errorf("all AI providers failed: %w", errors.Join(errs...))
+}
+
+// AnalyzeBatch は先頭のプロバイダーでまとめて分析する
+// 失敗した項目はAnalyzeAllにより1件ずつチェーン全体で再分析される
+func (c *Chain) AnalyzeBatch(ctx context.Context, items []Item) ([]*Analysis, error) {
+	if len(c.entries) == 0 {
+		return nil, fmt.Errorf("no AI providers configured")
+	}
+	primary := c.entries[0]
+	ba, ok := primary.Analyzer.(BatchAnalyzer)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support batch analysis", primary.Name)
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	return ba.AnalyzeBatch(ctx, items)
+}
+
+// analyzeWith はタイムアウト付きで1プロバイダーの分析を実行
+func (c *Chain) analyzeWith(ctx context.Context, entry ChainEntry, tweet twitter.Tweet, traderInfo string) (*Analysis, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	return entry.Analyzer.Analyze(ctx, tweet, traderInfo)
+}
+
+// StartCycle はサイクル開始を各プロバイダーに伝える
+func (c *Chain) StartCycle() {
+	for _, entry := range c.entries {
+		if ca, ok := entry.Analyzer.(CycleAware); ok {
+			ca.StartCycle()
+		}
+	}
+}
+
+var (
+	_ BatchAnalyzer = (*Chain)(nil)
+	_ CycleAware    = (*Chain)(nil)
+)
@@ -0,0 +1,154 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// ControlVariant は実験で既存のプロンプト・モデルを使ったグループ名
+const ControlVariant = "control"
+
+// Experiment は一定割合のツイートを別のプロンプト・モデルで分析するA/BテストのAnalyzer
+type Experiment struct {
+	control Analyzer
+	variant Analyzer
+	name    string // 代替グループの名前
+	percent int    // 代替グループに回す割合 (0-100)
+
+	mu    sync.Mutex
+	stats map[string]*variantStats
+}
+
+// variantStats はグループごとの分析件数とスコアの集計
+type variantStats struct {
+	count    int
+	scoreSum int
+}
+
+// NewExperiment は新しいA/Bテストを作成
+func NewExperiment(control, variant Analyzer, name string, percent int) *Experiment {
+	return &Experiment{
+		control: control,
+		variant: variant,
+		name:    name,
+		percent: percent,
+		stats:   make(map[string]*variantStats),
+	}
+}
+
+// Analyze はツイートIDで決まるグループのAnalyzerで分析し、結果にグループ名を付ける
+func (e *Experiment) Analyze(ctx context.Context, tweet twitter.Tweet, traderInfo string) (*Analysis, error) {
+	analyzer, variant := e.assign(tweet)
+	analysis, err := analyzer.Analyze(ctx, tweet, traderInfo)
+	if err != nil {
+		return nil, err
+	}
+	return e.tag(analysis, variant), nil
+}
+
+// AnalyzeBatch はグループごとにまとめて分析する
+func (e *Experiment) AnalyzeBatch(ctx context.Context, items []Item) ([]*Analysis, error) {
+	analyses := make([]*Analysis, len(items))
+
+	groups := make(map[string][]int)
+	for i, item := range items {
+		_, variant := e.assign(item.Tweet)
+		groups[variant] = append(groups[variant], i)
+	}
+
+	for variant, idx := range groups {
+		analyzer := e.control
+		if variant != ControlVariant {
+			analyzer = e.variant
+		}
+		ba, ok := analyzer.(BatchAnalyzer)
+		if !ok {
+			// nilのまま返し、AnalyzeAllの1件ずつの分析に任せる
+			continue
+		}
+
+		group := make([]Item, len(idx))
+		for j, i := range idx {
+			group[j] = items[i]
+		}
+		results, err := ba.AnalyzeBatch(ctx, group)
+		if err != nil {
+			return nil, err
+		}
+		for j, analysis := range results {
+			if j < len(idx) && analysis != nil {
+				analyses[idx[j]] = e.tag(analysis, variant)
+			}
+		}
+	}
+	return analyses, nil
+}
+
+// assign はツイートIDのハッシュでグループを決める（同じツイートは常に同じグループ）
+func (e *Experiment) assign(tweet twitter.Tweet) (Analyzer, string) {
+	h := fnv.New32a()
+	h.Write([]byte(tweet.ID))
+	if int(h.Sum32()%100) < e.percent {
+		return e.variant, e.name
+	}
+	return e.control, ControlVariant
+}
+
+// tag は分析結果にグループ名を付けて集計する
+func (e *Experiment) tag(analysis *Analysis, variant string) *Analysis {
+	analysis.Variant = variant
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s, ok := e.stats[variant]
+	if !ok {
+		s = &variantStats{}
+		e.stats[variant] = s
+	}
+	s.count++
+	s.scoreSum += analysis.Score
+	return analysis
+}
+
+// Summary はグループごとの分析件数と平均スコアを返す
+func (e *Experiment) Summary() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	names := make([]string, 0, len(e.stats))
+	for name := range e.stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		s := e.stats[name]
+		parts[i] = fmt.Sprintf("%s: %d analyses, avg score %.1f", name, s.count, float64(s.scoreSum)/float64(s.count))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// StartCycle はこれまでのグループごとの集計をログに出し、両グループのAnalyzerにサイクル開始を伝える
+func (e *Experiment) StartCycle() {
+	if summary := e.Summary(); summary != "" {
+		log.Printf("AI experiment stats: %s", summary)
+	}
+	for _, a := range []Analyzer{e.control, e.variant} {
+		if ca, ok := a.(CycleAware); ok {
+			ca.StartCycle()
+		}
+	}
+}
+
+var (
+	_ BatchAnalyzer = (*Experiment)(nil)
+	_ CycleAware    = (*Experiment)(nil)
+)
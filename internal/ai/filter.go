@@ -6,47 +6,77 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/Minatonton/x-crawler/internal/twitter"
 )
 
-// Filter はClaude APIを使った分析フィルター
+// Filter はClaude APIを使った分析フィルター (Analyzer実装)
 type Filter struct {
 	apiKey     string
 	model      string
+	retry      RetryPolicy
+	prompt     *Prompt
+	usage      *UsageTracker
+	limiter    *RateLimiter
+	maxImages  int
+	budget     retryBudget
 	httpClient *http.Client
+
+	stream      bool          // Messages APIをストリーミングで呼び出す
+	idleTimeout time.Duration // ストリーミング時にデータが途切れてから打ち切るまでの時間
 }
 
-// Analysis はAI分析結果
-type Analysis struct {
-	Score     int      `json:"score"`
-	Category  string   `json:"category"`
-	Sentiment string   `json:"sentiment"`
-	Tickers   []string `json:"tickers"`
-	Summary   string   `json:"summary"`
-	KeyPoints []string `json:"key_points"`
-	Urgency   string   `json:"urgency"`
-	Reasoning string   `json:"reasoning"`
+// FilterConfig はFilterの設定
+type FilterConfig struct {
+	APIKey    string
+	Model     string
+	Retry     RetryPolicy
+	Prompt    *Prompt
+	Usage     *UsageTracker // nilの場合は使用量を記録しない
+	Limiter   *RateLimiter  // nilの場合はリクエスト数を制限しない
+	MaxImages int           // 1ツイートあたりにvision入力として渡す画像の最大数（0の場合は画像を渡さない）
+
+	// Stream はストリーミングで応答を受け取る（全体のタイムアウトの代わりにIdleTimeoutを使う）
+	Stream      bool
+	IdleTimeout time.Duration
 }
 
 // NewFilter は新しいAIフィルターを作成
-func NewFilter(apiKey, model string) *Filter {
+func NewFilter(cfg FilterConfig) *Filter {
+	httpClient := &http.Client{
+		Timeout: 60 * time.Second,
+	}
+	if cfg.Stream {
+		// 応答が届き続ける限り待つ（ヘッダーまでは60秒、以降はIdleTimeoutで打ち切る）
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.ResponseHeaderTimeout = 60 * time.Second
+		httpClient = &http.Client{Transport: transport}
+	}
 	return &Filter{
-		apiKey: apiKey,
-		model:  model,
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
-		},
+		apiKey:      cfg.APIKey,
+		model:       cfg.Model,
+		retry:       cfg.Retry,
+		prompt:      cfg.Prompt,
+		usage:       cfg.Usage,
+		limiter:     cfg.Limiter,
+		maxImages:   cfg.MaxImages,
+		httpClient:  httpClient,
+		stream:      cfg.Stream,
+		idleTimeout: cfg.IdleTimeout,
 	}
 }
 
 // Analyze はツイートを分析
 func (f *Filter) Analyze(ctx context.Context, tweet twitter.Tweet, traderInfo string) (*Analysis, error) {
-	prompt := f.buildPrompt(tweet, traderInfo)
+	prompt, err := f.prompt.Build(tweet, traderInfo)
+	if err != nil {
+		return nil, err
+	}
 
-	analysis, err := f.callClaudeAPI(ctx, prompt)
+	analysis, err := f.callClaudeAPI(ctx, f.messageContent(prompt, tweet))
 	if err != nil {
 		return nil, err
 	}
@@ -54,155 +84,379 @@ func (f *Filter) Analyze(ctx context.Context, tweet twitter.Tweet, traderInfo st
 	return analysis, nil
 }
 
-// buildPrompt はAI分析用のプロンプトを構築
-func (f *Filter) buildPrompt(tweet twitter.Tweet, traderInfo string) string {
-	return fmt.Sprintf(`あなたは経験豊富な金融アナリストです。以下のXポストを分析してください。
-
-投稿者: @%s
-投稿者情報: %s
-投稿時刻: %s
-内容:
-%s
-
-以下の形式でJSONを返してください:
-{
-  "score": 0-100,
-  "category": "buy_signal|sell_signal|earnings_beat|earnings_miss|sec_filing|merger_acquisition|analyst_upgrade|analyst_downgrade|market_news|executive_trade|other",
-  "sentiment": "bullish|bearish|neutral",
-  "tickers": ["AAPL", "TSLA"],
-  "summary": "簡潔な日本語サマリー (1-2行)",
-  "key_points": ["ポイント1", "ポイント2"],
-  "urgency": "critical|high|normal|low",
-  "reasoning": "スコアの理由"
-}
-
-評価基準:
-1. 投稿者の信頼性と影響力
-2. 情報の具体性 (数値、ティッカーシンボル、価格目標)
-3. 時間的価値 (速報性、タイムリー性)
-4. アクション可能性 (すぐに取引判断に使えるか)
-5. 情報源の信頼性 (一次情報か)
-
-高スコア例 (80-100):
-- 決算発表の速報
-- SEC提出書類の通知
-- 有名投資家の売買報告
-- M&A発表
-- 大口取引の検出
-
-中スコア例 (60-79):
-- アナリストレポート
-- 市場コメンタリー
-- 業界ニュース
-
-低スコア例 (0-59):
-- 一般的な市場コメント
-- 個人的な意見
-- 既知の情報`,
-		tweet.Username,
-		traderInfo,
-		tweet.CreatedAt.Format("2006-01-02 15:04:05 MST"),
-		tweet.Text,
-	)
+// StartCycle はサイクルごとのリトライ枠をリセット
+func (f *Filter) StartCycle() {
+	f.budget.reset()
+}
+
+// AnalyzeBatch は複数ツイートを1回のリクエストで分析
+func (f *Filter) AnalyzeBatch(ctx context.Context, items []Item) ([]*Analysis, error) {
+	prompt, err := f.prompt.BuildBatch(items)
+	if err != nil {
+		return nil, err
+	}
+
+	maxTokens := 1024 * len(items)
+	if maxTokens > 8192 {
+		maxTokens = 8192
+	}
+
+	tweets := make([]twitter.Tweet, len(items))
+	for i, item := range items {
+		tweets[i] = item.Tweet
+	}
+
+	content, err := f.createMessage(ctx, f.messageContent(prompt, tweets...), batchTool(f.prompt.CategoryNames()), maxTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, block := range content {
+		if block.Type != "tool_use" || block.Name != batchToolName {
+			continue
+		}
+
+		var input struct {
+			Analyses []struct {
+				Index *int `json:"index"`
+				Analysis
+			} `json:"analyses"`
+		}
+		if err := json.Unmarshal(block.Input, &input); err != nil {
+			return nil, fmt.Errorf("failed to parse batch tool input: %w", err)
+		}
+
+		analyses := make([]*Analysis, len(items))
+		for _, a := range input.Analyses {
+			if a.Index == nil || *a.Index < 0 || *a.Index >= len(items) {
+				continue
+			}
+			analysis := a.Analysis
+			if err := validateAnalysis(&analysis, f.prompt.CategoryNames()); err != nil {
+				// 不正な項目はnilのまま返し、1件ずつの分析（修正依頼あり）に任せる
+				log.Printf("Invalid batch analysis for tweet %s: %v", items[*a.Index].Tweet.ID, err)
+				continue
+			}
+			analyses[*a.Index] = &analysis
+		}
+		return analyses, nil
+	}
+
+	return nil, fmt.Errorf("no batch analysis in Claude API response")
+}
+
+// Relevance は一次選別としてツイートの関連度 (0-100) を評価
+func (f *Filter) Relevance(ctx context.Context, tweet twitter.Tweet, traderInfo string) (int, string, error) {
+	prompt, err := f.prompt.BuildTriage(tweet, traderInfo)
+	if err != nil {
+		return 0, "", err
+	}
+
+	content, err := f.createMessage(ctx, textContent(prompt), triageTool(), 256)
+	if err != nil {
+		return 0, "", err
+	}
+
+	for _, block := range content {
+		if block.Type != "tool_use" || block.Name != triageToolName {
+			continue
+		}
+		var input struct {
+			Relevance int    `json:"relevance"`
+			Reason    string `json:"reason"`
+		}
+		if err := json.Unmarshal(block.Input, &input); err != nil {
+			return 0, "", fmt.Errorf("failed to parse triage tool input: %w", err)
+		}
+		return input.Relevance, input.Reason, nil
+	}
+
+	return 0, "", fmt.Errorf("no relevance in Claude API response")
+}
+
+// contentBlock はMessages APIの応答コンテンツ
+type contentBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text"`
+	Name  string          `json:"name"`
+	Input json.RawMessage `json:"input"`
 }
 
 // callClaudeAPI はClaude APIを呼び出し
-func (f *Filter) callClaudeAPI(ctx context.Context, prompt string) (*Analysis, error) {
+func (f *Filter) callClaudeAPI(ctx context.Context, message []map[string]interface{}) (*Analysis, error) {
+	content, err := f.createMessage(ctx, message, analysisTool(f.prompt.CategoryNames()), 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis, output, err := f.decodeAnalysis(content)
+	if err != nil && output != "" {
+		// 不正な出力は一度だけ修正を依頼する
+		log.Printf("Invalid analysis from Claude, requesting repair: %v", err)
+		return f.repairAnalysis(ctx, output, err)
+	}
+	return analysis, err
+}
+
+// repairAnalysis は不正な出力と問題点を渡して修正した分析結果を再取得
+func (f *Filter) repairAnalysis(ctx context.Context, output string, cause error) (*Analysis, error) {
+	prompt, err := f.prompt.BuildRepair(output, repairProblems(cause))
+	if err != nil {
+		return nil, err
+	}
+	content, err := f.createMessage(ctx, textContent(prompt), analysisTool(f.prompt.CategoryNames()), 1024)
+	if err != nil {
+		return nil, fmt.Errorf("repair request failed: %w (original: %v)", err, cause)
+	}
+	analysis, _, err := f.decodeAnalysis(content)
+	if err != nil {
+		return nil, fmt.Errorf("analysis still invalid after repair: %w", err)
+	}
+	return analysis, nil
+}
+
+// decodeAnalysis は応答から分析結果を取り出して検証する
+// 失敗した場合は修正依頼に使う元の出力も返す
+func (f *Filter) decodeAnalysis(content []contentBlock) (*Analysis, string, error) {
+	var analysis *Analysis
+	var output string
+	var err error
+
+	// ツール呼び出しの入力をそのままAnalysisとして使う
+	for _, block := range content {
+		if block.Type == "tool_use" && block.Name == analysisToolName {
+			output = string(block.Input)
+			analysis = &Analysis{}
+			if jerr := json.Unmarshal(block.Input, analysis); jerr != nil {
+				analysis, err = nil, fmt.Errorf("failed to parse tool input: %w (input: %s)", jerr, output)
+			}
+			break
+		}
+	}
+
+	// ツールが使われなかった場合はテキストからJSONを抽出（フォールバック）
+	if output == "" {
+		for _, block := range content {
+			if block.Type == "text" && block.Text != "" {
+				output = block.Text
+				analysis, err = parseAnalysis(block.Text)
+				break
+			}
+		}
+	}
+
+	if output == "" {
+		return nil, "", fmt.Errorf("no analysis in Claude API response")
+	}
+	if err == nil {
+		err = validateAnalysis(analysis, f.prompt.CategoryNames())
+	}
+	if err != nil {
+		return nil, output, err
+	}
+	return analysis, output, nil
+}
+
+// textContent はテキストのみのユーザーメッセージを構築
+func textContent(prompt string) []map[string]interface{} {
+	return []map[string]interface{}{
+		{"type": "text", "text": prompt},
+	}
+}
+
+// messageContent はプロンプトと添付画像からユーザーメッセージを構築
+// 複数ツイートの場合は画像の前にポスト番号を示すテキストを挟む
+func (f *Filter) messageContent(prompt string, tweets ...twitter.Tweet) []map[string]interface{} {
+	content := textContent(prompt)
+	if f.maxImages <= 0 {
+		return content
+	}
+
+	for i, tweet := range tweets {
+		urls := tweet.ImageURLs()
+		if len(urls) == 0 {
+			continue
+		}
+		if len(urls) > f.maxImages {
+			urls = urls[:f.maxImages]
+		}
+
+		label := "添付画像 (チャート・決算表・提出書類などのスクリーンショットの場合は内容も分析に含めてください):"
+		if len(tweets) > 1 {
+			label = fmt.Sprintf("ポスト %d の%s", i, label)
+		}
+		content = append(content, map[string]interface{}{"type": "text", "text": label})
+
+		for _, u := range urls {
+			content = append(content, map[string]interface{}{
+				"type": "image",
+				"source": map[string]string{
+					"type": "url",
+					"url":  u,
+				},
+			})
+		}
+	}
+
+	return content
+}
+
+// createMessage は指定したツールの使用を強制してMessages APIを呼び出す
+func (f *Filter) createMessage(ctx context.Context, message []map[string]interface{}, tool map[string]interface{}, maxTokens int) (content []contentBlock, err error) {
+	if err := f.usage.Allow(); err != nil {
+		return nil, err
+	}
+	if err := f.limiter.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer f.limiter.Release()
+
 	requestBody := map[string]interface{}{
 		"model":       f.model,
-		"max_tokens":  2048,
+		"max_tokens":  maxTokens,
 		"temperature": 0.2,
-		"messages": []map[string]string{
+		// 分析結果はツール呼び出しとして構造化JSONで受け取る
+		"tools": []map[string]interface{}{tool},
+		"tool_choice": map[string]string{
+			"type": "tool",
+			"name": tool["name"].(string),
+		},
+		"messages": []map[string]interface{}{
 			{
 				"role":    "user",
-				"content": prompt,
+				"content": message,
 			},
 		},
 	}
 
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, err
+	if f.stream {
+		requestBody["stream"] = true
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonData))
+	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", f.apiKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
+	// 失敗した呼び出しも含めて使用量を記録
+	start := time.Now()
+	var usage Usage
+	defer func() {
+		f.usage.Record(ctx, f.model, usage, time.Since(start), err)
+	}()
 
-	resp, err := f.httpClient.Do(req)
+	if f.stream {
+		content, usage, err = f.createMessageStream(ctx, jsonData)
+		return content, err
+	}
+
+	resp, err := f.doWithRetry(ctx, jsonData)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Claude API error (status %d): %s", resp.StatusCode, string(body))
-	}
-
 	var claudeResp struct {
-		Content []struct {
-			Text string `json:"text"`
-		} `json:"content"`
+		Content []contentBlock `json:"content"`
+		Usage   struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&claudeResp); err != nil {
 		return nil, err
 	}
 
+	usage = Usage{
+		InputTokens:  claudeResp.Usage.InputTokens,
+		OutputTokens: claudeResp.Usage.OutputTokens,
+	}
+
 	if len(claudeResp.Content) == 0 {
 		return nil, fmt.Errorf("empty response from Claude API")
 	}
 
-	// JSONレスポンスをパース
-	var analysis Analysis
-	text := claudeResp.Content[0].Text
+	return claudeResp.Content, nil
+}
 
-	// JSONブロックを抽出（```json ... ```のような形式に対応）
-	text = extractJSON(text)
+// createMessageStream はストリーミングでMessages APIを呼び出し、応答と使用量を返す
+// 一定時間データが届かない場合やerrorイベントを受け取った場合はすぐに失敗する
+func (f *Filter) createMessageStream(ctx context.Context, body []byte) ([]contentBlock, Usage, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	if err := json.Unmarshal([]byte(text), &analysis); err != nil {
-		return nil, fmt.Errorf("failed to parse AI response: %w (response: %s)", err, text)
+	resp, err := f.doWithRetry(streamCtx, body)
+	if err != nil {
+		return nil, Usage{}, err
 	}
+	defer resp.Body.Close()
 
-	return &analysis, nil
-}
-
-// extractJSON はマークダウンのコードブロックからJSONを抽出
-func extractJSON(text string) string {
-	// ```json ... ``` の形式を探す
-	start := -1
-	end := -1
+	// ヘッダー受信後はデータが途切れた時点で打ち切る
+	watchdog := newIdleWatchdog(f.idleTimeout, cancel)
+	defer watchdog.stop()
 
-	for i := 0; i < len(text)-6; i++ {
-		if text[i:i+7] == "```json" {
-			start = i + 7
-		} else if text[i:i+3] == "```" && start != -1 {
-			end = i
-			break
+	content, usage, err := readStream(resp.Body, watchdog.reset)
+	if err != nil {
+		if ctx.Err() == nil && streamCtx.Err() != nil {
+			return nil, usage, fmt.Errorf("Claude API stream stalled for %s: %w", f.idleTimeout, err)
 		}
+		return nil, usage, err
 	}
 
-	if start != -1 && end != -1 {
-		return text[start:end]
+	if len(content) == 0 {
+		return nil, usage, fmt.Errorf("empty response from Claude API")
 	}
 
-	// JSONブロックが見つからない場合は、{}で囲まれた部分を探す
-	for i := 0; i < len(text); i++ {
-		if text[i] == '{' {
-			// 最後の}を探す
-			for j := len(text) - 1; j > i; j-- {
-				if text[j] == '}' {
-					return text[i : j+1]
-				}
-			}
+	return content, usage, nil
+}
+
+// doWithRetry は過負荷エラー時に指数バックオフでリトライしながらリクエストを送信
+func (f *Filter) doWithRetry(ctx context.Context, body []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := f.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("x-api-key", f.apiKey)
+		req.Header.Set("anthropic-version", "2023-06-01")
+
+		resp, err := f.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
 		}
-	}
 
-	return text
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		apiErr := fmt.Errorf("Claude API error (status %d): %s", resp.StatusCode, string(respBody))
+
+		if !isRetryableStatus(resp.StatusCode) || attempt >= f.retry.MaxRetries {
+			return nil, apiErr
+		}
+		if !f.budget.take(f.retry.BudgetPerCycle) {
+			return nil, fmt.Errorf("%w (retry budget exhausted for this cycle)", apiErr)
+		}
+
+		delay := f.retry.retryDelay(attempt, resp.Header)
+		log.Printf("Claude API overloaded (status %d), retrying in %s (attempt %d/%d)",
+			resp.StatusCode, delay, attempt+1, f.retry.MaxRetries)
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
 }
+
+var (
+	_ BatchAnalyzer = (*Filter)(nil)
+	_ CycleAware    = (*Filter)(nil)
+	_ Triager       = (*Filter)(nil)
+)
@@ -0,0 +1,88 @@
+package ai
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter はAI APIへの同時リクエスト数と1分あたりのリクエスト数を制限する
+// nilの場合は制限しない
+type RateLimiter struct {
+	slots chan struct{} // 同時実行数のセマフォ（nilの場合は無制限）
+
+	mu       sync.Mutex
+	rpm      int
+	tokens   float64
+	lastFill time.Time
+}
+
+// NewRateLimiter は新しいRateLimiterを作成（いずれも0以下の場合は無制限）
+func NewRateLimiter(maxConcurrent, requestsPerMinute int) *RateLimiter {
+	l := &RateLimiter{
+		rpm:      requestsPerMinute,
+		tokens:   float64(requestsPerMinute),
+		lastFill: time.Now(),
+	}
+	if maxConcurrent > 0 {
+		l.slots = make(chan struct{}, maxConcurrent)
+	}
+	return l
+}
+
+// Acquire は同時実行枠を確保する（終了時にReleaseを呼ぶ）
+func (l *RateLimiter) Acquire(ctx context.Context) error {
+	if l == nil || l.slots == nil {
+		return nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release は同時実行枠を解放
+func (l *RateLimiter) Release() {
+	if l == nil || l.slots == nil {
+		return
+	}
+	<-l.slots
+}
+
+// Wait は1分あたりのリクエスト数の枠が空くまで待つ（HTTPリクエストごとに呼ぶ）
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil || l.rpm <= 0 {
+		return nil
+	}
+	for {
+		delay := l.reserve()
+		if delay == 0 {
+			return nil
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve はトークンを1つ取得し、足りない場合は補充までの待ち時間を返す
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	perToken := time.Minute / time.Duration(l.rpm)
+	l.tokens += float64(now.Sub(l.lastFill)) / float64(perToken)
+	if l.tokens > float64(l.rpm) {
+		l.tokens = float64(l.rpm)
+	}
+	l.lastFill = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) * float64(perToken))
+}
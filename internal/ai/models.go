@@ -0,0 +1,180 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ModelLister はプロバイダーで利用可能なモデルIDを新しい順に返す
+type ModelLister func(ctx context.Context) ([]string, error)
+
+// modelAlias は最新の推奨モデルに解決されるエイリアス
+type modelAlias struct {
+	provider string
+	family   string // モデルIDに含まれる系列名
+	fallback string // モデル一覧を取得できない場合の解決先
+}
+
+// modelAliases は設定で使えるモデル名のエイリアス
+var modelAliases = map[string]modelAlias{
+	"sonnet-latest": {ProviderAnthropic, "sonnet", "claude-3-5-sonnet-20241022"},
+	"haiku-latest":  {ProviderAnthropic, "haiku", "claude-3-5-haiku-20241022"},
+	"opus-latest":   {ProviderAnthropic, "opus", "claude-3-opus-20240229"},
+}
+
+// ResolveModel はエイリアスを解決し、モデル名がavailable（新しい順のモデル一覧）に存在するかを検証する
+// availableがnilの場合（一覧を取得できなかった場合）は検証せず、エイリアスは既定のモデルに解決する
+func ResolveModel(provider, model string, available []string) (string, error) {
+	alias, isAlias := modelAliases[strings.ToLower(model)]
+	if isAlias && alias.provider != provider {
+		return "", fmt.Errorf("model alias %q is not available for provider %s", model, provider)
+	}
+
+	if available == nil {
+		if isAlias {
+			return alias.fallback, nil
+		}
+		return model, nil
+	}
+
+	if isAlias {
+		for _, id := range available {
+			if strings.Contains(id, alias.family) {
+				return id, nil
+			}
+		}
+		return "", fmt.Errorf("no %s model is available for alias %q", alias.family, model)
+	}
+
+	for _, id := range available {
+		if id == model {
+			return model, nil
+		}
+	}
+	// プロバイダー側のエイリアス (例: claude-3-5-sonnet-latest) は一覧に含まれない
+	if prefix := strings.TrimSuffix(model, "-latest"); prefix != model {
+		for _, id := range available {
+			if strings.HasPrefix(id, prefix) {
+				return model, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("unknown model %q for provider %s (available: %s)",
+		model, provider, strings.Join(similarModels(model, available), ", "))
+}
+
+// similarModels はモデル名と系列が近いモデルを最大5件返す（なければ新しい順に5件）
+func similarModels(model string, available []string) []string {
+	var similar []string
+	for _, id := range available {
+		for _, part := range strings.FieldsFunc(model, func(r rune) bool { return r == '-' || r == '.' }) {
+			if len(part) >= 4 && strings.Contains(id, part) {
+				similar = append(similar, id)
+				break
+			}
+		}
+	}
+	if len(similar) == 0 {
+		similar = available
+	}
+	if len(similar) > 5 {
+		similar = similar[:5]
+	}
+	return similar
+}
+
+// AnthropicModels はAnthropicのModels APIでモデル一覧を取得するModelListerを返す
+func AnthropicModels(apiKey string) ModelLister {
+	return func(ctx context.Context) ([]string, error) {
+		var ids []string
+		afterID := ""
+		for {
+			url := "https://api.anthropic.com/v1/models?limit=1000"
+			if afterID != "" {
+				url += "&after_id=" + afterID
+			}
+			var page struct {
+				Data []struct {
+					ID string `json:"id"`
+				} `json:"data"`
+				HasMore bool   `json:"has_more"`
+				LastID  string `json:"last_id"`
+			}
+			if err := getModelsJSON(ctx, url, map[string]string{
+				"x-api-key":         apiKey,
+				"anthropic-version": "2023-06-01",
+			}, &page); err != nil {
+				return nil, err
+			}
+			// 新しい順に返される
+			for _, m := range page.Data {
+				ids = append(ids, m.ID)
+			}
+			if !page.HasMore || page.LastID == "" {
+				return ids, nil
+			}
+			afterID = page.LastID
+		}
+	}
+}
+
+// OpenAIModels はOpenAI互換APIの /models でモデル一覧を取得するModelListerを返す
+func OpenAIModels(apiKey, baseURL string) ModelLister {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+	return func(ctx context.Context) ([]string, error) {
+		var list struct {
+			Data []struct {
+				ID      string `json:"id"`
+				Created int64  `json:"created"`
+			} `json:"data"`
+		}
+		if err := getModelsJSON(ctx, strings.TrimRight(baseURL, "/")+"/models", map[string]string{
+			"Authorization": "Bearer " + apiKey,
+		}, &list); err != nil {
+			return nil, err
+		}
+		sort.SliceStable(list.Data, func(i, j int) bool {
+			return list.Data[i].Created > list.Data[j].Created
+		})
+		ids := make([]string, len(list.Data))
+		for i, m := range list.Data {
+			ids[i] = m.ID
+		}
+		return ids, nil
+	}
+}
+
+// getModelsJSON はモデル一覧APIを呼び出してJSONをデコード
+func getModelsJSON(ctx context.Context, url string, headers map[string]string, v interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	for k, val := range headers {
+		req.Header.Set(k, val)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("models API error (status %d): %s", resp.StatusCode, string(body))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,198 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// DefaultOpenAIBaseURL はOpenAI APIのデフォルトのベースURL
+const DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// OpenAIAnalyzer はOpenAI互換のChat Completions APIを使ったAnalyzer実装
+type OpenAIAnalyzer struct {
+	apiKey     string
+	model      string
+	baseURL    string
+	apiVersion string // Azure OpenAIの場合のみ指定
+	prompt     *Prompt
+	usage      *UsageTracker
+	limiter    *RateLimiter
+	httpClient *http.Client
+}
+
+// NewOpenAIAnalyzer は新しいOpenAIAnalyzerを作成
+// baseURLを変更することでAzure OpenAIやOpenRouterなどの互換APIを利用できる。
+// apiVersionを指定した場合はAzure OpenAIとして扱い、api-keyヘッダーで認証する。
+func NewOpenAIAnalyzer(apiKey, model, baseURL, apiVersion string, prompt *Prompt, usage *UsageTracker, limiter *RateLimiter) *OpenAIAnalyzer {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+	return &OpenAIAnalyzer{
+		apiKey:     apiKey,
+		model:      model,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiVersion: apiVersion,
+		prompt:     prompt,
+		usage:      usage,
+		limiter:    limiter,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// Analyze はツイートを分析
+func (o *OpenAIAnalyzer) Analyze(ctx context.Context, tweet twitter.Tweet, traderInfo string) (*Analysis, error) {
+	prompt, err := o.prompt.Build(tweet, traderInfo)
+	if err != nil {
+		return nil, err
+	}
+	return o.callChatAPI(ctx, prompt)
+}
+
+// callChatAPI はChat Completions APIで分析し、不正な出力は一度だけ修正を依頼する
+func (o *OpenAIAnalyzer) callChatAPI(ctx context.Context, prompt string) (*Analysis, error) {
+	output, err := o.chat(ctx, prompt, 2048)
+	if err != nil {
+		return nil, err
+	}
+	analysis, err := o.decodeAnalysis(output)
+	if err == nil {
+		return analysis, nil
+	}
+
+	log.Printf("Invalid analysis from OpenAI, requesting repair: %v", err)
+	repairPrompt, perr := o.prompt.BuildRepair(output, repairProblems(err))
+	if perr != nil {
+		return nil, perr
+	}
+	output, rerr := o.chat(ctx, repairPrompt, 1024)
+	if rerr != nil {
+		return nil, fmt.Errorf("repair request failed: %w (original: %v)", rerr, err)
+	}
+	analysis, err = o.decodeAnalysis(output)
+	if err != nil {
+		return nil, fmt.Errorf("analysis still invalid after repair: %w", err)
+	}
+	return analysis, nil
+}
+
+// decodeAnalysis は応答テキストから分析結果を取り出して検証する
+func (o *OpenAIAnalyzer) decodeAnalysis(output string) (*Analysis, error) {
+	analysis, err := parseAnalysis(output)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAnalysis(analysis, o.prompt.CategoryNames()); err != nil {
+		return nil, err
+	}
+	return analysis, nil
+}
+
+// chat はChat Completions APIを呼び出し、応答テキストを返す
+func (o *OpenAIAnalyzer) chat(ctx context.Context, prompt string, maxTokens int) (output string, err error) {
+	if err := o.usage.Allow(); err != nil {
+		return "", err
+	}
+	if err := o.limiter.Acquire(ctx); err != nil {
+		return "", err
+	}
+	defer o.limiter.Release()
+	if err := o.limiter.Wait(ctx); err != nil {
+		return "", err
+	}
+
+	requestBody := map[string]interface{}{
+		"model":       o.model,
+		"max_tokens":  maxTokens,
+		"temperature": 0.2,
+		"response_format": map[string]string{
+			"type": "json_object",
+		},
+		"messages": []map[string]string{
+			{
+				"role":    "user",
+				"content": prompt,
+			},
+		},
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return "", err
+	}
+
+	// 失敗した呼び出しも含めて使用量を記録
+	start := time.Now()
+	var usage Usage
+	defer func() {
+		o.usage.Record(ctx, o.model, usage, time.Since(start), err)
+	}()
+
+	endpoint := o.baseURL + "/chat/completions"
+	if o.apiVersion != "" {
+		endpoint += "?api-version=" + url.QueryEscape(o.apiVersion)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiVersion != "" {
+		req.Header.Set("api-key", o.apiKey)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("OpenAI API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var chatResp struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return "", err
+	}
+
+	usage = Usage{
+		InputTokens:  chatResp.Usage.PromptTokens,
+		OutputTokens: chatResp.Usage.CompletionTokens,
+	}
+
+	if len(chatResp.Choices) == 0 {
+		return "", fmt.Errorf("empty response from OpenAI API")
+	}
+
+	return chatResp.Choices[0].Message.Content, nil
+}
+
+var _ Analyzer = (*OpenAIAnalyzer)(nil)
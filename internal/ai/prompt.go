@@ -0,0 +1,330 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// DefaultPromptTemplate はデフォルトの分析プロンプト（text/template形式）
+// 出力形式と評価基準は組み込みの "instructions" テンプレートを参照する
+const DefaultPromptTemplate = `あなたは経験豊富な金融アナリストです。以下のXポストを分析してください。
+
+投稿者: @{{.Tweet.Username}}
+投稿者情報: {{.TraderInfo}}
+投稿時刻: {{.CreatedAt}}
+内容:
+{{.Tweet.Text}}
+{{template "articles" .Tweet}}
+{{template "instructions" .}}`
+
+// builtinTemplates はカスタムテンプレートからも参照できる組み込みテンプレート
+// カスタムテンプレート内で同名のテンプレートを define すると上書きできる
+const builtinTemplates = `{{define "articles"}}{{range .Articles}}
+リンク先記事: {{.Title}} ({{.URL}})
+{{.Text}}
+{{end}}{{end}}{{define "instructions"}}以下の形式でJSONを返してください:
+{
+  "score": 0-100,
+  "confidence": 0-100,
+  "category": "{{join (categoryNames .Categories) "|"}}",
+  "sentiment": "bullish|bearish|neutral",
+  "tickers": ["AAPL", "TSLA"],
+  "summary": "簡潔なサマリー (1-2行)",
+  "key_points": ["ポイント1", "ポイント2"],
+  "urgency": "critical|high|normal|low",
+  "reasoning": "スコアの理由"
+}
+
+{{if hasDescriptions .Categories}}
+カテゴリの定義:
+{{range .Categories}}{{if .Description}}- {{.Name}}: {{.Description}}
+{{end}}{{end}}{{end}}
+summary・key_points・reasoning は必ず{{.OutputLanguage}}で記述してください。
+
+score はトレーディング上の重要度、confidence はその判断の確信度です。
+情報が曖昧・未確認・推測に基づく場合は、score が高くても confidence を低くしてください。
+
+評価基準:
+1. 投稿者の信頼性と影響力 (経歴が示す専門分野に関する投稿は重視)
+2. 情報の具体性 (数値、ティッカーシンボル、価格目標)
+3. 時間的価値 (速報性、タイムリー性)
+4. アクション可能性 (すぐに取引判断に使えるか)
+5. 情報源の信頼性 (一次情報か)
+
+高スコア例 (80-100):
+- 決算発表の速報
+- SEC提出書類の通知
+- 有名投資家の売買報告
+- M&A発表
+- 大口取引の検出
+
+中スコア例 (60-79):
+- アナリストレポート
+- 市場コメンタリー
+- 業界ニュース
+
+低スコア例 (0-59):
+- 一般的な市場コメント
+- 個人的な意見
+- 既知の情報{{if .Examples}}
+
+参考例 (この基準に合わせてスコアを付けてください):
+{{range .Examples}}
+- 投稿者: @{{.Author}}
+  内容: {{.Text}}
+  → score: {{.Score}}, category: {{.Category}}{{if .Sentiment}}, sentiment: {{.Sentiment}}{{end}}{{if .Urgency}}, urgency: {{.Urgency}}{{end}}{{if .Reason}}
+  理由: {{.Reason}}{{end}}
+{{end}}{{end}}{{if .Watchlist}}
+
+ウォッチリスト銘柄 (関連する場合はスコアを高めに評価):
+{{join .Watchlist ", "}}{{end}}{{end}}{{define "batch"}}あなたは経験豊富な金融アナリストです。以下の{{len .Items}}件のXポストをそれぞれ独立に分析してください。
+{{range $i, $item := .Items}}
+### ポスト {{$i}}
+投稿者: @{{$item.Tweet.Username}}
+投稿者情報: {{$item.TraderInfo}}
+投稿時刻: {{$item.CreatedAt}}
+内容:
+{{$item.Tweet.Text}}
+{{template "articles" $item.Tweet}}{{end}}
+{{template "instructions" .}}
+
+各ポストの分析結果を、上記のポスト番号を index として付けて配列で返してください。{{end}}{{define "triage"}}以下のXポストが株式トレーディングにとってどの程度関連性があるかを0-100で評価してください。
+具体的な銘柄・数値・決算・SEC提出書類・M&A・売買報告などを含む場合は高く、一般的な雑談や意見は低く評価します。
+
+投稿者: @{{.Tweet.Username}} ({{.TraderInfo}})
+内容:
+{{.Tweet.Text}}{{if .Watchlist}}
+
+ウォッチリスト銘柄: {{join .Watchlist ", "}}{{end}}{{end}}{{define "repair"}}先ほどの分析結果に以下の問題があります。問題を修正した完全な分析結果を返してください。
+
+問題:
+{{range .Problems}}- {{.}}
+{{end}}
+元の出力:
+{{.Output}}
+
+category は {{join (categoryNames .Categories) "|"}}、sentiment は bullish|bearish|neutral、urgency は critical|high|normal|low のいずれか、score と confidence は0-100の整数、summary は必須です。{{end}}`
+
+// Example はfew-shot用の参考例
+type Example struct {
+	Author    string
+	Text      string
+	Score     int
+	Category  string
+	Sentiment string
+	Urgency   string
+	Reason    string
+}
+
+// ExampleSource はフィードバックなどから動的に参考例を提供する
+type ExampleSource interface {
+	Examples() []Example
+}
+
+// PromptContext はツイートによらず全プロンプトに共通の変数
+type PromptContext struct {
+	Watchlist      []string   // ウォッチリストのティッカー
+	Examples       []Example  // few-shotの参考例
+	OutputLanguage string     // サマリー等の出力言語名（例: 日本語, English）
+	Categories     []Category // カテゴリ体系（空の場合はデフォルト）
+
+	// Feedback は設定の参考例に追加する動的な参考例（nilの場合は使わない）
+	Feedback ExampleSource
+}
+
+// languageNames は言語コードとプロンプトで使う言語名の対応
+var languageNames = map[string]string{
+	"ja": "日本語",
+	"en": "English",
+	"zh": "中文",
+	"ko": "한국어",
+}
+
+// LanguageName は言語コードをプロンプト用の言語名に変換（未知のコードはそのまま返す）
+func LanguageName(code string) string {
+	if name, ok := languageNames[strings.ToLower(code)]; ok {
+		return name
+	}
+	return code
+}
+
+// PromptData はプロンプトテンプレートに渡す変数
+type PromptData struct {
+	PromptContext
+	Tweet      twitter.Tweet // 分析対象のツイート
+	TraderInfo string        // 投稿者またはキーワードの情報
+	CreatedAt  string        // 投稿時刻（フォーマット済み）
+}
+
+// BatchPromptData は複数ツイートをまとめて分析するテンプレートに渡す変数
+type BatchPromptData struct {
+	PromptContext
+	Items []PromptData
+}
+
+// RepairPromptData は不正な分析結果の修正を依頼するテンプレートに渡す変数
+type RepairPromptData struct {
+	PromptContext
+	Output   string   // モデルの元の出力
+	Problems []string // 検証で見つかった問題
+}
+
+// Prompt はテンプレートから分析プロンプトを構築
+type Prompt struct {
+	tmpl *template.Template
+	pc   PromptContext
+}
+
+// NewPrompt はテンプレート文字列からPromptを作成（空の場合はデフォルトテンプレート）
+func NewPrompt(text string, pc PromptContext) (*Prompt, error) {
+	if text == "" {
+		text = DefaultPromptTemplate
+	}
+	if pc.OutputLanguage == "" {
+		pc.OutputLanguage = LanguageName("ja")
+	}
+	pc.Categories = normalizeCategories(pc.Categories)
+
+	tmpl := template.Must(template.New("prompt").Funcs(template.FuncMap{
+		"join":          strings.Join,
+		"categoryNames": categoryNames,
+		"hasDescriptions": func(categories []Category) bool {
+			for _, c := range categories {
+				if c.Description != "" {
+					return true
+				}
+			}
+			return false
+		},
+	}).Parse(builtinTemplates))
+	if _, err := tmpl.Parse(text); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+
+	return &Prompt{
+		tmpl: tmpl,
+		pc:   pc,
+	}, nil
+}
+
+// CategoryNames はカテゴリ名の一覧を返す
+func (p *Prompt) CategoryNames() []string {
+	return categoryNames(p.pc.Categories)
+}
+
+// Build はツイートの分析プロンプトを構築
+func (p *Prompt) Build(tweet twitter.Tweet, traderInfo string) (string, error) {
+	var buf bytes.Buffer
+	if err := p.tmpl.Execute(&buf, p.data(tweet, traderInfo)); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// BuildBatch は複数ツイートをまとめて分析するプロンプトを構築
+func (p *Prompt) BuildBatch(items []Item) (string, error) {
+	var data BatchPromptData
+	for _, item := range items {
+		data.Items = append(data.Items, p.data(item.Tweet, item.TraderInfo))
+	}
+	if len(data.Items) > 0 {
+		data.PromptContext = data.Items[0].PromptContext
+	}
+
+	var buf bytes.Buffer
+	if err := p.tmpl.ExecuteTemplate(&buf, "batch", data); err != nil {
+		return "", fmt.Errorf("failed to render batch prompt template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// BuildTriage は一次選別用の短いプロンプトを構築
+func (p *Prompt) BuildTriage(tweet twitter.Tweet, traderInfo string) (string, error) {
+	var buf bytes.Buffer
+	if err := p.tmpl.ExecuteTemplate(&buf, "triage", p.data(tweet, traderInfo)); err != nil {
+		return "", fmt.Errorf("failed to render triage prompt template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// BuildRepair は不正な分析結果の修正を依頼する短いプロンプトを構築
+func (p *Prompt) BuildRepair(output string, problems []string) (string, error) {
+	var buf bytes.Buffer
+	data := RepairPromptData{
+		PromptContext: p.pc,
+		Output:        output,
+		Problems:      problems,
+	}
+	if err := p.tmpl.ExecuteTemplate(&buf, "repair", data); err != nil {
+		return "", fmt.Errorf("failed to render repair prompt template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// data はテンプレートに渡す変数を作成
+func (p *Prompt) data(tweet twitter.Tweet, traderInfo string) PromptData {
+	pc := p.pc
+	if pc.Feedback != nil {
+		if dynamic := pc.Feedback.Examples(); len(dynamic) > 0 {
+			pc.Examples = append(append([]Example(nil), pc.Examples...), dynamic...)
+		}
+	}
+	return PromptData{
+		PromptContext: pc,
+		Tweet:         tweet,
+		TraderInfo:    traderInfo,
+		CreatedAt:     tweet.CreatedAt.Format("2006-01-02 15:04:05 MST"),
+	}
+}
+
+// parseAnalysis はモデルの応答テキストからAnalysisをパース
+func parseAnalysis(text string) (*Analysis, error) {
+	// JSONブロックを抽出（```json ... ```のような形式に対応）
+	text = extractJSON(text)
+
+	var analysis Analysis
+	if err := json.Unmarshal([]byte(text), &analysis); err != nil {
+		return nil, fmt.Errorf("failed to parse AI response: %w (response: %s)", err, text)
+	}
+
+	return &analysis, nil
+}
+
+// extractJSON はマークダウンのコードブロックからJSONを抽出
+func extractJSON(text string) string {
+	// ```json ... ``` の形式を探す
+	start := -1
+	end := -1
+
+	for i := 0; i < len(text)-6; i++ {
+		if text[i:i+7] == "```json" {
+			start = i + 7
+		} else if text[i:i+3] == "```" && start != -1 {
+			end = i
+			break
+		}
+	}
+
+	if start != -1 && end != -1 {
+		return text[start:end]
+	}
+
+	// JSONブロックが見つからない場合は、{}で囲まれた部分を探す
+	for i := 0; i < len(text); i++ {
+		if text[i] == '{' {
+			// 最後の}を探す
+			for j := len(text) - 1; j > i; j-- {
+				if text[j] == '}' {
+					return text[i : j+1]
+				}
+			}
+		}
+	}
+
+	return text
+}
@@ -0,0 +1,124 @@
+package ai
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// 個人情報の伏せ方
+const (
+	RedactMask  = "mask"  // [email] などの記号に置き換える
+	RedactStrip = "strip" // 削除する
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// 区切り付きの電話番号のみ対象（価格・日付などの数値を誤って伏せないため）
+	phonePattern  = regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?\(?\d{2,4}\)?[\s.-]\d{3,4}[\s.-]\d{4}\b|\+\d{1,3}[\s.-]?\d{1,4}(?:[\s.-]?\d{2,4}){2,4}\b`)
+	handlePattern = regexp.MustCompile(`(^|[^\w@])@(\w{1,15})\b`)
+)
+
+// Redactor は外部のAIプロバイダーに送る前にツイート本文から個人情報を伏せるAnalyzer
+// メールアドレス・電話番号・監視対象外の@ハンドルを対象とする
+type Redactor struct {
+	analyzer Analyzer
+	mode     string
+
+	mu      sync.RWMutex
+	allowed map[string]bool // 伏せない@ハンドル（小文字）
+}
+
+// NewRedactor は新しいRedactorを作成
+// allowedHandlesは伏せずに残す@ハンドル（監視対象のトレーダーなど）。投稿者自身は常に残す
+func NewRedactor(analyzer Analyzer, allowedHandles []string, mode string) *Redactor {
+	r := &Redactor{
+		analyzer: analyzer,
+		mode:     mode,
+	}
+	r.SetAllowedHandles(allowedHandles)
+	return r
+}
+
+// SetAllowedHandles は伏せずに残す@ハンドルを置き換える（設定の再読み込みでトレーダーが変わった場合）
+func (r *Redactor) SetAllowedHandles(handles []string) {
+	allowed := make(map[string]bool, len(handles))
+	for _, h := range handles {
+		allowed[strings.ToLower(strings.TrimPrefix(h, "@"))] = true
+	}
+	r.mu.Lock()
+	r.allowed = allowed
+	r.mu.Unlock()
+}
+
+// Analyze は個人情報を伏せたツイートを分析
+func (r *Redactor) Analyze(ctx context.Context, tweet twitter.Tweet, traderInfo string) (*Analysis, error) {
+	return r.analyzer.Analyze(ctx, r.redactTweet(tweet), traderInfo)
+}
+
+// AnalyzeBatch は個人情報を伏せたツイートをまとめて分析
+func (r *Redactor) AnalyzeBatch(ctx context.Context, items []Item) ([]*Analysis, error) {
+	ba, ok := r.analyzer.(BatchAnalyzer)
+	if !ok {
+		return make([]*Analysis, len(items)), nil
+	}
+	redacted := make([]Item, len(items))
+	for i, item := range items {
+		redacted[i] = Item{Tweet: r.redactTweet(item.Tweet), TraderInfo: item.TraderInfo}
+	}
+	return ba.AnalyzeBatch(ctx, redacted)
+}
+
+// redactTweet は本文とリンク先記事の本文から個人情報を伏せたコピーを返す
+func (r *Redactor) redactTweet(tweet twitter.Tweet) twitter.Tweet {
+	tweet.Text = r.Redact(tweet.Text, tweet.Username)
+	if len(tweet.Articles) > 0 {
+		articles := make([]twitter.Article, len(tweet.Articles))
+		for i, a := range tweet.Articles {
+			a.Text = r.Redact(a.Text, tweet.Username)
+			articles[i] = a
+		}
+		tweet.Articles = articles
+	}
+	return tweet
+}
+
+// Redact はテキストからメールアドレス・電話番号・許可されていない@ハンドルを伏せる
+func (r *Redactor) Redact(text, author string) string {
+	text = emailPattern.ReplaceAllString(text, r.replacement("[email]"))
+	text = phonePattern.ReplaceAllString(text, r.replacement("[phone]"))
+	r.mu.RLock()
+	allowed := r.allowed
+	r.mu.RUnlock()
+	return handlePattern.ReplaceAllStringFunc(text, func(m string) string {
+		sub := handlePattern.FindStringSubmatch(m)
+		handle := strings.ToLower(sub[2])
+		if allowed[handle] || strings.EqualFold(handle, author) {
+			return m
+		}
+		return sub[1] + r.replacement("@[user]")
+	})
+}
+
+// replacement は伏せ方に応じた置換文字列を返す
+func (r *Redactor) replacement(mask string) string {
+	if r.mode == RedactStrip {
+		return ""
+	}
+	return mask
+}
+
+// StartCycle は内側のAnalyzerにサイクル開始を伝える
+func (r *Redactor) StartCycle() {
+	if ca, ok := r.analyzer.(CycleAware); ok {
+		ca.StartCycle()
+	}
+}
+
+var (
+	_ BatchAnalyzer = (*Redactor)(nil)
+	_ CycleAware    = (*Redactor)(nil)
+)
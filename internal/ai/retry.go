@@ -0,0 +1,107 @@
+package ai
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy は過負荷エラー (429/503/529) に対するリトライ設定
+type RetryPolicy struct {
+	MaxRetries     int           // 1リクエストあたりの最大リトライ回数
+	BudgetPerCycle int           // 1クロールサイクルあたりのリトライ総数の上限（0の場合は無制限）
+	BaseDelay      time.Duration // 指数バックオフの初期待機時間
+	MaxDelay       time.Duration // 待機時間の上限（Retry-Afterにも適用）
+}
+
+// CycleAware はクロールサイクルの開始を通知されるAnalyzer
+type CycleAware interface {
+	StartCycle()
+}
+
+// retryBudget はサイクル単位のリトライ残数を管理
+type retryBudget struct {
+	mu   sync.Mutex
+	used int
+}
+
+// take はリトライ枠を1つ消費し、上限に達している場合はfalseを返す
+func (b *retryBudget) take(limit int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if limit > 0 && b.used >= limit {
+		return false
+	}
+	b.used++
+	return true
+}
+
+// reset はリトライ残数をリセット
+func (b *retryBudget) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used = 0
+}
+
+// isRetryableStatus は過負荷・レート制限を示すステータスかどうか
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, 529:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryDelay は次のリトライまでの待機時間を計算（Retry-Afterヘッダーを優先）
+func (p RetryPolicy) retryDelay(attempt int, header http.Header) time.Duration {
+	if d, ok := parseRetryAfter(header.Get("Retry-After")); ok {
+		if p.MaxDelay > 0 && d > p.MaxDelay {
+			return p.MaxDelay
+		}
+		return d
+	}
+
+	delay := p.BaseDelay << attempt
+	if p.MaxDelay > 0 && (delay > p.MaxDelay || delay <= 0) {
+		delay = p.MaxDelay
+	}
+	// 同時リトライが重ならないようにジッターを加える
+	if delay > 0 {
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+	}
+	return delay
+}
+
+// parseRetryAfter はRetry-Afterヘッダー（秒数またはHTTP日付）をパース
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// sleepContext はコンテキストがキャンセルされるまで待機
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
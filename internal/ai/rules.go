@@ -0,0 +1,197 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// ProviderRules はルールベースのスコアラーを表すプロバイダー名
+const ProviderRules = "rules"
+
+// ruleConfidence はルールベースの分析結果に付ける確信度
+const ruleConfidence = 50
+
+var (
+	numberPattern = regexp.MustCompile(`\$\d[\d,]*(\.\d+)?|\d[\d,]*(\.\d+)?\s*(%|[kKmMbB]\b|bn\b|million\b|billion\b)`)
+	filingPattern = regexp.MustCompile(`(?i)\b(8-K|10-Q|10-K|S-1|13F|13D|13G|Form 4|SEC filing|prospectus)\b`)
+)
+
+// ruleKeyword はキーワードとそれに対応するカテゴリ・センチメント
+type ruleKeyword struct {
+	pattern   *regexp.Regexp
+	category  string
+	sentiment string
+}
+
+// ruleKeywords はカテゴリ推定に使うキーワード（先に一致したものを優先）
+var ruleKeywords = []ruleKeyword{
+	{regexp.MustCompile(`(?i)\b(beats?|tops?|exceeds?)\b.*\b(estimates?|expectations?|consensus)\b`), "earnings_beat", "bullish"},
+	{regexp.MustCompile(`(?i)\b(miss(es)?|falls? short|below)\b.*\b(estimates?|expectations?|consensus)\b`), "earnings_miss", "bearish"},
+	{regexp.MustCompile(`(?i)\b(acquires?|acquisition|merger|to buy|takeover|buyout)\b`), "merger_acquisition", "bullish"},
+	{regexp.MustCompile(`(?i)\bupgrades?\b`), "analyst_upgrade", "bullish"},
+	{regexp.MustCompile(`(?i)\bdowngrades?\b`), "analyst_downgrade", "bearish"},
+	{regexp.MustCompile(`(?i)\b(insider|ceo|cfo|director)\b.*\b(buys?|sells?|bought|sold)\b`), "executive_trade", ""},
+	{regexp.MustCompile(`(?i)\b(earnings|eps|revenue|guidance)\b`), "market_news", ""},
+	{regexp.MustCompile(`(?i)(breaking|^\*|速報)`), "market_news", ""},
+}
+
+var (
+	bullishPattern = regexp.MustCompile(`(?i)\b(surges?|soars?|jumps?|rall(y|ies)|record high|raises?|beats?|upgrades?|bullish)\b`)
+	bearishPattern = regexp.MustCompile(`(?i)\b(plunges?|tumbles?|falls?|drops?|cuts?|misses?|downgrades?|bearish|lawsuit|probe)\b`)
+)
+
+// RuleScorer はAIを使わずにヒューリスティックでスコアを付けるAnalyzer
+type RuleScorer struct {
+	categories map[string]bool // 利用可能なカテゴリ
+
+	mu         sync.RWMutex
+	priorities map[string]int // ユーザー名（小文字）ごとの優先度スコア
+}
+
+// NewRuleScorer は新しいRuleScorerを作成
+// prioritiesはユーザー名ごとの優先度スコア (0-100)、categoriesはカテゴリ体系（空の場合はデフォルト）
+func NewRuleScorer(priorities map[string]int, categories []Category) *RuleScorer {
+	available := make(map[string]bool)
+	for _, c := range normalizeCategories(categories) {
+		available[c.Name] = true
+	}
+	r := &RuleScorer{categories: available}
+	r.SetPriorities(priorities)
+	return r
+}
+
+// SetPriorities はユーザー名ごとの優先度スコアを置き換える（設定の再読み込みでトレーダーが変わった場合）
+func (r *RuleScorer) SetPriorities(priorities map[string]int) {
+	normalized := make(map[string]int, len(priorities))
+	for name, score := range priorities {
+		normalized[strings.ToLower(strings.TrimPrefix(name, "@"))] = score
+	}
+	r.mu.Lock()
+	r.priorities = normalized
+	r.mu.Unlock()
+}
+
+// Analyze はキャッシュタグ・数値・提出書類キーワード・投稿者の優先度・エンゲージメントからスコアを付ける
+func (r *RuleScorer) Analyze(ctx context.Context, tweet twitter.Tweet, traderInfo string) (*Analysis, error) {
+	text := tweet.Text
+	score := 20
+	var reasons []string
+
+	// 投稿者の優先度 (critical=100 → +30)
+	r.mu.RLock()
+	p, ok := r.priorities[strings.ToLower(tweet.Username)]
+	r.mu.RUnlock()
+	if ok {
+		bonus := (p - 40) / 2
+		if bonus > 0 {
+			score += bonus
+			reasons = append(reasons, fmt.Sprintf("author priority +%d", bonus))
+		}
+	}
+
+	tickers := ExtractCashtags(text)
+	switch {
+	case len(tickers) >= 2:
+		score += 20
+		reasons = append(reasons, "multiple cashtags +20")
+	case len(tickers) == 1:
+		score += 15
+		reasons = append(reasons, "cashtag +15")
+	}
+
+	if numberPattern.MatchString(text) {
+		score += 10
+		reasons = append(reasons, "numbers +10")
+	}
+
+	category := OtherCategory
+	sentiment := "neutral"
+	if filingPattern.MatchString(text) {
+		score += 20
+		category = "sec_filing"
+		reasons = append(reasons, "filing keyword +20")
+	} else {
+		for _, kw := range ruleKeywords {
+			if kw.pattern.MatchString(text) {
+				score += 15
+				category = kw.category
+				if kw.sentiment != "" {
+					sentiment = kw.sentiment
+				}
+				reasons = append(reasons, fmt.Sprintf("%s keyword +15", kw.category))
+				break
+			}
+		}
+	}
+	if !r.categories[category] {
+		category = OtherCategory
+	}
+
+	if sentiment == "neutral" {
+		bull := bullishPattern.MatchString(text)
+		bear := bearishPattern.MatchString(text)
+		if bull && !bear {
+			sentiment = "bullish"
+		} else if bear && !bull {
+			sentiment = "bearish"
+		}
+	}
+
+	if m := tweet.Metrics; m != nil {
+		engagement := m.LikeCount + 2*m.RetweetCount + 2*m.QuoteCount
+		switch {
+		case engagement >= 1000:
+			score += 10
+			reasons = append(reasons, "high engagement +10")
+		case engagement >= 100:
+			score += 5
+			reasons = append(reasons, "engagement +5")
+		}
+	}
+
+	if score > 100 {
+		score = 100
+	}
+
+	return &Analysis{
+		Score:      score,
+		Confidence: ruleConfidence,
+		Category:   category,
+		Sentiment:  sentiment,
+		Tickers:    tickers,
+		Summary:    summarize(text, 120),
+		Urgency:    urgencyForScore(score),
+		Reasoning:  "rule-based: " + strings.Join(reasons, ", "),
+	}, nil
+}
+
+// urgencyForScore はスコアから緊急度を決める
+func urgencyForScore(score int) string {
+	switch {
+	case score >= 90:
+		return "critical"
+	case score >= 75:
+		return "high"
+	case score >= 50:
+		return "normal"
+	default:
+		return "low"
+	}
+}
+
+// summarize は本文を指定文字数で切り詰めてサマリーにする
+func summarize(text string, maxChars int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= maxChars {
+		return text
+	}
+	return string([]rune(text)[:maxChars]) + "…"
+}
+
+var _ Analyzer = (*RuleScorer)(nil)
@@ -0,0 +1,117 @@
+package ai
+
+// analysisToolName はAnalysisを返させるためのツール名
+const analysisToolName = "record_analysis"
+
+// analysisSchema はAnalysisのJSON Schema
+func analysisSchema(categories []string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"score": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     0,
+				"maximum":     100,
+				"description": "トレーディング上の重要度スコア",
+			},
+			"confidence": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     0,
+				"maximum":     100,
+				"description": "分析の確信度（情報が曖昧・推測に基づく場合は低く）",
+			},
+			"category": map[string]interface{}{
+				"type": "string",
+				"enum": categories,
+			},
+			"sentiment": map[string]interface{}{
+				"type": "string",
+				"enum": []string{"bullish", "bearish", "neutral"},
+			},
+			"tickers": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+			},
+			"summary": map[string]interface{}{
+				"type":        "string",
+				"description": "簡潔なサマリー (1-2行)",
+			},
+			"key_points": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+			},
+			"urgency": map[string]interface{}{
+				"type": "string",
+				"enum": []string{"critical", "high", "normal", "low"},
+			},
+			"reasoning": map[string]interface{}{
+				"type":        "string",
+				"description": "スコアの理由",
+			},
+		},
+		"required": []string{"score", "confidence", "category", "sentiment", "tickers", "summary", "key_points", "urgency", "reasoning"},
+	}
+}
+
+// analysisTool はAnthropic tool use用のツール定義
+func analysisTool(categories []string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":         analysisToolName,
+		"description":  "ポストの分析結果を記録する",
+		"input_schema": analysisSchema(categories),
+	}
+}
+
+// batchToolName は複数ツイートのAnalysisを返させるためのツール名
+const batchToolName = "record_analyses"
+
+// batchTool はバッチ分析用のツール定義
+func batchTool(categories []string) map[string]interface{} {
+	item := analysisSchema(categories)
+	item["properties"].(map[string]interface{})["index"] = map[string]interface{}{
+		"type":        "integer",
+		"description": "ポスト番号",
+	}
+	item["required"] = append(item["required"].([]string), "index")
+
+	return map[string]interface{}{
+		"name":        batchToolName,
+		"description": "複数ポストの分析結果をまとめて記録する",
+		"input_schema": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"analyses": map[string]interface{}{
+					"type":  "array",
+					"items": item,
+				},
+			},
+			"required": []string{"analyses"},
+		},
+	}
+}
+
+// triageToolName は一次選別の関連度を返させるためのツール名
+const triageToolName = "record_relevance"
+
+// triageTool は一次選別用のツール定義
+func triageTool() map[string]interface{} {
+	return map[string]interface{}{
+		"name":        triageToolName,
+		"description": "ポストのトレーディング関連度を記録する",
+		"input_schema": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"relevance": map[string]interface{}{
+					"type":    "integer",
+					"minimum": 0,
+					"maximum": 100,
+				},
+				"reason": map[string]interface{}{
+					"type":        "string",
+					"description": "短い理由",
+				},
+			},
+			"required": []string{"relevance"},
+		},
+	}
+}
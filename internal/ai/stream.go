@@ -0,0 +1,142 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// streamEvent はMessages APIのストリーミングイベント（使用するフィールドのみ）
+type streamEvent struct {
+	Type         string       `json:"type"`
+	Index        int          `json:"index"`
+	ContentBlock contentBlock `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+	} `json:"delta"`
+	Message struct {
+		Usage struct {
+			InputTokens int `json:"input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+	Usage struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// streamBlock は組み立て中のコンテンツブロック
+type streamBlock struct {
+	block contentBlock
+	input strings.Builder // tool_useの入力JSON
+}
+
+// idleWatchdog は一定時間データが届かない場合にリクエストをキャンセルする
+type idleWatchdog struct {
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+// newIdleWatchdog はtimeout後にcancelを呼ぶウォッチドッグを作成（timeoutが0以下の場合は無効）
+func newIdleWatchdog(timeout time.Duration, cancel context.CancelFunc) *idleWatchdog {
+	if timeout <= 0 {
+		return &idleWatchdog{}
+	}
+	return &idleWatchdog{
+		timer:   time.AfterFunc(timeout, cancel),
+		timeout: timeout,
+	}
+}
+
+// reset はデータ受信時にタイマーを延長
+func (w *idleWatchdog) reset() {
+	if w.timer != nil {
+		w.timer.Reset(w.timeout)
+	}
+}
+
+// stop はタイマーを停止
+func (w *idleWatchdog) stop() {
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
+
+// readStream はServer-Sent Eventsのストリームからコンテンツブロックと使用量を組み立てる
+// errorイベントを受け取った時点で失敗として返す
+func readStream(body io.Reader, onData func()) ([]contentBlock, Usage, error) {
+	var usage Usage
+	var blocks []*streamBlock
+	completed := false
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		onData()
+
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		var event streamEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), &event); err != nil {
+			return nil, usage, fmt.Errorf("failed to parse stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			usage.InputTokens = event.Message.Usage.InputTokens
+
+		case "content_block_start":
+			for len(blocks) <= event.Index {
+				blocks = append(blocks, &streamBlock{})
+			}
+			blocks[event.Index].block = event.ContentBlock
+
+		case "content_block_delta":
+			if event.Index >= len(blocks) {
+				return nil, usage, fmt.Errorf("stream delta for unknown content block %d", event.Index)
+			}
+			b := blocks[event.Index]
+			switch event.Delta.Type {
+			case "text_delta":
+				b.block.Text += event.Delta.Text
+			case "input_json_delta":
+				b.input.WriteString(event.Delta.PartialJSON)
+			}
+
+		case "message_delta":
+			usage.OutputTokens = event.Usage.OutputTokens
+
+		case "message_stop":
+			completed = true
+
+		case "error":
+			return nil, usage, fmt.Errorf("Claude API stream error (%s): %s", event.Error.Type, event.Error.Message)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, usage, fmt.Errorf("failed to read stream: %w", err)
+	}
+	if !completed {
+		return nil, usage, fmt.Errorf("Claude API stream ended before message_stop")
+	}
+
+	content := make([]contentBlock, 0, len(blocks))
+	for _, b := range blocks {
+		if b.block.Type == "tool_use" && b.input.Len() > 0 {
+			b.block.Input = json.RawMessage(b.input.String())
+		}
+		content = append(content, b.block)
+	}
+	return content, usage, nil
+}
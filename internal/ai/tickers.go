@@ -0,0 +1,154 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+var (
+	cashtagPattern = regexp.MustCompile(`\$([A-Za-z]{1,5}(?:\.[A-Za-z]{1,2})?)\b`)
+	tickerPattern  = regexp.MustCompile(`^[A-Z]{1,5}(\.[A-Z]{1,2})?$`)
+)
+
+// ExtractCashtags は本文中の$TICKERを重複なく大文字で返す
+func ExtractCashtags(text string) []string {
+	seen := make(map[string]bool)
+	var tickers []string
+	for _, m := range cashtagPattern.FindAllStringSubmatch(text, -1) {
+		ticker := strings.ToUpper(m[1])
+		if !seen[ticker] {
+			seen[ticker] = true
+			tickers = append(tickers, ticker)
+		}
+	}
+	return tickers
+}
+
+// SymbolSet は有効なティッカーシンボルの集合
+type SymbolSet map[string]bool
+
+// LoadSymbols はシンボル一覧ファイルを読み込む
+// 1行に1シンボル（カンマ・空白区切りの場合は先頭の列）、#以降はコメント
+func LoadSymbols(path string) (SymbolSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open symbols file: %w", err)
+	}
+	defer f.Close()
+
+	symbols := make(SymbolSet)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == '\t' || r == ' '
+		})
+		if len(fields) == 0 {
+			continue
+		}
+		symbols[normalizeTicker(fields[0])] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read symbols file: %w", err)
+	}
+	return symbols, nil
+}
+
+// Valid はティッカーが有効かを返す（一覧が空の場合は形式のみ確認）
+func (s SymbolSet) Valid(ticker string) bool {
+	if !tickerPattern.MatchString(ticker) {
+		return false
+	}
+	return len(s) == 0 || s[ticker]
+}
+
+// MergeTickers は本文のキャッシュタグとAIが返したティッカーを統合し、有効なものだけを返す
+// 本文のキャッシュタグを先に並べる
+func (s SymbolSet) MergeTickers(text string, aiTickers []string) []string {
+	seen := make(map[string]bool)
+	var merged []string
+	for _, t := range append(ExtractCashtags(text), aiTickers...) {
+		t = normalizeTicker(t)
+		if seen[t] || !s.Valid(t) {
+			continue
+		}
+		seen[t] = true
+		merged = append(merged, t)
+	}
+	return merged
+}
+
+// normalizeTicker は$を除いて大文字にする
+func normalizeTicker(ticker string) string {
+	return strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(ticker), "$"))
+}
+
+// TickerMerger は分析結果のティッカーを本文のキャッシュタグと統合・検証するAnalyzer
+type TickerMerger struct {
+	analyzer Analyzer
+	symbols  SymbolSet
+}
+
+// NewTickerMerger は新しいTickerMergerを作成（symbolsがnilの場合は形式のみ検証）
+func NewTickerMerger(analyzer Analyzer, symbols SymbolSet) *TickerMerger {
+	return &TickerMerger{
+		analyzer: analyzer,
+		symbols:  symbols,
+	}
+}
+
+// Analyze は分析後にティッカーを統合する
+func (m *TickerMerger) Analyze(ctx context.Context, tweet twitter.Tweet, traderInfo string) (*Analysis, error) {
+	analysis, err := m.analyzer.Analyze(ctx, tweet, traderInfo)
+	if err != nil {
+		return nil, err
+	}
+	return m.merge(tweet, analysis), nil
+}
+
+// AnalyzeBatch はまとめて分析した後にそれぞれのティッカーを統合する
+func (m *TickerMerger) AnalyzeBatch(ctx context.Context, items []Item) ([]*Analysis, error) {
+	ba, ok := m.analyzer.(BatchAnalyzer)
+	if !ok {
+		return make([]*Analysis, len(items)), nil
+	}
+	// エラーの場合も分析できた項目は返す
+	analyses, err := ba.AnalyzeBatch(ctx, items)
+	for i, analysis := range analyses {
+		if i < len(items) {
+			analyses[i] = m.merge(items[i].Tweet, analysis)
+		}
+	}
+	return analyses, err
+}
+
+// merge は分析結果のコピーにティッカーを統合して返す
+func (m *TickerMerger) merge(tweet twitter.Tweet, analysis *Analysis) *Analysis {
+	if analysis == nil {
+		return nil
+	}
+	merged := analysis.clone()
+	merged.Tickers = m.symbols.MergeTickers(tweet.Text, analysis.Tickers)
+	return &merged
+}
+
+// StartCycle は内側のAnalyzerにサイクル開始を伝える
+func (m *TickerMerger) StartCycle() {
+	if ca, ok := m.analyzer.(CycleAware); ok {
+		ca.StartCycle()
+	}
+}
+
+var (
+	_ BatchAnalyzer = (*TickerMerger)(nil)
+	_ CycleAware    = (*TickerMerger)(nil)
+)
@@ -0,0 +1,143 @@
+package ai
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// Triager は安価なモデルでツイートの関連度を評価する
+type Triager interface {
+	Relevance(ctx context.Context, tweet twitter.Tweet, traderInfo string) (relevance int, reason string, err error)
+}
+
+// Triage は一次選別を通過したツイートだけを本分析に回すAnalyzer
+type Triage struct {
+	triager   Triager
+	analyzer  Analyzer
+	threshold int
+
+	mu     sync.Mutex
+	passed map[string]bool // 選別を通過したが本分析に失敗したツイート（再分析では選別を省く）
+}
+
+// NewTriage は新しい二段階分析を作成
+// thresholdはmin_score以下にすること（選別で落ちたツイートは関連度をスコアとして返すため）
+func NewTriage(triager Triager, analyzer Analyzer, threshold int) *Triage {
+	return &Triage{
+		triager:   triager,
+		analyzer:  analyzer,
+		threshold: threshold,
+		passed:    make(map[string]bool),
+	}
+}
+
+// Analyze は関連度が閾値以上のツイートのみ本分析する
+func (t *Triage) Analyze(ctx context.Context, tweet twitter.Tweet, traderInfo string) (*Analysis, error) {
+	t.mu.Lock()
+	passed := t.passed[tweet.ID]
+	delete(t.passed, tweet.ID)
+	t.mu.Unlock()
+	if !passed {
+		if rejected := t.triage(ctx, tweet, traderInfo); rejected != nil {
+			return rejected, nil
+		}
+	}
+	return t.analyzer.Analyze(ctx, tweet, traderInfo)
+}
+
+// AnalyzeBatch は一次選別を通過したツイートだけをまとめて本分析する
+func (t *Triage) AnalyzeBatch(ctx context.Context, items []Item) ([]*Analysis, error) {
+	analyses := make([]*Analysis, len(items))
+
+	var passIdx []int
+	var passed []Item
+	for i, item := range items {
+		if rejected := t.triage(ctx, item.Tweet, item.TraderInfo); rejected != nil {
+			analyses[i] = rejected
+			continue
+		}
+		passIdx = append(passIdx, i)
+		passed = append(passed, item)
+	}
+
+	if len(passed) == 0 {
+		return analyses, nil
+	}
+
+	if ba, ok := t.analyzer.(BatchAnalyzer); ok && len(passed) > 1 {
+		results, err := ba.AnalyzeBatch(ctx, passed)
+		if err != nil {
+			log.Printf("Batch AI analysis failed for %d triaged tweets, falling back to per-tweet analysis: %v", len(passed), err)
+			results = nil
+		}
+		for j, analysis := range results {
+			if j < len(passIdx) {
+				analyses[passIdx[j]] = analysis
+			}
+		}
+	}
+
+	// 通過分はnilで返すとAnalyzeAllがAnalyzeで選別からやり直すため、ここで1件ずつ本分析する
+	var firstErr error
+	for j, i := range passIdx {
+		if analyses[i] != nil {
+			continue
+		}
+		item := passed[j]
+		analysis, err := t.analyzer.Analyze(WithCallTweets(ctx, item.Tweet.ID), item.Tweet, item.TraderInfo)
+		if err != nil {
+			// AnalyzeAllがAnalyzeで再分析するときに選別をやり直さないよう記録
+			t.mu.Lock()
+			t.passed[item.Tweet.ID] = true
+			t.mu.Unlock()
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		analyses[i] = analysis
+	}
+	return analyses, firstErr
+}
+
+// triage は関連度を評価し、閾値未満の場合は選別結果のAnalysisを返す
+// 一次選別自体が失敗した場合は本分析に回す（nilを返す）
+func (t *Triage) triage(ctx context.Context, tweet twitter.Tweet, traderInfo string) *Analysis {
+	relevance, reason, err := t.triager.Relevance(ctx, tweet, traderInfo)
+	if err != nil {
+		log.Printf("Triage failed for tweet %s, sending to full analysis: %v", tweet.ID, err)
+		return nil
+	}
+	if relevance >= t.threshold {
+		return nil
+	}
+
+	log.Printf("Tweet %s rejected by triage: relevance %d < %d", tweet.ID, relevance, t.threshold)
+	return &Analysis{
+		Score:     relevance,
+		Category:  "other",
+		Sentiment: "neutral",
+		Urgency:   "low",
+		Reasoning: "triage: " + reason,
+	}
+}
+
+// StartCycle は選別・本分析の両方にサイクル開始を伝える
+func (t *Triage) StartCycle() {
+	t.mu.Lock()
+	t.passed = make(map[string]bool)
+	t.mu.Unlock()
+	for _, v := range []interface{}{t.triager, t.analyzer} {
+		if ca, ok := v.(CycleAware); ok {
+			ca.StartCycle()
+		}
+	}
+}
+
+var (
+	_ BatchAnalyzer = (*Triage)(nil)
+	_ CycleAware    = (*Triage)(nil)
+)
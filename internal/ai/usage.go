@@ -0,0 +1,216 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExceeded は1日のAIコスト上限に達した場合のエラー
+var ErrBudgetExceeded = errors.New("daily AI budget exceeded")
+
+// Price は100万トークンあたりの料金 (USD)
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// defaultPrices はモデル名の接頭辞ごとのデフォルト料金
+var defaultPrices = map[string]Price{
+	"claude-3-5-sonnet": {Input: 3, Output: 15},
+	"claude-3-7-sonnet": {Input: 3, Output: 15},
+	"claude-sonnet-4":   {Input: 3, Output: 15},
+	"claude-3-5-haiku":  {Input: 0.8, Output: 4},
+	"claude-3-haiku":    {Input: 0.25, Output: 1.25},
+	"claude-3-opus":     {Input: 15, Output: 75},
+	"claude-opus-4":     {Input: 15, Output: 75},
+	"gpt-4o-mini":       {Input: 0.15, Output: 0.6},
+	"gpt-4o":            {Input: 2.5, Output: 10},
+}
+
+// Usage は1回のAPI呼び出しのトークン使用量
+type Usage struct {
+	InputTokens  int
+	OutputTokens int
+}
+
+// UsageStats は1日分の累計使用量
+type UsageStats struct {
+	Day          string
+	Calls        int
+	InputTokens  int
+	OutputTokens int
+	CostUSD      float64
+}
+
+// String はログ出力用の表現
+func (s UsageStats) String() string {
+	return fmt.Sprintf("day=%s calls=%d input_tokens=%d output_tokens=%d cost=$%.4f",
+		s.Day, s.Calls, s.InputTokens, s.OutputTokens, s.CostUSD)
+}
+
+// UsageTracker はトークン使用量と推定コストを日単位で集計し、予算上限を管理
+type UsageTracker struct {
+	mu       sync.Mutex
+	prices   map[string]Price
+	dailyUSD float64 // 0の場合は上限なし
+	stats    UsageStats
+	exceeded bool
+	now      func() time.Time
+	recorder func(Call) // nilの場合は呼び出しごとに記録しない
+}
+
+// NewUsageTracker は新しいUsageTrackerを作成
+// pricesはデフォルト料金を上書きする（キーはモデル名の接頭辞）
+func NewUsageTracker(dailyUSD float64, prices map[string]Price) *UsageTracker {
+	merged := make(map[string]Price, len(defaultPrices)+len(prices))
+	for k, v := range defaultPrices {
+		merged[k] = v
+	}
+	for k, v := range prices {
+		merged[k] = v
+	}
+	return &UsageTracker{
+		prices:   merged,
+		dailyUSD: dailyUSD,
+		now:      time.Now,
+	}
+}
+
+// SetRecorder は呼び出しごとの記録先を設定（使用量の台帳に保存する場合）
+func (t *UsageTracker) SetRecorder(fn func(Call)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recorder = fn
+}
+
+// Record はAPI呼び出しの使用量を記録し、今回の推定コストを返す
+// errは呼び出しが失敗した場合のエラー（失敗した呼び出しも記録先に記録する）
+func (t *UsageTracker) Record(ctx context.Context, model string, usage Usage, latency time.Duration, err error) float64 {
+	if t == nil {
+		return 0
+	}
+
+	t.mu.Lock()
+	t.rollover()
+	cost := t.add(model, usage)
+	recorder := t.recorder
+	t.mu.Unlock()
+
+	if recorder != nil {
+		info := callInfoFrom(ctx)
+		call := Call{
+			At:           t.now(),
+			TweetIDs:     info.tweetIDs,
+			Source:       info.source,
+			Model:        model,
+			InputTokens:  usage.InputTokens,
+			OutputTokens: usage.OutputTokens,
+			CostUSD:      cost,
+			LatencyMS:    latency.Milliseconds(),
+			Outcome:      CallOK,
+		}
+		if err != nil {
+			call.Outcome = CallError
+			call.Error = err.Error()
+		}
+		recorder(call)
+	}
+	return cost
+}
+
+// Restore は台帳に記録された当日の呼び出しを集計に加える（再起動しても予算上限を正しく扱う）
+func (t *UsageTracker) Restore(calls []Call) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+	for _, c := range calls {
+		if c.At.In(t.now().Location()).Format("2006-01-02") != t.stats.Day {
+			continue
+		}
+		t.stats.Calls++
+		t.stats.InputTokens += c.InputTokens
+		t.stats.OutputTokens += c.OutputTokens
+		t.stats.CostUSD += c.CostUSD
+	}
+	t.checkBudget()
+}
+
+// add は使用量を当日の集計に加え、今回の推定コストを返す（ロック取得済みで呼ぶ）
+func (t *UsageTracker) add(model string, usage Usage) float64 {
+	cost := t.cost(model, usage)
+	t.stats.Calls++
+	t.stats.InputTokens += usage.InputTokens
+	t.stats.OutputTokens += usage.OutputTokens
+	t.stats.CostUSD += cost
+	t.checkBudget()
+	return cost
+}
+
+// checkBudget は予算上限に達したかを判定（ロック取得済みで呼ぶ）
+func (t *UsageTracker) checkBudget() {
+	if t.dailyUSD > 0 && t.stats.CostUSD >= t.dailyUSD && !t.exceeded {
+		t.exceeded = true
+		log.Printf("Daily AI budget exceeded: $%.4f / $%.2f, AI analysis paused until tomorrow", t.stats.CostUSD, t.dailyUSD)
+	}
+}
+
+// Allow は予算上限に達していない場合nilを返す
+func (t *UsageTracker) Allow() error {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+
+	if t.exceeded {
+		return fmt.Errorf("%w ($%.2f / $%.2f)", ErrBudgetExceeded, t.stats.CostUSD, t.dailyUSD)
+	}
+	return nil
+}
+
+// Stats は当日の累計使用量を返す
+func (t *UsageTracker) Stats() UsageStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+	return t.stats
+}
+
+// rollover は日付が変わっていれば集計をリセット（ロック取得済みで呼ぶ）
+func (t *UsageTracker) rollover() {
+	day := t.now().Format("2006-01-02")
+	if t.stats.Day != day {
+		t.stats = UsageStats{Day: day}
+		t.exceeded = false
+	}
+}
+
+// cost はモデルの料金表から推定コストを計算
+func (t *UsageTracker) cost(model string, usage Usage) float64 {
+	price, ok := t.priceFor(model)
+	if !ok {
+		return 0
+	}
+	return (float64(usage.InputTokens)*price.Input + float64(usage.OutputTokens)*price.Output) / 1_000_000
+}
+
+// priceFor は最も長く一致する接頭辞の料金を返す
+func (t *UsageTracker) priceFor(model string) (Price, bool) {
+	var best string
+	for prefix := range t.prices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return t.prices[best], true
+}
@@ -0,0 +1,72 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ValidationError は分析結果がスキーマを満たしていないことを表す
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid analysis: " + strings.Join(e.Problems, "; ")
+}
+
+var (
+	validSentiments = []string{"bullish", "bearish", "neutral"}
+	validUrgencies  = []string{"critical", "high", "normal", "low"}
+)
+
+// validateAnalysis は分析結果を正規化した上でスキーマに沿っているかを検証
+func validateAnalysis(a *Analysis, categories []string) error {
+	a.Sentiment = strings.ToLower(strings.TrimSpace(a.Sentiment))
+	a.Urgency = strings.ToLower(strings.TrimSpace(a.Urgency))
+	a.Category = strings.TrimSpace(a.Category)
+
+	var problems []string
+	if a.Score < 0 || a.Score > 100 {
+		problems = append(problems, fmt.Sprintf("score %d is out of range 0-100", a.Score))
+	}
+	if a.Confidence < 0 || a.Confidence > 100 {
+		problems = append(problems, fmt.Sprintf("confidence %d is out of range 0-100", a.Confidence))
+	}
+	if !contains(categories, a.Category) {
+		problems = append(problems, fmt.Sprintf("category %q is not one of %s", a.Category, strings.Join(categories, "|")))
+	}
+	if !contains(validSentiments, a.Sentiment) {
+		problems = append(problems, fmt.Sprintf("sentiment %q is not one of %s", a.Sentiment, strings.Join(validSentiments, "|")))
+	}
+	if !contains(validUrgencies, a.Urgency) {
+		problems = append(problems, fmt.Sprintf("urgency %q is not one of %s", a.Urgency, strings.Join(validUrgencies, "|")))
+	}
+	if strings.TrimSpace(a.Summary) == "" {
+		problems = append(problems, "summary is missing")
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// repairProblems は修正プロンプトに含める問題点の一覧を返す
+func repairProblems(err error) []string {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return ve.Problems
+	}
+	return []string{err.Error()}
+}
+
+// contains はスライスに値が含まれるかを返す
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package article
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+const (
+	maxBodyBytes       = 2 << 20 // 読み込むページサイズの上限
+	minParagraphLength = 40      // 本文とみなす段落の最小文字数
+)
+
+var (
+	titlePattern      = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	ogTitlePattern    = regexp.MustCompile(`(?is)<meta[^>]+property=["']og:title["'][^>]+content=["']([^"']*)["']`)
+	noisePattern      = regexp.MustCompile(`(?is)<(script|style|noscript|nav|header|footer|aside|form|svg)[^>]*>.*?</(script|style|noscript|nav|header|footer|aside|form|svg)>`)
+	articlePattern    = regexp.MustCompile(`(?is)<article[^>]*>(.*?)</article>`)
+	paragraphPattern  = regexp.MustCompile(`(?is)<p[^>]*>(.*?)</p>`)
+	tagPattern        = regexp.MustCompile(`(?s)<[^>]+>`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// Fetcher はリンク先ページを取得して本文を抽出
+type Fetcher struct {
+	maxChars   int
+	maxLinks   int
+	httpClient *http.Client
+}
+
+// NewFetcher は新しいFetcherを作成
+func NewFetcher(maxChars, maxLinks int, timeout time.Duration) *Fetcher {
+	return &Fetcher{
+		maxChars: maxChars,
+		maxLinks: maxLinks,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// FetchForTweet はツイートのリンク先を取得し、取得できた本文を返す
+// 取得に失敗したリンクは無視する
+func (f *Fetcher) FetchForTweet(ctx context.Context, tweet twitter.Tweet) []twitter.Article {
+	var articles []twitter.Article
+	for _, link := range tweet.LinkURLs() {
+		if len(articles) >= f.maxLinks {
+			break
+		}
+		a, err := f.Fetch(ctx, link)
+		if err != nil || a.Text == "" {
+			continue
+		}
+		articles = append(articles, *a)
+	}
+	return articles
+}
+
+// Fetch はページを取得して本文を抽出
+func (f *Fetcher) Fetch(ctx context.Context, link string) (*twitter.Article, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", link, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; x-crawler/1.0)")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("article fetch error (status %d): %s", resp.StatusCode, link)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.Contains(ct, "html") {
+		return nil, fmt.Errorf("unsupported content type %s: %s", ct, link)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	title, text := Extract(string(body))
+	return &twitter.Article{
+		URL:   resp.Request.URL.String(),
+		Title: title,
+		Text:  truncate(text, f.maxChars),
+	}, nil
+}
+
+// Extract はHTMLからタイトルと本文を抽出
+// <article>要素があればその中を、なければページ全体の段落を本文とみなす
+func Extract(page string) (title, text string) {
+	if m := ogTitlePattern.FindStringSubmatch(page); m != nil {
+		title = cleanText(m[1])
+	} else if m := titlePattern.FindStringSubmatch(page); m != nil {
+		title = cleanText(m[1])
+	}
+
+	page = noisePattern.ReplaceAllString(page, " ")
+	if m := articlePattern.FindStringSubmatch(page); m != nil {
+		page = m[1]
+	}
+
+	var paragraphs []string
+	for _, m := range paragraphPattern.FindAllStringSubmatch(page, -1) {
+		p := cleanText(m[1])
+		if utf8.RuneCountInString(p) >= minParagraphLength {
+			paragraphs = append(paragraphs, p)
+		}
+	}
+	if len(paragraphs) > 0 {
+		return title, strings.Join(paragraphs, "\n")
+	}
+
+	return title, cleanText(page)
+}
+
+// cleanText はタグを除去してHTMLエンティティと空白を正規化
+func cleanText(s string) string {
+	s = tagPattern.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(s, " "))
+}
+
+// truncate は文字数 (rune) で切り詰める
+func truncate(s string, maxChars int) string {
+	if maxChars <= 0 || utf8.RuneCountInString(s) <= maxChars {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:maxChars]) + "…"
+}
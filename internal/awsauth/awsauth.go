@@ -0,0 +1,292 @@
+// Package awsauth はAWSの認証情報の取得とSignature Version 4の署名（通知先・シークレット・S3の状態の保存で共通）
+package awsauth
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials はAWSの認証情報
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expires         time.Time // 一時的な認証情報の有効期限（ゼロ値は無期限）
+}
+
+// CredentialChain はAWS SDKの標準と同じ順序で認証情報を探す
+// 環境変数 → 共有認証情報ファイル (~/.aws/credentials) → コンテナ (ECS) → EC2インスタンスメタデータ (IMDSv2)
+type CredentialChain struct {
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	cached *Credentials
+}
+
+// NewCredentialChain は新しいCredentialChainを作成
+func NewCredentialChain() *CredentialChain {
+	return &CredentialChain{httpClient: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// Get は認証情報を返す（一時的な認証情報は期限の5分前に取り直す）
+func (c *CredentialChain) Get(ctx context.Context) (*Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cached != nil && (c.cached.Expires.IsZero() || time.Until(c.cached.Expires) > 5*time.Minute) {
+		return c.cached, nil
+	}
+
+	if creds := envCredentials(); creds != nil {
+		c.cached = creds
+		return creds, nil
+	}
+	creds, err := sharedCredentials()
+	if err != nil {
+		return nil, err
+	}
+	if creds == nil {
+		creds, err = c.containerCredentials(ctx)
+	}
+	if err == nil && creds == nil {
+		creds, err = c.instanceCredentials(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS credentials: %w", err)
+	}
+	c.cached = creds
+	return creds, nil
+}
+
+// envCredentials は環境変数の認証情報を返す（未設定の場合はnil）
+func envCredentials() *Credentials {
+	id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if id == "" || secret == "" {
+		return nil
+	}
+	return &Credentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}
+}
+
+// sharedCredentials は共有認証情報ファイルのプロファイル (AWS_PROFILE、未指定時はdefault) を返す
+// ファイルやプロファイルがない場合はnil
+func sharedCredentials() (*Credentials, error) {
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var creds Credentials
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if section != profile {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = strings.TrimSpace(value)
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = strings.TrimSpace(value)
+		case "aws_session_token":
+			creds.SessionToken = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, nil
+	}
+	return &creds, nil
+}
+
+// metadataCredentials はコンテナ・インスタンスメタデータの認証情報のレスポンス
+type metadataCredentials struct {
+	AccessKeyID     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	Token           string    `json:"Token"`
+	Expiration      time.Time `json:"Expiration"`
+}
+
+// containerCredentials はECSなどのコンテナの認証情報エンドポイントから取得（コンテナ外の場合はnil）
+func (c *CredentialChain) containerCredentials(ctx context.Context) (*Credentials, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if rel := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); rel != "" {
+		endpoint = "http://169.254.170.2" + rel
+	}
+	if endpoint == "" {
+		return nil, nil
+	}
+	header := http.Header{}
+	if token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN"); token != "" {
+		header.Set("Authorization", token)
+	}
+	body, err := c.fetch(ctx, "GET", endpoint, header)
+	if err != nil {
+		return nil, fmt.Errorf("container credentials: %w", err)
+	}
+	return parseMetadataCredentials(body)
+}
+
+// instanceCredentials はEC2インスタンスメタデータ (IMDSv2) のIAMロールの認証情報を取得
+func (c *CredentialChain) instanceCredentials(ctx context.Context) (*Credentials, error) {
+	const base = "http://169.254.169.254/latest"
+	token, err := c.fetch(ctx, "PUT", base+"/api/token", http.Header{"X-Aws-Ec2-Metadata-Token-Ttl-Seconds": {"21600"}})
+	if err != nil {
+		return nil, fmt.Errorf("no credentials found in environment, shared credentials file or instance metadata: %w", err)
+	}
+	header := http.Header{"X-Aws-Ec2-Metadata-Token": {string(token)}}
+	roles, err := c.fetch(ctx, "GET", base+"/meta-data/iam/security-credentials/", header)
+	if err != nil {
+		return nil, fmt.Errorf("instance metadata: %w", err)
+	}
+	role := strings.TrimSpace(strings.SplitN(string(roles), "\n", 2)[0])
+	if role == "" {
+		return nil, fmt.Errorf("instance metadata: no IAM role attached")
+	}
+	body, err := c.fetch(ctx, "GET", base+"/meta-data/iam/security-credentials/"+role, header)
+	if err != nil {
+		return nil, fmt.Errorf("instance metadata: %w", err)
+	}
+	return parseMetadataCredentials(body)
+}
+
+// fetch はメタデータエンドポイントにリクエストを送り、本文を返す
+func (c *CredentialChain) fetch(ctx context.Context, method, url string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	return body, nil
+}
+
+// parseMetadataCredentials はメタデータの認証情報のJSONを解析
+func parseMetadataCredentials(body []byte) (*Credentials, error) {
+	var m metadataCredentials
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode credentials: %w", err)
+	}
+	return &Credentials{AccessKeyID: m.AccessKeyID, SecretAccessKey: m.SecretAccessKey, SessionToken: m.Token, Expires: m.Expiration}, nil
+}
+
+// SignV4 はリクエストにAWS Signature Version 4の署名を付ける
+func SignV4(req *http.Request, body []byte, creds *Credentials, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	// 署名対象のヘッダー（小文字・ソート済み）
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
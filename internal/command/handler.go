@@ -0,0 +1,250 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/crawler"
+	"github.com/Minatonton/x-crawler/internal/feedback"
+	"github.com/Minatonton/x-crawler/internal/storage"
+)
+
+const (
+	// Path はSlackのスラッシュコマンド (/xcrawler) のRequest URLに設定するパス
+	Path = "/slack/commands"
+
+	// defaultMuteDuration はミュートの期間を省略した場合の期間
+	defaultMuteDuration = time.Hour
+)
+
+var (
+	usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`)
+	tickerPattern   = regexp.MustCompile(`^[A-Za-z0-9.\-]{1,10}$`)
+)
+
+// usage はコマンドの使い方
+const usage = "使い方:\n" +
+	"• `/xcrawler add-trader @username` 監視対象のトレーダーを追加\n" +
+	"• `/xcrawler mute TICKER [2h]` 銘柄の通知を一定時間止める（省略時は1h、日数は 1d のように指定）\n" +
+	"• `/xcrawler unmute TICKER` 銘柄のミュートを解除\n" +
+	"• `/xcrawler status` 直近のクロールの状況を表示"
+
+// Handler はSlackのスラッシュコマンドで監視対象やミュートを実行中に変更する
+type Handler struct {
+	store         *storage.ControlStore
+	status        func() crawler.Status
+	signingSecret []byte
+}
+
+// NewHandler は新しいHandlerを作成（statusは直近のクロール結果を返す関数、signingSecretはSlackアプリのSigning Secret）
+func NewHandler(store *storage.ControlStore, status func() crawler.Status, signingSecret string) *Handler {
+	return &Handler{
+		store:         store,
+		status:        status,
+		signingSecret: []byte(signingSecret),
+	}
+}
+
+// ServeHTTP はスラッシュコマンドを実行し、結果をSlackに返す
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if !feedback.VerifySlackSignature(h.signingSecret, r.Header, body, time.Now()) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	user := form.Get("user_name")
+	if user == "" {
+		user = form.Get("user_id")
+	}
+
+	args := strings.Fields(form.Get("text"))
+	if len(args) == 0 {
+		respond(w, false, usage)
+		return
+	}
+	switch strings.ToLower(args[0]) {
+	case "add-trader":
+		h.addTrader(w, args[1:], user)
+	case "mute":
+		h.mute(w, args[1:], user)
+	case "unmute":
+		h.unmute(w, args[1:], user)
+	case "status":
+		respond(w, false, h.statusText(time.Now()))
+	default:
+		respond(w, false, fmt.Sprintf("⚠️ 不明なコマンドです: %s\n%s", args[0], usage))
+	}
+}
+
+// addTrader は監視対象のトレーダーを追加（次のクロールから取得する）
+func (h *Handler) addTrader(w http.ResponseWriter, args []string, user string) {
+	if len(args) != 1 {
+		respond(w, false, usage)
+		return
+	}
+	username := strings.TrimPrefix(args[0], "@")
+	if !usernamePattern.MatchString(username) {
+		respond(w, false, fmt.Sprintf("⚠️ ユーザー名が正しくありません: %s", args[0]))
+		return
+	}
+	if !h.store.AddTrader(username) {
+		respond(w, false, fmt.Sprintf("@%s は既に追加されています", username))
+		return
+	}
+	if !h.save(w) {
+		return
+	}
+	log.Printf("Trader @%s added via Slack command (by %s)", username, user)
+	respond(w, true, fmt.Sprintf("✅ @%s を監視対象に追加しました（次のクロールから反映）", username))
+}
+
+// mute は銘柄の通知を一定時間止める
+func (h *Handler) mute(w http.ResponseWriter, args []string, user string) {
+	if len(args) < 1 || len(args) > 2 {
+		respond(w, false, usage)
+		return
+	}
+	ticker, ok := parseTicker(args[0])
+	if !ok {
+		respond(w, false, fmt.Sprintf("⚠️ 銘柄が正しくありません: %s", args[0]))
+		return
+	}
+	d := defaultMuteDuration
+	if len(args) == 2 {
+		var err error
+		if d, err = parseDuration(args[1]); err != nil {
+			respond(w, false, fmt.Sprintf("⚠️ 期間が正しくありません: %s", args[1]))
+			return
+		}
+	}
+
+	until := time.Now().Add(d)
+	h.store.Mute(ticker, until)
+	if !h.save(w) {
+		return
+	}
+	log.Printf("Ticker $%s muted until %s via Slack command (by %s)", ticker, until.Format(time.RFC3339), user)
+	respond(w, true, fmt.Sprintf("🔕 $%s の通知を %s までミュートしました", ticker, until.Format("01/02 15:04")))
+}
+
+// unmute は銘柄のミュートを解除
+func (h *Handler) unmute(w http.ResponseWriter, args []string, user string) {
+	if len(args) != 1 {
+		respond(w, false, usage)
+		return
+	}
+	ticker, ok := parseTicker(args[0])
+	if !ok {
+		respond(w, false, fmt.Sprintf("⚠️ 銘柄が正しくありません: %s", args[0]))
+		return
+	}
+	if !h.store.Unmute(ticker) {
+		respond(w, false, fmt.Sprintf("$%s はミュートされていません", ticker))
+		return
+	}
+	if !h.save(w) {
+		return
+	}
+	log.Printf("Ticker $%s unmuted via Slack command (by %s)", ticker, user)
+	respond(w, true, fmt.Sprintf("🔔 $%s のミュートを解除しました", ticker))
+}
+
+// statusText は直近のクロールの状況と実行時の設定を返す
+func (h *Handler) statusText(now time.Time) string {
+	var b strings.Builder
+	st := h.status()
+	if st.LastRun.IsZero() {
+		b.WriteString("📋 まだクロールしていません\n")
+	} else {
+		fmt.Fprintf(&b, "📋 最終クロール: %s（%s前）\n", st.LastRun.Format("01/02 15:04"), now.Sub(st.LastRun).Round(time.Second))
+		fmt.Fprintf(&b, "処理 %d / 通知 %d / 既読 %d / AI再試行待ち %d / Slack送信待ち %d\n",
+			st.Processed, st.Notified, st.Seen, st.RetryQueue, st.SlackPending)
+	}
+
+	if traders := h.store.Traders(); len(traders) > 0 {
+		b.WriteString("➕ 追加したトレーダー: @" + strings.Join(traders, ", @") + "\n")
+	}
+	if mutes := h.store.Mutes(now); len(mutes) > 0 {
+		parts := make([]string, len(mutes))
+		for i, m := range mutes {
+			parts[i] = fmt.Sprintf("$%s (%sまで)", m.Ticker, m.Until.Format("01/02 15:04"))
+		}
+		b.WriteString("🔕 ミュート中: " + strings.Join(parts, ", ") + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// save は変更を保存し、失敗した場合はエラーを返してfalseを返す
+func (h *Handler) save(w http.ResponseWriter) bool {
+	if err := h.store.Save(); err != nil {
+		log.Printf("Failed to save control state: %v", err)
+		respond(w, false, "⚠️ 変更を保存できませんでした: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// parseTicker は銘柄を正規化（先頭の $ を除いて大文字にする）
+func parseTicker(s string) (string, bool) {
+	ticker := strings.ToUpper(strings.TrimPrefix(s, "$"))
+	return ticker, tickerPattern.MatchString(ticker)
+}
+
+// parseDuration はミュートの期間を解釈（Goの時間表記に加えて 1d のような日数を受け付ける）
+func parseDuration(s string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, err
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive: %s", s)
+	}
+	return d, nil
+}
+
+// respond はコマンドの結果を返す（inChannelの場合はチャンネル全体に表示する）
+func respond(w http.ResponseWriter, inChannel bool, text string) {
+	responseType := "ephemeral"
+	if inChannel {
+		responseType = "in_channel"
+	}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(map[string]interface{}{
+		"response_type": responseType,
+		"text":          text,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf.Bytes())
+}
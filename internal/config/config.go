@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,77 +14,1415 @@ import (
 
 // Config はアプリケーション全体の設定
 type Config struct {
-	Interval string      `yaml:"interval"`
-	AI       AIConfig    `yaml:"ai"`
-	Traders  []Trader    `yaml:"traders"`
-	Keywords []Keyword   `yaml:"keywords"`
-	Slack    SlackConfig `yaml:"slack"`
-	Log      LogConfig   `yaml:"log"`
+	Interval   string           `yaml:"interval"`
+	Reload     ReloadConfig     `yaml:"reload"`
+	AI         AIConfig         `yaml:"ai"`
+	Categories []Category       `yaml:"categories"`
+	Traders    []Trader         `yaml:"traders"`
+	Keywords   []Keyword        `yaml:"keywords"`
+	Slack      SlackConfig      `yaml:"slack"`
+	Feedback   FeedbackConfig   `yaml:"feedback"`
+	Sentiment  SentimentConfig  `yaml:"sentiment"`
+	Stats      StatsConfig      `yaml:"stats"`
+	Analyses   AnalysesConfig   `yaml:"analyses"`
+	Storage    StorageConfig    `yaml:"storage"`
+	Embeddings EmbeddingConfig  `yaml:"embeddings"`
+	Quotes     QuotesConfig     `yaml:"quotes"`
+	Dedupe     DedupeConfig     `yaml:"dedupe"`
+	Relevance  RelevanceConfig  `yaml:"relevance"`
+	Server     ServerConfig     `yaml:"server"`
+	Commands   CommandsConfig   `yaml:"commands"`
+	Email      EmailConfig      `yaml:"email"`
+	Webhooks   []WebhookSink    `yaml:"webhooks"`
+	Plugins    []PluginConfig   `yaml:"plugins"`
+	LINE       LINEConfig       `yaml:"line"`
+	Desktop    DesktopConfig    `yaml:"desktop"`
+	Kafka      KafkaConfig      `yaml:"kafka"`
+	NATS       NATSConfig       `yaml:"nats"`
+	MQTT       MQTTConfig       `yaml:"mqtt"`
+	AWS        AWSConfig        `yaml:"aws"`
+	Archive    ArchiveConfig    `yaml:"archive"`
+	WebSocket  WebSocketConfig  `yaml:"websocket"`
+	Feed       FeedConfig       `yaml:"feed"`
+	Routing    RoutingConfig    `yaml:"routing"`
+	PagerDuty  PagerDutyConfig  `yaml:"pagerduty"`
+	Opsgenie   OpsgenieConfig   `yaml:"opsgenie"`
+	SMS        SMSConfig        `yaml:"sms"`
+	GoogleChat GoogleChatConfig `yaml:"google_chat"`
+	Log        LogConfig        `yaml:"log"`
+
+	Include          []string             `yaml:"include"`            // 重ねて読み込む設定ファイル（相対パスは設定ファイルのディレクトリから、ワイルドカード可）
+	Profiles         map[string]yaml.Node `yaml:"profiles"`           // プロファイルごとに上書きする項目（dev, prod など）
+	AllowUnknownKeys bool                 `yaml:"allow_unknown_keys"` // 未知のキーをエラーにせず無視する（新しいバージョン向けの設定を共有する場合など）
+	Profile          string               `yaml:"-"`                  // 適用したプロファイル（未選択の場合は空）
+	Files            []string             `yaml:"-"`                  // 読み込んだ設定ファイル（本体が先頭、include のファイルが続く）
+}
+
+// ReloadConfig は再起動せずに設定ファイルを読み込み直す設定（SIGHUPでは常に読み込み直す）
+// 反映するのは traders, keywords, interval とスコアのしきい値だけで、それ以外の変更は再起動が必要
+type ReloadConfig struct {
+	Watch         bool   `yaml:"watch"`          // 設定ファイルの更新を監視して読み込み直す
+	WatchInterval string `yaml:"watch_interval"` // 更新を確認する間隔（デフォルト: 10s）
+}
+
+// FeedbackConfig は通知へのフィードバックとスコア補正の設定
+type FeedbackConfig struct {
+	Enabled            bool           `yaml:"enabled"`
+	File               string         `yaml:"file"`                 // フィードバックの保存先
+	Secret             string         `yaml:"secret"`               // フィードバックURLの署名用シークレット
+	SlackSigningSecret string         `yaml:"slack_signing_secret"` // SlackアプリのSigning Secret（指定時はインタラクティブボタンを使用）
+	Examples           int            `yaml:"examples"`             // プロンプトに含める最近のフィードバック件数
+	MaxOffset          int            `yaml:"max_offset"`           // 投稿者ごとのスコア補正の上限（0の場合は補正しない）
+	MinSamples         int            `yaml:"min_samples"`          // スコア補正に必要な最小フィードバック件数
+	Outcomes           OutcomesConfig `yaml:"outcomes"`
+}
+
+// OutcomesConfig は通知した銘柄の事後の値動き（N時間後の騰落率）の記録の設定（株価は quotes.provider から取得）
+type OutcomesConfig struct {
+	Enabled bool  `yaml:"enabled"`
+	Hours   []int `yaml:"hours"` // 通知から値動きを測る時間（デフォルト: [1, 4, 24]）
+}
+
+// SentimentConfig は銘柄別センチメントの日次サマリーの設定
+type SentimentConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	File          string `yaml:"file"`           // 集計データの保存先
+	ReportTime    string `yaml:"report_time"`    // 前日分のサマリーを投稿する時刻 (HH:MM)
+	MaxTickers    int    `yaml:"max_tickers"`    // サマリーに含める最大銘柄数
+	TopTweets     int    `yaml:"top_tweets"`     // 銘柄ごとに含める上位ツイート数
+	RetentionDays int    `yaml:"retention_days"` // 集計データの保持日数
+}
+
+// StatsConfig はクロール統計の日次レポートの設定
+type StatsConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	File          string `yaml:"file"`           // 統計の保存先
+	ReportTime    string `yaml:"report_time"`    // 前日分のレポートを投稿する時刻 (HH:MM)
+	TopTickers    int    `yaml:"top_tickers"`    // レポートに含める通知の多い銘柄数
+	RetentionDays int    `yaml:"retention_days"` // 統計の保持日数
+}
+
+// AnalysesConfig はAI分析結果の保存設定
+type AnalysesConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	File       string `yaml:"file"`        // 分析結果の保存先
+	MaxRecords int    `yaml:"max_records"` // 保持する最大件数（超えた分は古い順に削除）
+}
+
+// StorageConfig は既読ツイートなどの状態の保存先の設定
+type StorageConfig struct {
+	Backend    string              `yaml:"backend"` // json（デフォルト、-seen のファイル）, sqlite, redis, bbolt, remote
+	JSON       JSONStoreConfig     `yaml:"json"`
+	SQLite     SQLiteConfig        `yaml:"sqlite"`
+	Redis      RedisConfig         `yaml:"redis"`
+	Bolt       BoltConfig          `yaml:"bbolt"`
+	Remote     RemoteStateConfig   `yaml:"remote"`
+	Archive    TweetArchiveConfig  `yaml:"archive"`
+	Content    ContentDedupeConfig `yaml:"content_dedupe"`
+	Encryption EncryptionConfig    `yaml:"encryption"`
+	AILedger   AILedgerConfig      `yaml:"ai_ledger"`
+}
+
+// AILedgerConfig はAI API呼び出しごとの記録（使用量の監査用）の設定
+// backend: sqlite の場合はデータベースに、それ以外は日ごとのJSONLファイルに保存する
+type AILedgerConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	Dir           string `yaml:"dir"`            // JSONLファイルの保存先（デフォルト: archive/ai_calls）
+	RetentionDays int    `yaml:"retention_days"` // JSONLファイルを保持する日数（0の場合は削除しない、SQLiteは sqlite.retention_days）
+}
+
+// EncryptionConfig は状態ファイルとアーカイブの暗号化（AES-256-GCM）の設定
+type EncryptionConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Key        string `yaml:"key"`         // 32バイトの鍵（base64 または16進数、通常は ${STATE_ENCRYPTION_KEY}）
+	KeyCommand string `yaml:"key_command"` // 鍵を標準出力に出すコマンド（KMSで復号する場合など。keyより優先）
+}
+
+// ContentDedupeConfig は本文のハッシュによる重複抑制の設定（同じ本文を転載するボット対策）
+type ContentDedupeConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	TTL     string `yaml:"ttl"`  // 同じ本文を重複とみなす期間（デフォルト: 24h）
+	File    string `yaml:"file"` // ハッシュの保存先（backend: json の場合、デフォルト: content_hashes.json）
+}
+
+// TweetArchiveConfig は取得した全ツイートとAI分析結果のアーカイブの設定（保存先のバックエンドとは別に保存）
+type TweetArchiveConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	Dir           string `yaml:"dir"`            // 日ごとのJSONLファイルの保存先（デフォルト: archive/tweets）
+	RetentionDays int    `yaml:"retention_days"` // 保持する日数（0の場合は削除しない）
+}
+
+// JSONStoreConfig はJSONファイルの保存先の設定
+type JSONStoreConfig struct {
+	RetentionDays int    `yaml:"retention_days"` // 既読IDを保持する日数（0の場合は削除しない）
+	MaxIDs        int    `yaml:"max_ids"`        // 保持する既読IDの上限（超えた分は最後に見たのが古い順に削除、0の場合は上限なし）
+	SaveEvery     int    `yaml:"save_every"`     // クロールの途中でも、既読IDをこの件数追加するたびに保存（0の場合は件数では保存しない）
+	SaveInterval  string `yaml:"save_interval"`  // クロールの途中でも、この間隔で保存（デフォルト: 1m、0の場合は時間では保存しない）
+}
+
+// BoltConfig はbboltの保存先の設定
+type BoltConfig struct {
+	File          string `yaml:"file"`           // デフォルト: state.bolt
+	RetentionDays int    `yaml:"retention_days"` // 既読IDを保持する日数（0の場合は削除しない）
+}
+
+// RemoteStateConfig はS3/GCSのオブジェクトに状態を保存する設定（永続ボリュームのないFargate・Cloud Run用）
+type RemoteStateConfig struct {
+	URL           string `yaml:"url"`            // s3://bucket/key または gs://bucket/key
+	Region        string `yaml:"region"`         // S3のリージョン（デフォルト: AWS_REGION）
+	Endpoint      string `yaml:"endpoint"`       // S3互換のサービスやエミュレーターのURL（省略時は本番のAPI）
+	RetentionDays int    `yaml:"retention_days"` // 既読IDを保持する日数（0の場合は削除しない）
+	SaveInterval  string `yaml:"save_interval"`  // クロールの途中でも保存する間隔（デフォルト: 1m、"0" で無効）
+}
+
+// RedisConfig はRedisの保存先の設定（複数のインスタンスやコンテナで状態を共有する場合）
+type RedisConfig struct {
+	URL           string `yaml:"url"`            // redis://[:password@]host:6379/0（デフォルト: redis://localhost:6379/0）
+	KeyPrefix     string `yaml:"key_prefix"`     // キーの接頭辞（デフォルト: xcrawler）
+	RetentionDays int    `yaml:"retention_days"` // 既読IDを保持する日数（0の場合は削除しない）
+	QueueName     string `yaml:"queue_name"`     // Slackの送信待ちを保存するキーの名前（インスタンスごとに変える、デフォルト: default）
+}
+
+// SQLiteConfig はSQLiteの保存先の設定
+type SQLiteConfig struct {
+	File          string `yaml:"file"`           // デフォルト: state.db
+	RetentionDays int    `yaml:"retention_days"` // 既読ID・ツイート・分析結果を保持する日数（0の場合は削除しない）
+}
+
+// EmbeddingConfig は埋め込みベクトルのプロバイダー設定
+type EmbeddingConfig struct {
+	Provider string `yaml:"provider"` // openai (OPENAI_API_KEY), voyage (VOYAGE_API_KEY)
+	Model    string `yaml:"model"`
+	BaseURL  string `yaml:"base_url"` // OpenAI互換APIのベースURL（空の場合はプロバイダーのデフォルト）
+}
+
+// QuotesConfig は通知に表示する株価の設定
+type QuotesConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Provider string `yaml:"provider"`  // yahoo (APIキー不要), alphavantage (ALPHAVANTAGE_API_KEY), finnhub (FINNHUB_API_KEY)
+	CacheTTL string `yaml:"cache_ttl"` // 同じ銘柄の株価を再利用する期間
+}
+
+// DedupeConfig は埋め込みによる意味的な重複抑制の設定
+type DedupeConfig struct {
+	Enabled   bool    `yaml:"enabled"`
+	Threshold float64 `yaml:"threshold"` // これ以上のコサイン類似度を重複とみなす
+	Window    string  `yaml:"window"`    // 比較対象とする通知済みツイートの期間
+}
+
+// RelevanceConfig は埋め込みによる戦略・ウォッチリストとの関連度評価の設定
+type RelevanceConfig struct {
+	Enabled       bool    `yaml:"enabled"`
+	Profile       string  `yaml:"profile"`        // 自然言語で書いた戦略・ウォッチリスト
+	Weight        float64 `yaml:"weight"`         // 最終スコアに占める関連度の比率 (0-1)
+	MinSimilarity float64 `yaml:"min_similarity"` // 関連度0とみなすコサイン類似度
+	MaxSimilarity float64 `yaml:"max_similarity"` // 関連度100とみなすコサイン類似度
+}
+
+// ServerConfig は組み込みHTTPサーバーの設定
+type ServerConfig struct {
+	Listen    string `yaml:"listen"`     // 待ち受けアドレス (例: :8080)
+	PublicURL string `yaml:"public_url"` // Slackのボタンなどから到達可能な外部URL
+}
+
+// CommandsConfig はSlackのスラッシュコマンド (/xcrawler) の設定
+type CommandsConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	File          string `yaml:"file"`           // コマンドで変更した監視対象・ミュートの保存先
+	SigningSecret string `yaml:"signing_secret"` // SlackアプリのSigning Secret（未指定時は feedback.slack_signing_secret）
+}
+
+// SinkFilter はSlack以外の通知先ごとの配信条件（指定した条件を全て満たす通知のみ送る）
+type SinkFilter struct {
+	Decisions  []string `yaml:"decisions"` // 送る処理 (notified, maybe, digest, updated, low_score, duplicate, muted, all)。未指定時は notified, maybe, digest
+	MinScore   int      `yaml:"min_score"`
+	MaxScore   int      `yaml:"max_score"` // 0の場合は上限なし
+	Urgencies  []string `yaml:"urgencies"`
+	Categories []string `yaml:"categories"`
+	Traders    []string `yaml:"traders"`
+	Tickers    []string `yaml:"tickers"`
+}
+
+// validate は配信条件の値を検証
+func (f SinkFilter) validate() error {
+	for _, d := range f.Decisions {
+		switch d {
+		case "notified", "maybe", "digest", "updated", "low_score", "duplicate", "muted", "all":
+		default:
+			return fmt.Errorf("invalid decision %q", d)
+		}
+	}
+	for _, u := range f.Urgencies {
+		switch u {
+		case "critical", "high", "normal", "low":
+		default:
+			return fmt.Errorf("invalid urgency %q", u)
+		}
+	}
+	if f.MaxScore > 0 && f.MaxScore < f.MinScore {
+		return fmt.Errorf("max_score (%d) must not be less than min_score (%d)", f.MaxScore, f.MinScore)
+	}
+	return nil
+}
+
+// escalationDefaults はオンコールへのエスカレーション向けの既定の配信条件を補う
+func (f *SinkFilter) escalationDefaults() {
+	if len(f.Urgencies) == 0 {
+		f.Urgencies = []string{"critical"}
+	}
+	if f.MinScore == 0 {
+		f.MinScore = 80
+	}
+}
+
+// EmailConfig はメール (SMTP) 通知の設定
+type EmailConfig struct {
+	Enabled      bool             `yaml:"enabled"`
+	Host         string           `yaml:"host"`
+	Port         int              `yaml:"port"` // デフォルト: 587（465の場合は暗黙のTLS）
+	Username     string           `yaml:"username"`
+	Password     string           `yaml:"password"`
+	From         string           `yaml:"from"`
+	TemplateFile string           `yaml:"template_file"` // 通知メールのHTMLテンプレート（未指定時は組み込み）
+	Recipients   []EmailRecipient `yaml:"recipients"`
+	Digest       EmailDigest      `yaml:"digest"`
+}
+
+// EmailRecipient はメールの宛先と配信条件
+type EmailRecipient struct {
+	Address    string `yaml:"address"`
+	SinkFilter `yaml:",inline"`
+}
+
+// EmailDigest は前日の通知をまとめた日次ダイジェストメールの設定
+type EmailDigest struct {
+	Enabled      bool     `yaml:"enabled"`
+	To           []string `yaml:"to"`
+	ReportTime   string   `yaml:"report_time"`   // 前日分を送る時刻 (HH:MM)
+	TemplateFile string   `yaml:"template_file"` // ダイジェストのHTMLテンプレート（未指定時は組み込み）
+}
+
+// GetReportTime は送信時刻を0時からの経過時間として返す
+func (d *EmailDigest) GetReportTime() (time.Duration, error) {
+	return parseReportTime(d.ReportTime)
+}
+
+// WebhookSink は通知をJSONでPOSTする外部システム向けWebhookの設定
+type WebhookSink struct {
+	Name        string            `yaml:"name"` // ログ用の名前
+	URL         string            `yaml:"url"`
+	Secret      string            `yaml:"secret"`       // HMAC-SHA256署名のシークレット（未指定時は署名しない）
+	Headers     map[string]string `yaml:"headers"`      // 追加のHTTPヘッダー（認証トークンなど）
+	MaxAttempts int               `yaml:"max_attempts"` // 一時的なエラー時の最大試行回数（デフォルト: 3）
+	Timeout     string            `yaml:"timeout"`      // 1回のリクエストのタイムアウト（デフォルト: 10s）
+	SinkFilter  `yaml:",inline"`
+}
+
+// PluginConfig は notifier.Register で組み込んだ独自の通知先の設定
+type PluginConfig struct {
+	Type       string    `yaml:"type"`    // 登録した種類
+	Name       string    `yaml:"name"`    // ログ・ルーティング用の名前（未指定時は通知先のName()）
+	Options    yaml.Node `yaml:"options"` // 通知先ごとの設定（notifier.Options.Decodeで読み込む）
+	SinkFilter `yaml:",inline"`
+}
+
+// LINEConfig はLINE Messaging APIによる通知の設定
+type LINEConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	ChannelToken string   `yaml:"channel_token"` // チャネルアクセストークン
+	To           []string `yaml:"to"`            // 送信先のユーザー・グループ・トークルームのID
+	SinkFilter   `yaml:",inline"`
+}
+
+// PagerDutyConfig はPagerDutyへのエスカレーションの設定
+// 配信条件の既定は urgencies: [critical], min_score: 80
+type PagerDutyConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	RoutingKey string `yaml:"routing_key"` // Events API v2のIntegration Key
+	SinkFilter `yaml:",inline"`
+}
+
+// OpsgenieConfig はOpsgenieへのエスカレーションの設定
+// 配信条件の既定は urgencies: [critical], min_score: 80
+type OpsgenieConfig struct {
+	Enabled    bool     `yaml:"enabled"`
+	APIKey     string   `yaml:"api_key"`
+	APIURL     string   `yaml:"api_url"` // デフォルト: https://api.opsgenie.com（EUは https://api.eu.opsgenie.com）
+	Tags       []string `yaml:"tags"`
+	SinkFilter `yaml:",inline"`
+}
+
+// SMSConfig はTwilioによるSMS通知の設定
+// 配信条件の既定は urgencies: [critical], min_score: 80
+type SMSConfig struct {
+	Enabled    bool     `yaml:"enabled"`
+	AccountSID string   `yaml:"account_sid"`
+	AuthToken  string   `yaml:"auth_token"`
+	From       string   `yaml:"from"` // 送信元の電話番号 (E.164) またはMessaging ServiceのSID (MG...)
+	To         []string `yaml:"to"`   // 送信先の電話番号 (E.164)
+	SinkFilter `yaml:",inline"`
+}
+
+// GoogleChatConfig はGoogle ChatのIncoming Webhookによる通知の設定
+type GoogleChatConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	WebhookURL string `yaml:"webhook_url"`
+	SinkFilter `yaml:",inline"`
+}
+
+// DesktopConfig はOSのデスクトップ通知の設定
+type DesktopConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	SinkFilter `yaml:",inline"`
+}
+
+// KafkaConfig は処理結果をKafkaのトピックに送る設定（Kafka REST Proxy経由）
+type KafkaConfig struct {
+	Enabled    bool              `yaml:"enabled"`
+	RESTURL    string            `yaml:"rest_url"` // Kafka REST ProxyのURL
+	Topic      string            `yaml:"topic"`    // {decision} {urgency} {category} {ticker} {username} を置換（デフォルト: xcrawler.events）
+	Headers    map[string]string `yaml:"headers"`  // 追加のHTTPヘッダー（認証など）
+	SinkFilter `yaml:",inline"`  // decisions の未指定時は全ての処理
+}
+
+// NATSConfig は処理結果をNATSのサブジェクトにパブリッシュする設定
+type NATSConfig struct {
+	Enabled    bool             `yaml:"enabled"`
+	URL        string           `yaml:"url"`     // nats://host:4222 または tls://host:4222（user:pass@ で認証）
+	Token      string           `yaml:"token"`   // トークン認証
+	Subject    string           `yaml:"subject"` // {decision} {urgency} {category} {ticker} {username} を置換（デフォルト: xcrawler.events.{decision}）
+	SinkFilter `yaml:",inline"` // decisions の未指定時は全ての処理
+}
+
+// MQTTConfig はMQTTブローカー (3.1.1) に処理結果をパブリッシュする設定
+type MQTTConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	URL        string `yaml:"url"`       // mqtt://host:1883 または mqtts://host:8883（user:pass@ で認証）
+	ClientID   string `yaml:"client_id"` // 未指定時はランダム
+	Topic      string `yaml:"topic"`     // {decision} {urgency} {category} {ticker} {username} を置換（{ticker} は銘柄ごとにパブリッシュ、デフォルト: xcrawler/{urgency}/{ticker}）
+	QoS        int    `yaml:"qos"`       // 0 または 1
+	Retain     bool   `yaml:"retain"`
+	SinkFilter `yaml:",inline"`
+}
+
+// AWSConfig はAmazon SNS/SQSに通知を送る設定
+// 認証情報はAWS SDKの標準の順序（環境変数、~/.aws/credentials、ECS、EC2インスタンスメタデータ）で探す
+type AWSConfig struct {
+	Region string    `yaml:"region"` // 未指定時はトピックのARN・キューのURL、AWS_REGION の順
+	SNS    SNSConfig `yaml:"sns"`
+	SQS    SQSConfig `yaml:"sqs"`
+}
+
+// SNSConfig はAmazon SNSのトピックに発行する設定
+type SNSConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	TopicARN   string `yaml:"topic_arn"`
+	SinkFilter `yaml:",inline"`
+}
+
+// SQSConfig はAmazon SQSのキューに送る設定
+type SQSConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	QueueURL   string `yaml:"queue_url"`
+	SinkFilter `yaml:",inline"`
+}
+
+// ArchiveConfig は通知をJSONLファイルに追記するローカルアーカイブの設定
+type ArchiveConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	File       string `yaml:"file"`        // 書き込み中のファイル（デフォルト: archive/notifications.jsonl）
+	Rotate     string `yaml:"rotate"`      // none, daily (デフォルト), size
+	MaxSizeMB  int    `yaml:"max_size_mb"` // rotate: size の上限（デフォルト: 100）
+	MaxFiles   int    `yaml:"max_files"`   // 残すローテーション済みファイルの数（0の場合は全て残す）
+	SinkFilter `yaml:",inline"`
+}
+
+// WebSocketConfig は組み込みHTTPサーバーから通知をリアルタイム配信するWebSocketの設定
+type WebSocketConfig struct {
+	Enabled        bool     `yaml:"enabled"`
+	Token          string   `yaml:"token"`           // 接続に必要なトークン（server.listen がループバック以外の場合は必須）
+	MaxClients     int      `yaml:"max_clients"`     // 同時接続数の上限（0の場合は無制限）
+	AllowedOrigins []string `yaml:"allowed_origins"` // 接続を許可するブラウザのOrigin（同一ホストとOriginのないクライアントは常に許可）
+	SinkFilter     `yaml:",inline"`
+}
+
+// FeedConfig は組み込みHTTPサーバーから通知をRSS/Atomフィードで配信する設定
+type FeedConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Title      string `yaml:"title"`     // デフォルト: X Trading Crawler
+	MaxItems   int    `yaml:"max_items"` // フィードに含める直近の件数（デフォルト: 100）
+	Token      string `yaml:"token"`     // 購読に必要なトークン（?token=、空の場合は認証しない）
+	SinkFilter `yaml:",inline"`
+}
+
+// RoutingConfig はSlack以外の通知先への振り分けルールの設定
+// ルールにもdefaultにも名前のない通知先は振り分けの対象外で、従来どおり全ての通知を受け取る
+type RoutingConfig struct {
+	Rules   []RoutingRule `yaml:"rules"`
+	Default []string      `yaml:"default"` // どのルールにも一致しない通知の送り先
+}
+
+// RoutingRule は振り分けルール（条件に一致した通知をsinksに送る）
+type RoutingRule struct {
+	Name       string   `yaml:"name"`
+	Sinks      []string `yaml:"sinks"` // 通知先の名前 (email, line, google_chat, sms, desktop, pagerduty, opsgenie, kafka, nats, mqtt, sns, sqs, jsonl, websocket, feed, webhook:<name>)
+	Stop       bool     `yaml:"stop"`  // 一致した場合に後続のルールを評価しない
+	SinkFilter `yaml:",inline"`
+}
+
+// Category はユーザー定義の分析カテゴリ
+type Category struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"` // AIに伝えるカテゴリの定義
+	Emoji       string `yaml:"emoji"`       // 通知タイトルに付ける絵文字
+	Color       string `yaml:"color"`       // 通知の色 (例: #6F42C1)
+	Channel     string `yaml:"channel"`     // 投稿先チャンネル
 }
 
 // AIConfig はAI分析の設定
 type AIConfig struct {
+	Enabled       bool             `yaml:"enabled"`
+	Provider      string           `yaml:"provider"`         // anthropic, openai, rules
+	Providers     []string         `yaml:"providers"`        // フォールバック順のプロバイダー一覧（指定時はproviderより優先）
+	Timeout       string           `yaml:"provider_timeout"` // プロバイダーごとのタイムアウト (例: 30s)
+	MinScore      int              `yaml:"min_score"`
+	MinConfidence int              `yaml:"min_confidence"` // これ未満の確信度は「要確認」として通知（0の場合は無効）
+	Model         string           `yaml:"model"`
+	OnFailure     string           `yaml:"on_failure"`    // notify-simple, queue-for-retry, skip
+	RuleFallback  bool             `yaml:"rule_fallback"` // AIが無効・利用不可の場合にルールベースのスコアラーを使う
+	OpenAI        OpenAIConfig     `yaml:"openai"`
+	Retry         RetryConfig      `yaml:"retry"`
+	Triage        TriageConfig     `yaml:"triage"`
+	Vision        VisionConfig     `yaml:"vision"`
+	Stream        StreamConfig     `yaml:"stream"`
+	Articles      ArticleConfig    `yaml:"articles"`
+	Experiment    ExperimentConfig `yaml:"experiment"`
+	Limits        LimitsConfig     `yaml:"limits"`
+	Redaction     RedactionConfig  `yaml:"redaction"`
+
+	PromptTemplate string    `yaml:"prompt_template"` // 分析プロンプト (Go text/template)
+	PromptFile     string    `yaml:"prompt_file"`     // 分析プロンプトのテンプレートファイル
+	Watchlist      []string  `yaml:"watchlist"`       // プロンプトに渡すウォッチリストのティッカー
+	SymbolsFile    string    `yaml:"symbols_file"`    // ティッカーの検証に使うシンボル一覧ファイル（空の場合は形式のみ検証）
+	Examples       []Example `yaml:"examples"`        // few-shotの参考例
+	OutputLanguage string    `yaml:"output_language"` // サマリー等の出力言語 (ja, en など)
+	CacheTTL       string    `yaml:"cache_ttl"`       // 同一本文の分析結果を再利用する期間（空の場合は無効）
+	BatchSize      int       `yaml:"batch_size"`      // 1リクエストでまとめて分析する最大件数（1以下の場合は無効）
+	ThreadAnalysis bool      `yaml:"thread_analysis"` // 同じ投稿者のスレッドをまとめて1件として分析
+
+	DailyBudgetUSD float64                `yaml:"daily_budget_usd"` // 1日あたりのAIコスト上限（0の場合は無制限）
+	Pricing        map[string]PriceConfig `yaml:"pricing"`          // モデル名の接頭辞ごとの料金（デフォルトを上書き）
+}
+
+// PriceConfig は100万トークンあたりの料金 (USD)
+type PriceConfig struct {
+	Input  float64 `yaml:"input"`
+	Output float64 `yaml:"output"`
+}
+
+// Example はスコア基準を調整するためのfew-shot参考例
+type Example struct {
+	Author    string `yaml:"author"`
+	Text      string `yaml:"text"`
+	Score     int    `yaml:"score"`
+	Category  string `yaml:"category"`
+	Sentiment string `yaml:"sentiment"`
+	Urgency   string `yaml:"urgency"`
+	Reason    string `yaml:"reason"`
+}
+
+// RetryConfig はClaude APIの過負荷エラー時のリトライ設定
+type RetryConfig struct {
+	MaxRetries     int    `yaml:"max_retries"`      // 1リクエストあたりの最大リトライ回数
+	BudgetPerCycle int    `yaml:"budget_per_cycle"` // 1サイクルあたりのリトライ総数の上限
+	BaseDelay      string `yaml:"base_delay"`       // 指数バックオフの初期待機時間
+	MaxDelay       string `yaml:"max_delay"`        // 待機時間の上限
+}
+
+// TriageConfig は安価なモデルによる一次選別の設定
+type TriageConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	Model     string `yaml:"model"`     // 一次選別に使うClaudeモデル
+	Threshold int    `yaml:"threshold"` // 本分析に回す最低関連度 (min_score以下)
+}
+
+// VisionConfig は添付画像の分析設定（anthropicプロバイダーのみ）
+type VisionConfig struct {
+	Enabled   bool `yaml:"enabled"`
+	MaxImages int  `yaml:"max_images"` // 1ポストあたりの最大画像数
+}
+
+// StreamConfig はClaude APIのストリーミング設定（anthropicプロバイダーのみ）
+type StreamConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	IdleTimeout string `yaml:"idle_timeout"` // データが途切れてから打ち切るまでの時間
+}
+
+// ExperimentConfig はプロンプト・モデルのA/Bテストの設定
+type ExperimentConfig struct {
+	Enabled        bool   `yaml:"enabled"`
+	Name           string `yaml:"name"`            // 代替グループの名前（通知・記録に付く）
+	Percent        int    `yaml:"percent"`         // 代替グループに回すツイートの割合 (0-100)
+	Model          string `yaml:"model"`           // 代替グループのモデル（空の場合は通常と同じ）
+	PromptTemplate string `yaml:"prompt_template"` // 代替グループのプロンプト（空の場合は通常と同じ）
+	PromptFile     string `yaml:"prompt_file"`
+}
+
+// LimitsConfig はAI APIへのリクエスト制限（プロバイダーごとに適用、0の場合は無制限）
+type LimitsConfig struct {
+	MaxConcurrent     int `yaml:"max_concurrent"`      // 同時リクエスト数の上限
+	RequestsPerMinute int `yaml:"requests_per_minute"` // 1分あたりのリクエスト数の上限（リトライ・修正依頼を含む）
+}
+
+// RedactionConfig はAIプロバイダーに送る前に個人情報を伏せる設定
+type RedactionConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	Mode         string   `yaml:"mode"`          // mask（記号に置換）, strip（削除）
+	AllowHandles []string `yaml:"allow_handles"` // 監視対象のトレーダー以外に伏せない@ハンドル
+}
+
+// ArticleConfig はリンク先記事の取得設定
+type ArticleConfig struct {
 	Enabled  bool   `yaml:"enabled"`
-	MinScore int    `yaml:"min_score"`
-	Model    string `yaml:"model"`
+	MaxChars int    `yaml:"max_chars"` // プロンプトに含める本文の最大文字数
+	MaxLinks int    `yaml:"max_links"` // 1ポストあたりに取得する最大リンク数
+	Timeout  string `yaml:"timeout"`   // 1ページあたりの取得タイムアウト
+}
+
+// OpenAIConfig はOpenAI互換APIの設定
+type OpenAIConfig struct {
+	Model      string `yaml:"model"`
+	BaseURL    string `yaml:"base_url"`    // Azure OpenAI / OpenRouter などを使う場合に変更
+	APIVersion string `yaml:"api_version"` // Azure OpenAI の場合のみ指定
 }
 
+// AI分析失敗時の挙動
+const (
+	OnFailureNotifySimple = "notify-simple"   // シンプル通知にフォールバック
+	OnFailureQueueRetry   = "queue-for-retry" // リトライキューに入れて次回サイクルで再分析
+	OnFailureSkip         = "skip"            // 通知せず未読のまま残し次回サイクルで再取得
+)
+
 // Trader は監視対象のトレーダー
 type Trader struct {
 	Username    string `yaml:"username"`
 	DisplayName string `yaml:"display_name"`
-	Priority    string `yaml:"priority"` // critical, high, normal, low
+	Priority    string `yaml:"priority"`      // critical, high, normal, low
+	Context     string `yaml:"context"`       // AIプロンプトに渡す経歴・専門分野
+	OnAIFailure string `yaml:"on_ai_failure"` // 未指定時は ai.on_failure
+
+	// 以下は未指定時は全体の設定に従う個別の上書き
+	MinScore       int    `yaml:"min_score"`       // 通知する最低スコア（未指定時は ai.min_score）
+	MaxResults     int    `yaml:"max_results"`     // 1回に取得するツイート数 (5-100、未指定時は10)
+	Interval       string `yaml:"interval"`        // 取得間隔（全体の interval より長い場合のみ有効）
+	Channel        string `yaml:"channel"`         // 通知の投稿先チャンネル（slack.routes に一致しない通知に適用）
+	SkipAI         bool   `yaml:"skip_ai"`         // AI分析せずにシンプル通知する
+	IncludeReplies bool   `yaml:"include_replies"` // 他人へのリプライも取得する
+}
+
+// GetInterval はトレーダー個別の取得間隔を返す（未指定の場合は0）
+func (t Trader) GetInterval() (time.Duration, error) {
+	if t.Interval == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(t.Interval)
 }
 
 // Keyword は監視対象のキーワード
 type Keyword struct {
-	Query string `yaml:"query"`
-	Name  string `yaml:"name"`
+	Query       string `yaml:"query"`
+	Name        string `yaml:"name"`
+	OnAIFailure string `yaml:"on_ai_failure"` // 未指定時は ai.on_failure
+
+	Lang          string   `yaml:"lang"`           // 言語 (en, ja など、クエリに lang: を付加)
+	ExcludeTerms  []string `yaml:"exclude_terms"`  // 含む投稿を除外する語句（クエリに -語句 を付加）
+	ResultType    string   `yaml:"result_type"`    // recent (新しい順、デフォルト) または popular (関連度・反応の多い順)
+	MinEngagement int      `yaml:"min_engagement"` // いいね・リポスト・リプライ・引用の合計がこれ未満の投稿は処理しない
+	MinScore      int      `yaml:"min_score"`      // 通知する最低スコア（未指定時は ai.min_score）
+}
+
+// キーワード検索の並び順
+const (
+	ResultTypeRecent  = "recent"
+	ResultTypePopular = "popular"
+)
+
+// SearchQuery は lang と exclude_terms を付加した検索クエリを返す
+func (k Keyword) SearchQuery() string {
+	var extra []string
+	if k.Lang != "" && !strings.Contains(k.Query, "lang:") {
+		extra = append(extra, "lang:"+k.Lang)
+	}
+	for _, term := range k.ExcludeTerms {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		if strings.ContainsAny(term, " \t") {
+			term = strconv.Quote(term)
+		}
+		extra = append(extra, "-"+term)
+	}
+	if len(extra) == 0 {
+		return k.Query
+	}
+	// ORより空白（AND）が先に結合されるため、元のクエリを括弧で囲む
+	return "(" + k.Query + ") " + strings.Join(extra, " ")
 }
 
 // SlackConfig はSlack通知の設定
 type SlackConfig struct {
+	Mode            string            `yaml:"mode"` // webhook (デフォルト) または bot
+	WebhookURL      string            `yaml:"webhook_url"`
+	MaybeWebhookURL string            `yaml:"maybe_webhook_url"` // 確信度の低い通知の投稿先（未指定時は通常のWebhook）
+	BotToken        string            `yaml:"bot_token"`         // botモードのBot User OAuth Token (xoxb-...)
+	Channel         string            `yaml:"channel"`           // botモードの投稿先チャンネル（名前またはID）
+	MaybeChannel    string            `yaml:"maybe_channel"`     // botモードで確信度の低い通知の投稿先（未指定時は通常のチャンネル）
+	ThreadByTicker  bool              `yaml:"thread_by_ticker"`  // 同じ日の同じ銘柄の通知を最初の通知のスレッドにまとめる（botモードのみ）
+	Username        string            `yaml:"username"`
+	IconEmoji       string            `yaml:"icon_emoji"`
+	Routes          []SlackRoute      `yaml:"routes"`          // 通知の振り分けルール（上から順に評価し最初に一致したものを使用）
+	Webhooks        []SlackWebhook    `yaml:"webhooks"`        // 条件に応じて通知の複製を送る追加のWebhook
+	OpsWebhookURL   string            `yaml:"ops_webhook_url"` // 運用向けの通知（統計レポートなど）の投稿先（未指定時は通常のWebhook）
+	OpsChannel      string            `yaml:"ops_channel"`     // 運用向けの通知の投稿先チャンネル（botモード）
+	Alerts          AlertConfig       `yaml:"alerts"`
+	QueueFile       string            `yaml:"queue_file"` // 送信待ちの通知の保存先（未指定時は保存しない）
+	Digest          DigestConfig      `yaml:"digest"`
+	Throttle        ThrottleConfig    `yaml:"throttle"`
+	Language        string            `yaml:"language"` // 通知の表示言語 (ja, en)。未指定時は ai.output_language（対応していない場合は ja）
+	Messages        map[string]string `yaml:"messages"` // 表示文言の個別上書き（キーは slack/messages.go を参照）
+	Styles          SlackStyles       `yaml:"styles"`
+	Mentions        MentionConfig     `yaml:"mentions"`
+	Chart           ChartConfig       `yaml:"chart"`
+	IncludeMedia    bool              `yaml:"include_media"` // ポストの添付画像を通知に含める
+	ShowMetrics     bool              `yaml:"show_metrics"`  // エンゲージメントと投稿者のフォロワー数を表示
+	Compact         bool              `yaml:"compact"`       // 全ての通知を1行の簡潔な形式で投稿（routes, webhooks ごとにも指定可能）
+	Template        string            `yaml:"template"`      // ペイロード全体のGoテンプレート (JSON)
+	TemplateFile    string            `yaml:"template_file"` // templateをファイルから読み込む場合のパス
+}
+
+// ChartConfig は通知に添付するチャート画像の設定
+type ChartConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	URL     string `yaml:"url"` // 画像URLのテンプレート（{ticker} を銘柄に置換）。未指定時はFinvizの日足チャート
+}
+
+// SlackWebhook は追加のWebhookと配信条件（空でない条件を全て満たす通知のみ送る）
+type SlackWebhook struct {
+	Name       string   `yaml:"name"` // ログ用の名前
+	URL        string   `yaml:"url"`
+	MinScore   int      `yaml:"min_score"`
+	Categories []string `yaml:"categories"`
+	Traders    []string `yaml:"traders"`
+	Tickers    []string `yaml:"tickers"`
+	Compact    bool     `yaml:"compact"` // 1行の簡潔な形式で送る
+}
+
+// AlertConfig は運用上の障害を運用向けの投稿先に通知する設定
+type AlertConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Interval string `yaml:"interval"` // 同じ種類のアラートを再送するまでの最短間隔（デフォルト: 1h）
+}
+
+// MentionConfig は緊急の通知に付けるメンションの設定
+type MentionConfig struct {
+	Targets   []string `yaml:"targets"`   // @here, @channel, ユーザーグループID (S...), ユーザーID (U...)
+	Urgencies []string `yaml:"urgencies"` // メンションを付ける緊急度（デフォルト: critical）
+}
+
+// SlackStyles は通知の絵文字・色の対応（未指定の項目は既定値）
+type SlackStyles struct {
+	Urgency    map[string]SlackStyle `yaml:"urgency"`    // critical, high, normal, low
+	Sentiment  map[string]SlackStyle `yaml:"sentiment"`  // bullish, bearish, neutral, unknown
+	Categories map[string]SlackStyle `yaml:"categories"` // カテゴリ名 (categories の emoji / color より優先)
+}
+
+// SlackStyle は絵文字と色
+type SlackStyle struct {
+	Emoji string `yaml:"emoji"`
+	Color string `yaml:"color"` // 例: #FF0000
+}
+
+// DigestConfig は緊急でない通知をまとめて投稿するダイジェストの設定
+type DigestConfig struct {
+	Enabled   bool     `yaml:"enabled"`
+	Interval  string   `yaml:"interval"`  // まとめる間隔 (例: 1h, 24h)
+	Immediate []string `yaml:"immediate"` // ダイジェストに溜めず即時通知する緊急度（デフォルト: critical）
+}
+
+// ThrottleConfig は緊急度ごとの即時通知の上限（超えた分はダイジェストに回す）
+type ThrottleConfig struct {
+	Enabled bool           `yaml:"enabled"`
+	Window  string         `yaml:"window"` // 上限を数える期間（デフォルト: 1h）
+	Limits  map[string]int `yaml:"limits"` // 緊急度 -> 期間内の上限（指定しない緊急度は無制限）
+}
+
+// SlackRoute は通知の振り分けルール
+// 指定した条件 (category, urgency, trader) を全て満たす通知を指定の投稿先に送る
+type SlackRoute struct {
+	Category   string `yaml:"category"`
+	Urgency    string `yaml:"urgency"` // critical, high, normal, low
+	Trader     string `yaml:"trader"`  // 投稿者のユーザー名
+	Channel    string `yaml:"channel"`
 	WebhookURL string `yaml:"webhook_url"`
-	Username   string `yaml:"username"`
-	IconEmoji  string `yaml:"icon_emoji"`
+	Compact    bool   `yaml:"compact"` // 1行の簡潔な形式で投稿する
 }
 
+// Slack通知の送信方式
+const (
+	SlackModeWebhook = "webhook" // Incoming Webhook
+	SlackModeBot     = "bot"     // Botトークン + Web API (chat.postMessage / chat.update)
+)
+
 // LogConfig はログの設定
 type LogConfig struct {
 	Level string `yaml:"level"` // debug, info, warn, error
 }
 
-// Load は設定ファイルを読み込む
+// Load は設定ファイルを読み込む（include のファイルを重ね、X_CRAWLER_PROFILE が指定されていればそのプロファイルを重ねる）
+// 未知のキーがある場合は allow_unknown_keys: true でなければエラーにする
 func Load(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
+	return load(path, SelectedProfile(), true)
+}
+
+// load は設定ファイルを include のファイルと重ねて読み込み、profileが空でなければそのプロファイルを重ねる
+// strictの場合は未知のキーをエラーにする（Validateは未知のキーをファイルごとに確認するためfalse）
+func load(path, profile string, strict bool) (*Config, error) {
+	root, files, err := readConfig(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, err
+	}
+	if profile != "" {
+		if err := applyProfile(root, profile); err != nil {
+			return nil, err
+		}
+	}
+	if err := resolveSecrets(root); err != nil {
+		return nil, err
 	}
-
-	// 環境変数を展開
-	content := os.ExpandEnv(string(data))
-
 	var config Config
-	if err := yaml.Unmarshal([]byte(content), &config); err != nil {
+	if err := root.Decode(&config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	if strict && !config.AllowUnknownKeys {
+		if err := checkUnknownKeys(root); err != nil {
+			return nil, err
+		}
+	}
+	config.Profile = profile
+	config.Files = files
 
 	// デフォルト値の設定
 	if config.Interval == "" {
 		config.Interval = "5m"
 	}
+	if config.Reload.WatchInterval == "" {
+		config.Reload.WatchInterval = "10s"
+	}
+	if d, err := time.ParseDuration(config.Reload.WatchInterval); err != nil || d <= 0 {
+		return nil, fmt.Errorf("invalid reload.watch_interval: %s", config.Reload.WatchInterval)
+	}
 	if config.AI.MinScore == 0 {
 		config.AI.MinScore = 70
 	}
+	if config.AI.OutputLanguage == "" {
+		config.AI.OutputLanguage = "ja"
+	}
+	if config.AI.Provider == "" {
+		config.AI.Provider = "anthropic"
+	}
 	if config.AI.Model == "" {
 		config.AI.Model = "claude-3-5-sonnet-20241022"
 	}
+	if config.AI.Retry.MaxRetries == 0 {
+		config.AI.Retry.MaxRetries = 3
+	}
+	if config.AI.Retry.BudgetPerCycle == 0 {
+		config.AI.Retry.BudgetPerCycle = 20
+	}
+	if config.AI.Retry.BaseDelay == "" {
+		config.AI.Retry.BaseDelay = "2s"
+	}
+	if config.AI.Retry.MaxDelay == "" {
+		config.AI.Retry.MaxDelay = "60s"
+	}
+	if config.AI.Triage.Model == "" {
+		config.AI.Triage.Model = "claude-3-5-haiku-20241022"
+	}
+	if config.AI.Triage.Threshold == 0 {
+		config.AI.Triage.Threshold = 40
+	}
+	if config.AI.Triage.Enabled && config.AI.Triage.Threshold > config.AI.MinScore {
+		return nil, fmt.Errorf("ai.triage.threshold (%d) must not exceed ai.min_score (%d)",
+			config.AI.Triage.Threshold, config.AI.MinScore)
+	}
+	if config.AI.Vision.MaxImages == 0 {
+		config.AI.Vision.MaxImages = 4
+	}
+	if config.AI.Articles.MaxChars == 0 {
+		config.AI.Articles.MaxChars = 2000
+	}
+	if config.AI.Articles.MaxLinks == 0 {
+		config.AI.Articles.MaxLinks = 2
+	}
+	if config.AI.Articles.Timeout == "" {
+		config.AI.Articles.Timeout = "10s"
+	}
+	if config.AI.Experiment.Name == "" {
+		config.AI.Experiment.Name = "variant"
+	}
+	if config.AI.Experiment.Enabled {
+		if config.AI.Experiment.Percent <= 0 || config.AI.Experiment.Percent > 100 {
+			return nil, fmt.Errorf("ai.experiment.percent must be between 1 and 100")
+		}
+		if config.AI.Experiment.Name == "control" {
+			return nil, fmt.Errorf("ai.experiment.name must not be \"control\"")
+		}
+	}
+	if config.AI.Redaction.Mode == "" {
+		config.AI.Redaction.Mode = "mask"
+	}
+	if config.AI.Redaction.Mode != "mask" && config.AI.Redaction.Mode != "strip" {
+		return nil, fmt.Errorf("invalid ai.redaction.mode: %s (expected mask or strip)", config.AI.Redaction.Mode)
+	}
+	if config.AI.Stream.IdleTimeout == "" {
+		config.AI.Stream.IdleTimeout = "20s"
+	}
+	if _, err := time.ParseDuration(config.AI.Stream.IdleTimeout); err != nil {
+		return nil, fmt.Errorf("invalid ai.stream.idle_timeout: %w", err)
+	}
+	if config.AI.OpenAI.Model == "" {
+		config.AI.OpenAI.Model = "gpt-4o-mini"
+	}
+	if config.AI.OnFailure == "" {
+		config.AI.OnFailure = OnFailureNotifySimple
+	}
+	if err := validateOnFailure(config.AI.OnFailure); err != nil {
+		return nil, fmt.Errorf("ai.on_failure: %w", err)
+	}
+	if config.AI.BatchSize > 20 {
+		return nil, fmt.Errorf("ai.batch_size must be 20 or less")
+	}
+	seenCategories := make(map[string]bool)
+	for i, c := range config.Categories {
+		if c.Name == "" {
+			return nil, fmt.Errorf("categories[%d]: name is required", i)
+		}
+		if seenCategories[c.Name] {
+			return nil, fmt.Errorf("categories[%d]: duplicate category %q", i, c.Name)
+		}
+		seenCategories[c.Name] = true
+	}
+	for i, ex := range config.AI.Examples {
+		if ex.Text == "" || ex.Category == "" {
+			return nil, fmt.Errorf("ai.examples[%d]: text and category are required", i)
+		}
+		if ex.Score < 0 || ex.Score > 100 {
+			return nil, fmt.Errorf("ai.examples[%d]: score must be between 0 and 100", i)
+		}
+	}
+	for i, t := range config.Traders {
+		if err := validateOnFailure(t.OnAIFailure); err != nil {
+			return nil, fmt.Errorf("trader @%s on_ai_failure: %w", t.Username, err)
+		}
+		if t.MinScore < 0 || t.MinScore > 100 {
+			return nil, fmt.Errorf("traders[%d].min_score: must be between 0 and 100 (got %d)", i, t.MinScore)
+		}
+		if t.MaxResults != 0 && (t.MaxResults < 5 || t.MaxResults > 100) {
+			return nil, fmt.Errorf("traders[%d].max_results: must be between 5 and 100 (got %d)", i, t.MaxResults)
+		}
+		if d, err := t.GetInterval(); err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid traders[%d].interval: %q", i, t.Interval)
+		}
+	}
+	for i, k := range config.Keywords {
+		if err := validateOnFailure(k.OnAIFailure); err != nil {
+			return nil, fmt.Errorf("keyword '%s' on_ai_failure: %w", k.Name, err)
+		}
+		switch k.ResultType {
+		case "", ResultTypeRecent, ResultTypePopular:
+		default:
+			return nil, fmt.Errorf("invalid keywords[%d].result_type: %s (expected %s or %s)", i, k.ResultType, ResultTypeRecent, ResultTypePopular)
+		}
+		if k.MinScore < 0 || k.MinScore > 100 {
+			return nil, fmt.Errorf("keywords[%d].min_score: must be between 0 and 100 (got %d)", i, k.MinScore)
+		}
+		if k.MinEngagement < 0 {
+			return nil, fmt.Errorf("keywords[%d].min_engagement: must not be negative (got %d)", i, k.MinEngagement)
+		}
+		if strings.ContainsAny(k.Lang, " :") {
+			return nil, fmt.Errorf("invalid keywords[%d].lang: %q (expected a language code such as en or ja)", i, k.Lang)
+		}
+	}
+	if config.Slack.Mode == "" {
+		config.Slack.Mode = SlackModeWebhook
+	}
+	if config.Slack.Mode != SlackModeWebhook && config.Slack.Mode != SlackModeBot {
+		return nil, fmt.Errorf("invalid slack.mode: %s (expected %s or %s)", config.Slack.Mode, SlackModeWebhook, SlackModeBot)
+	}
+	if config.Slack.Mode == SlackModeBot && config.Slack.Channel == "" {
+		return nil, fmt.Errorf("slack.channel is required in bot mode")
+	}
+	if config.Slack.ThreadByTicker && config.Slack.Mode != SlackModeBot {
+		return nil, fmt.Errorf("slack.thread_by_ticker requires slack.mode: %s", SlackModeBot)
+	}
+	if config.Slack.Digest.Interval == "" {
+		config.Slack.Digest.Interval = "1h"
+	}
+	if d, err := time.ParseDuration(config.Slack.Digest.Interval); err != nil || d <= 0 {
+		return nil, fmt.Errorf("invalid slack.digest.interval: %s", config.Slack.Digest.Interval)
+	}
+	if config.Slack.Digest.Immediate == nil {
+		config.Slack.Digest.Immediate = []string{"critical"}
+	}
+	for _, u := range config.Slack.Digest.Immediate {
+		switch u {
+		case "critical", "high", "normal", "low":
+		default:
+			return nil, fmt.Errorf("invalid slack.digest.immediate urgency %q (expected critical, high, normal or low)", u)
+		}
+	}
+	for i := range config.Slack.Webhooks {
+		w := &config.Slack.Webhooks[i]
+		if w.Name == "" {
+			w.Name = fmt.Sprintf("webhook %d", i+1)
+		}
+		if w.URL == "" {
+			return nil, fmt.Errorf("slack.webhooks[%d] requires url", i)
+		}
+		if w.MinScore < 0 || w.MinScore > 100 {
+			return nil, fmt.Errorf("slack.webhooks[%d].min_score must be between 0 and 100", i)
+		}
+	}
+	if config.Slack.Throttle.Window == "" {
+		config.Slack.Throttle.Window = "1h"
+	}
+	if _, err := time.ParseDuration(config.Slack.Throttle.Window); err != nil {
+		return nil, fmt.Errorf("invalid slack.throttle.window: %w", err)
+	}
+	for u, limit := range config.Slack.Throttle.Limits {
+		switch u {
+		case "critical", "high", "normal", "low":
+		default:
+			return nil, fmt.Errorf("invalid slack.throttle.limits key %q (expected critical, high, normal or low)", u)
+		}
+		if limit < 0 {
+			return nil, fmt.Errorf("slack.throttle.limits.%s must not be negative", u)
+		}
+	}
+	if config.Slack.Alerts.Interval == "" {
+		config.Slack.Alerts.Interval = "1h"
+	}
+	if _, err := time.ParseDuration(config.Slack.Alerts.Interval); err != nil {
+		return nil, fmt.Errorf("invalid slack.alerts.interval: %w", err)
+	}
+	if config.Slack.Chart.URL != "" && !strings.Contains(config.Slack.Chart.URL, "{ticker}") {
+		return nil, fmt.Errorf("slack.chart.url must contain {ticker}")
+	}
+	if config.Slack.Mentions.Urgencies == nil {
+		config.Slack.Mentions.Urgencies = []string{"critical"}
+	}
+	for _, u := range config.Slack.Mentions.Urgencies {
+		switch u {
+		case "critical", "high", "normal", "low":
+		default:
+			return nil, fmt.Errorf("invalid slack.mentions.urgencies value %q (expected critical, high, normal or low)", u)
+		}
+	}
+	for u := range config.Slack.Styles.Urgency {
+		switch u {
+		case "critical", "high", "normal", "low":
+		default:
+			return nil, fmt.Errorf("invalid slack.styles.urgency key %q (expected critical, high, normal or low)", u)
+		}
+	}
+	for s := range config.Slack.Styles.Sentiment {
+		switch s {
+		case "bullish", "bearish", "neutral", "unknown":
+		default:
+			return nil, fmt.Errorf("invalid slack.styles.sentiment key %q (expected bullish, bearish, neutral or unknown)", s)
+		}
+	}
+	for i, r := range config.Slack.Routes {
+		if r.Category == "" && r.Urgency == "" && r.Trader == "" {
+			return nil, fmt.Errorf("slack.routes[%d]: at least one of category, urgency or trader is required", i)
+		}
+		if r.Channel == "" && r.WebhookURL == "" {
+			return nil, fmt.Errorf("slack.routes[%d]: channel or webhook_url is required", i)
+		}
+		switch r.Urgency {
+		case "", "critical", "high", "normal", "low":
+		default:
+			return nil, fmt.Errorf("slack.routes[%d]: invalid urgency %q (expected critical, high, normal or low)", i, r.Urgency)
+		}
+	}
 	if config.Slack.Username == "" {
 		config.Slack.Username = "X Trading Bot"
 	}
 	if config.Slack.IconEmoji == "" {
 		config.Slack.IconEmoji = ":chart_with_upwards_trend:"
 	}
+	if config.Feedback.File == "" {
+		config.Feedback.File = "feedback.json"
+	}
+	if config.Feedback.Examples == 0 {
+		config.Feedback.Examples = 5
+	}
+	if config.Feedback.MinSamples == 0 {
+		config.Feedback.MinSamples = 5
+	}
+	if config.Feedback.Enabled && config.Feedback.Secret == "" && config.Feedback.SlackSigningSecret == "" {
+		return nil, fmt.Errorf("feedback.secret or feedback.slack_signing_secret is required when feedback is enabled")
+	}
+	if len(config.Feedback.Outcomes.Hours) == 0 {
+		config.Feedback.Outcomes.Hours = []int{1, 4, 24}
+	}
+	sort.Ints(config.Feedback.Outcomes.Hours)
+	if config.Feedback.Outcomes.Hours[0] <= 0 {
+		return nil, fmt.Errorf("invalid feedback.outcomes.hours: %v (must be positive)", config.Feedback.Outcomes.Hours)
+	}
+	if config.Sentiment.File == "" {
+		config.Sentiment.File = "sentiment.json"
+	}
+	if config.Sentiment.ReportTime == "" {
+		config.Sentiment.ReportTime = "09:00"
+	}
+	if _, err := config.Sentiment.GetReportTime(); err != nil {
+		return nil, fmt.Errorf("invalid sentiment.report_time: %w", err)
+	}
+	if config.Sentiment.MaxTickers == 0 {
+		config.Sentiment.MaxTickers = 10
+	}
+	if config.Sentiment.TopTweets == 0 {
+		config.Sentiment.TopTweets = 3
+	}
+	if config.Sentiment.RetentionDays == 0 {
+		config.Sentiment.RetentionDays = 7
+	}
+	if config.Stats.File == "" {
+		config.Stats.File = "stats.json"
+	}
+	if config.Stats.ReportTime == "" {
+		config.Stats.ReportTime = "09:00"
+	}
+	if _, err := config.Stats.GetReportTime(); err != nil {
+		return nil, fmt.Errorf("invalid stats.report_time: %w", err)
+	}
+	if config.Stats.TopTickers == 0 {
+		config.Stats.TopTickers = 10
+	}
+	if config.Stats.RetentionDays == 0 {
+		config.Stats.RetentionDays = 30
+	}
+	if config.Storage.Backend == "" {
+		config.Storage.Backend = "json"
+	}
+	switch config.Storage.Backend {
+	case "json", "sqlite", "redis", "bbolt", "remote":
+	default:
+		return nil, fmt.Errorf("invalid storage.backend: %s (expected json, sqlite, redis, bbolt or remote)", config.Storage.Backend)
+	}
+	if config.Storage.Backend == "remote" && config.Storage.Remote.URL == "" {
+		return nil, fmt.Errorf("storage.remote.url is required when storage.backend is remote")
+	}
+	if config.Storage.Remote.SaveInterval == "" {
+		config.Storage.Remote.SaveInterval = "1m"
+	}
+	if d, err := time.ParseDuration(config.Storage.Remote.SaveInterval); err != nil || d < 0 {
+		return nil, fmt.Errorf("invalid storage.remote.save_interval: %s", config.Storage.Remote.SaveInterval)
+	}
+	if config.Storage.SQLite.File == "" {
+		config.Storage.SQLite.File = "state.db"
+	}
+	if config.Storage.JSON.SaveInterval == "" {
+		config.Storage.JSON.SaveInterval = "1m"
+	}
+	if d, err := time.ParseDuration(config.Storage.JSON.SaveInterval); err != nil || d < 0 {
+		return nil, fmt.Errorf("invalid storage.json.save_interval: %s", config.Storage.JSON.SaveInterval)
+	}
+	if config.Storage.Content.TTL == "" {
+		config.Storage.Content.TTL = "24h"
+	}
+	if d, err := time.ParseDuration(config.Storage.Content.TTL); err != nil || d <= 0 {
+		return nil, fmt.Errorf("invalid storage.content_dedupe.ttl: %s", config.Storage.Content.TTL)
+	}
+	if config.Storage.Content.File == "" {
+		config.Storage.Content.File = "content_hashes.json"
+	}
+	if config.Storage.Encryption.Enabled && config.Storage.Encryption.Key == "" && config.Storage.Encryption.KeyCommand == "" {
+		return nil, fmt.Errorf("storage.encryption requires key or key_command")
+	}
+	if config.Storage.Archive.Dir == "" {
+		config.Storage.Archive.Dir = "archive/tweets"
+	}
+	if config.Storage.AILedger.Dir == "" {
+		config.Storage.AILedger.Dir = "archive/ai_calls"
+	}
+	if config.Storage.Bolt.File == "" {
+		config.Storage.Bolt.File = "state.bolt"
+	}
+	if config.Storage.Redis.URL == "" {
+		config.Storage.Redis.URL = "redis://localhost:6379/0"
+	}
+	if config.Storage.Redis.KeyPrefix == "" {
+		config.Storage.Redis.KeyPrefix = "xcrawler"
+	}
+	if config.Storage.Redis.QueueName == "" {
+		config.Storage.Redis.QueueName = "default"
+	}
+	if config.Analyses.File == "" {
+		config.Analyses.File = "analyses.json"
+	}
+	if config.Analyses.MaxRecords == 0 {
+		config.Analyses.MaxRecords = 10000
+	}
+	if config.Embeddings.Provider == "" {
+		config.Embeddings.Provider = "openai"
+	}
+	if config.Quotes.Provider == "" {
+		config.Quotes.Provider = "yahoo"
+	}
+	switch config.Quotes.Provider {
+	case "yahoo", "alphavantage", "finnhub":
+	default:
+		return nil, fmt.Errorf("invalid quotes.provider: %s (expected yahoo, alphavantage or finnhub)", config.Quotes.Provider)
+	}
+	if config.Quotes.CacheTTL == "" {
+		config.Quotes.CacheTTL = "1m"
+	}
+	if _, err := time.ParseDuration(config.Quotes.CacheTTL); err != nil {
+		return nil, fmt.Errorf("invalid quotes.cache_ttl: %w", err)
+	}
+	defaultEmbeddingModel := map[string]string{
+		"openai": "text-embedding-3-small",
+		"voyage": "voyage-3-lite",
+	}
+	if _, ok := defaultEmbeddingModel[config.Embeddings.Provider]; !ok {
+		return nil, fmt.Errorf("unknown embeddings provider: %s", config.Embeddings.Provider)
+	}
+	if config.Embeddings.Model == "" {
+		config.Embeddings.Model = defaultEmbeddingModel[config.Embeddings.Provider]
+	}
+	if config.Dedupe.Threshold == 0 {
+		config.Dedupe.Threshold = 0.92
+	}
+	if config.Dedupe.Window == "" {
+		config.Dedupe.Window = "6h"
+	}
+	if _, err := time.ParseDuration(config.Dedupe.Window); err != nil {
+		return nil, fmt.Errorf("invalid dedupe.window: %w", err)
+	}
+	if config.Relevance.Weight == 0 {
+		config.Relevance.Weight = 0.3
+	}
+	if config.Relevance.MinSimilarity == 0 {
+		config.Relevance.MinSimilarity = 0.2
+	}
+	if config.Relevance.MaxSimilarity == 0 {
+		config.Relevance.MaxSimilarity = 0.6
+	}
+	if config.Relevance.Enabled {
+		if strings.TrimSpace(config.Relevance.Profile) == "" {
+			return nil, fmt.Errorf("relevance.profile is required when relevance is enabled")
+		}
+		if config.Relevance.Weight < 0 || config.Relevance.Weight > 1 {
+			return nil, fmt.Errorf("relevance.weight must be between 0 and 1")
+		}
+		if config.Relevance.MinSimilarity >= config.Relevance.MaxSimilarity {
+			return nil, fmt.Errorf("relevance.min_similarity must be less than max_similarity")
+		}
+	}
+	if config.Server.Listen == "" {
+		config.Server.Listen = ":8080"
+	}
+	if config.Email.Port == 0 {
+		config.Email.Port = 587
+	}
+	if config.Email.Digest.ReportTime == "" {
+		config.Email.Digest.ReportTime = "09:00"
+	}
+	if _, err := config.Email.Digest.GetReportTime(); err != nil {
+		return nil, fmt.Errorf("invalid email.digest.report_time: %w", err)
+	}
+	if config.Email.Enabled {
+		if config.Email.Host == "" || config.Email.From == "" {
+			return nil, fmt.Errorf("email.host and email.from are required when email is enabled")
+		}
+		if len(config.Email.Recipients) == 0 && !config.Email.Digest.Enabled {
+			return nil, fmt.Errorf("email.recipients or email.digest is required when email is enabled")
+		}
+		for i, r := range config.Email.Recipients {
+			if r.Address == "" {
+				return nil, fmt.Errorf("email.recipients[%d]: address is required", i)
+			}
+			if err := r.SinkFilter.validate(); err != nil {
+				return nil, fmt.Errorf("email.recipients[%d]: %w", i, err)
+			}
+		}
+		if config.Email.Digest.Enabled && len(config.Email.Digest.To) == 0 {
+			return nil, fmt.Errorf("email.digest.to is required when the email digest is enabled")
+		}
+	}
+	for i := range config.Webhooks {
+		w := &config.Webhooks[i]
+		if w.URL == "" {
+			return nil, fmt.Errorf("webhooks[%d]: url is required", i)
+		}
+		if w.Name == "" {
+			w.Name = fmt.Sprintf("webhook%d", i+1)
+		}
+		if w.MaxAttempts == 0 {
+			w.MaxAttempts = 3
+		}
+		if w.Timeout == "" {
+			w.Timeout = "10s"
+		}
+		if _, err := time.ParseDuration(w.Timeout); err != nil {
+			return nil, fmt.Errorf("invalid webhooks[%d].timeout: %w", i, err)
+		}
+		if err := w.SinkFilter.validate(); err != nil {
+			return nil, fmt.Errorf("webhooks[%d]: %w", i, err)
+		}
+	}
+	for i, p := range config.Plugins {
+		if p.Type == "" {
+			return nil, fmt.Errorf("plugins[%d]: type is required", i)
+		}
+		if err := p.SinkFilter.validate(); err != nil {
+			return nil, fmt.Errorf("plugins[%d]: %w", i, err)
+		}
+	}
+	if config.LINE.Enabled && (config.LINE.ChannelToken == "" || len(config.LINE.To) == 0) {
+		return nil, fmt.Errorf("line.channel_token and line.to are required when LINE is enabled")
+	}
+	if err := config.LINE.SinkFilter.validate(); err != nil {
+		return nil, fmt.Errorf("line: %w", err)
+	}
+	if config.PagerDuty.Enabled && config.PagerDuty.RoutingKey == "" {
+		return nil, fmt.Errorf("pagerduty.routing_key is required when PagerDuty is enabled")
+	}
+	config.PagerDuty.SinkFilter.escalationDefaults()
+	if err := config.PagerDuty.SinkFilter.validate(); err != nil {
+		return nil, fmt.Errorf("pagerduty: %w", err)
+	}
+	if config.Opsgenie.Enabled && config.Opsgenie.APIKey == "" {
+		return nil, fmt.Errorf("opsgenie.api_key is required when Opsgenie is enabled")
+	}
+	config.Opsgenie.SinkFilter.escalationDefaults()
+	if err := config.Opsgenie.SinkFilter.validate(); err != nil {
+		return nil, fmt.Errorf("opsgenie: %w", err)
+	}
+	if config.SMS.Enabled && (config.SMS.AccountSID == "" || config.SMS.AuthToken == "" || config.SMS.From == "" || len(config.SMS.To) == 0) {
+		return nil, fmt.Errorf("sms.account_sid, sms.auth_token, sms.from and sms.to are required when SMS is enabled")
+	}
+	config.SMS.SinkFilter.escalationDefaults()
+	if err := config.SMS.SinkFilter.validate(); err != nil {
+		return nil, fmt.Errorf("sms: %w", err)
+	}
+	if config.GoogleChat.Enabled && config.GoogleChat.WebhookURL == "" {
+		return nil, fmt.Errorf("google_chat.webhook_url is required when Google Chat is enabled")
+	}
+	if err := config.GoogleChat.SinkFilter.validate(); err != nil {
+		return nil, fmt.Errorf("google_chat: %w", err)
+	}
+	if err := config.Desktop.SinkFilter.validate(); err != nil {
+		return nil, fmt.Errorf("desktop: %w", err)
+	}
+	if config.Kafka.Topic == "" {
+		config.Kafka.Topic = "xcrawler.events"
+	}
+	if len(config.Kafka.Decisions) == 0 {
+		config.Kafka.Decisions = []string{"all"}
+	}
+	if config.Kafka.Enabled && config.Kafka.RESTURL == "" {
+		return nil, fmt.Errorf("kafka.rest_url is required when Kafka is enabled")
+	}
+	if err := config.Kafka.SinkFilter.validate(); err != nil {
+		return nil, fmt.Errorf("kafka: %w", err)
+	}
+	if config.MQTT.URL == "" {
+		config.MQTT.URL = "mqtt://localhost:1883"
+	}
+	if config.MQTT.Topic == "" {
+		config.MQTT.Topic = "xcrawler/{urgency}/{ticker}"
+	}
+	if config.MQTT.QoS != 0 && config.MQTT.QoS != 1 {
+		return nil, fmt.Errorf("mqtt.qos must be 0 or 1")
+	}
+	if err := config.MQTT.SinkFilter.validate(); err != nil {
+		return nil, fmt.Errorf("mqtt: %w", err)
+	}
+	if config.NATS.URL == "" {
+		config.NATS.URL = "nats://localhost:4222"
+	}
+	if config.NATS.Subject == "" {
+		config.NATS.Subject = "xcrawler.events.{decision}"
+	}
+	if len(config.NATS.Decisions) == 0 {
+		config.NATS.Decisions = []string{"all"}
+	}
+	if err := config.NATS.SinkFilter.validate(); err != nil {
+		return nil, fmt.Errorf("nats: %w", err)
+	}
+	if config.Archive.File == "" {
+		config.Archive.File = "archive/notifications.jsonl"
+	}
+	if config.Archive.Rotate == "" {
+		config.Archive.Rotate = "daily"
+	}
+	if config.Archive.Rotate != "none" && config.Archive.Rotate != "daily" && config.Archive.Rotate != "size" {
+		return nil, fmt.Errorf("invalid archive.rotate: %s (expected none, daily or size)", config.Archive.Rotate)
+	}
+	if config.Archive.MaxSizeMB == 0 {
+		config.Archive.MaxSizeMB = 100
+	}
+	if err := config.Archive.SinkFilter.validate(); err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+	if err := config.WebSocket.SinkFilter.validate(); err != nil {
+		return nil, fmt.Errorf("websocket: %w", err)
+	}
+	if config.WebSocket.Enabled && config.WebSocket.Token == "" && !loopbackListen(config.Server.Listen) {
+		return nil, fmt.Errorf("websocket.token is required when server.listen is not a loopback address (%s)", config.Server.Listen)
+	}
+	if config.Feed.Title == "" {
+		config.Feed.Title = "X Trading Crawler"
+	}
+	if config.Feed.MaxItems == 0 {
+		config.Feed.MaxItems = 100
+	}
+	if err := config.Feed.SinkFilter.validate(); err != nil {
+		return nil, fmt.Errorf("feed: %w", err)
+	}
+	for i, rule := range config.Routing.Rules {
+		if len(rule.Sinks) == 0 {
+			return nil, fmt.Errorf("routing.rules[%d]: sinks is required", i)
+		}
+		if err := rule.SinkFilter.validate(); err != nil {
+			return nil, fmt.Errorf("routing.rules[%d]: %w", i, err)
+		}
+	}
+	if config.AWS.SNS.Enabled && config.AWS.SNS.TopicARN == "" {
+		return nil, fmt.Errorf("aws.sns.topic_arn is required when SNS is enabled")
+	}
+	if err := config.AWS.SNS.SinkFilter.validate(); err != nil {
+		return nil, fmt.Errorf("aws.sns: %w", err)
+	}
+	if config.AWS.SQS.Enabled && config.AWS.SQS.QueueURL == "" {
+		return nil, fmt.Errorf("aws.sqs.queue_url is required when SQS is enabled")
+	}
+	if err := config.AWS.SQS.SinkFilter.validate(); err != nil {
+		return nil, fmt.Errorf("aws.sqs: %w", err)
+	}
+	if config.Commands.File == "" {
+		config.Commands.File = "control.json"
+	}
+	if config.Commands.SigningSecret == "" {
+		config.Commands.SigningSecret = config.Feedback.SlackSigningSecret
+	}
+	if config.Commands.Enabled && config.Commands.SigningSecret == "" {
+		return nil, fmt.Errorf("commands.signing_secret (or feedback.slack_signing_secret) is required when commands are enabled")
+	}
 	if config.Log.Level == "" {
 		config.Log.Level = "info"
 	}
@@ -94,6 +1435,110 @@ func (c *Config) GetInterval() (time.Duration, error) {
 	return time.ParseDuration(c.Interval)
 }
 
+// validateOnFailure はAI分析失敗時の挙動の値を検証（空文字は未指定として許可）
+func validateOnFailure(v string) error {
+	switch v {
+	case "", OnFailureNotifySimple, OnFailureQueueRetry, OnFailureSkip:
+		return nil
+	default:
+		return fmt.Errorf("unknown value %q (expected %s, %s or %s)",
+			v, OnFailureNotifySimple, OnFailureQueueRetry, OnFailureSkip)
+	}
+}
+
+// OnFailureFor は個別設定を考慮したAI分析失敗時の挙動を返す
+func (c *Config) OnFailureFor(override string) string {
+	if override != "" {
+		return override
+	}
+	return c.AI.OnFailure
+}
+
+// ProviderChain はフォールバック順のAIプロバイダー一覧を返す
+func (a *AIConfig) ProviderChain() []string {
+	if len(a.Providers) > 0 {
+		return a.Providers
+	}
+	return []string{a.Provider}
+}
+
+// GetProviderTimeout はプロバイダーごとのタイムアウトを返す（未指定の場合は0）
+func (a *AIConfig) GetProviderTimeout() (time.Duration, error) {
+	if a.Timeout == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(a.Timeout)
+}
+
+// GetCacheTTL は分析キャッシュの有効期間を返す（未指定の場合は0）
+func (a *AIConfig) GetCacheTTL() (time.Duration, error) {
+	if a.CacheTTL == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(a.CacheTTL)
+}
+
+// LoadPromptTemplate は設定またはファイルからプロンプトテンプレートを読み込む
+// どちらも未指定の場合は空文字を返す（デフォルトテンプレートを使用）
+func (a *AIConfig) LoadPromptTemplate() (string, error) {
+	return loadPromptTemplate("ai", a.PromptTemplate, a.PromptFile)
+}
+
+// LoadPromptTemplate は代替グループのプロンプトテンプレートを返す（未指定の場合は空文字）
+func (e *ExperimentConfig) LoadPromptTemplate() (string, error) {
+	return loadPromptTemplate("ai.experiment", e.PromptTemplate, e.PromptFile)
+}
+
+// loadPromptTemplate はインライン指定またはファイルからプロンプトテンプレートを読み込む
+func loadPromptTemplate(section, inline, file string) (string, error) {
+	if file == "" {
+		return inline, nil
+	}
+	if inline != "" {
+		return "", fmt.Errorf("%s.prompt_template and %s.prompt_file are mutually exclusive", section, section)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt file: %w", err)
+	}
+	return string(data), nil
+}
+
+// LoadTemplate は設定またはファイルからメッセージテンプレートを読み込む
+// どちらも未指定の場合は空文字を返す（組み込みの形式を使用）
+func (s *SlackConfig) LoadTemplate() (string, error) {
+	if s.TemplateFile == "" {
+		return s.Template, nil
+	}
+	if s.Template != "" {
+		return "", fmt.Errorf("slack.template and slack.template_file are mutually exclusive")
+	}
+	data, err := os.ReadFile(s.TemplateFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read slack template file: %w", err)
+	}
+	return string(data), nil
+}
+
+// GetReportTime は投稿時刻を0時からの経過時間として返す
+func (s *SentimentConfig) GetReportTime() (time.Duration, error) {
+	return parseReportTime(s.ReportTime)
+}
+
+// GetReportTime は投稿時刻を0時からの経過時間として返す
+func (s *StatsConfig) GetReportTime() (time.Duration, error) {
+	return parseReportTime(s.ReportTime)
+}
+
+// parseReportTime は HH:MM 形式の時刻を0時からの経過時間に変換
+func parseReportTime(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
 // GetPriorityScore は優先度をスコアに変換
 func (t *Trader) GetPriorityScore() int {
 	switch strings.ToLower(t.Priority) {
@@ -109,3 +1554,16 @@ func (t *Trader) GetPriorityScore() int {
 		return 60
 	}
 }
+
+// loopbackListen は待ち受けアドレスがループバック (localhost, 127.0.0.1, ::1) のみかを返す
+func loopbackListen(listen string) bool {
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil || host == "" {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// 設定ファイルの形式（拡張子で判定）
+const (
+	FormatYAML = "yaml" // .yaml, .yml とその他の拡張子
+	FormatTOML = "toml" // .toml
+	FormatJSON = "json" // .json
+)
+
+// FileFormat は設定ファイルの形式を拡張子から判定する
+func FileFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return FormatTOML
+	case ".json":
+		return FormatJSON
+	default:
+		return FormatYAML
+	}
+}
+
+// parseConfigData は設定ファイルの内容を形式に応じて解析し、YAMLのノードとして返す
+// エラーは行番号がわかる場合 "line N: 内容" の形式にする
+func parseConfigData(path string, content []byte) (*yaml.Node, error) {
+	switch FileFormat(path) {
+	case FormatTOML:
+		// TOMLはキーの行番号を取得できないため、解析後の値からファイル中の記述順にノードを作る（構文エラー以外は行番号なし）
+		var values map[string]interface{}
+		md, err := toml.Decode(string(content), &values)
+		if err != nil {
+			var parseErr toml.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("line %d: %s", parseErr.Position.Line, parseErr.Message)
+			}
+			return nil, err
+		}
+		order := make(map[string]int)
+		for i, key := range md.Keys() {
+			path := strings.Join(key, "\x00")
+			if _, ok := order[path]; !ok {
+				order[path] = i
+			}
+		}
+		return tomlNode(values, nil, order)
+	default:
+		// JSONはYAMLとして解析できる（行番号も保持される）
+		var root yaml.Node
+		if err := yaml.Unmarshal(content, &root); err != nil {
+			return nil, err
+		}
+		return &root, nil
+	}
+}
+
+// tomlNode はTOMLの値をYAMLのノードに変換する
+// テーブルのキーは order (MetaData.Keys の順序) に従って並べ、未知のキーのエラーが毎回同じ順序になるようにする
+func tomlNode(value interface{}, path []string, order map[string]int) (*yaml.Node, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		position := func(key string) int {
+			if i, ok := order[strings.Join(append(path[:len(path):len(path)], key), "\x00")]; ok {
+				return i
+			}
+			return len(order)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if pi, pj := position(keys[i]), position(keys[j]); pi != pj {
+				return pi < pj
+			}
+			return keys[i] < keys[j]
+		})
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range keys {
+			child, err := tomlNode(v[key], append(path[:len(path):len(path)], key), order)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+		}
+		return node, nil
+	case []map[string]interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			child, err := tomlNode(item, path, order)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			child, err := tomlNode(item, path, order)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	default:
+		var node yaml.Node
+		if err := node.Encode(v); err != nil {
+			return nil, err
+		}
+		return &node, nil
+	}
+}
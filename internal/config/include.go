@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readConfig は設定ファイルを読み込み、include で指定したファイルを重ねたノードと読み込んだファイルの一覧（優先度の高い順、本体が先頭）を返す
+// include のファイルを順に重ね（後のファイルが優先）、最後に本体を重ねる（本体が最優先）
+// マッピングはキーごとに再帰的に重ね、それ以外（リスト・値）は置き換える
+func readConfig(path string) (*yaml.Node, []string, error) {
+	return readConfigFile(path, nil)
+}
+
+// readConfigFile はreadConfigの本体（stackは循環の検出に使う読み込み中のファイル）
+func readConfigFile(path string, stack []string) (*yaml.Node, []string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	for _, p := range stack {
+		if p == abs {
+			return nil, nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), abs)
+		}
+	}
+	isInclude := len(stack) > 0
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if isInclude {
+			return nil, nil, fmt.Errorf("failed to read included config file %s: %w", path, err)
+		}
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// 環境変数を展開
+	content := os.ExpandEnv(string(data))
+
+	root, err := parseConfigData(path, []byte(content))
+	if err != nil {
+		if isInclude {
+			return nil, nil, fmt.Errorf("failed to parse included config file %s: %w", path, err)
+		}
+		return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	doc := documentRoot(root)
+	if doc == nil {
+		doc = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+
+	var included []string
+	patterns, err := includePatterns(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var base *yaml.Node
+	for _, pattern := range patterns {
+		matches, err := resolveInclude(filepath.Dir(path), pattern)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, m := range matches {
+			node, sub, err := readConfigFile(m, append(stack, abs))
+			if err != nil {
+				return nil, nil, err
+			}
+			if base == nil {
+				base = node
+			} else {
+				mergeNodes(base, node)
+			}
+			// 後に include したファイルほど優先度が高い
+			included = append(sub, included...)
+		}
+	}
+	if base != nil {
+		mergeNodes(base, doc)
+		doc = base
+	}
+	return doc, append([]string{path}, included...), nil
+}
+
+// includePatterns は include のファイル名の一覧を返す
+func includePatterns(doc *yaml.Node) ([]string, error) {
+	node, _ := childNode(doc, "include")
+	if node == nil {
+		return nil, nil
+	}
+	var patterns []string
+	if err := node.Decode(&patterns); err != nil {
+		return nil, fmt.Errorf("invalid include: expected a list of file names")
+	}
+	return patterns, nil
+}
+
+// resolveInclude はincludeのパス（相対パスはincludeを書いたファイルのディレクトリから）を解決する
+// ワイルドカードを含む場合は一致したファイルを名前順に返し（一致しなくてもよい）、含まない場合はファイルが存在する必要がある
+func resolveInclude(dir, pattern string) ([]string, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("invalid include: empty file name")
+	}
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(dir, pattern)
+	}
+	if !strings.ContainsAny(pattern, "*?[") {
+		return []string{pattern}, nil
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include %q: %w", pattern, err)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileEnv はプロファイルを選択する環境変数（-profile フラグでも指定できる）
+const ProfileEnv = "X_CRAWLER_PROFILE"
+
+// SelectedProfile は環境変数で選択されたプロファイル名を返す（未指定の場合は空）
+func SelectedProfile() string {
+	return strings.TrimSpace(os.Getenv(ProfileEnv))
+}
+
+// ProfileNames は設定ファイルに定義されたプロファイル名を返す
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyProfile は profiles.<name> の内容を設定ファイルの他の項目に重ねる
+// マッピングはキーごとに再帰的に重ね、それ以外（リスト・値）は置き換える
+func applyProfile(root *yaml.Node, name string) error {
+	doc := documentRoot(root)
+	if doc == nil || doc.Kind != yaml.MappingNode {
+		return fmt.Errorf("unknown profile %q (no profiles are defined)", name)
+	}
+	profiles, _ := childNode(doc, "profiles")
+	if profiles == nil || profiles.Kind != yaml.MappingNode {
+		return fmt.Errorf("unknown profile %q (no profiles are defined)", name)
+	}
+	profile, _ := childNode(profiles, name)
+	if profile == nil {
+		var names []string
+		for i := 0; i+1 < len(profiles.Content); i += 2 {
+			names = append(names, profiles.Content[i].Value)
+		}
+		return fmt.Errorf("unknown profile %q (defined: %s)", name, strings.Join(names, ", "))
+	}
+	if profile.Kind == yaml.ScalarNode && profile.Tag == "!!null" {
+		return nil
+	}
+	if profile.Kind != yaml.MappingNode {
+		return fmt.Errorf("profiles.%s must be a mapping", name)
+	}
+	for i := 0; i+1 < len(profile.Content); i += 2 {
+		if profile.Content[i].Value == "profiles" {
+			return fmt.Errorf("profiles.%s must not contain profiles", name)
+		}
+	}
+	mergeNodes(doc, profile)
+	return nil
+}
+
+// documentRoot はドキュメントノードの中身を返す（空のファイルの場合はnil）
+func documentRoot(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return node.Content[0]
+	}
+	return node
+}
+
+// mergeNodes はsrcをdstに重ねる（両方マッピングの場合はキーごとに再帰的に、それ以外はsrcで置き換え）
+func mergeNodes(dst, src *yaml.Node) {
+	if dst.Kind != yaml.MappingNode || src.Kind != yaml.MappingNode {
+		*dst = *src
+		return
+	}
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		merged := false
+		for j := 0; j+1 < len(dst.Content); j += 2 {
+			if dst.Content[j].Value == key.Value {
+				mergeNodes(dst.Content[j+1], value)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			dst.Content = append(dst.Content, key, value)
+		}
+	}
+}
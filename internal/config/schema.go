@@ -0,0 +1,77 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JSONSchema は設定ファイルのJSON Schema (draft-07) を返す（エディタの補完やCIでの検証に使う）
+// 未知のキーは additionalProperties: false で拒否する
+func JSONSchema() ([]byte, error) {
+	g := &schemaGenerator{defs: make(map[string]interface{})}
+	root := g.structSchema(reflect.TypeOf(Config{}))
+	root["$schema"] = "http://json-schema.org/draft-07/schema#"
+	root["title"] = "x-crawler config"
+	root["definitions"] = g.defs
+	// プロファイルの中身は設定ファイルの他の項目と同じ形式
+	root["properties"].(map[string]interface{})["profiles"] = map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": map[string]interface{}{"$ref": "#"},
+	}
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// schemaGenerator は設定の型からJSON Schemaを作る（構造体は definitions にまとめて参照する）
+type schemaGenerator struct {
+	defs map[string]interface{}
+}
+
+// schema は型のスキーマを返す
+func (g *schemaGenerator) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(yaml.Node{}) {
+		return map[string]interface{}{} // 任意の値（通知先ごとの設定など）
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if _, ok := g.defs[t.Name()]; !ok {
+			g.defs[t.Name()] = nil // 再帰する型のために先に登録する
+			g.defs[t.Name()] = g.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + t.Name()}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema は構造体のyamlのキーを properties にしたスキーマを返す（inlineの構造体は展開）
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	fields := make(map[string]reflect.Type)
+	yamlFields(t, fields)
+	props := make(map[string]interface{}, len(fields))
+	for name, ft := range fields {
+		props[name] = g.schema(ft)
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+// secretsTimeout は設定ファイルのシークレットの参照を全て取得するまでのタイムアウト
+const secretsTimeout = time.Minute
+
+// resolveSecrets は値がシークレットの参照 (vault://, awssm://, ssm://) の項目を取得した値に置き換える
+// プロファイルを重ねた後に呼ぶ（profiles の中は置き換えない）
+func resolveSecrets(root *yaml.Node) error {
+	doc := documentRoot(root)
+	if doc == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), secretsTimeout)
+	defer cancel()
+	return resolveSecretNodes(ctx, secrets.NewResolver(), doc, "")
+}
+
+// resolveSecretNodes はノードを再帰的にたどってシークレットの参照を置き換える（pathはエラーに表示する項目名）
+func resolveSecretNodes(ctx context.Context, r *secrets.Resolver, node *yaml.Node, path string) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if path == "" && key == "profiles" {
+				continue
+			}
+			if err := resolveSecretNodes(ctx, r, node.Content[i+1], joinPath(path, key)); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if err := resolveSecretNodes(ctx, r, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if node.Tag != "!!str" || !secrets.IsReference(node.Value) {
+			return nil
+		}
+		value, err := r.Resolve(ctx, node.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		node.Value = value
+	}
+	return nil
+}
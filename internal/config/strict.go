@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkUnknownKeys は include・プロファイルを重ねた設定の未知のキー（打ち間違いなど）をエラーにする
+// 適用していないプロファイルの中は確認しない（validate で確認できる）
+func checkUnknownKeys(root *yaml.Node) error {
+	doc := documentRoot(root)
+	if doc == nil {
+		return nil
+	}
+	v := &validator{}
+	v.unknownKeys("", doc, reflect.TypeOf(Config{}), "")
+	if len(v.issues) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(v.issues))
+	for _, issue := range v.issues {
+		if issue.Path == "" {
+			msgs = append(msgs, issue.Message)
+		} else {
+			msgs = append(msgs, issue.Path+": "+issue.Message)
+		}
+	}
+	return fmt.Errorf("%s (set allow_unknown_keys: true to ignore unknown keys)", strings.Join(msgs, "; "))
+}
+
+// suggestKey は未知のキーに近い項目名があれば " (did you mean "..."?)" を返す
+func suggestKey(key string, fields map[string]reflect.Type) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// 2文字以内の違いのみ候補にする（短いキーはほぼ別の項目名になるため違いを少なくする）
+	best, bestDist := "", min(3, len(key)-1)
+	for _, name := range names {
+		if d := editDistance(strings.ToLower(key), name); d < bestDist {
+			best, bestDist = name, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// editDistance は2つの文字列のレーベンシュタイン距離を返す
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
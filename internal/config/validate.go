@@ -0,0 +1,507 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+// Issue は設定ファイルの問題
+type Issue struct {
+	File    string // include したファイルの問題の場合はそのパス（設定ファイル本体の場合は空）
+	Line    int    // 0の場合は行が不明
+	Path    string // 問題のある項目 (例: traders[1].priority)
+	Message string
+}
+
+// String は "ファイル: 行: 項目: 内容" の形式で返す
+func (i Issue) String() string {
+	var b strings.Builder
+	if i.File != "" {
+		b.WriteString(i.File + ": ")
+	}
+	if i.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", i.Line)
+	}
+	if i.Path != "" {
+		b.WriteString(i.Path + ": ")
+	}
+	b.WriteString(i.Message)
+	return b.String()
+}
+
+// yamlLinePattern はyamlのエラーメッセージに含まれる行番号
+var yamlLinePattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// loadErrorPathPattern はLoadのエラーメッセージの先頭の項目名 (例: invalid storage.json.save_interval)
+var loadErrorPathPattern = regexp.MustCompile(`^(?:invalid |unknown )?([a-z_]+(?:\.[a-z_]+|\[\d+\])+)`)
+
+// Check は読み込んだ設定の追加の検証（モデル名など、configパッケージの外で行うもの）
+// 返すIssueのLineが0の場合はPathから行を補う
+type Check func(cfg *Config) []Issue
+
+// Validate は設定ファイルを検証し、見つかった問題を行番号の順に全て返す
+// 構文・未知のキー・型の誤り・interval・優先度・Webhook URLの形式・重複したトレーダーを確認し、
+// 最後にLoadの検証と、Loadに成功した場合はchecksを行う
+// include したファイルも同様に確認し、プロファイルがある場合はそれぞれを重ねた設定についても、プロファイルで上書きした項目を確認する
+// ファイルが読めない場合だけerrorを返す
+func Validate(path string, checks ...Check) ([]Issue, error) {
+	var issues []Issue
+
+	// include したファイルも確認する（include を読み込めない場合は設定ファイル本体だけを確認し、エラーはLoadの検証で追加）
+	paths := []string{path}
+	merged, files, err := readConfig(path)
+	if err == nil {
+		paths = files
+	}
+
+	// include を重ねた設定（デフォルト値の補完前）の値
+	var cfg Config
+	if merged != nil {
+		_ = merged.Decode(&cfg) // 型の誤りはファイルごとに追加する
+	}
+
+	v := &validator{}
+	for i, p := range paths {
+		var file string
+		if i > 0 {
+			file = p
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			if i == 0 {
+				return nil, fmt.Errorf("failed to read config file: %w", err)
+			}
+			continue
+		}
+		content := []byte(os.ExpandEnv(string(data)))
+
+		root, err := parseConfigData(p, content)
+		if err != nil {
+			issue := yamlIssue(err.Error())
+			issue.File = file
+			if i == 0 {
+				return []Issue{issue}, nil
+			}
+			issues = append(issues, issue)
+			continue
+		}
+		doc := documentRoot(root)
+		if doc == nil {
+			doc = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		v.files = append(v.files, configFile{path: file, root: doc})
+
+		// 未知のキー（プロファイルを含む、allow_unknown_keys: true の場合は確認しない）・型の誤り
+		if !cfg.AllowUnknownKeys {
+			v.unknownKeys(file, doc, reflect.TypeOf(Config{}), "")
+			if profiles, _ := childNode(doc, "profiles"); profiles != nil && profiles.Kind == yaml.MappingNode {
+				for j := 0; j+1 < len(profiles.Content); j += 2 {
+					v.unknownKeys(file, profiles.Content[j+1], reflect.TypeOf(Config{}), "profiles."+profiles.Content[j].Value)
+				}
+			}
+		}
+		if err := doc.Decode(&Config{}); err != nil {
+			var typeErr *yaml.TypeError
+			msgs := []string{err.Error()}
+			if errors.As(err, &typeErr) {
+				msgs = typeErr.Errors
+			}
+			for _, msg := range msgs {
+				issue := yamlIssue(msg)
+				issue.File = file
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	v.checkFields(&cfg)
+
+	// Loadの検証（デフォルト値の補完後の範囲・組み合わせの確認、最初の1件のみ）
+	// プロファイルがある場合はそれぞれを重ねた設定も確認する
+	for _, name := range cfg.ProfileNames() {
+		v.profile = "profiles." + name
+		loaded, err := load(path, name, false)
+		if err != nil {
+			v.addLoadError(err)
+		} else {
+			v.checkFields(loaded)
+			v.runChecks(loaded, checks)
+		}
+	}
+	v.profile = ""
+	loaded, err := load(path, "", false)
+	if err != nil {
+		v.addLoadError(err)
+	} else {
+		v.runChecks(loaded, checks)
+	}
+
+	issues = append(issues, v.issues...)
+	// 設定ファイル本体、include したファイルの順に、それぞれ行番号の順に並べる
+	order := make(map[string]int, len(paths))
+	for i, p := range paths {
+		if i > 0 {
+			order[p] = i
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if fi, fj := order[issues[i].File], order[issues[j].File]; fi != fj {
+			return fi < fj
+		}
+		if issues[i].Line == 0 || issues[j].Line == 0 {
+			return issues[j].Line == 0 && issues[i].Line != 0
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues, nil
+}
+
+// checkFields はinterval・トレーダー・キーワード・Webhook URLの値を確認
+func (v *validator) checkFields(cfg *Config) {
+	if cfg.Interval != "" {
+		if d, err := time.ParseDuration(cfg.Interval); err != nil || d <= 0 {
+			v.add("interval", fmt.Sprintf("invalid duration %q (expected e.g. 30s, 5m, 1h)", cfg.Interval))
+		}
+	}
+	if cfg.Reload.WatchInterval != "" {
+		if d, err := time.ParseDuration(cfg.Reload.WatchInterval); err != nil || d <= 0 {
+			v.add("reload.watch_interval", fmt.Sprintf("invalid duration %q (expected e.g. 10s)", cfg.Reload.WatchInterval))
+		}
+	}
+
+	// トレーダー
+	seen := make(map[string]int)
+	for i, t := range cfg.Traders {
+		p := fmt.Sprintf("traders[%d]", i)
+		if strings.TrimSpace(t.Username) == "" {
+			v.add(p, "username is required")
+		}
+		if strings.HasPrefix(t.Username, "@") {
+			v.add(p+".username", fmt.Sprintf("remove the leading @ from %q", t.Username))
+		}
+		switch strings.ToLower(t.Priority) {
+		case "", "critical", "high", "normal", "low":
+		default:
+			v.add(p+".priority", fmt.Sprintf("unknown priority %q (expected critical, high, normal or low)", t.Priority))
+		}
+		key := strings.ToLower(strings.TrimPrefix(t.Username, "@"))
+		if first, ok := seen[key]; ok && key != "" {
+			v.add(p+".username", fmt.Sprintf("duplicate trader %q (first defined at %s)", t.Username, v.where(fmt.Sprintf("traders[%d]", first))))
+		} else {
+			seen[key] = i
+		}
+	}
+
+	// キーワード
+	names := make(map[string]int)
+	for i, k := range cfg.Keywords {
+		p := fmt.Sprintf("keywords[%d]", i)
+		if strings.TrimSpace(k.Query) == "" {
+			v.add(p, "query is required")
+		}
+		if first, ok := names[k.Name]; ok && k.Name != "" {
+			v.add(p+".name", fmt.Sprintf("duplicate keyword %q (first defined at %s)", k.Name, v.where(fmt.Sprintf("keywords[%d]", first))))
+		} else {
+			names[k.Name] = i
+		}
+	}
+
+	// Webhook URL
+	v.checkSlackWebhook("slack.webhook_url", cfg.Slack.WebhookURL)
+	v.checkSlackWebhook("slack.maybe_webhook_url", cfg.Slack.MaybeWebhookURL)
+	v.checkSlackWebhook("slack.ops_webhook_url", cfg.Slack.OpsWebhookURL)
+	for i, r := range cfg.Slack.Routes {
+		v.checkSlackWebhook(fmt.Sprintf("slack.routes[%d].webhook_url", i), r.WebhookURL)
+	}
+	for i, w := range cfg.Slack.Webhooks {
+		v.checkSlackWebhook(fmt.Sprintf("slack.webhooks[%d].url", i), w.URL)
+	}
+	for i, w := range cfg.Webhooks {
+		v.checkURL(fmt.Sprintf("webhooks[%d].url", i), w.URL)
+	}
+	v.checkURL("google_chat.webhook_url", cfg.GoogleChat.WebhookURL)
+	v.checkURL("server.public_url", cfg.Server.PublicURL)
+}
+
+// runChecks はLoadした設定に追加の検証を行う
+func (v *validator) runChecks(cfg *Config, checks []Check) {
+	for _, check := range checks {
+		for _, issue := range check(cfg) {
+			v.report(issue)
+		}
+	}
+}
+
+// yamlIssue はyamlのエラーメッセージをIssueにする
+func yamlIssue(msg string) Issue {
+	if m := yamlLinePattern.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return Issue{Line: line, Message: m[2]}
+	}
+	return Issue{Message: strings.TrimPrefix(msg, "yaml: ")}
+}
+
+// validator は項目名から行番号を引きながら問題を集める
+type validator struct {
+	files   []configFile // 優先度の高い順（設定ファイル本体、後に include したファイル、...）
+	profile string       // 確認中のプロファイルの項目名 (profiles.<名前>、設定ファイルの他の項目の場合は空)
+	issues  []Issue
+}
+
+// configFile は検証する設定ファイル
+type configFile struct {
+	path string // include したファイルのパス（設定ファイル本体の場合は空）
+	root *yaml.Node
+}
+
+// addLoadError はLoadのエラーを項目の行とともに追加
+func (v *validator) addLoadError(err error) {
+	msg := strings.TrimPrefix(err.Error(), "failed to parse config file: ")
+	if strings.HasPrefix(msg, "yaml:") {
+		return
+	}
+	m := loadErrorPathPattern.FindStringSubmatch(msg)
+	if m == nil {
+		file, line := v.locate(v.profile)
+		v.issues = append(v.issues, Issue{File: file, Line: line, Path: v.profile, Message: msg})
+		return
+	}
+	if v.profile == "" {
+		file, line := v.locate(m[1])
+		v.issues = append(v.issues, Issue{File: file, Line: line, Message: msg})
+		return
+	}
+	// プロファイルで上書きしていない項目のエラーは設定ファイルの他の項目のエラーとして追加される
+	if node, file, line := v.find(v.profile + "." + m[1]); node != nil {
+		v.issues = append(v.issues, Issue{File: file, Line: line, Path: v.profile, Message: msg})
+	}
+}
+
+// unknownKeys はfileのnodeのキーのうちtの項目にないものを問題として追加
+func (v *validator) unknownKeys(file string, node *yaml.Node, t reflect.Type, path string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(yaml.Node{}) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := make(map[string]reflect.Type)
+		yamlFields(t, fields)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			ft, ok := fields[key]
+			if !ok {
+				v.issues = append(v.issues, Issue{File: file, Line: node.Content[i].Line, Path: path, Message: fmt.Sprintf("unknown key %q%s", key, suggestKey(key, fields))})
+				continue
+			}
+			v.unknownKeys(file, node.Content[i+1], ft, joinPath(path, key))
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v.unknownKeys(file, node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value))
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			v.unknownKeys(file, item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// joinPath は項目名にキーを加える
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// yamlFields は構造体のyamlのキーと型を集める（inlineの構造体は展開）
+func yamlFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			yamlFields(f.Type, fields)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+}
+
+// add は項目の問題を追加
+func (v *validator) add(path, msg string) {
+	v.report(Issue{Path: path, Message: msg})
+}
+
+// report は問題に行番号を補って追加
+// プロファイルの確認中はプロファイルで上書きした項目の問題だけを追加する（それ以外は設定ファイルの他の項目の問題として追加される）
+func (v *validator) report(issue Issue) {
+	if v.profile != "" {
+		if issue.Path == "" {
+			return
+		}
+		node, file, line := v.find(v.profile + "." + issue.Path)
+		if node == nil {
+			return
+		}
+		issue.Path = v.profile + "." + issue.Path
+		issue.File, issue.Line = file, line
+	} else if issue.Line == 0 && issue.Path != "" {
+		issue.File, issue.Line = v.locate(issue.Path)
+	}
+	v.issues = append(v.issues, issue)
+}
+
+// where は項目の位置を "line N"（include したファイルの場合は "ファイル:N"）の形式で返す
+// プロファイルの確認中はプロファイルで上書きした項目を優先する
+func (v *validator) where(path string) string {
+	file, line := v.locate(path)
+	if v.profile != "" {
+		if node, f, l := v.find(v.profile + "." + path); node != nil {
+			file, line = f, l
+		}
+	}
+	if file != "" {
+		return fmt.Sprintf("%s:%d", file, line)
+	}
+	return fmt.Sprintf("line %d", line)
+}
+
+// checkURL は空でない値が http(s) の絶対URLかを確認
+func (v *validator) checkURL(path, value string) bool {
+	// シークレットの参照はLoadの検証で取得して確認する
+	if value == "" || secrets.IsReference(value) {
+		return false
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		v.add(path, fmt.Sprintf("invalid URL %q (expected https://...)", value))
+		return false
+	}
+	return true
+}
+
+// checkSlackWebhook は空でない値がSlackのIncoming WebhookのURLかを確認
+func (v *validator) checkSlackWebhook(path, value string) {
+	if !v.checkURL(path, value) {
+		return
+	}
+	u, _ := url.Parse(value)
+	if u.Host != "hooks.slack.com" || !strings.HasPrefix(u.Path, "/services/") {
+		v.add(path, fmt.Sprintf("%q does not look like a Slack webhook URL (expected https://hooks.slack.com/services/...)", value))
+	}
+}
+
+// locate は項目名 (例: traders[1].priority) のファイルと行番号を返す（見つからない場合は設定ファイル本体の最も近い親の行、なければ0）
+func (v *validator) locate(path string) (string, int) {
+	_, file, line := v.find(path)
+	return file, line
+}
+
+// find は優先度の高い設定ファイルから順に項目名を探し、ノードとそのファイル・行を返す
+// 見つからない場合はnilと設定ファイル本体の最も近い親の行を返す
+func (v *validator) find(path string) (*yaml.Node, string, int) {
+	for _, f := range v.files {
+		if node, line := findNode(f.root, path); node != nil {
+			return node, f.path, line
+		}
+	}
+	if len(v.files) == 0 {
+		return nil, "", 0
+	}
+	_, line := findNode(v.files[0].root, path)
+	return nil, "", line
+}
+
+// findNode はrootから項目名のノードとその行を返す（見つからない場合はnilと最も近い親の行）
+func findNode(root *yaml.Node, path string) (*yaml.Node, int) {
+	node := root
+	line := 0
+	for _, part := range splitPath(path) {
+		next, at := childNode(node, part)
+		if next == nil {
+			return nil, line
+		}
+		node = next
+		line = at
+	}
+	return node, line
+}
+
+// splitPath は項目名をキーと添字に分ける (traders[1].priority -> traders, [1], priority)
+func splitPath(path string) []string {
+	var parts []string
+	for _, p := range strings.Split(path, ".") {
+		for p != "" {
+			i := strings.Index(p, "[")
+			if i < 0 {
+				parts = append(parts, p)
+				break
+			}
+			if i > 0 {
+				parts = append(parts, p[:i])
+			}
+			j := strings.Index(p, "]")
+			if j < i {
+				break
+			}
+			parts = append(parts, p[i:j+1])
+			p = p[j+1:]
+		}
+	}
+	return parts
+}
+
+// childNode はマッピングのキー、またはシーケンスの添字 ([n]) の子ノードとその行（キーの場合はキーの行）を返す
+func childNode(node *yaml.Node, part string) (*yaml.Node, int) {
+	if node == nil {
+		return nil, 0
+	}
+	if strings.HasPrefix(part, "[") {
+		i, err := strconv.Atoi(strings.Trim(part, "[]"))
+		if err != nil || node.Kind != yaml.SequenceNode || i >= len(node.Content) {
+			return nil, 0
+		}
+		return node.Content[i], node.Content[i].Line
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, 0
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == part {
+			return node.Content[i+1], node.Content[i].Line
+		}
+	}
+	return nil, 0
+}
@@ -2,41 +2,240 @@ package crawler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/article"
 	"github.com/Minatonton/x-crawler/internal/config"
+	"github.com/Minatonton/x-crawler/internal/digest"
+	"github.com/Minatonton/x-crawler/internal/embedding"
+	"github.com/Minatonton/x-crawler/internal/feedback"
+	"github.com/Minatonton/x-crawler/internal/sentiment"
+	"github.com/Minatonton/x-crawler/internal/sink"
 	"github.com/Minatonton/x-crawler/internal/slack"
+	"github.com/Minatonton/x-crawler/internal/stats"
 	"github.com/Minatonton/x-crawler/internal/storage"
 	"github.com/Minatonton/x-crawler/internal/twitter"
 )
 
+// 運用アラートの種類
+const (
+	alertXAPI    = "x_api"   // X APIの取得エラー（認証エラーを含む）
+	alertAI      = "ai"      // AIプロバイダーの障害
+	alertStorage = "storage" // 状態の保存の失敗
+)
+
+// maxAIRetryAttempts はリトライキュー経由でAI分析を再試行する最大回数
+const maxAIRetryAttempts = 3
+
 // Crawler はクロール処理を実行
 type Crawler struct {
 	config        *config.Config
 	twitterClient *twitter.Client
-	aiFilter      *ai.Filter
+	analyzer      ai.Analyzer
 	slackNotifier *slack.Notifier
-	seenTweets    *storage.SeenTweets
+	seenTweets    storage.SeenStore
+	articles      *article.Fetcher
+	feedback      *feedback.Calibrator
+	outcomes      *feedback.OutcomeTracker
+	sentiment     *sentiment.Aggregator
+	stats         *stats.Collector
+	archives      []storage.ArchiveStore
+	contents      storage.ContentStore
+	checkpoints   storage.CheckpointStore
+	embedder      embedding.Embedder
+	dedupe        *embedding.Deduper
+	relevance     *embedding.Relevance
+	digest        *digest.Digest
+	throttle      *digest.Throttle
+	control       *storage.ControlStore
+	sinks         []sink.Sink
+	retryQueue    []retryItem
+	lastFetched   map[string]time.Time // 個別の取得間隔があるトレーダーの最後の取得時刻（ユーザー名は小文字）
+
+	statusMu sync.Mutex
+	status   Status
+}
+
+// Status は直近のクロールの結果（Slackの /xcrawler status で表示）
+type Status struct {
+	LastRun      time.Time
+	Processed    int
+	Notified     int
+	Seen         int
+	RetryQueue   int
+	SlackPending int
+}
+
+// Option はCrawlerの任意設定
+type Option func(*Crawler)
+
+// WithSentiment は銘柄別センチメントの集計と日次サマリーを有効化
+func WithSentiment(a *sentiment.Aggregator) Option {
+	return func(c *Crawler) {
+		c.sentiment = a
+	}
+}
+
+// WithThrottle は緊急度ごとに即時通知の件数を制限し、超えた分をダイジェストに回す（WithDigestが必要）
+func WithThrottle(t *digest.Throttle) Option {
+	return func(c *Crawler) {
+		c.throttle = t
+	}
+}
+
+// WithControl はSlackコマンドで追加した監視対象と銘柄のミュートを反映
+func WithControl(cs *storage.ControlStore) Option {
+	return func(c *Crawler) {
+		c.control = cs
+	}
+}
+
+// WithSinks はSlackに加えて通知を送る通知先を指定
+func WithSinks(sinks ...sink.Sink) Option {
+	return func(c *Crawler) {
+		c.sinks = append(c.sinks, sinks...)
+	}
+}
+
+// WithStats はクロール統計の集計と日次レポートを有効化
+func WithStats(s *stats.Collector) Option {
+	return func(c *Crawler) {
+		c.stats = s
+	}
+}
+
+// WithDigest は緊急でない通知をダイジェストにまとめて投稿する
+func WithDigest(d *digest.Digest) Option {
+	return func(c *Crawler) {
+		c.digest = d
+	}
+}
+
+// WithArchive は取得したツイート・AI分析結果の保存先を追加
+func WithArchive(a storage.ArchiveStore) Option {
+	return func(c *Crawler) {
+		c.archives = append(c.archives, a)
+	}
+}
+
+// WithContentDedupe は正規化した本文が同じツイート（転載ボットなど）を期限内は重複として処理しない
+func WithContentDedupe(cs storage.ContentStore) Option {
+	return func(c *Crawler) {
+		c.contents = cs
+	}
+}
+
+// WithCheckpoints は取得元ごとのチェックポイント（最後に取得した最新のツイートID）を記録
+func WithCheckpoints(cs storage.CheckpointStore) Option {
+	return func(c *Crawler) {
+		c.checkpoints = cs
+	}
+}
+
+// WithEmbedder は重複抑制・関連度評価に使う埋め込みプロバイダーを指定
+func WithEmbedder(e embedding.Embedder) Option {
+	return func(c *Crawler) {
+		c.embedder = e
+	}
+}
+
+// WithDeduper は通知済みツイートとの意味的な重複の抑制を有効化（WithEmbedderが必要）
+func WithDeduper(d *embedding.Deduper) Option {
+	return func(c *Crawler) {
+		c.dedupe = d
+	}
+}
+
+// WithRelevance は戦略との関連度をスコアに反映（WithEmbedderが必要）
+func WithRelevance(r *embedding.Relevance) Option {
+	return func(c *Crawler) {
+		c.relevance = r
+	}
+}
+
+// WithArticleFetcher はリンク先記事の取得を有効化
+func WithArticleFetcher(f *article.Fetcher) Option {
+	return func(c *Crawler) {
+		c.articles = f
+	}
+}
+
+// source はツイートの取得元（トレーダーまたはキーワード）
+type source struct {
+	kind        string // trader, keyword
+	key         string // チェックポイント・AI使用量の記録に使う取得元のキー（trader:ユーザー名, keyword:名前）
+	info        string // AI分析・通知に渡す取得元情報
+	context     string // AI分析にのみ渡す投稿者の経歴・専門分野
+	onAIFailure string // 個別設定のAI分析失敗時の挙動
+	minScore    int    // 個別設定の通知する最低スコア（0の場合は ai.min_score）
+	skipAI      bool   // AI分析せずにシンプル通知する
+}
+
+// minScoreOr は個別設定があればその最低スコアを、なければdefを返す
+func (s source) minScoreOr(def int) int {
+	if s.minScore > 0 {
+		return s.minScore
+	}
+	return def
+}
+
+// aiInfo はAI分析に渡す取得元情報（経歴がある場合は付加）
+func (s source) aiInfo() string {
+	if s.context == "" {
+		return s.info
+	}
+	return fmt.Sprintf("%s / 経歴: %s", s.info, s.context)
+}
+
+// retryItem はAI分析または通知の再試行待ちツイート
+type retryItem struct {
+	tweet    twitter.Tweet
+	src      source
+	attempts int
+	analysis *ai.Analysis // 分析済みで通知だけ失敗した場合の分析結果（再分析せず通知のみ再試行）
+}
+
+// WithFeedback はフィードバックによるスコア補正と通知記録を有効化
+func WithFeedback(cal *feedback.Calibrator) Option {
+	return func(c *Crawler) {
+		c.feedback = cal
+	}
+}
+
+// WithOutcomes は通知した銘柄の事後の値動きの記録を有効化
+func WithOutcomes(t *feedback.OutcomeTracker) Option {
+	return func(c *Crawler) {
+		c.outcomes = t
+	}
 }
 
-// New は新しいCrawlerを作成
+// New は新しいCrawlerを作成（analyzerがnilの場合はAI分析なしで通知）
 func New(
 	cfg *config.Config,
 	twitterClient *twitter.Client,
-	aiFilter *ai.Filter,
+	analyzer ai.Analyzer,
 	slackNotifier *slack.Notifier,
-	seenTweets *storage.SeenTweets,
+	seenTweets storage.SeenStore,
+	opts ...Option,
 ) *Crawler {
-	return &Crawler{
+	c := &Crawler{
 		config:        cfg,
 		twitterClient: twitterClient,
-		aiFilter:      aiFilter,
+		analyzer:      analyzer,
 		slackNotifier: slackNotifier,
 		seenTweets:    seenTweets,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // Run はクロール処理を実行
@@ -44,11 +243,27 @@ func (c *Crawler) Run(ctx context.Context) error {
 	totalProcessed := 0
 	totalNotified := 0
 
+	// サイクル単位の状態（リトライ枠など）をリセット
+	if ca, ok := c.analyzer.(ai.CycleAware); ok {
+		ca.StartCycle()
+	}
+
+	// 前回サイクルで送れなかったSlack通知を再送
+	c.slackNotifier.RetryPending(ctx)
+
+	// 前回サイクルでAI分析に失敗したツイートを再試行
+	totalNotified += c.processRetryQueue(ctx)
+
 	// トレーダーのツイートを取得
-	for _, trader := range c.config.Traders {
+	for _, trader := range c.traders() {
+		if !c.traderDue(trader, time.Now()) {
+			continue
+		}
 		processed, notified, err := c.processTrader(ctx, trader)
 		if err != nil {
 			log.Printf("Error processing trader @%s: %v", trader.Username, err)
+			c.countError(stats.ErrorFetch)
+			c.alert(ctx, alertXAPI, fmt.Sprintf("Failed to fetch tweets of @%s", trader.Username), err)
 			continue
 		}
 		totalProcessed += processed
@@ -60,6 +275,8 @@ func (c *Crawler) Run(ctx context.Context) error {
 		processed, notified, err := c.processKeyword(ctx, keyword)
 		if err != nil {
 			log.Printf("Error processing keyword '%s': %v", keyword.Name, err)
+			c.countError(stats.ErrorFetch)
+			c.alert(ctx, alertXAPI, fmt.Sprintf("Failed to search tweets for '%s'", keyword.Name), err)
 			continue
 		}
 		totalProcessed += processed
@@ -69,135 +286,788 @@ func (c *Crawler) Run(ctx context.Context) error {
 	// 既読ツイートを保存
 	if err := c.seenTweets.Save(); err != nil {
 		log.Printf("Failed to save seen tweets: %v", err)
+		c.alert(ctx, alertStorage, "Failed to save seen tweets", err)
+	}
+	if c.outcomes != nil {
+		c.outcomes.Check(ctx)
+	}
+	if c.feedback != nil {
+		if err := c.feedback.Save(); err != nil {
+			log.Printf("Failed to save feedback: %v", err)
+			c.alert(ctx, alertStorage, "Failed to save feedback", err)
+		}
+	}
+	if c.contents != nil {
+		if err := c.contents.Save(); err != nil {
+			log.Printf("Failed to save content hashes: %v", err)
+			c.alert(ctx, alertStorage, "Failed to save content hashes", err)
+		}
+	}
+	for _, a := range c.archives {
+		if err := a.Save(); err != nil {
+			log.Printf("Failed to save archive: %v", err)
+			c.alert(ctx, alertStorage, "Failed to save archive", err)
+		}
+	}
+	if c.digest != nil {
+		c.FlushDigest(ctx, false)
+	}
+	if c.sentiment != nil {
+		c.reportSentiment(ctx)
+		if err := c.sentiment.Save(); err != nil {
+			log.Printf("Failed to save sentiment: %v", err)
+			c.alert(ctx, alertStorage, "Failed to save sentiment", err)
+		}
+	}
+	if c.stats != nil {
+		c.stats.RecordUsage(c.twitterClient.RequestCount())
+		c.reportStats(ctx)
+		if err := c.stats.Save(); err != nil {
+			log.Printf("Failed to save stats: %v", err)
+			c.alert(ctx, alertStorage, "Failed to save stats", err)
+		}
 	}
 
-	log.Printf("Crawl complete: processed=%d, notified=%d, total_seen=%d",
-		totalProcessed, totalNotified, c.seenTweets.Count())
+	for _, s := range c.sinks {
+		if t, ok := s.(sink.Ticker); ok {
+			t.Tick(ctx, time.Now())
+		}
+	}
+	if c.control != nil {
+		if err := c.control.Save(); err != nil {
+			log.Printf("Failed to save control state: %v", err)
+			c.alert(ctx, alertStorage, "Failed to save control state", err)
+		}
+	}
+
+	status := Status{
+		LastRun:      time.Now(),
+		Processed:    totalProcessed,
+		Notified:     totalNotified,
+		Seen:         c.seenTweets.Count(),
+		RetryQueue:   len(c.retryQueue),
+		SlackPending: c.slackNotifier.PendingCount(),
+	}
+	c.statusMu.Lock()
+	c.status = status
+	c.statusMu.Unlock()
+
+	log.Printf("Crawl complete: processed=%d, notified=%d, total_seen=%d, retry_queue=%d, slack_pending=%d",
+		status.Processed, status.Notified, status.Seen, status.RetryQueue, status.SlackPending)
 
 	return nil
 }
 
+// Status は直近のクロールの結果を返す（まだクロールしていない場合はゼロ値）
+func (c *Crawler) Status() Status {
+	c.statusMu.Lock()
+	defer c.statusMu.Unlock()
+	return c.status
+}
+
+// Reload は読み込み直した設定のうち監視対象（traders, keywords）とスコアのしきい値を反映する
+// 既読ID・リトライ待ち・ダイジェストなどの状態はそのまま引き継ぎ、次のクロールから使う
+func (c *Crawler) Reload(cfg *config.Config) {
+	next := *c.config
+	next.Interval = cfg.Interval
+	next.Traders = cfg.Traders
+	next.Keywords = cfg.Keywords
+	next.AI.MinScore = cfg.AI.MinScore
+	next.AI.MinConfidence = cfg.AI.MinConfidence
+	next.AI.OnFailure = cfg.AI.OnFailure
+	next.Relevance.Weight = cfg.Relevance.Weight
+	c.config = &next
+}
+
+// traders は設定ファイルのトレーダーにSlackコマンドで追加したユーザーを加えて返す
+func (c *Crawler) traders() []config.Trader {
+	if c.control == nil {
+		return c.config.Traders
+	}
+	traders := append([]config.Trader(nil), c.config.Traders...)
+	for _, username := range c.control.Traders() {
+		known := false
+		for _, t := range c.config.Traders {
+			if strings.EqualFold(t.Username, username) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			traders = append(traders, config.Trader{Username: username})
+		}
+	}
+	return traders
+}
+
+// traderDue は個別の取得間隔があるトレーダーについて、前回の取得から間隔が経過したかを返す（経過していれば取得時刻を記録）
+// クロールは全体の interval ごとに実行されるため、その半分までの早さは許容する
+func (c *Crawler) traderDue(trader config.Trader, now time.Time) bool {
+	interval, _ := trader.GetInterval()
+	if interval <= 0 {
+		return true
+	}
+	global, _ := c.config.GetInterval()
+	key := strings.ToLower(trader.Username)
+	if last, ok := c.lastFetched[key]; ok && now.Sub(last)+global/2 < interval {
+		return false
+	}
+	if c.lastFetched == nil {
+		c.lastFetched = make(map[string]time.Time)
+	}
+	c.lastFetched[key] = now
+	return true
+}
+
+// mutedTicker はミュート中の銘柄があれば返す
+func (c *Crawler) mutedTicker(analysis *ai.Analysis) (string, bool) {
+	if c.control == nil {
+		return "", false
+	}
+	now := time.Now()
+	for _, ticker := range analysis.Tickers {
+		if c.control.Muted(ticker, now) {
+			return ticker, true
+		}
+	}
+	return "", false
+}
+
 // processTrader はトレーダーのツイートを処理
 func (c *Crawler) processTrader(ctx context.Context, trader config.Trader) (processed, notified int, err error) {
-	tweets, err := c.twitterClient.GetUserTweets(ctx, trader.Username, 10)
+	maxResults := trader.MaxResults
+	if maxResults == 0 {
+		maxResults = 10
+	}
+	tweets, err := c.twitterClient.GetUserTweets(ctx, trader.Username, maxResults, trader.IncludeReplies)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	src := source{
+		kind:        "trader",
+		key:         "trader:" + trader.Username,
+		info:        fmt.Sprintf("%s (Priority: %s)", trader.DisplayName, trader.Priority),
+		context:     trader.Context,
+		onAIFailure: trader.OnAIFailure,
+		minScore:    trader.MinScore,
+		skipAI:      trader.SkipAI,
+	}
+	c.recordFetched(tweets, src, src.key)
+
+	processed, notified = c.processTweets(ctx, tweets, src)
+	return processed, notified, nil
+}
+
+// processKeyword はキーワード検索を処理
+func (c *Crawler) processKeyword(ctx context.Context, keyword config.Keyword) (processed, notified int, err error) {
+	var sortOrder string
+	if keyword.ResultType == config.ResultTypePopular {
+		sortOrder = twitter.SortRelevancy
+	}
+	tweets, err := c.twitterClient.SearchTweets(ctx, keyword.SearchQuery(), 10, sortOrder)
 	if err != nil {
 		return 0, 0, err
 	}
+	if keyword.MinEngagement > 0 {
+		// 反応は後から増えるため既読にはせず、次回以降の検索で再び判定する
+		engaged := tweets[:0]
+		for _, tweet := range tweets {
+			if tweet.Engagement() >= keyword.MinEngagement {
+				engaged = append(engaged, tweet)
+			}
+		}
+		if skipped := len(tweets) - len(engaged); skipped > 0 {
+			log.Printf("Skipped %d tweets for '%s' with engagement below %d", skipped, keyword.Name, keyword.MinEngagement)
+		}
+		tweets = engaged
+	}
 
-	traderInfo := fmt.Sprintf("%s (Priority: %s)", trader.DisplayName, trader.Priority)
+	src := source{
+		kind:        "keyword",
+		key:         "keyword:" + keyword.Name,
+		info:        fmt.Sprintf("Keyword: %s", keyword.Name),
+		onAIFailure: keyword.OnAIFailure,
+		minScore:    keyword.MinScore,
+	}
+	c.recordFetched(tweets, src, src.key)
 
+	processed, notified = c.processTweets(ctx, tweets, src)
+	return processed, notified, nil
+}
+
+// recordFetched は取得した未読ツイートをArchiveStoreに、取得元のチェックポイント（最新のツイートID）をCheckpointStoreに記録
+func (c *Crawler) recordFetched(tweets []twitter.Tweet, src source, checkpoint string) {
+	if len(tweets) == 0 {
+		return
+	}
+	newest := tweets[0].ID
 	for _, tweet := range tweets {
-		// 既読チェック
-		if c.seenTweets.Has(tweet.ID) {
+		if storage.NewerTweetID(tweet.ID, newest) {
+			newest = tweet.ID
+		}
+		if len(c.archives) == 0 || c.seenTweets.Has(tweet.ID) || c.queuedForRetry(tweet.ID) {
 			continue
 		}
+		for _, a := range c.archives {
+			if err := a.RecordTweet(tweet, src.info); err != nil {
+				log.Printf("Failed to record tweet: %v", err)
+			}
+		}
+	}
+	if c.checkpoints != nil {
+		if err := c.checkpoints.SetCheckpoint(checkpoint, newest); err != nil {
+			log.Printf("Failed to record checkpoint: %v", err)
+		}
+	}
+}
 
-		processed++
+// processTweets は未読ツイートを順に処理
+func (c *Crawler) processTweets(ctx context.Context, tweets []twitter.Tweet, src source) (processed, notified int) {
+	var unseen []twitter.Tweet
+	for _, tweet := range tweets {
+		// 既読チェック（リトライキューで再分析を待っているものも除く）
+		if c.seenTweets.Has(tweet.ID) || c.queuedForRetry(tweet.ID) {
+			continue
+		}
+		if first := c.duplicateContent(tweet); first != "" {
+			log.Printf("Skipping tweet %s by @%s: same content as tweet %s", tweet.ID, tweet.Username, first)
+			c.seenTweets.Add(tweet.ID)
+			continue
+		}
+		unseen = append(unseen, tweet)
+	}
+	processed = len(unseen)
+	if c.stats != nil {
+		c.stats.AddFetched(len(tweets), processed)
+	}
 
-		// AI分析（有効な場合）
-		if c.aiFilter != nil {
-			analysis, err := c.aiFilter.Analyze(ctx, tweet, traderInfo)
-			if err != nil {
-				log.Printf("AI analysis failed for tweet %s: %v", tweet.ID, err)
-				// AI分析失敗時はシンプル通知にフォールバック
-				if err := c.slackNotifier.NotifySimple(ctx, tweet, traderInfo); err != nil {
-					log.Printf("Failed to send simple notification: %v", err)
-					continue
-				}
-			} else {
-				// スコアチェック
-				if analysis.Score < c.config.AI.MinScore {
-					log.Printf("Tweet %s score too low: %d < %d", tweet.ID, analysis.Score, c.config.AI.MinScore)
-					c.seenTweets.Add(tweet.ID)
-					continue
-				}
-
-				// Slack通知
-				if err := c.slackNotifier.NotifyTweet(ctx, tweet, analysis); err != nil {
-					log.Printf("Failed to notify tweet %s: %v", tweet.ID, err)
-					continue
-				}
-
-				log.Printf("Notified: @%s - Score: %d, Category: %s, Sentiment: %s",
-					tweet.Username, analysis.Score, analysis.Category, analysis.Sentiment)
-			}
-		} else {
-			// AI分析なしでシンプル通知
-			if err := c.slackNotifier.NotifySimple(ctx, tweet, traderInfo); err != nil {
-				log.Printf("Failed to notify tweet %s: %v", tweet.ID, err)
-				continue
-			}
-			log.Printf("Notified (no AI): @%s", tweet.Username)
+	// スレッドは1件にまとめて分析・通知し、含まれる全ツイートを既読にする
+	var threadIDs map[string][]string
+	if c.config.AI.ThreadAnalysis {
+		candidates := unseen
+		if c.slackNotifier.BotMode() {
+			// 続きが投稿されたスレッドは既読の部分も含めて再分析し、元のメッセージを更新する
+			candidates = withThreadContext(tweets, unseen)
 		}
+		unseen, threadIDs = combineThreads(candidates)
+	}
 
-		c.seenTweets.Add(tweet.ID)
+	var costBefore float64
+	if c.stats != nil {
+		costBefore = c.stats.AICost()
+	}
+	results := c.analyzeTweets(ctx, unseen, src)
+	if c.stats != nil {
+		attributeCost(results, c.stats.AICost()-costBefore)
+	}
+
+	for i, tweet := range unseen {
+		var ok bool
+		switch {
+		case results == nil:
+			ok = c.notifyWithoutAI(ctx, tweet, src)
+		case results[i].Err != nil:
+			log.Printf("AI analysis failed for tweet %s: %v", tweet.ID, results[i].Err)
+			c.countError(stats.ErrorAI)
+			c.alert(ctx, alertAI, "AI analysis failed", results[i].Err)
+			ok = c.handleAIFailure(ctx, tweet, src, 0)
+		default:
+			ok = c.handleAnalysis(ctx, tweet, src, results[i].Analysis)
+		}
+		if ids, isThread := threadIDs[tweet.ID]; isThread && (c.seenTweets.Has(tweet.ID) || c.queuedForRetry(tweet.ID)) {
+			for _, id := range ids {
+				c.seenTweets.Add(id)
+			}
+		}
+		if !ok {
+			continue
+		}
 		notified++
 
 		// レート制限対策: 少し待機
 		time.Sleep(500 * time.Millisecond)
 	}
 
-	return processed, notified, nil
+	return processed, notified
 }
 
-// processKeyword はキーワード検索を処理
-func (c *Crawler) processKeyword(ctx context.Context, keyword config.Keyword) (processed, notified int, err error) {
-	tweets, err := c.twitterClient.SearchTweets(ctx, keyword.Query, 10)
+// duplicateContent は期限内に同じ本文の別のツイートを処理していればそのIDを返す
+func (c *Crawler) duplicateContent(tweet twitter.Tweet) string {
+	if c.contents == nil {
+		return ""
+	}
+	hash := twitter.ContentHash(tweet.Text)
+	if hash == "" {
+		return ""
+	}
+	first, err := c.contents.MarkContent(hash, tweet.ID)
 	if err != nil {
-		return 0, 0, err
+		log.Printf("Failed to check content hash of tweet %s: %v", tweet.ID, err)
+		return ""
 	}
+	return first
+}
 
-	for _, tweet := range tweets {
-		// 既読チェック
-		if c.seenTweets.Has(tweet.ID) {
-			continue
+// withThreadContext は未読のスレッドの続きがある場合、同じスレッドの既読ツイートも加えて返す（元の並び順）
+func withThreadContext(tweets, unseen []twitter.Tweet) []twitter.Tweet {
+	grown := make(map[string]bool)
+	isUnseen := make(map[string]bool, len(unseen))
+	for _, t := range unseen {
+		isUnseen[t.ID] = true
+		if t.ConversationID != "" && t.InReplyToUserID == t.AuthorID {
+			grown[t.ConversationID+"/"+t.AuthorID] = true
+		}
+	}
+	if len(grown) == 0 {
+		return unseen
+	}
+
+	var result []twitter.Tweet
+	for _, t := range tweets {
+		if isUnseen[t.ID] || grown[t.ConversationID+"/"+t.AuthorID] {
+			result = append(result, t)
 		}
+	}
+	return result
+}
 
-		processed++
+// combineThreads はスレッドを1件のツイートにまとめ、まとめたツイートのIDごとに元のIDを返す
+func combineThreads(tweets []twitter.Tweet) ([]twitter.Tweet, map[string][]string) {
+	threads := twitter.GroupThreads(tweets)
+	combined := make([]twitter.Tweet, len(threads))
+	threadIDs := make(map[string][]string)
+	for i, th := range threads {
+		combined[i] = th.Combined()
+		if len(th.Tweets) > 1 {
+			threadIDs[combined[i].ID] = th.IDs()
+			log.Printf("Combined thread of %d tweets by @%s into one analysis", len(th.Tweets), combined[i].Username)
+		}
+	}
+	return combined, threadIDs
+}
 
-		keywordInfo := fmt.Sprintf("Keyword: %s", keyword.Name)
+// analyzeTweets はツイートをAI分析する（AI分析が無効、または取得元がAI分析しない設定の場合はnilを返す）
+func (c *Crawler) analyzeTweets(ctx context.Context, tweets []twitter.Tweet, src source) []ai.Result {
+	if c.analyzer == nil || src.skipAI {
+		return nil
+	}
 
-		// AI分析（有効な場合）
-		if c.aiFilter != nil {
-			analysis, err := c.aiFilter.Analyze(ctx, tweet, keywordInfo)
-			if err != nil {
-				log.Printf("AI analysis failed for tweet %s: %v", tweet.ID, err)
-				if err := c.slackNotifier.NotifySimple(ctx, tweet, keywordInfo); err != nil {
-					log.Printf("Failed to send simple notification: %v", err)
-					continue
-				}
+	items := make([]ai.Item, len(tweets))
+	for i, tweet := range tweets {
+		// リンク先記事の本文をプロンプトに含める
+		if c.articles != nil {
+			tweet.Articles = c.articles.FetchForTweet(ctx, tweet)
+		}
+		items[i] = ai.Item{Tweet: tweet, TraderInfo: src.aiInfo()}
+	}
+	return ai.AnalyzeAll(ai.WithCallSource(ctx, src.key), c.analyzer, items, c.config.AI.BatchSize)
+}
+
+// attributeCost は分析にかかったAIコストを分析できたツイート（キャッシュの再利用を除く）に按分する（集計用）
+func attributeCost(results []ai.Result, cost float64) {
+	if cost <= 0 {
+		return
+	}
+	var n int
+	for _, r := range results {
+		if r.Err == nil && r.Analysis != nil && !r.Analysis.Cached {
+			n++
+		}
+	}
+	for _, r := range results {
+		if r.Err == nil && r.Analysis != nil && !r.Analysis.Cached {
+			r.Analysis.CostUSD = cost / float64(n)
+		}
+	}
+}
+
+// notifyWithoutAI はAI分析なしでシンプル通知
+func (c *Crawler) notifyWithoutAI(ctx context.Context, tweet twitter.Tweet, src source) bool {
+	if err := c.slackNotifier.NotifySimple(ctx, tweet, src.info); notifyFailed(err) {
+		log.Printf("Failed to notify tweet %s: %v", tweet.ID, err)
+		c.countError(stats.ErrorNotify)
+		return false
+	}
+	log.Printf("Notified (%s, no AI): @%s", src.kind, tweet.Username)
+	c.publish(ctx, tweet, src, nil, storage.DecisionNotified)
+	c.seenTweets.Add(tweet.ID)
+	return true
+}
+
+// handleAnalysis はAI分析結果に基づいて通知
+func (c *Crawler) handleAnalysis(ctx context.Context, tweet twitter.Tweet, src source, analysis *ai.Analysis) bool {
+	// フィードバックに基づく投稿者ごとのスコア補正
+	if c.feedback != nil {
+		if offset := c.feedback.ScoreOffset(tweet.Username); offset != 0 {
+			log.Printf("Adjusted score for @%s by feedback: %d%+d", tweet.Username, analysis.Score, offset)
+			analysis.Score = clampScore(analysis.Score + offset)
+		}
+	}
+
+	// 戦略との関連度をスコアに反映
+	var vector []float64
+	if c.relevance != nil {
+		vector = c.embed(ctx, tweet)
+		if vector != nil {
+			if relevance, err := c.relevance.Score(ctx, vector); err != nil {
+				log.Printf("Relevance scoring failed for tweet %s: %v", tweet.ID, err)
 			} else {
-				// スコアチェック
-				if analysis.Score < c.config.AI.MinScore {
-					log.Printf("Tweet %s score too low: %d < %d", tweet.ID, analysis.Score, c.config.AI.MinScore)
-					c.seenTweets.Add(tweet.ID)
-					continue
-				}
-
-				// Slack通知
-				if err := c.slackNotifier.NotifyTweet(ctx, tweet, analysis); err != nil {
-					log.Printf("Failed to notify tweet %s: %v", tweet.ID, err)
-					continue
-				}
-
-				log.Printf("Notified (keyword): @%s - Score: %d, Category: %s",
-					tweet.Username, analysis.Score, analysis.Category)
+				blended := blendScore(analysis.Score, relevance, c.config.Relevance.Weight)
+				log.Printf("Blended score for tweet %s with relevance %d: %d -> %d", tweet.ID, relevance, analysis.Score, blended)
+				analysis.Score = blended
 			}
-		} else {
-			// AI分析なしでシンプル通知
-			if err := c.slackNotifier.NotifySimple(ctx, tweet, keywordInfo); err != nil {
-				log.Printf("Failed to notify tweet %s: %v", tweet.ID, err)
-				continue
+		}
+	}
+
+	// 通知済みのツイート（編集前の投稿やスレッドの起点）は新しい通知を送らず元のメッセージを更新
+	updated, err := c.slackNotifier.UpdateTweet(ctx, tweet, analysis)
+	if err != nil {
+		log.Printf("Failed to update Slack message for tweet %s: %v", tweet.ID, err)
+		return false
+	}
+	if updated {
+		log.Printf("Updated notification (%s): @%s - Score: %d, Category: %s",
+			src.kind, tweet.Username, analysis.Score, analysis.Category)
+		c.recordAnalysis(ctx, tweet, src, analysis, storage.DecisionUpdated)
+		c.recordNotification(tweet, src, analysis)
+		c.seenTweets.Add(tweet.ID)
+		return true
+	}
+
+	// 銘柄別センチメントは通知の有無によらず集計
+	if c.sentiment != nil {
+		c.sentiment.Record(tweet, analysis)
+	}
+
+	// スコアチェック
+	if minScore := src.minScoreOr(c.config.AI.MinScore); analysis.Score < minScore {
+		log.Printf("Tweet %s score too low: %d < %d", tweet.ID, analysis.Score, minScore)
+		c.recordAnalysis(ctx, tweet, src, analysis, storage.DecisionLowScore)
+		c.seenTweets.Add(tweet.ID)
+		return false
+	}
+
+	// Slackコマンドでミュートした銘柄は通知しない
+	if ticker, ok := c.mutedTicker(analysis); ok {
+		log.Printf("Tweet %s mentions muted ticker $%s, skipping notification", tweet.ID, ticker)
+		c.recordAnalysis(ctx, tweet, src, analysis, storage.DecisionMuted)
+		c.seenTweets.Add(tweet.ID)
+		return false
+	}
+
+	// 通知済みツイートと意味的にほぼ同一の場合は通知しない
+	if c.dedupe != nil {
+		if vector == nil {
+			vector = c.embed(ctx, tweet)
+		}
+		if vector != nil {
+			if dupOf, similarity := c.dedupe.Duplicate(vector); dupOf != "" {
+				log.Printf("Tweet %s suppressed as duplicate of %s (similarity: %.3f)", tweet.ID, dupOf, similarity)
+				c.recordAnalysis(ctx, tweet, src, analysis, storage.DecisionDuplicate)
+				c.seenTweets.Add(tweet.ID)
+				return false
 			}
-			log.Printf("Notified (keyword, no AI): @%s", tweet.Username)
 		}
+	}
 
+	// 確信度が低い場合は「要確認」として通知
+	if c.config.AI.MinConfidence > 0 && analysis.Confidence < c.config.AI.MinConfidence {
+		if err := c.slackNotifier.NotifyMaybe(ctx, tweet, analysis); notifyFailed(err) {
+			log.Printf("Failed to notify tweet %s: %v", tweet.ID, err)
+			c.countError(stats.ErrorNotify)
+			return false
+		}
+		log.Printf("Notified as maybe (%s): @%s - Score: %d, Confidence: %d < %d",
+			src.kind, tweet.Username, analysis.Score, analysis.Confidence, c.config.AI.MinConfidence)
+		c.recordAnalysis(ctx, tweet, src, analysis, storage.DecisionMaybe)
+		c.rememberNotified(tweet, vector)
+		c.recordNotification(tweet, src, analysis)
 		c.seenTweets.Add(tweet.ID)
-		notified++
+		return true
+	}
 
-		// レート制限対策: 少し待機
-		time.Sleep(500 * time.Millisecond)
+	// 緊急でない通知と、緊急度ごとの上限を超えた通知はダイジェストにまとめる
+	toDigest := c.digest != nil && !c.digest.Immediate(analysis)
+	if c.digest != nil && !toDigest && c.throttle != nil && !c.throttle.Allow(analysis.Urgency, time.Now()) {
+		log.Printf("Rate limit for %s notifications reached, moving tweet %s to digest", analysis.Urgency, tweet.ID)
+		toDigest = true
+	}
+	if toDigest {
+		c.digest.Add(tweet, analysis)
+		log.Printf("Queued for digest (%s): @%s - Score: %d, Category: %s, Urgency: %s",
+			src.kind, tweet.Username, analysis.Score, analysis.Category, analysis.Urgency)
+		c.recordAnalysis(ctx, tweet, src, analysis, storage.DecisionDigest)
+		c.rememberNotified(tweet, vector)
+		c.recordNotification(tweet, src, analysis)
+		c.seenTweets.Add(tweet.ID)
+		return true
 	}
 
-	return processed, notified, nil
+	// Slack通知
+	if err := c.slackNotifier.NotifyTweet(ctx, tweet, analysis); notifyFailed(err) {
+		log.Printf("Failed to notify tweet %s: %v", tweet.ID, err)
+		c.countError(stats.ErrorNotify)
+		return false
+	}
+
+	log.Printf("Notified (%s): @%s - Score: %d, Category: %s, Sentiment: %s",
+		src.kind, tweet.Username, analysis.Score, analysis.Category, analysis.Sentiment)
+	c.recordAnalysis(ctx, tweet, src, analysis, storage.DecisionNotified)
+	c.rememberNotified(tweet, vector)
+	c.recordNotification(tweet, src, analysis)
+	c.seenTweets.Add(tweet.ID)
+	return true
+}
+
+// reportSentiment は前日分の銘柄別センチメントサマリーを投稿（投稿済みの場合は何もしない）
+func (c *Crawler) reportSentiment(ctx context.Context) {
+	report, due := c.sentiment.Due(time.Now())
+	if !due {
+		return
+	}
+	if len(report.Tickers) > 0 {
+		if err := c.slackNotifier.NotifySentimentReport(ctx, report); notifyFailed(err) {
+			log.Printf("Failed to post sentiment report for %s: %v", report.Day, err)
+			return
+		}
+		log.Printf("Posted sentiment report for %s (%d tickers)", report.Day, len(report.Tickers))
+	}
+	c.sentiment.MarkReported(report)
+}
+
+// reportStats は前日分のクロール統計を投稿（投稿済みの場合は何もしない）
+func (c *Crawler) reportStats(ctx context.Context) {
+	report, due := c.stats.Due(time.Now())
+	if !due {
+		return
+	}
+	if err := c.slackNotifier.NotifyStatsReport(ctx, report); notifyFailed(err) {
+		log.Printf("Failed to post stats report for %s: %v", report.Day, err)
+		return
+	}
+	log.Printf("Posted stats report for %s", report.Day)
+	c.stats.MarkReported(report)
+}
+
+// FlushDigest は投稿時刻に達したダイジェストを投稿（forceがtrueの場合は時刻によらず投稿）
+func (c *Crawler) FlushDigest(ctx context.Context, force bool) {
+	if c.digest == nil {
+		return
+	}
+	items, since, due := c.digest.Due(time.Now(), force)
+	if !due {
+		return
+	}
+	if err := c.slackNotifier.NotifyDigest(ctx, items, since); notifyFailed(err) {
+		log.Printf("Failed to post digest (%d items): %v", len(items), err)
+		c.countError(stats.ErrorNotify)
+		return
+	}
+	c.digest.Remove(len(items))
+	log.Printf("Posted digest (%d items)", len(items))
+}
+
+// embed はツイートの埋め込みベクトルを返す（失敗した場合はnil）
+func (c *Crawler) embed(ctx context.Context, tweet twitter.Tweet) []float64 {
+	if c.embedder == nil {
+		return nil
+	}
+	vectors, err := c.embedder.Embed(ctx, []string{tweet.Text})
+	if err != nil {
+		log.Printf("Failed to embed tweet %s: %v", tweet.ID, err)
+		return nil
+	}
+	return vectors[0]
+}
+
+// blendScore はAIスコアと関連度をweightの比率で混ぜる
+func blendScore(score, relevance int, weight float64) int {
+	return clampScore(int(math.Round((1-weight)*float64(score) + weight*float64(relevance))))
+}
+
+// rememberNotified は通知したツイートを重複判定の対象に加える
+func (c *Crawler) rememberNotified(tweet twitter.Tweet, vector []float64) {
+	if c.dedupe != nil {
+		c.dedupe.Remember(tweet.ID, vector)
+	}
+}
+
+// recordAnalysis はAI分析結果とその処理を保存用に記録し、Slack以外の通知先にも送る
+func (c *Crawler) recordAnalysis(ctx context.Context, tweet twitter.Tweet, src source, analysis *ai.Analysis, decision string) {
+	if c.stats != nil {
+		c.stats.AddDecision(tweet.Username, decision, analysis)
+	}
+	c.publish(ctx, tweet, src, analysis, decision)
+	if len(c.archives) == 0 {
+		return
+	}
+	rec := storage.AnalysisRecord{
+		TweetID:    tweet.ID,
+		Username:   tweet.Username,
+		Source:     src.info,
+		Text:       tweet.Text,
+		CreatedAt:  tweet.CreatedAt,
+		Analysis:   *analysis,
+		Decision:   decision,
+		AnalyzedAt: time.Now(),
+	}
+	for _, a := range c.archives {
+		if err := a.RecordAnalysis(rec); err != nil {
+			log.Printf("Failed to record analysis: %v", err)
+		}
+	}
+}
+
+// recordNotification はフィードバック用に通知を記録
+func (c *Crawler) recordNotification(tweet twitter.Tweet, src source, analysis *ai.Analysis) {
+	if c.feedback != nil {
+		c.feedback.RecordNotification(tweet, src.info, analysis)
+	}
+}
+
+// publish はSlack以外の通知先に処理結果を送る（analysisはAI分析なしの場合nil）
+// 通知しなかった処理も送り、どの処理を扱うかは通知先ごとに決める
+func (c *Crawler) publish(ctx context.Context, tweet twitter.Tweet, src source, analysis *ai.Analysis, decision string) {
+	if len(c.sinks) == 0 {
+		return
+	}
+	event := sink.Event{
+		Tweet:      tweet,
+		Analysis:   analysis,
+		Decision:   decision,
+		Source:     src.kind,
+		SourceInfo: src.info,
+		At:         time.Now(),
+	}
+	for _, s := range c.sinks {
+		if err := s.Send(ctx, event); err != nil {
+			log.Printf("Failed to notify tweet %s via %s: %v", tweet.ID, s.Name(), err)
+			c.countError(stats.ErrorNotify)
+		}
+	}
+}
+
+// alert は運用アラートを通知（同じ種類のアラートはNotifier側で間引かれる）
+func (c *Crawler) alert(ctx context.Context, kind, what string, err error) {
+	c.slackNotifier.Alert(ctx, kind, fmt.Sprintf("%s: %v", what, err))
+}
+
+// countError はエラーを統計に記録
+func (c *Crawler) countError(kind string) {
+	if c.stats != nil {
+		c.stats.AddError(kind)
+	}
+}
+
+// notifyFailed は通知に失敗したかを返す（再送キューに入った場合は通知済みとして扱う）
+func notifyFailed(err error) bool {
+	return err != nil && !errors.Is(err, slack.ErrQueued)
+}
+
+// clampScore はスコアを0-100に収める
+func clampScore(score int) int {
+	if score < 0 {
+		return 0
+	}
+	if score > 100 {
+		return 100
+	}
+	return score
+}
+
+// handleAIFailure は設定に従ってAI分析失敗時の処理を行う
+func (c *Crawler) handleAIFailure(ctx context.Context, tweet twitter.Tweet, src source, attempts int) bool {
+	switch c.config.OnFailureFor(src.onAIFailure) {
+	case config.OnFailureSkip:
+		// 既読にせず、次回サイクルの取得時に再分析する
+		log.Printf("Skipped tweet %s, will retry next cycle", tweet.ID)
+		return false
+
+	case config.OnFailureQueueRetry:
+		if attempts < maxAIRetryAttempts {
+			// 通知するまでは既読にしない（キューはメモリ上のみのため、再起動後は取得範囲内なら再取得される）
+			c.retryQueue = append(c.retryQueue, retryItem{tweet: tweet, src: src, attempts: attempts + 1})
+			log.Printf("Queued tweet %s for AI retry (attempt %d/%d)", tweet.ID, attempts+1, maxAIRetryAttempts)
+			return false
+		}
+		log.Printf("AI retry limit reached for tweet %s, falling back to simple notification", tweet.ID)
+	}
+
+	// シンプル通知にフォールバック
+	if err := c.slackNotifier.NotifySimple(ctx, tweet, src.info); notifyFailed(err) {
+		log.Printf("Failed to send simple notification: %v", err)
+		c.countError(stats.ErrorNotify)
+		return false
+	}
+	c.seenTweets.Add(tweet.ID)
+	return true
+}
+
+// processRetryQueue はリトライキューのツイートを再分析し、通知件数を返す
+func (c *Crawler) processRetryQueue(ctx context.Context) int {
+	if len(c.retryQueue) == 0 || c.analyzer == nil {
+		return 0
+	}
+
+	queue := c.retryQueue
+	c.retryQueue = nil
+
+	log.Printf("Retrying %d queued tweets", len(queue))
+
+	notified := 0
+	for _, item := range queue {
+		var ok bool
+		switch {
+		case item.analysis != nil:
+			// 分析済みの結果で通知のみ再試行（スコア補正前の結果を渡す）
+			analysis := *item.analysis
+			ok = c.handleAnalysis(ctx, item.tweet, item.src, &analysis)
+		case item.attempts >= maxAIRetryAttempts:
+			// 再試行の上限に達した後のシンプル通知の失敗は、再分析せず通知のみ再試行
+			ok = c.handleAIFailure(ctx, item.tweet, item.src, item.attempts)
+		default:
+			var costBefore float64
+			if c.stats != nil {
+				costBefore = c.stats.AICost()
+			}
+			callCtx := ai.WithCallTweets(ai.WithCallSource(ctx, item.src.key), item.tweet.ID)
+			analysis, err := c.analyzer.Analyze(callCtx, item.tweet, item.src.aiInfo())
+			if c.stats != nil {
+				attributeCost([]ai.Result{{Analysis: analysis, Err: err}}, c.stats.AICost()-costBefore)
+			}
+			if err != nil {
+				log.Printf("AI analysis retry failed for tweet %s: %v", item.tweet.ID, err)
+				c.countError(stats.ErrorAI)
+				c.alert(ctx, alertAI, "AI analysis retry failed", err)
+				ok = c.handleAIFailure(ctx, item.tweet, item.src, item.attempts)
+			} else {
+				stored := *analysis
+				item.analysis = &stored
+				ok = c.handleAnalysis(ctx, item.tweet, item.src, analysis)
+			}
+		}
+		if ok {
+			notified++
+			time.Sleep(500 * time.Millisecond)
+			continue
+		}
+		// 通知に失敗したツイートは取得範囲から外れても失われないよう、次回サイクルで再試行
+		if !c.seenTweets.Has(item.tweet.ID) && !c.queuedForRetry(item.tweet.ID) {
+			log.Printf("Tweet %s was not notified, keeping it in the retry queue", item.tweet.ID)
+			c.retryQueue = append(c.retryQueue, item)
+		}
+	}
+
+	return notified
+}
+
+// queuedForRetry はツイートがリトライキューで再分析を待っているかを返す
+func (c *Crawler) queuedForRetry(id string) bool {
+	for _, item := range c.retryQueue {
+		if item.tweet.ID == id {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,133 @@
+package digest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// NoTicker は銘柄を含まない通知のグループ名
+const NoTicker = ""
+
+// Item はダイジェストにまとめる通知
+type Item struct {
+	Tweet    twitter.Tweet
+	Analysis *ai.Analysis
+}
+
+// Group は銘柄ごとにまとめた通知（カテゴリ、スコアの高い順）
+type Group struct {
+	Ticker string // 銘柄を含まない場合はNoTicker
+	Items  []Item
+}
+
+// Digest は緊急でない通知を溜めて一定間隔でまとめて投稿する
+type Digest struct {
+	interval  time.Duration
+	immediate map[string]bool // 即時通知する緊急度
+
+	mu      sync.Mutex
+	items   []Item
+	started time.Time // 最初の通知を溜めた時刻
+}
+
+// NewDigest は新しいDigestを作成
+// immediateに含まれる緊急度の通知はダイジェストに溜めずに即時通知する
+func NewDigest(interval time.Duration, immediate []string) *Digest {
+	d := &Digest{
+		interval:  interval,
+		immediate: make(map[string]bool, len(immediate)),
+	}
+	for _, u := range immediate {
+		d.immediate[u] = true
+	}
+	return d
+}
+
+// Immediate は分析結果を即時通知すべきかを返す
+func (d *Digest) Immediate(analysis *ai.Analysis) bool {
+	return d.immediate[analysis.Urgency]
+}
+
+// Add は通知をダイジェストに溜める
+func (d *Digest) Add(tweet twitter.Tweet, analysis *ai.Analysis) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.items) == 0 {
+		d.started = time.Now()
+	}
+	d.items = append(d.items, Item{Tweet: tweet, Analysis: analysis})
+}
+
+// Len は溜まっている通知の件数を返す
+func (d *Digest) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.items)
+}
+
+// Due は投稿時刻に達していれば溜まっている通知を返す（投稿後はRemoveを呼ぶ）
+// forceがtrueの場合は時刻によらず返す（終了時など）
+func (d *Digest) Due(now time.Time, force bool) ([]Item, time.Time, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.items) == 0 {
+		return nil, time.Time{}, false
+	}
+	if !force && now.Sub(d.started) < d.interval {
+		return nil, time.Time{}, false
+	}
+	return append([]Item(nil), d.items...), d.started, true
+}
+
+// Remove は投稿済みの先頭n件を取り除く
+func (d *Digest) Remove(n int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if n > len(d.items) {
+		n = len(d.items)
+	}
+	d.items = append([]Item(nil), d.items[n:]...)
+	if len(d.items) > 0 {
+		d.started = time.Now()
+	}
+}
+
+// Groups は通知を最初の銘柄ごとにまとめる（件数の多い順、銘柄なしは最後）
+func Groups(items []Item) []Group {
+	index := make(map[string]int)
+	var groups []Group
+	for _, item := range items {
+		ticker := NoTicker
+		if len(item.Analysis.Tickers) > 0 {
+			ticker = item.Analysis.Tickers[0]
+		}
+		i, ok := index[ticker]
+		if !ok {
+			i = len(groups)
+			index[ticker] = i
+			groups = append(groups, Group{Ticker: ticker})
+		}
+		groups[i].Items = append(groups[i].Items, item)
+	}
+
+	for _, g := range groups {
+		sort.SliceStable(g.Items, func(i, j int) bool {
+			a, b := g.Items[i].Analysis, g.Items[j].Analysis
+			if a.Category != b.Category {
+				return a.Category < b.Category
+			}
+			return a.Score > b.Score
+		})
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].Ticker == NoTicker) != (groups[j].Ticker == NoTicker) {
+			return groups[j].Ticker == NoTicker
+		}
+		return len(groups[i].Items) > len(groups[j].Items)
+	})
+	return groups
+}
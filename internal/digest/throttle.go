@@ -0,0 +1,48 @@
+package digest
+
+import (
+	"sync"
+	"time"
+)
+
+// Throttle は緊急度ごとに一定時間内の即時通知の件数を制限する
+type Throttle struct {
+	limits map[string]int // 緊急度 -> 期間内の上限（含まれない緊急度は無制限）
+	window time.Duration
+
+	mu   sync.Mutex
+	sent map[string][]time.Time // 緊急度 -> 期間内に通知した時刻
+}
+
+// NewThrottle は新しいThrottleを作成
+func NewThrottle(limits map[string]int, window time.Duration) *Throttle {
+	return &Throttle{
+		limits: limits,
+		window: window,
+		sent:   make(map[string][]time.Time),
+	}
+}
+
+// Allow は緊急度の通知を今送ってよいかを返し、送ってよい場合は件数に数える
+func (t *Throttle) Allow(urgency string, now time.Time) bool {
+	limit, ok := t.limits[urgency]
+	if !ok {
+		return true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	recent := t.sent[urgency][:0]
+	for _, at := range t.sent[urgency] {
+		if now.Sub(at) < t.window {
+			recent = append(recent, at)
+		}
+	}
+	if len(recent) >= limit {
+		t.sent[urgency] = recent
+		return false
+	}
+	t.sent[urgency] = append(recent, now)
+	return true
+}
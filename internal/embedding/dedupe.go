@@ -0,0 +1,71 @@
+package embedding
+
+import (
+	"sync"
+	"time"
+)
+
+// recentItem は重複判定用に保持する通知済みツイート
+type recentItem struct {
+	tweetID    string
+	vector     []float64
+	notifiedAt time.Time
+}
+
+// Deduper は通知済みツイートと意味的にほぼ同一のツイートを検出する
+type Deduper struct {
+	mu        sync.Mutex
+	threshold float64       // これ以上のコサイン類似度を重複とみなす
+	window    time.Duration // 比較対象とする通知済みツイートの期間
+	recent    []recentItem
+}
+
+// NewDeduper は新しいDeduperを作成
+func NewDeduper(threshold float64, window time.Duration) *Deduper {
+	return &Deduper{
+		threshold: threshold,
+		window:    window,
+	}
+}
+
+// Duplicate はベクトルが期間内に通知したツイートと重複している場合にその元ツイートIDと類似度を返す
+func (d *Deduper) Duplicate(vector []float64) (dupOf string, similarity float64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.pruneLocked(time.Now())
+	for _, item := range d.recent {
+		if s := Cosine(vector, item.vector); s >= d.threshold && s > similarity {
+			dupOf, similarity = item.tweetID, s
+		}
+	}
+	return dupOf, similarity
+}
+
+// Remember は通知したツイートを重複判定の対象に加える
+func (d *Deduper) Remember(tweetID string, vector []float64) {
+	if vector == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.recent = append(d.recent, recentItem{
+		tweetID:    tweetID,
+		vector:     vector,
+		notifiedAt: time.Now(),
+	})
+}
+
+// pruneLocked は期間外の通知済みツイートを削除（ロック取得済みで呼ぶ）
+func (d *Deduper) pruneLocked(now time.Time) {
+	cutoff := now.Add(-d.window)
+	kept := d.recent[:0]
+	for _, item := range d.recent {
+		if item.notifiedAt.After(cutoff) {
+			kept = append(kept, item)
+		}
+	}
+	d.recent = kept
+}
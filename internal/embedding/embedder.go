@@ -0,0 +1,143 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 対応している埋め込みプロバイダー
+const (
+	ProviderOpenAI = "openai"
+	ProviderVoyage = "voyage"
+)
+
+// デフォルトのエンドポイント
+const (
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+	DefaultVoyageBaseURL = "https://api.voyageai.com/v1"
+)
+
+// Embedder はテキストを埋め込みベクトルに変換する
+type Embedder interface {
+	// Embed はtextsと同じ順序でベクトルを返す
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// HTTPEmbedder は /embeddings 形式のAPI (OpenAI互換・Voyage AI) を使ったEmbedder実装
+type HTTPEmbedder struct {
+	name       string
+	endpoint   string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOpenAIEmbedder はOpenAI互換APIのEmbedderを作成（baseURLが空の場合はOpenAI）
+func NewOpenAIEmbedder(apiKey, model, baseURL string) *HTTPEmbedder {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+	return newHTTPEmbedder("OpenAI", baseURL, apiKey, model)
+}
+
+// NewVoyageEmbedder はVoyage AIのEmbedderを作成
+func NewVoyageEmbedder(apiKey, model, baseURL string) *HTTPEmbedder {
+	if baseURL == "" {
+		baseURL = DefaultVoyageBaseURL
+	}
+	return newHTTPEmbedder("Voyage", baseURL, apiKey, model)
+}
+
+func newHTTPEmbedder(name, baseURL, apiKey, model string) *HTTPEmbedder {
+	return &HTTPEmbedder{
+		name:     name,
+		endpoint: strings.TrimRight(baseURL, "/") + "/embeddings",
+		apiKey:   apiKey,
+		model:    model,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Embed はテキストをまとめて埋め込みベクトルに変換
+func (e *HTTPEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"model": e.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.endpoint, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s embeddings API error (status %d): %s", e.name, resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float64, len(texts))
+	for _, d := range result.Data {
+		if d.Index >= 0 && d.Index < len(vectors) {
+			vectors[d.Index] = d.Embedding
+		}
+	}
+	for i, v := range vectors {
+		if v == nil {
+			return nil, fmt.Errorf("%s embeddings API returned no vector for input %d", e.name, i)
+		}
+	}
+	return vectors, nil
+}
+
+// Cosine は2つのベクトルのコサイン類似度を返す
+func Cosine(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+var _ Embedder = (*HTTPEmbedder)(nil)
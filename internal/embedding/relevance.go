@@ -0,0 +1,56 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Relevance は自然言語で書いた戦略・ウォッチリストとの関連度を評価する
+type Relevance struct {
+	mu       sync.Mutex
+	embedder Embedder
+	profile  string
+	vector   []float64 // profileのベクトル（初回に取得）
+	floor    float64   // 関連度0とみなすコサイン類似度
+	ceil     float64   // 関連度100とみなすコサイン類似度
+}
+
+// NewRelevance は新しいRelevanceを作成
+// コサイン類似度をfloor〜ceilの範囲で0-100に線形変換する
+func NewRelevance(embedder Embedder, profile string, floor, ceil float64) *Relevance {
+	return &Relevance{
+		embedder: embedder,
+		profile:  profile,
+		floor:    floor,
+		ceil:     ceil,
+	}
+}
+
+// Score はツイートのベクトルと戦略の関連度 (0-100) を返す
+func (r *Relevance) Score(ctx context.Context, vector []float64) (int, error) {
+	profile, err := r.profileVector(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	scaled := (Cosine(vector, profile) - r.floor) / (r.ceil - r.floor) * 100
+	return int(math.Round(math.Max(0, math.Min(100, scaled)))), nil
+}
+
+// profileVector は戦略のベクトルを返す（失敗した場合は次回再取得）
+func (r *Relevance) profileVector(ctx context.Context) ([]float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.vector != nil {
+		return r.vector, nil
+	}
+	vectors, err := r.embedder.Embed(ctx, []string{r.profile})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed relevance profile: %w", err)
+	}
+	r.vector = vectors[0]
+	return r.vector, nil
+}
@@ -0,0 +1,157 @@
+package evaluate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/storage"
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// Case は評価に使うツイートと任意の期待ラベル
+type Case struct {
+	TweetID  string    `json:"tweet_id"`
+	Username string    `json:"username"`
+	Source   string    `json:"source"`
+	Text     string    `json:"text"`
+	PostedAt time.Time `json:"created_at"`
+
+	ExpectedScore    *int   `json:"expected_score,omitempty"`
+	ExpectedCategory string `json:"expected_category,omitempty"`
+	ExpectedNotify   *bool  `json:"expected_notify,omitempty"` // 通知すべきか（useful/noiseのラベルに相当）
+}
+
+// Result は1ツイートの評価結果
+type Result struct {
+	TweetID  string       `json:"tweet_id"`
+	Analysis *ai.Analysis `json:"analysis,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// LoadCorpus は評価用のツイートを読み込む
+// Caseの配列、または保存された分析結果 (analyses.json) を受け付ける。
+// 分析結果の場合は保存時の分析をベースラインとして返す
+// ディレクトリの場合はアーカイブ (storage.archive) の分析結果を読み込む（再分析されたツイートは最新の分析）
+func LoadCorpus(path string) ([]Case, []Result, error) {
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		archive, err := storage.NewArchive(path, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		archived, err := archive.Analyses(time.Time{}, time.Time{})
+		if err != nil {
+			return nil, nil, err
+		}
+		records := make(map[string]storage.AnalysisRecord, len(archived))
+		for _, rec := range archived {
+			records[rec.TweetID] = rec
+		}
+		cases, baseline := recordCases(records)
+		return cases, baseline, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read corpus: %w", err)
+	}
+	// storage.encryption で暗号化した analyses.json も読めるようにする
+	if data, err = storage.DecryptFile(data); err != nil {
+		return nil, nil, fmt.Errorf("failed to read corpus: %w", err)
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var cases []Case
+		if err := json.Unmarshal(data, &cases); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse corpus: %w", err)
+		}
+		return cases, nil, nil
+	}
+
+	var records map[string]storage.AnalysisRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse analyses corpus: %w", err)
+	}
+	cases, baseline := recordCases(records)
+	return cases, baseline, nil
+}
+
+// recordCases は保存された分析結果をツイートID順のCaseとベースラインに変換
+func recordCases(records map[string]storage.AnalysisRecord) ([]Case, []Result) {
+	ids := make([]string, 0, len(records))
+	for id := range records {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	cases := make([]Case, len(ids))
+	baseline := make([]Result, len(ids))
+	for i, id := range ids {
+		rec := records[id]
+		cases[i] = Case{
+			TweetID:  rec.TweetID,
+			Username: rec.Username,
+			Source:   rec.Source,
+			Text:     rec.Text,
+			PostedAt: rec.CreatedAt,
+		}
+		analysis := rec.Analysis
+		baseline[i] = Result{TweetID: rec.TweetID, Analysis: &analysis}
+	}
+	return cases, baseline
+}
+
+// Run は全てのケースを分析する
+func Run(ctx context.Context, analyzer ai.Analyzer, cases []Case, batchSize int) []Result {
+	items := make([]ai.Item, len(cases))
+	for i, c := range cases {
+		items[i] = ai.Item{
+			Tweet: twitter.Tweet{
+				ID:        c.TweetID,
+				Text:      c.Text,
+				Username:  c.Username,
+				CreatedAt: c.PostedAt,
+			},
+			TraderInfo: c.Source,
+		}
+	}
+
+	results := make([]Result, len(cases))
+	for i, r := range ai.AnalyzeAll(ctx, analyzer, items, batchSize) {
+		results[i] = Result{TweetID: cases[i].TweetID, Analysis: r.Analysis}
+		if r.Err != nil {
+			results[i].Error = r.Err.Error()
+		}
+	}
+	return results
+}
+
+// LoadResults は以前の評価結果を読み込む
+func LoadResults(path string) ([]Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read results: %w", err)
+	}
+	var results []Result
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse results: %w", err)
+	}
+	return results, nil
+}
+
+// SaveResults は評価結果を保存（次回のベースラインとして使える）
+func SaveResults(path string, results []Result) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal results: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	return nil
+}
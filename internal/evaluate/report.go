@@ -0,0 +1,201 @@
+package evaluate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+)
+
+// Stats はスコア分布の集計
+type Stats struct {
+	Count    int
+	Errors   int
+	Mean     float64
+	Notified int     // min_score以上の件数
+	Buckets  [10]int // 0-9, 10-19, ..., 90-100
+}
+
+// Diff はベースラインや期待ラベルと食い違ったケース
+type Diff struct {
+	Case     Case
+	Baseline *ai.Analysis
+	Current  *ai.Analysis
+	Reasons  []string
+}
+
+// Report は評価結果のレポート
+type Report struct {
+	MinScore      int
+	Current       Stats
+	Baseline      *Stats // ベースラインがない場合はnil
+	Disagreements []Diff // ベースラインとの食い違い（スコア差の大きい順）
+	Mismatches    []Diff // 期待ラベルとの食い違い
+	Labeled       int    // 期待ラベル付きのケース数
+}
+
+// BuildReport は評価結果をベースライン・期待ラベルと比較する
+// scoreDelta以上のスコア差、カテゴリの違い、通知判定の違いを食い違いとして扱う
+func BuildReport(cases []Case, current, baseline []Result, minScore, scoreDelta int) *Report {
+	r := &Report{
+		MinScore: minScore,
+		Current:  stats(current, minScore),
+	}
+
+	base := make(map[string]*ai.Analysis)
+	if baseline != nil {
+		s := stats(baseline, minScore)
+		r.Baseline = &s
+		for _, res := range baseline {
+			base[res.TweetID] = res.Analysis
+		}
+	}
+
+	for i, c := range cases {
+		cur := current[i].Analysis
+		if cur == nil {
+			continue
+		}
+
+		if b := base[c.TweetID]; b != nil {
+			var reasons []string
+			if d := cur.Score - b.Score; d >= scoreDelta || -d >= scoreDelta {
+				reasons = append(reasons, fmt.Sprintf("score %d -> %d", b.Score, cur.Score))
+			}
+			if cur.Category != b.Category {
+				reasons = append(reasons, fmt.Sprintf("category %s -> %s", b.Category, cur.Category))
+			}
+			if (cur.Score >= minScore) != (b.Score >= minScore) {
+				reasons = append(reasons, fmt.Sprintf("notify %t -> %t", b.Score >= minScore, cur.Score >= minScore))
+			}
+			if len(reasons) > 0 {
+				r.Disagreements = append(r.Disagreements, Diff{Case: c, Baseline: b, Current: cur, Reasons: reasons})
+			}
+		}
+
+		if c.ExpectedScore == nil && c.ExpectedCategory == "" && c.ExpectedNotify == nil {
+			continue
+		}
+		r.Labeled++
+		var reasons []string
+		if c.ExpectedScore != nil {
+			if d := cur.Score - *c.ExpectedScore; d >= scoreDelta || -d >= scoreDelta {
+				reasons = append(reasons, fmt.Sprintf("score %d (expected %d)", cur.Score, *c.ExpectedScore))
+			}
+		}
+		if c.ExpectedCategory != "" && cur.Category != c.ExpectedCategory {
+			reasons = append(reasons, fmt.Sprintf("category %s (expected %s)", cur.Category, c.ExpectedCategory))
+		}
+		if c.ExpectedNotify != nil && (cur.Score >= minScore) != *c.ExpectedNotify {
+			reasons = append(reasons, fmt.Sprintf("notify %t (expected %t)", cur.Score >= minScore, *c.ExpectedNotify))
+		}
+		if len(reasons) > 0 {
+			r.Mismatches = append(r.Mismatches, Diff{Case: c, Current: cur, Reasons: reasons})
+		}
+	}
+
+	sort.SliceStable(r.Disagreements, func(i, j int) bool {
+		return scoreGap(r.Disagreements[i]) > scoreGap(r.Disagreements[j])
+	})
+	return r
+}
+
+// stats は評価結果のスコア分布を集計
+func stats(results []Result, minScore int) Stats {
+	var s Stats
+	total := 0
+	for _, res := range results {
+		if res.Analysis == nil {
+			s.Errors++
+			continue
+		}
+		score := res.Analysis.Score
+		s.Count++
+		total += score
+		if score >= minScore {
+			s.Notified++
+		}
+		bucket := score / 10
+		if bucket > 9 {
+			bucket = 9
+		}
+		if bucket < 0 {
+			bucket = 0
+		}
+		s.Buckets[bucket]++
+	}
+	if s.Count > 0 {
+		s.Mean = float64(total) / float64(s.Count)
+	}
+	return s
+}
+
+// scoreGap はベースラインとのスコア差の絶対値
+func scoreGap(d Diff) int {
+	gap := d.Current.Score - d.Baseline.Score
+	if gap < 0 {
+		return -gap
+	}
+	return gap
+}
+
+// Markdown はレポートをMarkdown形式で返す
+func (r *Report) Markdown() string {
+	var b strings.Builder
+
+	b.WriteString("# Prompt evaluation report\n\n")
+	b.WriteString("## Score distribution\n\n")
+	if r.Baseline != nil {
+		b.WriteString("| | baseline | current |\n|---|---|---|\n")
+		fmt.Fprintf(&b, "| analyzed | %d | %d |\n", r.Baseline.Count, r.Current.Count)
+		fmt.Fprintf(&b, "| errors | %d | %d |\n", r.Baseline.Errors, r.Current.Errors)
+		fmt.Fprintf(&b, "| mean score | %.1f | %.1f |\n", r.Baseline.Mean, r.Current.Mean)
+		fmt.Fprintf(&b, "| notified (>= %d) | %d | %d |\n", r.MinScore, r.Baseline.Notified, r.Current.Notified)
+		for i := range r.Current.Buckets {
+			fmt.Fprintf(&b, "| %s | %d | %d |\n", bucketLabel(i), r.Baseline.Buckets[i], r.Current.Buckets[i])
+		}
+	} else {
+		b.WriteString("| | current |\n|---|---|\n")
+		fmt.Fprintf(&b, "| analyzed | %d |\n", r.Current.Count)
+		fmt.Fprintf(&b, "| errors | %d |\n", r.Current.Errors)
+		fmt.Fprintf(&b, "| mean score | %.1f |\n", r.Current.Mean)
+		fmt.Fprintf(&b, "| notified (>= %d) | %d |\n", r.MinScore, r.Current.Notified)
+		for i, n := range r.Current.Buckets {
+			fmt.Fprintf(&b, "| %s | %d |\n", bucketLabel(i), n)
+		}
+	}
+
+	if r.Baseline != nil {
+		fmt.Fprintf(&b, "\n## Disagreements with baseline (%d)\n\n", len(r.Disagreements))
+		writeDiffs(&b, r.Disagreements)
+	}
+	if r.Labeled > 0 {
+		fmt.Fprintf(&b, "\n## Expected label mismatches (%d / %d labeled)\n\n", len(r.Mismatches), r.Labeled)
+		writeDiffs(&b, r.Mismatches)
+	}
+	return b.String()
+}
+
+// writeDiffs は食い違いの一覧を書き出す
+func writeDiffs(b *strings.Builder, diffs []Diff) {
+	if len(diffs) == 0 {
+		b.WriteString("None.\n")
+		return
+	}
+	for _, d := range diffs {
+		text := strings.Join(strings.Fields(d.Case.Text), " ")
+		if r := []rune(text); len(r) > 120 {
+			text = string(r[:120]) + "…"
+		}
+		fmt.Fprintf(b, "- **%s** @%s: %s\n  > %s\n", d.Case.TweetID, d.Case.Username, strings.Join(d.Reasons, ", "), text)
+	}
+}
+
+// bucketLabel はスコア帯の表示名
+func bucketLabel(i int) string {
+	if i == 9 {
+		return "90-100"
+	}
+	return fmt.Sprintf("%d-%d", i*10, i*10+9)
+}
@@ -0,0 +1,97 @@
+package feedback
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/storage"
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// noiseExampleScore はノイズと評価された例をプロンプトで示すときのスコア
+const noiseExampleScore = 20
+
+// Calibrator はユーザーフィードバックからスコアの補正情報を作る
+type Calibrator struct {
+	store       *storage.FeedbackStore
+	maxExamples int // プロンプトに含める最大件数
+	maxOffset   int // 投稿者ごとのスコア補正の上限
+	minSamples  int // スコア補正に必要な最小フィードバック件数
+}
+
+// NewCalibrator は新しいCalibratorを作成
+func NewCalibrator(store *storage.FeedbackStore, maxExamples, maxOffset, minSamples int) *Calibrator {
+	return &Calibrator{
+		store:       store,
+		maxExamples: maxExamples,
+		maxOffset:   maxOffset,
+		minSamples:  minSamples,
+	}
+}
+
+// RecordNotification は通知したツイートを記録
+func (c *Calibrator) RecordNotification(tweet twitter.Tweet, source string, analysis *ai.Analysis) {
+	rec := storage.NotificationRecord{
+		TweetID:    tweet.ID,
+		Username:   tweet.Username,
+		Source:     source,
+		Text:       tweet.Text,
+		NotifiedAt: time.Now(),
+	}
+	if analysis != nil {
+		rec.Score = analysis.Score
+		rec.Category = analysis.Category
+		rec.Tickers = analysis.Tickers
+	}
+	c.store.RecordNotification(rec)
+}
+
+// Examples は最近のフィードバックをfew-shotの参考例として返す (ai.ExampleSource実装)
+func (c *Calibrator) Examples() []ai.Example {
+	if c.maxExamples <= 0 {
+		return nil
+	}
+
+	var examples []ai.Example
+	for _, rec := range c.store.RecentLabeled(c.maxExamples) {
+		ex := ai.Example{
+			Author:   rec.Username,
+			Text:     rec.Text,
+			Score:    rec.Score,
+			Category: rec.Category,
+			Reason:   fmt.Sprintf("ユーザー評価: 有用 (当初スコア %d)", rec.Score),
+		}
+		if rec.Label == storage.LabelNoise {
+			ex.Score = noiseExampleScore
+			ex.Reason = fmt.Sprintf("ユーザー評価: ノイズ (当初スコア %d だったが低く評価すべき)", rec.Score)
+		}
+		examples = append(examples, ex)
+	}
+	return examples
+}
+
+// ScoreOffset は投稿者ごとのフィードバック傾向に基づくスコア補正値を返す
+// 有用の割合が高いほどプラス、ノイズの割合が高いほどマイナスになる
+func (c *Calibrator) ScoreOffset(username string) int {
+	if c.maxOffset <= 0 {
+		return 0
+	}
+
+	counts := c.store.LabelCounts()[username]
+	total := counts[0] + counts[1]
+	if total == 0 || total < c.minSamples {
+		return 0
+	}
+
+	ratio := float64(counts[0]-counts[1]) / float64(total)
+	return int(math.Round(ratio * float64(c.maxOffset)))
+}
+
+// Save はフィードバックを保存
+func (c *Calibrator) Save() error {
+	return c.store.Save()
+}
+
+var _ ai.ExampleSource = (*Calibrator)(nil)
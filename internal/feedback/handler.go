@@ -0,0 +1,96 @@
+package feedback
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Minatonton/x-crawler/internal/storage"
+)
+
+// Path はフィードバックAPIのパス
+const Path = "/feedback"
+
+// Handler はフィードバックを受け付けるHTTPハンドラー
+// GET/POST /feedback?tweet_id=...&label=useful|noise&sig=...
+type Handler struct {
+	store  *storage.FeedbackStore
+	secret []byte
+}
+
+// NewHandler は新しいHandlerを作成
+func NewHandler(store *storage.FeedbackStore, secret string) *Handler {
+	return &Handler{
+		store:  store,
+		secret: []byte(secret),
+	}
+}
+
+// ServeHTTP はフィードバックを記録
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	tweetID := r.Form.Get("tweet_id")
+	label := r.Form.Get("label")
+	if len(h.secret) == 0 {
+		http.Error(w, "feedback links are disabled (feedback.secret is not set)", http.StatusForbidden)
+		return
+	}
+	if !hmac.Equal([]byte(r.Form.Get("sig")), []byte(sign(h.secret, tweetID, label))) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+
+	if err := h.store.SetLabel(tweetID, label, ""); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err := h.store.Save(); err != nil {
+		log.Printf("Failed to save feedback: %v", err)
+	}
+
+	log.Printf("Feedback recorded: tweet %s = %s", tweetID, label)
+	fmt.Fprintf(w, "Feedback recorded: %s\n", label)
+}
+
+// Links はSlack通知に埋め込むフィードバックURLを生成
+type Links struct {
+	baseURL string
+	secret  []byte
+}
+
+// NewLinks は新しいLinksを作成（baseURLは外部からアクセス可能なサーバーのURL）
+func NewLinks(baseURL, secret string) *Links {
+	return &Links{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  []byte(secret),
+	}
+}
+
+// URL は署名付きのフィードバックURLを返す
+func (l *Links) URL(tweetID, label string) string {
+	params := url.Values{}
+	params.Set("tweet_id", tweetID)
+	params.Set("label", label)
+	params.Set("sig", sign(l.secret, tweetID, label))
+	return l.baseURL + Path + "?" + params.Encode()
+}
+
+// sign はツイートIDとラベルのHMAC署名を返す
+func sign(secret []byte, tweetID, label string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(tweetID + ":" + label))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,159 @@
+package feedback
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/storage"
+)
+
+const (
+	// InteractionPath はSlackのInteractivity Request URLに設定するパス
+	InteractionPath = "/slack/interactions"
+
+	// ActionName はフィードバックボタンのアクション名（valueは "ラベル:ツイートID"）
+	ActionName = "feedback"
+
+	// maxRequestAge はSlackリクエストのタイムスタンプの許容誤差（リプレイ対策）
+	maxRequestAge = 5 * time.Minute
+)
+
+// InteractionHandler はSlackのインタラクティブボタンからのフィードバックを受け付ける
+type InteractionHandler struct {
+	store         *storage.FeedbackStore
+	signingSecret []byte
+}
+
+// interactionPayload はSlackから送られるインタラクションのうち必要な部分
+type interactionPayload struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Actions []struct {
+		Name     string `json:"name"`      // 旧形式 (attachments) のボタン
+		ActionID string `json:"action_id"` // Block Kitのボタン
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// NewInteractionHandler は新しいInteractionHandlerを作成（signingSecretはSlackアプリのSigning Secret）
+func NewInteractionHandler(store *storage.FeedbackStore, signingSecret string) *InteractionHandler {
+	return &InteractionHandler{
+		store:         store,
+		signingSecret: []byte(signingSecret),
+	}
+}
+
+// ServeHTTP はボタンの操作をフィードバックとして記録し、押したユーザーにだけ結果を返す
+func (h *InteractionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if !VerifySlackSignature(h.signingSecret, r.Header, body, time.Now()) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	var payload interactionPayload
+	if err := json.Unmarshal([]byte(form.Get("payload")), &payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	user := payload.User.Username
+	if user == "" {
+		user = payload.User.Name
+	}
+	if user == "" {
+		user = payload.User.ID
+	}
+
+	for _, action := range payload.Actions {
+		if action.Name != ActionName && action.ActionID != ActionName {
+			continue
+		}
+		label, tweetID, ok := strings.Cut(action.Value, ":")
+		if !ok {
+			continue
+		}
+
+		if err := h.store.SetLabel(tweetID, label, user); err != nil {
+			respond(w, "⚠️ フィードバックを記録できませんでした: "+err.Error())
+			return
+		}
+		if err := h.store.Save(); err != nil {
+			log.Printf("Failed to save feedback: %v", err)
+		}
+		log.Printf("Feedback recorded via Slack: tweet %s = %s (by %s)", tweetID, label, user)
+		respond(w, "✅ フィードバックを記録しました: "+labelText(label))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// VerifySlackSignature はSlackのリクエスト署名 (X-Slack-Signature) を検証
+func VerifySlackSignature(signingSecret []byte, header http.Header, body []byte, now time.Time) bool {
+	ts := header.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(sec, 0)); age > maxRequestAge || age < -maxRequestAge {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, signingSecret)
+	mac.Write([]byte("v0:" + ts + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(header.Get("X-Slack-Signature")), []byte(expected))
+}
+
+// respond はボタンを押したユーザーにだけ表示するメッセージを返す（元のメッセージは変更しない）
+func respond(w http.ResponseWriter, text string) {
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(map[string]interface{}{
+		"response_type":    "ephemeral",
+		"replace_original": false,
+		"text":             text,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf.Bytes())
+}
+
+// labelText はラベルの表示名
+func labelText(label string) string {
+	switch label {
+	case storage.LabelUseful:
+		return "👍 有用"
+	case storage.LabelNoise:
+		return "👎 ノイズ"
+	default:
+		return label
+	}
+}
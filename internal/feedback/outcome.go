@@ -0,0 +1,90 @@
+package feedback
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/quote"
+	"github.com/Minatonton/x-crawler/internal/storage"
+)
+
+// basePriceWindow は通知直後の株価として扱う通知からの経過時間の上限
+const basePriceWindow = 30 * time.Minute
+
+// OutcomeTracker は通知した銘柄の通知直後とN時間後の株価を取得し、値動きを通知記録に追加する
+// クロールのたびにCheckを呼び、測る時刻を大きく過ぎた記録（停止していた間など）は測らない
+type OutcomeTracker struct {
+	store    *storage.FeedbackStore
+	provider quote.Provider
+	hours    []int // 昇順
+}
+
+// NewOutcomeTracker は新しいOutcomeTrackerを作成（hoursは昇順）
+func NewOutcomeTracker(store *storage.FeedbackStore, provider quote.Provider, hours []int) *OutcomeTracker {
+	return &OutcomeTracker{store: store, provider: provider, hours: hours}
+}
+
+// Check は通知直後の株価が未取得の記録と、測る時刻になった値動きを記録する
+func (t *OutcomeTracker) Check(ctx context.Context) {
+	now := time.Now()
+	var measured int
+	for _, rec := range t.store.WithTickers() {
+		if ctx.Err() != nil {
+			return
+		}
+		elapsed := now.Sub(rec.NotifiedAt)
+
+		if rec.BasePrices == nil {
+			if elapsed > basePriceWindow {
+				continue
+			}
+			prices := make(map[string]float64, len(rec.Tickers))
+			quotes, errs := quote.Fetch(ctx, t.provider, rec.Tickers)
+			for _, err := range errs {
+				log.Printf("Failed to fetch base price for tweet %s: %v", rec.TweetID, err)
+			}
+			for symbol, q := range quotes {
+				prices[symbol] = q.Price
+			}
+			if err := t.store.SetBasePrices(rec.TweetID, prices, now); err != nil {
+				log.Printf("Failed to record base prices: %v", err)
+			}
+			continue
+		}
+
+		for _, h := range t.hours {
+			horizon := time.Duration(h) * time.Hour
+			// 測る時刻から期間の半分を過ぎた場合は値動きとして扱わない
+			if elapsed < horizon || elapsed > horizon+horizon/2 {
+				continue
+			}
+			for symbol, base := range rec.BasePrices {
+				if base == 0 || rec.HasOutcome(symbol, h) {
+					continue
+				}
+				q, err := t.provider.Quote(ctx, symbol)
+				if err != nil {
+					log.Printf("Failed to fetch price of %s for tweet %s: %v", symbol, rec.TweetID, err)
+					continue
+				}
+				o := storage.Outcome{
+					Ticker:     symbol,
+					Hours:      h,
+					BasePrice:  base,
+					Price:      q.Price,
+					ChangePct:  (q.Price - base) / base * 100,
+					MeasuredAt: now,
+				}
+				if err := t.store.AddOutcome(rec.TweetID, o); err != nil {
+					log.Printf("Failed to record outcome: %v", err)
+					continue
+				}
+				measured++
+			}
+		}
+	}
+	if measured > 0 {
+		log.Printf("Recorded %d price outcomes of notified tweets", measured)
+	}
+}
@@ -0,0 +1,154 @@
+package quote
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// newHTTPClient は株価APIで使うHTTPクライアント
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: 5 * time.Second}
+}
+
+// Yahoo はYahoo Financeのチャート APIを使ったProvider（APIキー不要）
+type Yahoo struct {
+	httpClient *http.Client
+}
+
+// NewYahoo は新しいYahooを作成
+func NewYahoo() *Yahoo {
+	return &Yahoo{httpClient: newHTTPClient()}
+}
+
+// Quote は銘柄の株価を取得
+func (y *Yahoo) Quote(ctx context.Context, symbol string) (*Quote, error) {
+	var result struct {
+		Chart struct {
+			Result []struct {
+				Meta struct {
+					Symbol             string  `json:"symbol"`
+					RegularMarketPrice float64 `json:"regularMarketPrice"`
+					ChartPreviousClose float64 `json:"chartPreviousClose"`
+					PreviousClose      float64 `json:"previousClose"`
+				} `json:"meta"`
+			} `json:"result"`
+			Error *struct {
+				Description string `json:"description"`
+			} `json:"error"`
+		} `json:"chart"`
+	}
+	endpoint := "https://query1.finance.yahoo.com/v8/finance/chart/" + url.PathEscape(symbol) + "?range=1d&interval=1d"
+	if err := getJSON(ctx, y.httpClient, "Yahoo", endpoint, &result); err != nil {
+		return nil, err
+	}
+	if result.Chart.Error != nil {
+		return nil, fmt.Errorf("Yahoo quote API error: %s", result.Chart.Error.Description)
+	}
+	if len(result.Chart.Result) == 0 {
+		return nil, fmt.Errorf("no quote for %s", symbol)
+	}
+
+	meta := result.Chart.Result[0].Meta
+	prev := meta.PreviousClose
+	if prev == 0 {
+		prev = meta.ChartPreviousClose
+	}
+	return newQuote(symbol, meta.RegularMarketPrice, prev), nil
+}
+
+// AlphaVantage はAlpha VantageのGLOBAL_QUOTEを使ったProvider
+type AlphaVantage struct {
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewAlphaVantage は新しいAlphaVantageを作成
+func NewAlphaVantage(apiKey string) *AlphaVantage {
+	return &AlphaVantage{apiKey: apiKey, httpClient: newHTTPClient()}
+}
+
+// Quote は銘柄の株価を取得
+func (a *AlphaVantage) Quote(ctx context.Context, symbol string) (*Quote, error) {
+	var result struct {
+		GlobalQuote map[string]string `json:"Global Quote"`
+		Note        string            `json:"Note"`
+		Information string            `json:"Information"`
+	}
+	params := url.Values{}
+	params.Set("function", "GLOBAL_QUOTE")
+	params.Set("symbol", symbol)
+	params.Set("apikey", a.apiKey)
+	if err := getJSON(ctx, a.httpClient, "Alpha Vantage", "https://www.alphavantage.co/query?"+params.Encode(), &result); err != nil {
+		return nil, err
+	}
+	if len(result.GlobalQuote) == 0 {
+		// レート制限時はNote/Informationにメッセージが入る
+		if msg := result.Note + result.Information; msg != "" {
+			return nil, fmt.Errorf("Alpha Vantage quote API error: %s", msg)
+		}
+		return nil, fmt.Errorf("no quote for %s", symbol)
+	}
+
+	price, err := strconv.ParseFloat(result.GlobalQuote["05. price"], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Alpha Vantage price for %s: %w", symbol, err)
+	}
+	prev, _ := strconv.ParseFloat(result.GlobalQuote["08. previous close"], 64)
+	q := newQuote(symbol, price, prev)
+	if pct, err := strconv.ParseFloat(strings.TrimSuffix(result.GlobalQuote["10. change percent"], "%"), 64); err == nil {
+		q.ChangePercent = pct
+	}
+	return q, nil
+}
+
+// Finnhub はFinnhubの/quoteを使ったProvider
+type Finnhub struct {
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewFinnhub は新しいFinnhubを作成
+func NewFinnhub(apiKey string) *Finnhub {
+	return &Finnhub{apiKey: apiKey, httpClient: newHTTPClient()}
+}
+
+// Quote は銘柄の株価を取得
+func (f *Finnhub) Quote(ctx context.Context, symbol string) (*Quote, error) {
+	var result struct {
+		Current       float64 `json:"c"`
+		Change        float64 `json:"d"`
+		ChangePercent float64 `json:"dp"`
+		PreviousClose float64 `json:"pc"`
+	}
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("token", f.apiKey)
+	if err := getJSON(ctx, f.httpClient, "Finnhub", "https://finnhub.io/api/v1/quote?"+params.Encode(), &result); err != nil {
+		return nil, err
+	}
+	// 未知の銘柄は全て0で返る
+	if result.Current == 0 {
+		return nil, fmt.Errorf("no quote for %s", symbol)
+	}
+	return &Quote{
+		Symbol:        symbol,
+		Price:         result.Current,
+		Change:        result.Change,
+		ChangePercent: result.ChangePercent,
+	}, nil
+}
+
+// newQuote は現在値と前日終値からQuoteを作成
+func newQuote(symbol string, price, previousClose float64) *Quote {
+	q := &Quote{Symbol: symbol, Price: price}
+	if previousClose > 0 {
+		q.Change = price - previousClose
+		q.ChangePercent = q.Change / previousClose * 100
+	}
+	return q
+}
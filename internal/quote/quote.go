@@ -0,0 +1,120 @@
+package quote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 対応している株価プロバイダー
+const (
+	ProviderYahoo        = "yahoo"
+	ProviderAlphaVantage = "alphavantage"
+	ProviderFinnhub      = "finnhub"
+)
+
+// Quote は銘柄の現在値と当日の騰落
+type Quote struct {
+	Symbol        string
+	Price         float64
+	Change        float64 // 前日終値からの変化
+	ChangePercent float64 // 前日終値からの変化率 (%)
+}
+
+// Provider は銘柄の株価を取得する
+type Provider interface {
+	Quote(ctx context.Context, symbol string) (*Quote, error)
+}
+
+// Cache は取得した株価を一定時間キャッシュするProvider
+// 同じ銘柄の通知が続いた場合にAPIの呼び出しを抑える
+type Cache struct {
+	provider Provider
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	quote     *Quote
+	fetchedAt time.Time
+}
+
+// NewCache は新しいCacheを作成
+func NewCache(provider Provider, ttl time.Duration) *Cache {
+	return &Cache{
+		provider: provider,
+		ttl:      ttl,
+		entries:  make(map[string]cacheEntry),
+	}
+}
+
+// Quote はキャッシュが有効であればそれを、なければプロバイダーから取得して返す
+func (c *Cache) Quote(ctx context.Context, symbol string) (*Quote, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[symbol]; ok && time.Since(e.fetchedAt) < c.ttl {
+		c.mu.Unlock()
+		return e.quote, nil
+	}
+	c.mu.Unlock()
+
+	q, err := c.provider.Quote(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for s, e := range c.entries {
+		if now.Sub(e.fetchedAt) >= c.ttl {
+			delete(c.entries, s)
+		}
+	}
+	c.entries[symbol] = cacheEntry{quote: q, fetchedAt: now}
+	return q, nil
+}
+
+// Fetch は複数銘柄の株価を取得する（取得できなかった銘柄は含まない）
+func Fetch(ctx context.Context, provider Provider, symbols []string) (map[string]*Quote, []error) {
+	quotes := make(map[string]*Quote, len(symbols))
+	var errs []error
+	for _, s := range symbols {
+		q, err := provider.Quote(ctx, s)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s, err))
+			continue
+		}
+		quotes[s] = q
+	}
+	return quotes, errs
+}
+
+// getJSON はGETリクエストの結果をvにデコード
+func getJSON(ctx context.Context, client *http.Client, name, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; x-crawler)")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s quote API error (status %d): %s", name, resp.StatusCode, string(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s quote response: %w", name, err)
+	}
+	return nil
+}
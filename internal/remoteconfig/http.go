@@ -0,0 +1,137 @@
+package remoteconfig
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Minatonton/x-crawler/internal/storage"
+)
+
+// httpObject はHTTP(S)のURLの設定ファイル
+type httpObject struct {
+	httpClient  *http.Client
+	url         string
+	token       string
+	conditional bool   // 前回のETagで条件付きリクエストにする
+	etag        string // 前回のETag
+}
+
+func newHTTPObject(rawURL, token string, conditional bool) *httpObject {
+	return &httpObject{httpClient: &http.Client{Timeout: fetchTimeout}, url: rawURL, token: token, conditional: conditional}
+}
+
+// String はURLを返す
+func (o *httpObject) String() string {
+	return o.url
+}
+
+// Get は内容とETagを返す（前回から変わっていない場合はerrNotModified）
+func (o *httpObject) Get(ctx context.Context) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if o.token != "" {
+		req.Header.Set("Authorization", "Bearer "+o.token)
+	}
+	if o.conditional && o.etag != "" {
+		req.Header.Set("If-None-Match", o.etag)
+	}
+	body, etag, err := doGet(o.httpClient, req, o.url)
+	if err != nil {
+		return nil, "", err
+	}
+	if o.conditional {
+		o.etag = etag
+	}
+	return body, etag, nil
+}
+
+// consulObject はConsulのKVのキーの設定ファイル
+// consul://host:8500/path/to/key（ホストを省略した場合は CONSUL_HTTP_ADDR、なければ 127.0.0.1:8500）
+// ACLトークンは CONSUL_HTTP_TOKEN
+type consulObject struct {
+	httpClient *http.Client
+	addr       string
+	key        string
+	token      string
+}
+
+func newConsulObject(u *url.URL) (*consulObject, error) {
+	key := strings.TrimPrefix(u.Path, "/")
+	if key == "" {
+		return nil, fmt.Errorf("invalid config URL %q (expected consul://host:8500/path/to/key)", u.String())
+	}
+	addr := u.Host
+	if addr == "" {
+		addr = os.Getenv("CONSUL_HTTP_ADDR")
+	}
+	if addr == "" {
+		addr = "127.0.0.1:8500"
+	}
+	if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+		if os.Getenv("CONSUL_HTTP_SSL") == "true" {
+			addr = "https://" + addr
+		} else {
+			addr = "http://" + addr
+		}
+	}
+	return &consulObject{
+		httpClient: &http.Client{Timeout: fetchTimeout},
+		addr:       strings.TrimSuffix(addr, "/"),
+		key:        key,
+		token:      os.Getenv("CONSUL_HTTP_TOKEN"),
+	}, nil
+}
+
+// String はキーのURLを返す
+func (o *consulObject) String() string {
+	return "consul://" + strings.TrimPrefix(strings.TrimPrefix(o.addr, "http://"), "https://") + "/" + o.key
+}
+
+// Get は値とインデックス (X-Consul-Index) を返す
+func (o *consulObject) Get(ctx context.Context) ([]byte, string, error) {
+	reqURL := o.addr + "/v1/kv/" + o.key + "?raw"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if o.token != "" {
+		req.Header.Set("X-Consul-Token", o.token)
+	}
+	return doGet(o.httpClient, req, o.String())
+}
+
+// doGet はリクエストを送り、内容とETagを返す
+func doGet(httpClient *http.Client, req *http.Request, name string) ([]byte, string, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return nil, "", errNotModified
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, "", storage.ErrObjectNotFound
+	case resp.StatusCode != http.StatusOK:
+		s := strings.TrimSpace(string(body))
+		if len(s) > 200 {
+			s = s[:200] + "..."
+		}
+		return nil, "", fmt.Errorf("failed to fetch %s: status %d: %s", name, resp.StatusCode, s)
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		return body, etag, nil
+	}
+	return body, resp.Header.Get("X-Consul-Index"), nil
+}
@@ -0,0 +1,245 @@
+package remoteconfig
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/storage"
+)
+
+// fetchTimeout は1回の取得のタイムアウト
+const fetchTimeout = 30 * time.Second
+
+// errNotModified は前回の取得から内容が変わっていないことを表す（HTTPの304）
+var errNotModified = errors.New("not modified")
+
+// object は取得元の1つのファイル（S3/GCSのオブジェクト、HTTPのURL、ConsulのKV）
+type object interface {
+	// Get は内容とバージョン（ETagなど）を返す
+	Get(ctx context.Context) ([]byte, string, error)
+	// String はログに表示するURL
+	String() string
+}
+
+// Source はリモートの設定ファイルを取得してローカルのキャッシュファイルに保存する
+// Loadやinclude・形式の判定はキャッシュファイルに対して行う
+type Source struct {
+	url    string
+	object object
+	sig    object // 署名の取得元（<URL>.sig、署名を検証しない場合はnil）
+	secret []byte
+	token  string
+	path   string   // キャッシュファイル
+	hash   [32]byte // キャッシュファイルの内容のハッシュ
+}
+
+// Option はSourceの任意設定
+type Option func(*Source)
+
+// WithSecret は取得した設定ファイルの署名（<URL>.sig に置いたHMAC-SHA256の16進数）を検証する
+func WithSecret(secret string) Option {
+	return func(s *Source) {
+		s.secret = []byte(secret)
+	}
+}
+
+// WithToken はHTTP(S)の取得元にBearerトークンを送る
+func WithToken(token string) Option {
+	return func(s *Source) {
+		s.token = token
+	}
+}
+
+// IsRemote は設定ファイルのパスがリモートのURL（http, https, s3, gs, consul）かを返す
+func IsRemote(p string) bool {
+	for _, scheme := range []string{"http://", "https://", "s3://", "gs://", "consul://"} {
+		if strings.HasPrefix(p, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultCachePath はキャッシュファイルの既定のパス（URLごとに別のファイル、形式の判定のためURLの拡張子を引き継ぐ）
+func DefaultCachePath(rawURL string) string {
+	ext := ".yaml"
+	if u, err := url.Parse(rawURL); err == nil {
+		switch e := strings.ToLower(path.Ext(u.Path)); e {
+		case ".yml", ".toml", ".json":
+			ext = e
+		}
+	}
+	sum := sha256.Sum256([]byte(rawURL))
+	return fmt.Sprintf("config.remote-%x%s", sum[:4], ext)
+}
+
+// New は取得元を作成（cachePathが空の場合はDefaultCachePath）
+// 前回のキャッシュファイルがあれば、その内容と同じ場合は書き込まない
+func New(rawURL, cachePath string, opts ...Option) (*Source, error) {
+	if cachePath == "" {
+		cachePath = DefaultCachePath(rawURL)
+	}
+	s := &Source{url: rawURL, path: cachePath}
+	for _, opt := range opts {
+		opt(s)
+	}
+	var err error
+	if s.object, err = s.open(rawURL, true); err != nil {
+		return nil, err
+	}
+	if len(s.secret) > 0 {
+		if s.sig, err = s.open(signatureURL(rawURL), false); err != nil {
+			return nil, err
+		}
+	}
+	if data, err := os.ReadFile(cachePath); err == nil {
+		s.hash = sha256.Sum256(data)
+	}
+	return s, nil
+}
+
+// open はURLの取得元を開く（conditionalの場合、HTTPは前回のETagで条件付きリクエストにする）
+func (s *Source) open(rawURL string, conditional bool) (object, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return newHTTPObject(rawURL, s.token, conditional), nil
+	case "s3", "gs":
+		return storage.OpenObjectStore(rawURL, "", "")
+	case "consul":
+		return newConsulObject(u)
+	default:
+		return nil, fmt.Errorf("unsupported config URL scheme %q (expected http, https, s3, gs or consul)", u.Scheme)
+	}
+}
+
+// signatureURL は署名の取得元（パスの末尾に .sig を付けたURL）を返す
+func signatureURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL + ".sig"
+	}
+	u.Path += ".sig"
+	u.RawPath = ""
+	return u.String()
+}
+
+// Path はキャッシュファイルのパスを返す
+func (s *Source) Path() string {
+	return s.path
+}
+
+// String は取得元のURLを返す
+func (s *Source) String() string {
+	return s.url
+}
+
+// Fetch は設定ファイルを取得し、内容が変わっていればキャッシュファイルに書き込んでtrueを返す
+// 署名を検証する場合、署名が一致しない内容は書き込まずにエラーを返す
+func (s *Source) Fetch(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	data, _, err := s.object.Get(ctx)
+	if errors.Is(err, errNotModified) {
+		return false, nil
+	}
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return false, fmt.Errorf("%s not found", s.object)
+	}
+	if err != nil {
+		return false, err
+	}
+	if s.sig != nil {
+		if err := s.verify(ctx, data); err != nil {
+			return false, err
+		}
+	}
+
+	hash := sha256.Sum256(data)
+	if hash == s.hash {
+		if _, err := os.Stat(s.path); err == nil {
+			return false, nil
+		}
+	}
+	if err := writeFile(s.path, data); err != nil {
+		return false, fmt.Errorf("failed to write config cache %s: %w", s.path, err)
+	}
+	s.hash = hash
+	return true, nil
+}
+
+// verify は設定ファイルの署名（HMAC-SHA256の16進数、"sha256=" の接頭辞は省略可）を検証する
+func (s *Source) verify(ctx context.Context, data []byte) error {
+	sig, _, err := s.sig.Get(ctx)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return fmt.Errorf("signature %s not found", s.sig)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch signature: %w", err)
+	}
+	want, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(sig)), "sha256="))
+	if err != nil {
+		return fmt.Errorf("invalid signature %s: %w", s.sig, err)
+	}
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(data)
+	if !hmac.Equal(mac.Sum(nil), want) {
+		return fmt.Errorf("signature mismatch for %s, refusing to use it", s.object)
+	}
+	return nil
+}
+
+// writeFile は一時ファイルに書き込んでから置き換える（読み込み中に途中の内容が見えないように）
+func writeFile(name string, data []byte) error {
+	if dir := filepath.Dir(name); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// Watch は間隔ごとに設定ファイルを取得し、内容が変わればchに通知する
+func (s *Source) Watch(ctx context.Context, interval time.Duration, ch chan<- struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := s.Fetch(ctx)
+			if err != nil {
+				log.Printf("Failed to refresh config from %s: %v", s.url, err)
+				continue
+			}
+			if !changed {
+				continue
+			}
+			log.Printf("Config at %s changed", s.url)
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/awsauth"
+)
+
+// secretsManager はAWS Secrets Managerのシークレットの値を返す（ARNの場合はARNのリージョン）
+func (r *Resolver) secretsManager(ctx context.Context, id string) (interface{}, error) {
+	region := ""
+	if parts := strings.Split(id, ":"); len(parts) >= 4 && parts[0] == "arn" {
+		region = parts[3]
+	}
+	var out struct {
+		SecretString string `json:"SecretString"`
+		SecretBinary []byte `json:"SecretBinary"`
+	}
+	if err := r.awsCall(ctx, "secretsmanager", "secretsmanager.GetSecretValue", region, map[string]interface{}{"SecretId": id}, &out); err != nil {
+		return nil, err
+	}
+	if out.SecretString == "" && out.SecretBinary != nil {
+		return string(out.SecretBinary), nil
+	}
+	return out.SecretString, nil
+}
+
+// parameterStore はSSM Parameter Storeのパラメータの値を返す
+// 階層のある名前は先頭の / を省略できる（ssm://x-crawler/key は /x-crawler/key）
+func (r *Resolver) parameterStore(ctx context.Context, name string) (interface{}, error) {
+	if strings.Contains(name, "/") && !strings.HasPrefix(name, "/") {
+		name = "/" + name
+	}
+	var out struct {
+		Parameter struct {
+			Value string `json:"Value"`
+		} `json:"Parameter"`
+	}
+	if err := r.awsCall(ctx, "ssm", "AmazonSSM.GetParameter", "", map[string]interface{}{"Name": name, "WithDecryption": true}, &out); err != nil {
+		return nil, err
+	}
+	return out.Parameter.Value, nil
+}
+
+// awsCall はAWSのJSON API (Secrets Manager, SSM) を呼び出す
+// リージョンは指定がなければ環境変数 (AWS_REGION, AWS_DEFAULT_REGION)
+func (r *Resolver) awsCall(ctx context.Context, service, target, region string, input, output interface{}) error {
+	for _, v := range []string{region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION")} {
+		if v != "" {
+			region = v
+			break
+		}
+	}
+	if region == "" {
+		return fmt.Errorf("AWS region is not set (set AWS_REGION)")
+	}
+	creds, err := r.creds.Get(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", r.awsEndpoint(service, region), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+	awsauth.SignV4(req, body, creds, region, service, time.Now())
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Type         string `json:"__type"`
+			Message      string `json:"message"`
+			MessageUpper string `json:"Message"`
+		}
+		if json.Unmarshal(data, &e) == nil && e.Type != "" {
+			code := e.Type[strings.LastIndex(e.Type, "#")+1:]
+			msg := e.Message
+			if msg == "" {
+				msg = e.MessageUpper
+			}
+			return fmt.Errorf("%s failed: %s: %s", target, code, msg)
+		}
+		return fmt.Errorf("%s returned status %d", target, resp.StatusCode)
+	}
+	if err := json.Unmarshal(data, output); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", target, err)
+	}
+	return nil
+}
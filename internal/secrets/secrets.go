@@ -0,0 +1,171 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/awsauth"
+)
+
+// 参照の形式（値全体が参照の場合のみ置き換える）
+//
+//	vault://<パス>#<フィールド>          Vault (VAULT_ADDR, VAULT_TOKEN)、KV v1/v2
+//	awssm://<シークレット名またはARN>#<キー>  AWS Secrets Manager（#<キー> はJSONのシークレットのキー、省略時は値全体）
+//	ssm://<パラメータ名>#<キー>              AWS Systems Manager Parameter Store（SecureStringは復号する）
+var schemes = []string{"vault", "awssm", "ssm"}
+
+// reference はシークレットの参照
+type reference struct {
+	scheme string
+	id     string // Vaultのパス、シークレット名、パラメータ名
+	field  string // 取り出すフィールド（省略可）
+}
+
+// String は参照を返す
+func (r reference) String() string {
+	s := r.scheme + "://" + r.id
+	if r.field != "" {
+		s += "#" + r.field
+	}
+	return s
+}
+
+// parseReference は値がシークレットの参照かを判定して分解する
+func parseReference(s string) (reference, bool) {
+	for _, scheme := range schemes {
+		rest, ok := strings.CutPrefix(s, scheme+"://")
+		if !ok {
+			continue
+		}
+		id, field, _ := strings.Cut(rest, "#")
+		return reference{scheme: scheme, id: id, field: field}, true
+	}
+	return reference{}, false
+}
+
+// IsReference は値がシークレットの参照 (vault://, awssm://, ssm://) かを返す
+func IsReference(s string) bool {
+	_, ok := parseReference(s)
+	return ok
+}
+
+// Resolver はシークレットの参照を取得した値に置き換える
+// 同じシークレットの複数のフィールドを参照する場合、取得は1回にする
+type Resolver struct {
+	httpClient  *http.Client
+	creds       *awsauth.CredentialChain
+	awsEndpoint func(service, region string) string
+
+	cache map[string]interface{} // scheme://id → 取得した値（文字列またはフィールドのマップ）
+}
+
+// NewResolver は新しいResolverを作成
+func NewResolver() *Resolver {
+	return &Resolver{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		creds:      awsauth.NewCredentialChain(),
+		awsEndpoint: func(service, region string) string {
+			return fmt.Sprintf("https://%s.%s.amazonaws.com/", service, region)
+		},
+		cache: make(map[string]interface{}),
+	}
+}
+
+// Resolve は値がシークレットの参照の場合は取得した値を、そうでない場合はそのまま返す
+func (r *Resolver) Resolve(ctx context.Context, s string) (string, error) {
+	ref, ok := parseReference(s)
+	if !ok {
+		return s, nil
+	}
+	if ref.id == "" {
+		return "", fmt.Errorf("invalid secret reference %q", s)
+	}
+	key := ref.scheme + "://" + ref.id
+	value, ok := r.cache[key]
+	if !ok {
+		var err error
+		switch ref.scheme {
+		case "vault":
+			value, err = r.vault(ctx, ref.id)
+		case "awssm":
+			value, err = r.secretsManager(ctx, ref.id)
+		case "ssm":
+			value, err = r.parameterStore(ctx, ref.id)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve secret %s: %w", ref, err)
+		}
+		r.cache[key] = value
+	}
+	v, err := selectField(value, ref.field)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %s: %w", ref, err)
+	}
+	return v, nil
+}
+
+// ResolveEnv は値がシークレットの参照の環境変数を取得した値に置き換え、置き換えた環境変数名を返す
+func (r *Resolver) ResolveEnv(ctx context.Context) ([]string, error) {
+	var names []string
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if !IsReference(value) {
+			continue
+		}
+		resolved, err := r.Resolve(ctx, value)
+		if err != nil {
+			return names, fmt.Errorf("%s: %w", name, err)
+		}
+		os.Setenv(name, resolved)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// selectField は取得した値からフィールドを取り出す
+// 文字列の値はフィールドを指定した場合のみJSONのオブジェクトとして解析する
+func selectField(value interface{}, field string) (string, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if field == "" {
+			if len(v) == 1 {
+				for _, x := range v {
+					return stringValue(x), nil
+				}
+			}
+			return "", fmt.Errorf("secret has %d fields, select one with #<field>", len(v))
+		}
+		x, ok := v[field]
+		if !ok {
+			return "", fmt.Errorf("field %q not found", field)
+		}
+		return stringValue(x), nil
+	case string:
+		if field == "" {
+			return v, nil
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(v), &m); err != nil {
+			return "", fmt.Errorf("secret is not a JSON object, cannot select field %q", field)
+		}
+		return selectField(m, field)
+	default:
+		return "", fmt.Errorf("unexpected secret value type %T", value)
+	}
+}
+
+// stringValue はJSONの値を文字列にする（文字列以外はJSONの表記）
+func stringValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// vault はVaultのシークレットのフィールドを返す
+// VAULT_ADDR（未指定時は https://127.0.0.1:8200）、VAULT_TOKEN（未指定時は ~/.vault-token）、VAULT_NAMESPACE を使う
+// KV v2 の場合はパスに data/ を含める（例: secret/data/x-crawler）
+func (r *Resolver) vault(ctx context.Context, path string) (interface{}, error) {
+	addr := strings.TrimSuffix(os.Getenv("VAULT_ADDR"), "/")
+	if addr == "" {
+		addr = "https://127.0.0.1:8200"
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			if data, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+				token = strings.TrimSpace(string(data))
+			}
+		}
+	}
+	if token == "" {
+		return nil, fmt.Errorf("VAULT_TOKEN is not set")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(body, &e) == nil && len(e.Errors) > 0 {
+			return nil, fmt.Errorf("Vault returned status %d: %s", resp.StatusCode, strings.Join(e.Errors, "; "))
+		}
+		return nil, fmt.Errorf("Vault returned status %d", resp.StatusCode)
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("failed to decode Vault response: %w", err)
+	}
+	if secret.Data == nil {
+		return nil, fmt.Errorf("Vault returned no data")
+	}
+	// KV v2 は data.data に値、data.metadata にバージョンなどを返す
+	if data, ok := secret.Data["data"].(map[string]interface{}); ok {
+		if _, ok := secret.Data["metadata"]; ok {
+			return data, nil
+		}
+	}
+	return secret.Data, nil
+}
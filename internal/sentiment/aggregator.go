@@ -0,0 +1,152 @@
+package sentiment
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/storage"
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// dayFormat は集計日の表記
+const dayFormat = "2006-01-02"
+
+// TickerSummary は1日分のティッカー別集計
+type TickerSummary struct {
+	Ticker    string
+	Mentions  int
+	AvgScore  float64
+	Bullish   int
+	Bearish   int
+	Neutral   int
+	TopTweets []storage.SentimentRecord // スコアの高い順
+}
+
+// Report は1日分のセンチメントサマリー
+type Report struct {
+	Day     string
+	Tickers []TickerSummary // 言及数の多い順
+}
+
+// Aggregator は分析結果をティッカー・日付ごとに集計する
+type Aggregator struct {
+	store      *storage.SentimentStore
+	reportAt   time.Duration // 前日分を投稿する時刻（0時からの経過時間）
+	maxTickers int           // サマリーに含める最大銘柄数
+	topTweets  int           // 銘柄ごとに含める上位ツイート数
+	retention  int           // 記録を保持する日数
+}
+
+// NewAggregator は新しいAggregatorを作成
+func NewAggregator(store *storage.SentimentStore, reportAt time.Duration, maxTickers, topTweets, retentionDays int) *Aggregator {
+	return &Aggregator{
+		store:      store,
+		reportAt:   reportAt,
+		maxTickers: maxTickers,
+		topTweets:  topTweets,
+		retention:  retentionDays,
+	}
+}
+
+// Record は銘柄を含む分析結果を記録
+func (a *Aggregator) Record(tweet twitter.Tweet, analysis *ai.Analysis) {
+	if analysis == nil || len(analysis.Tickers) == 0 {
+		return
+	}
+	a.store.Record(storage.SentimentRecord{
+		TweetID:    tweet.ID,
+		Username:   tweet.Username,
+		Text:       tweet.Text,
+		Tickers:    analysis.Tickers,
+		Score:      analysis.Score,
+		Sentiment:  analysis.Sentiment,
+		Summary:    analysis.Summary,
+		AnalyzedAt: time.Now(),
+	})
+}
+
+// Due は投稿すべき前日分のサマリーがあれば返す（投稿後はMarkReportedを呼ぶ）
+func (a *Aggregator) Due(now time.Time) (*Report, bool) {
+	today := startOfDay(now)
+	if now.Sub(today) < a.reportAt {
+		return nil, false
+	}
+	yesterday := today.AddDate(0, 0, -1)
+	day := yesterday.Format(dayFormat)
+	if a.store.LastReport() >= day {
+		return nil, false
+	}
+	return a.Summarize(yesterday), true
+}
+
+// MarkReported はサマリーの投稿を記録し、保持期間を過ぎた記録を削除
+func (a *Aggregator) MarkReported(report *Report) {
+	a.store.SetLastReport(report.Day)
+	if a.retention > 0 {
+		a.store.Prune(startOfDay(time.Now()).AddDate(0, 0, -a.retention))
+	}
+}
+
+// Summarize は指定日の分析結果をティッカーごとに集計
+func (a *Aggregator) Summarize(day time.Time) *Report {
+	from := startOfDay(day)
+	records := a.store.Between(from, from.AddDate(0, 0, 1))
+
+	byTicker := make(map[string]*TickerSummary)
+	totals := make(map[string]int)
+	for _, rec := range records {
+		for _, ticker := range rec.Tickers {
+			s, ok := byTicker[ticker]
+			if !ok {
+				s = &TickerSummary{Ticker: ticker}
+				byTicker[ticker] = s
+			}
+			s.Mentions++
+			totals[ticker] += rec.Score
+			switch rec.Sentiment {
+			case "bullish":
+				s.Bullish++
+			case "bearish":
+				s.Bearish++
+			default:
+				s.Neutral++
+			}
+			s.TopTweets = append(s.TopTweets, rec)
+		}
+	}
+
+	report := &Report{Day: from.Format(dayFormat)}
+	for ticker, s := range byTicker {
+		s.AvgScore = float64(totals[ticker]) / float64(s.Mentions)
+		sort.Slice(s.TopTweets, func(i, j int) bool {
+			return s.TopTweets[i].Score > s.TopTweets[j].Score
+		})
+		if len(s.TopTweets) > a.topTweets {
+			s.TopTweets = s.TopTweets[:a.topTweets]
+		}
+		report.Tickers = append(report.Tickers, *s)
+	}
+	sort.Slice(report.Tickers, func(i, j int) bool {
+		ti, tj := report.Tickers[i], report.Tickers[j]
+		if ti.Mentions != tj.Mentions {
+			return ti.Mentions > tj.Mentions
+		}
+		return ti.AvgScore > tj.AvgScore
+	})
+	if a.maxTickers > 0 && len(report.Tickers) > a.maxTickers {
+		report.Tickers = report.Tickers[:a.maxTickers]
+	}
+	return report
+}
+
+// Save は集計データを保存
+func (a *Aggregator) Save() error {
+	return a.store.Save()
+}
+
+// startOfDay はローカル時刻での日付の始まりを返す
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
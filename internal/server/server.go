@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Server はフィードバックAPIなどを提供する組み込みHTTPサーバー
+type Server struct {
+	mux *http.ServeMux
+	srv *http.Server
+}
+
+// New は新しいServerを作成
+func New(addr string) *Server {
+	mux := http.NewServeMux()
+	return &Server{
+		mux: mux,
+		srv: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// Handle はハンドラーを登録
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Start はバックグラウンドでサーバーを起動
+func (s *Server) Start() {
+	go func() {
+		log.Printf("HTTP server listening on %s", s.srv.Addr)
+		if err := s.srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("HTTP server error: %v", err)
+		}
+	}()
+}
+
+// Shutdown はサーバーを停止
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
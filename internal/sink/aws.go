@@ -0,0 +1,233 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/awsauth"
+)
+
+// awsRegion は設定・ARNやURLに含まれるリージョン・環境変数 (AWS_REGION, AWS_DEFAULT_REGION) の順にリージョンを決める
+func awsRegion(configured, fromResource string) string {
+	for _, r := range []string{configured, fromResource, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION")} {
+		if r != "" {
+			return r
+		}
+	}
+	return ""
+}
+
+// awsQueryClient はAWSのQuery API (SNS, SQS) を呼び出す
+type awsQueryClient struct {
+	service    string // 署名に使うサービス名 (sns, sqs)
+	region     string
+	version    string // APIのバージョン
+	creds      *awsauth.CredentialChain
+	httpClient *http.Client
+}
+
+// awsError はQuery APIのエラーレスポンス
+type awsError struct {
+	Error struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	} `xml:"Error"`
+}
+
+// call はendpointにActionを送る
+func (c *awsQueryClient) call(ctx context.Context, endpoint, action string, params url.Values) error {
+	creds, err := c.creds.Get(ctx)
+	if err != nil {
+		return err
+	}
+	params.Set("Action", action)
+	params.Set("Version", c.version)
+	body := []byte(params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	awsauth.SignV4(req, body, creds, c.region, c.service, time.Now())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		var e awsError
+		if xml.Unmarshal(data, &e) == nil && e.Error.Code != "" {
+			return fmt.Errorf("%s %s failed: %s: %s", strings.ToUpper(c.service), action, e.Error.Code, e.Error.Message)
+		}
+		return fmt.Errorf("%s %s returned status %d", strings.ToUpper(c.service), action, resp.StatusCode)
+	}
+	return nil
+}
+
+// eventAttributes は購読側のフィルターポリシーなどに使うメッセージ属性
+func eventAttributes(e Event) map[string]string {
+	attrs := map[string]string{"decision": e.Decision, "username": e.Tweet.Username}
+	if a := e.Analysis; a != nil {
+		if a.Urgency != "" {
+			attrs["urgency"] = a.Urgency
+		}
+		if a.Category != "" {
+			attrs["category"] = a.Category
+		}
+		if len(a.Tickers) > 0 {
+			attrs["ticker"] = a.Tickers[0]
+		}
+	}
+	return attrs
+}
+
+// setAttributes はメッセージ属性をQuery APIのパラメーターに展開
+// prefixはSNSでは "MessageAttributes.entry"、SQSでは "MessageAttribute"
+func setAttributes(params url.Values, prefix string, attrs map[string]string) {
+	i := 0
+	for _, name := range []string{"decision", "urgency", "category", "ticker", "username"} {
+		v, ok := attrs[name]
+		if !ok || v == "" {
+			continue
+		}
+		i++
+		p := fmt.Sprintf("%s.%d.", prefix, i)
+		params.Set(p+"Name", name)
+		params.Set(p+"Value.DataType", "String")
+		params.Set(p+"Value.StringValue", v)
+	}
+}
+
+// SNS は通知をJSON (Record) でAmazon SNSのトピックに発行するSink
+// メッセージ属性 (decision, urgency, category, ticker, username) で購読側がフィルターできる
+type SNS struct {
+	topicARN string
+	endpoint string
+	filter   Filter
+	client   *awsQueryClient
+}
+
+// NewSNS は新しいSNSを作成（regionが空の場合はトピックのARNから決める）
+func NewSNS(topicARN, region string, filter Filter) (*SNS, error) {
+	parts := strings.Split(topicARN, ":")
+	if len(parts) < 6 || parts[0] != "arn" || parts[2] != "sns" {
+		return nil, fmt.Errorf("invalid SNS topic ARN: %s", topicARN)
+	}
+	region = awsRegion(region, parts[3])
+	return &SNS{
+		topicARN: topicARN,
+		endpoint: fmt.Sprintf("https://sns.%s.amazonaws.com/", region),
+		filter:   filter,
+		client:   newAWSQueryClient("sns", region, "2010-03-31"),
+	}, nil
+}
+
+// Name はログ用の名前
+func (s *SNS) Name() string {
+	return "sns"
+}
+
+// Send は配信条件を満たす通知をトピックに発行する
+func (s *SNS) Send(ctx context.Context, event Event) error {
+	if !s.filter.Matches(event) {
+		return nil
+	}
+	record := NewRecord(event)
+	message, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	params := url.Values{}
+	params.Set("TopicArn", s.topicARN)
+	params.Set("Message", string(message))
+	params.Set("Subject", truncate(title(event), 90))
+	if strings.HasSuffix(s.topicARN, ".fifo") {
+		params.Set("MessageGroupId", event.Tweet.Username)
+		params.Set("MessageDeduplicationId", record.ID)
+	}
+	setAttributes(params, "MessageAttributes.entry", eventAttributes(event))
+	return s.client.call(ctx, s.endpoint, "Publish", params)
+}
+
+// SQS は通知をJSON (Record) でAmazon SQSのキューに送るSink
+type SQS struct {
+	queueURL string
+	filter   Filter
+	client   *awsQueryClient
+}
+
+// NewSQS は新しいSQSを作成（regionが空の場合はキューのURLから決める）
+func NewSQS(queueURL, region string, filter Filter) (*SQS, error) {
+	u, err := url.Parse(queueURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid SQS queue URL: %s", queueURL)
+	}
+	// sqs.<region>.amazonaws.com または <region>.queue.amazonaws.com
+	var fromURL string
+	if labels := strings.Split(u.Hostname(), "."); len(labels) >= 3 {
+		if labels[0] == "sqs" {
+			fromURL = labels[1]
+		} else if labels[1] == "queue" {
+			fromURL = labels[0]
+		}
+	}
+	region = awsRegion(region, fromURL)
+	if region == "" {
+		return nil, fmt.Errorf("AWS region is required for SQS queue %s", queueURL)
+	}
+	return &SQS{
+		queueURL: queueURL,
+		filter:   filter,
+		client:   newAWSQueryClient("sqs", region, "2012-11-05"),
+	}, nil
+}
+
+// Name はログ用の名前
+func (s *SQS) Name() string {
+	return "sqs"
+}
+
+// Send は配信条件を満たす通知をキューに送る
+func (s *SQS) Send(ctx context.Context, event Event) error {
+	if !s.filter.Matches(event) {
+		return nil
+	}
+	record := NewRecord(event)
+	message, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	params := url.Values{}
+	params.Set("QueueUrl", s.queueURL)
+	params.Set("MessageBody", string(message))
+	if strings.HasSuffix(s.queueURL, ".fifo") {
+		params.Set("MessageGroupId", event.Tweet.Username)
+		params.Set("MessageDeduplicationId", record.ID)
+	}
+	setAttributes(params, "MessageAttribute", eventAttributes(event))
+	return s.client.call(ctx, s.queueURL, "SendMessage", params)
+}
+
+// newAWSQueryClient は標準の認証情報チェーンを使うawsQueryClientを作成
+func newAWSQueryClient(service, region, version string) *awsQueryClient {
+	return &awsQueryClient{
+		service:    service,
+		region:     region,
+		version:    version,
+		creds:      awsauth.NewCredentialChain(),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
@@ -0,0 +1,95 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// desktopMaxBody はデスクトップ通知の本文の最大文字数
+const desktopMaxBody = 200
+
+// Desktop は通知をOSのデスクトップ通知で表示するSink
+// macOSはosascript、Linuxはnotify-send (libnotify)、WindowsはPowerShellのバルーン通知を使う
+type Desktop struct {
+	filter Filter
+	run    func(ctx context.Context, name string, args ...string) error
+}
+
+// NewDesktop は新しいDesktopを作成（対応していないOSの場合はエラー）
+func NewDesktop(filter Filter) (*Desktop, error) {
+	name, _ := desktopCommand("", "", "")
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("desktop notifications are not available on %s: %w", runtime.GOOS, err)
+	}
+	return &Desktop{
+		filter: filter,
+		run: func(ctx context.Context, name string, args ...string) error {
+			out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+			if err != nil {
+				return fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(string(out)))
+			}
+			return nil
+		},
+	}, nil
+}
+
+// Name はログ用の名前
+func (d *Desktop) Name() string {
+	return "desktop"
+}
+
+// Send は配信条件を満たす通知をデスクトップに表示する
+func (d *Desktop) Send(ctx context.Context, event Event) error {
+	if !d.filter.Matches(event) {
+		return nil
+	}
+	body := strings.TrimPrefix(plainText(event, desktopMaxBody), title(event)+"\n")
+	urgency := ""
+	if event.Analysis != nil {
+		urgency = event.Analysis.Urgency
+	}
+	name, args := desktopCommand(title(event), body, urgency)
+	return d.run(ctx, name, args...)
+}
+
+// desktopCommand はOSごとの通知コマンドと引数を返す
+func desktopCommand(title, body, urgency string) (string, []string) {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(title))
+		if urgency == "critical" {
+			script += ` sound name "Glass"`
+		}
+		return "osascript", []string{"-e", script}
+	case "windows":
+		script := "Add-Type -AssemblyName System.Windows.Forms; " +
+			"$n = New-Object System.Windows.Forms.NotifyIcon; " +
+			"$n.Icon = [System.Drawing.SystemIcons]::Information; $n.Visible = $true; " +
+			fmt.Sprintf("$n.ShowBalloonTip(10000, %s, %s, 'Info'); Start-Sleep -Seconds 10; $n.Dispose()",
+				powerShellString(title), powerShellString(body))
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}
+	default:
+		level := "normal"
+		switch urgency {
+		case "critical":
+			level = "critical"
+		case "low":
+			level = "low"
+		}
+		return "notify-send", []string{"--app-name=x-crawler", "--urgency=" + level, title, body}
+	}
+}
+
+// appleScriptString はAppleScriptの文字列リテラルを返す
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// powerShellString はPowerShellの単一引用符の文字列リテラルを返す
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
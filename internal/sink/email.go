@@ -0,0 +1,316 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultEmailTemplate は通知メールの組み込みのHTMLテンプレート
+const defaultEmailTemplate = `<html><body style="font-family:sans-serif">
+<p><b>@{{.Tweet.Username}}</b>{{with .SourceInfo}} ({{.}}){{end}}</p>
+<blockquote style="border-left:4px solid #ccc;margin:0;padding-left:8px;white-space:pre-wrap">{{.Tweet.Text}}</blockquote>
+{{with .Analysis}}<table cellpadding="4">
+<tr><th align="left">スコア</th><td>{{.Score}}/100</td></tr>
+<tr><th align="left">カテゴリ</th><td>{{.Category}}</td></tr>
+<tr><th align="left">緊急度</th><td>{{.Urgency}}</td></tr>
+<tr><th align="left">センチメント</th><td>{{.Sentiment}}</td></tr>
+{{if .Tickers}}<tr><th align="left">関連銘柄</th><td>{{range $i, $t := .Tickers}}{{if $i}}, {{end}}${{$t}}{{end}}</td></tr>{{end}}
+<tr><th align="left">要約</th><td>{{.Summary}}</td></tr>
+</table>
+{{if .KeyPoints}}<ul>{{range .KeyPoints}}<li>{{.}}</li>{{end}}</ul>{{end}}{{end}}
+<p><a href="{{.URL}}">ポストを見る</a></p>
+</body></html>`
+
+// defaultDigestTemplate は日次ダイジェストメールの組み込みのHTMLテンプレート
+const defaultDigestTemplate = `<html><body style="font-family:sans-serif">
+<h2>{{.Day}} の通知 {{len .Events}}件</h2>
+<table cellpadding="4" border="1" style="border-collapse:collapse">
+<tr><th>時刻</th><th>投稿者</th><th>スコア</th><th>銘柄</th><th>要約</th></tr>
+{{range .Events}}<tr>
+<td>{{.At.Format "15:04"}}</td>
+<td><a href="{{.URL}}">@{{.Tweet.Username}}</a></td>
+{{with .Analysis}}<td>{{.Score}}</td><td>{{range $i, $t := .Tickers}}{{if $i}}, {{end}}${{$t}}{{end}}</td><td>{{.Summary}}</td>{{else}}<td>-</td><td>-</td><td>{{$.Truncate .Tweet.Text}}</td>{{end}}
+</tr>{{end}}
+</table>
+</body></html>`
+
+// SMTPConfig はメール送信に使うSMTPサーバーの設定
+type SMTPConfig struct {
+	Host     string
+	Port     int // 465の場合は暗黙のTLS、それ以外はサーバーが対応していればSTARTTLS
+	Username string
+	Password string
+	From     string
+}
+
+// Recipient はメールの宛先と配信条件
+type Recipient struct {
+	Address string
+	Filter  Filter
+}
+
+// Email は通知をメールで送るSink（宛先ごとの配信条件と、任意の日次ダイジェスト）
+type Email struct {
+	smtp       SMTPConfig
+	recipients []Recipient
+	template   *template.Template
+
+	digestTo       []string           // 日次ダイジェストの宛先（空の場合は送らない）
+	digestAt       time.Duration      // ダイジェストを送る時刻（0時からの経過時間）
+	digestTemplate *template.Template // ダイジェストのテンプレート
+
+	mu         sync.Mutex
+	events     map[string][]Event // 日付 (YYYY-MM-DD) -> その日の通知
+	lastDigest string             // 最後にダイジェストを送った対象日
+	send       func(to []string, msg []byte) error
+}
+
+// EmailOption はEmailの任意設定
+type EmailOption func(*Email) error
+
+// WithEmailTemplate は通知メールのHTMLテンプレートを指定（Eventが渡される）
+func WithEmailTemplate(text string) EmailOption {
+	return func(e *Email) error {
+		tmpl, err := template.New("email").Parse(text)
+		if err != nil {
+			return fmt.Errorf("failed to parse email template: %w", err)
+		}
+		e.template = tmpl
+		return nil
+	}
+}
+
+// WithEmailDigest は前日の通知をまとめた日次ダイジェストを毎日atにtoへ送る
+// tmplが空の場合は組み込みのテンプレートを使う（Day, Events, Truncate が渡される）
+// ダイジェスト用の通知はメモリ上にのみ保持する（再起動すると失われる）
+func WithEmailDigest(to []string, at time.Duration, tmpl string) EmailOption {
+	return func(e *Email) error {
+		if tmpl == "" {
+			tmpl = defaultDigestTemplate
+		}
+		t, err := template.New("digest").Parse(tmpl)
+		if err != nil {
+			return fmt.Errorf("failed to parse email digest template: %w", err)
+		}
+		e.digestTo = to
+		e.digestAt = at
+		e.digestTemplate = t
+		return nil
+	}
+}
+
+// NewEmail は新しいEmailを作成
+func NewEmail(cfg SMTPConfig, recipients []Recipient, opts ...EmailOption) (*Email, error) {
+	e := &Email{
+		smtp:       cfg,
+		recipients: recipients,
+		template:   template.Must(template.New("email").Parse(defaultEmailTemplate)),
+		events:     make(map[string][]Event),
+	}
+	e.send = e.sendSMTP
+	for _, opt := range opts {
+		if err := opt(e); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// Name はログ用の名前
+func (e *Email) Name() string {
+	return "email"
+}
+
+// Send は配信条件を満たす宛先に通知メールを送り、ダイジェスト用に記録する
+func (e *Email) Send(ctx context.Context, event Event) error {
+	if e.digestTemplate != nil && (Filter{}).Matches(event) {
+		e.mu.Lock()
+		day := event.At.Format("2006-01-02")
+		e.events[day] = append(e.events[day], event)
+		e.mu.Unlock()
+	}
+
+	var to []string
+	for _, r := range e.recipients {
+		if r.Filter.Matches(event) {
+			to = append(to, r.Address)
+		}
+	}
+	if len(to) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	if err := e.template.Execute(&body, event); err != nil {
+		return fmt.Errorf("failed to render email template: %w", err)
+	}
+	subject := subjectFor(event)
+
+	// 宛先同士にアドレスが見えないように1通ずつ送る
+	var errs []string
+	for _, addr := range to {
+		if err := e.send([]string{addr}, e.compose(addr, subject, body.Bytes())); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", addr, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to send email: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Tick はダイジェストの時刻を過ぎていれば前日分のダイジェストを送る
+func (e *Email) Tick(ctx context.Context, now time.Time) {
+	if e.digestTemplate == nil {
+		return
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if now.Sub(today) < e.digestAt {
+		return
+	}
+	day := today.AddDate(0, 0, -1).Format("2006-01-02")
+
+	e.mu.Lock()
+	if e.lastDigest >= day {
+		e.mu.Unlock()
+		return
+	}
+	events := e.events[day]
+	e.mu.Unlock()
+
+	if len(events) > 0 {
+		var body bytes.Buffer
+		if err := e.digestTemplate.Execute(&body, digestData{Day: day, Events: events}); err != nil {
+			log.Printf("Failed to render email digest: %v", err)
+			return
+		}
+		subject := fmt.Sprintf("[x-crawler] %s の通知 %d件", day, len(events))
+		for _, addr := range e.digestTo {
+			if err := e.send([]string{addr}, e.compose(addr, subject, body.Bytes())); err != nil {
+				log.Printf("Failed to send email digest to %s: %v", addr, err)
+				return
+			}
+		}
+		log.Printf("Sent email digest for %s (%d items) to %d recipients", day, len(events), len(e.digestTo))
+	}
+
+	// 送った日以前の通知は不要
+	e.mu.Lock()
+	e.lastDigest = day
+	for d := range e.events {
+		if d <= day {
+			delete(e.events, d)
+		}
+	}
+	e.mu.Unlock()
+}
+
+// compose はHTMLメールのメッセージを作成
+func (e *Email) compose(to, subject string, body []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", e.smtp.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", randomID(), e.smtp.Host)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	w := quotedprintable.NewWriter(&buf)
+	w.Write(body)
+	w.Close()
+	return buf.Bytes()
+}
+
+// sendSMTP はSMTPサーバーにメールを送信
+func (e *Email) sendSMTP(to []string, msg []byte) error {
+	addr := net.JoinHostPort(e.smtp.Host, strconv.Itoa(e.smtp.Port))
+	var auth smtp.Auth
+	if e.smtp.Username != "" {
+		auth = smtp.PlainAuth("", e.smtp.Username, e.smtp.Password, e.smtp.Host)
+	}
+	if e.smtp.Port != 465 {
+		return smtp.SendMail(addr, auth, e.smtp.From, to, msg)
+	}
+
+	// SMTPS (暗黙のTLS)
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: e.smtp.Host})
+	if err != nil {
+		return err
+	}
+	c, err := smtp.NewClient(conn, e.smtp.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if auth != nil {
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(e.smtp.From); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// digestData はダイジェストメールのテンプレートに渡すデータ
+type digestData struct {
+	Day    string
+	Events []Event
+}
+
+// Truncate は本文を先頭の100文字に切り詰める（AI分析なしの通知の表示用）
+func (digestData) Truncate(s string) string {
+	return truncate(s, 100)
+}
+
+// subjectFor は通知メールの件名
+func subjectFor(event Event) string {
+	a := event.Analysis
+	if a == nil {
+		return fmt.Sprintf("[x-crawler] @%s の新しい投稿", event.Tweet.Username)
+	}
+	subject := fmt.Sprintf("[x-crawler] %s @%s %d/100", strings.ToUpper(a.Urgency), event.Tweet.Username, a.Score)
+	if len(a.Tickers) > 0 {
+		subject += " $" + strings.Join(a.Tickers, " $")
+	}
+	return subject
+}
+
+// randomID はMessage-ID用のランダムな文字列
+func randomID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,281 @@
+package sink
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// FeedAtomPath はAtomフィードのパス
+	FeedAtomPath = "/feed.atom"
+
+	// FeedRSSPath はRSS 2.0フィードのパス
+	FeedRSSPath = "/feed.rss"
+
+	// feedMaxSummary はフィードの項目に含める要約・本文の最大文字数
+	feedMaxSummary = 2000
+)
+
+// Feed は通知を直近maxItems件保持し、組み込みHTTPサーバーからRSS/Atomフィードとして配信するSink
+// 項目はメモリ上にのみ保持する（再起動後は空から始まるが、フィードリーダーは項目のIDで既読を管理する）
+type Feed struct {
+	title    string
+	baseURL  string // 外部から到達可能なURL（自己参照のリンク用、空の場合はリクエストのHostから組み立てる）
+	token    string // 空の場合は認証しない
+	maxItems int
+	filter   Filter
+
+	mu    sync.RWMutex
+	items []Event // 新しい順
+}
+
+// NewFeed は新しいFeedを作成
+func NewFeed(title, baseURL, token string, maxItems int, filter Filter) *Feed {
+	return &Feed{
+		title:    title,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		token:    token,
+		maxItems: maxItems,
+		filter:   filter,
+	}
+}
+
+// Name はログ用の名前
+func (f *Feed) Name() string {
+	return "feed"
+}
+
+// Send は配信条件を満たす通知をフィードの先頭に追加する（同じポストの更新は置き換える）
+func (f *Feed) Send(ctx context.Context, event Event) error {
+	if !f.filter.Matches(event) {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	items := make([]Event, 0, len(f.items)+1)
+	items = append(items, event)
+	for _, item := range f.items {
+		if item.Tweet.ID != event.Tweet.ID {
+			items = append(items, item)
+		}
+	}
+	if len(items) > f.maxItems {
+		items = items[:f.maxItems]
+	}
+	f.items = items
+	return nil
+}
+
+// AtomHandler はAtomフィードのハンドラー
+func (f *Feed) AtomHandler() http.Handler {
+	return f.handler("application/atom+xml; charset=utf-8", f.atom)
+}
+
+// RSSHandler はRSS 2.0フィードのハンドラー
+func (f *Feed) RSSHandler() http.Handler {
+	return f.handler("application/rss+xml; charset=utf-8", f.rss)
+}
+
+// handler はトークンを検証してフィードのXMLを返すハンドラーを作成
+// フィードリーダーはヘッダーを設定できないことが多いため、トークンはクエリの ?token= で指定
+func (f *Feed) handler(contentType string, build func(self string, items []Event) interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if f.token != "" && subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(f.token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		base := f.baseURL
+		if base == "" {
+			scheme := "http"
+			if r.TLS != nil {
+				scheme = "https"
+			}
+			base = scheme + "://" + r.Host
+		}
+		self := base + r.URL.Path
+		if f.token != "" {
+			self += "?token=" + f.token
+		}
+
+		f.mu.RLock()
+		items := append([]Event(nil), f.items...)
+		f.mu.RUnlock()
+
+		data, err := xml.MarshalIndent(build(self, items), "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte(xml.Header))
+		w.Write(data)
+	})
+}
+
+// atomFeed はAtom (RFC 4287) のフィード
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Author     atomAuthor     `xml:"author"`
+	Summary    string         `xml:"summary"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// atom はAtomフィードを作成
+func (f *Feed) atom(self string, items []Event) interface{} {
+	updated := time.Now()
+	if len(items) > 0 {
+		updated = items[0].At
+	}
+	feed := atomFeed{
+		Title:   f.title,
+		ID:      strings.SplitN(self, "?", 2)[0],
+		Updated: updated.UTC().Format(time.RFC3339),
+		Link:    atomLink{Href: self, Rel: "self"},
+	}
+	for _, e := range items {
+		entry := atomEntry{
+			Title:   title(e),
+			ID:      feedItemID(e),
+			Link:    atomLink{Href: e.URL()},
+			Updated: e.At.UTC().Format(time.RFC3339),
+			Author:  atomAuthor{Name: "@" + e.Tweet.Username, URI: "https://x.com/" + e.Tweet.Username},
+			Summary: feedSummary(e),
+		}
+		if !e.Tweet.CreatedAt.IsZero() {
+			entry.Published = e.Tweet.CreatedAt.UTC().Format(time.RFC3339)
+		}
+		for _, c := range feedCategories(e) {
+			entry.Categories = append(entry.Categories, atomCategory{Term: c})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+// rssFeed はRSS 2.0のフィード
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Description string   `xml:"description"`
+	Categories  []string `xml:"category"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// rss はRSS 2.0フィードを作成
+func (f *Feed) rss(self string, items []Event) interface{} {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         f.title,
+			Link:          self,
+			Description:   f.title,
+			LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+	for _, e := range items {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       title(e),
+			Link:        e.URL(),
+			GUID:        rssGUID{Value: feedItemID(e)},
+			PubDate:     e.At.UTC().Format(time.RFC1123Z),
+			Description: feedSummary(e),
+			Categories:  feedCategories(e),
+		})
+	}
+	return feed
+}
+
+// feedItemID はポストごとに一意な項目のID
+func feedItemID(e Event) string {
+	return "urn:x-crawler:tweet:" + e.Tweet.ID
+}
+
+// feedSummary は項目の本文（銘柄、要約またはポストの本文）
+func feedSummary(e Event) string {
+	body := e.Tweet.Text
+	var tickers string
+	if a := e.Analysis; a != nil {
+		body = a.Summary
+		if len(a.Tickers) > 0 {
+			tickers = "$" + strings.Join(a.Tickers, " $") + "\n"
+		}
+	}
+	return tickers + truncate(strings.TrimSpace(body), feedMaxSummary)
+}
+
+// feedCategories は項目のカテゴリ（分析のカテゴリ、緊急度、銘柄）
+func feedCategories(e Event) []string {
+	a := e.Analysis
+	if a == nil {
+		return nil
+	}
+	var categories []string
+	if a.Category != "" {
+		categories = append(categories, a.Category)
+	}
+	if a.Urgency != "" {
+		categories = append(categories, fmt.Sprintf("urgency:%s", a.Urgency))
+	}
+	for _, t := range a.Tickers {
+		categories = append(categories, "$"+t)
+	}
+	return categories
+}
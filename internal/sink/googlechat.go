@@ -0,0 +1,121 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// googleChatMaxText はカードに含める要約・本文の最大文字数
+const googleChatMaxText = 2000
+
+// GoogleChat は通知をGoogle ChatのIncoming Webhookにカード (Cards v2) で送るSink
+type GoogleChat struct {
+	webhookURL string
+	filter     Filter
+	httpClient *http.Client
+}
+
+// NewGoogleChat は新しいGoogleChatを作成
+func NewGoogleChat(webhookURL string, filter Filter) *GoogleChat {
+	return &GoogleChat{
+		webhookURL: webhookURL,
+		filter:     filter,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name はログ用の名前
+func (g *GoogleChat) Name() string {
+	return "google_chat"
+}
+
+// Send は配信条件を満たす通知をカードで送る
+func (g *GoogleChat) Send(ctx context.Context, event Event) error {
+	if !g.filter.Matches(event) {
+		return nil
+	}
+	body, err := json.Marshal(googleChatMessage(event))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", g.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		json.Unmarshal(data, &apiErr)
+		return fmt.Errorf("Google Chat API returned status %d: %s", resp.StatusCode, apiErr.Error.Message)
+	}
+	return nil
+}
+
+// googleChatMessage はCards v2のメッセージを作成（textは通知のプレビュー用）
+func googleChatMessage(e Event) map[string]interface{} {
+	var widgets []map[string]interface{}
+	subtitle := "@" + e.Tweet.Username
+	body := e.Tweet.Text
+	if a := e.Analysis; a != nil {
+		subtitle = fmt.Sprintf("@%s · %s", e.Tweet.Username, a.Category)
+		widgets = append(widgets, decoratedText("Score", fmt.Sprintf("%d/100 (%s)", a.Score, a.Urgency)))
+		if a.Sentiment != "" {
+			widgets = append(widgets, decoratedText("Sentiment", a.Sentiment))
+		}
+		if len(a.Tickers) > 0 {
+			widgets = append(widgets, decoratedText("Tickers", "$"+strings.Join(a.Tickers, " $")))
+		}
+		body = a.Summary
+	}
+	if body = truncate(strings.TrimSpace(body), googleChatMaxText); body != "" {
+		widgets = append(widgets, map[string]interface{}{
+			"textParagraph": map[string]string{"text": html.EscapeString(body)},
+		})
+	}
+	widgets = append(widgets, map[string]interface{}{
+		"buttonList": map[string]interface{}{
+			"buttons": []map[string]interface{}{{
+				"text":    "View post",
+				"onClick": map[string]interface{}{"openLink": map[string]string{"url": e.URL()}},
+			}},
+		},
+	})
+
+	return map[string]interface{}{
+		"text": title(e),
+		"cardsV2": []map[string]interface{}{{
+			"cardId": "tweet-" + e.Tweet.ID,
+			"card": map[string]interface{}{
+				"header":   map[string]string{"title": title(e), "subtitle": subtitle},
+				"sections": []map[string]interface{}{{"widgets": widgets}},
+			},
+		}},
+	}
+}
+
+// decoratedText はラベル付きのテキストのウィジェット
+func decoratedText(label, text string) map[string]interface{} {
+	return map[string]interface{}{
+		"decoratedText": map[string]string{"topLabel": label, "text": html.EscapeString(text)},
+	}
+}
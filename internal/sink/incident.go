@@ -0,0 +1,225 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// pagerDutyEventsURL はPagerDuty Events API v2のエンドポイント
+	pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+	// OpsgenieDefaultURL はOpsgenie Alert APIの既定のベースURL（EUリージョンは https://api.eu.opsgenie.com）
+	OpsgenieDefaultURL = "https://api.opsgenie.com"
+
+	// incidentMaxSummary はアラートの件名の最大文字数（Opsgenieのmessageの上限）
+	incidentMaxSummary = 130
+)
+
+// pagerDutySeverities は緊急度ごとのPagerDutyの重大度
+var pagerDutySeverities = map[string]string{
+	"critical": "critical",
+	"high":     "error",
+	"normal":   "warning",
+	"low":      "info",
+}
+
+// opsgeniePriorities は緊急度ごとのOpsgenieの優先度
+var opsgeniePriorities = map[string]string{
+	"critical": "P1",
+	"high":     "P2",
+	"normal":   "P3",
+	"low":      "P4",
+}
+
+// PagerDuty は通知をPagerDutyのインシデントとして起票するSink
+type PagerDuty struct {
+	routingKey string // サービスのIntegration Key
+	filter     Filter
+	httpClient *http.Client
+	endpoint   string
+}
+
+// NewPagerDuty は新しいPagerDutyを作成
+func NewPagerDuty(routingKey string, filter Filter) *PagerDuty {
+	return &PagerDuty{
+		routingKey: routingKey,
+		filter:     filter,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		endpoint:   pagerDutyEventsURL,
+	}
+}
+
+// Name はログ用の名前
+func (p *PagerDuty) Name() string {
+	return "pagerduty"
+}
+
+// Send は配信条件を満たす通知でアラートを発生させる（同じポストは1件のインシデントにまとまる）
+func (p *PagerDuty) Send(ctx context.Context, event Event) error {
+	if !p.filter.Matches(event) {
+		return nil
+	}
+	record := NewRecord(event)
+	severity := "warning"
+	if a := event.Analysis; a != nil {
+		if s, ok := pagerDutySeverities[a.Urgency]; ok {
+			severity = s
+		}
+	}
+	payload := map[string]interface{}{
+		"routing_key":  p.routingKey,
+		"event_action": "trigger",
+		"dedup_key":    incidentKey(event),
+		"client":       "x-crawler",
+		"client_url":   event.URL(),
+		"payload": map[string]interface{}{
+			"summary":        truncate(incidentSummary(event), incidentMaxSummary),
+			"source":         "x.com/" + event.Tweet.Username,
+			"severity":       severity,
+			"timestamp":      event.At.UTC().Format(time.RFC3339),
+			"component":      strings.Join(analysisTickers(event), ","),
+			"group":          analysisCategory(event),
+			"class":          event.Decision,
+			"custom_details": record,
+		},
+		"links": []map[string]string{{"href": event.URL(), "text": "View post"}},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return postIncident(ctx, p.httpClient, p.endpoint, nil, body, "PagerDuty")
+}
+
+// Opsgenie は通知をOpsgenieのアラートとして作成するSink
+type Opsgenie struct {
+	apiKey     string
+	tags       []string
+	filter     Filter
+	httpClient *http.Client
+	endpoint   string
+}
+
+// NewOpsgenie は新しいOpsgenieを作成（baseURLが空の場合はOpsgenieDefaultURL）
+func NewOpsgenie(apiKey, baseURL string, tags []string, filter Filter) *Opsgenie {
+	if baseURL == "" {
+		baseURL = OpsgenieDefaultURL
+	}
+	return &Opsgenie{
+		apiKey:     apiKey,
+		tags:       tags,
+		filter:     filter,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		endpoint:   strings.TrimSuffix(baseURL, "/") + "/v2/alerts",
+	}
+}
+
+// Name はログ用の名前
+func (o *Opsgenie) Name() string {
+	return "opsgenie"
+}
+
+// Send は配信条件を満たす通知でアラートを作成する（同じポストはaliasで1件にまとまる）
+func (o *Opsgenie) Send(ctx context.Context, event Event) error {
+	if !o.filter.Matches(event) {
+		return nil
+	}
+	priority := "P3"
+	if a := event.Analysis; a != nil {
+		if p, ok := opsgeniePriorities[a.Urgency]; ok {
+			priority = p
+		}
+	}
+	details := map[string]string{
+		"decision": event.Decision,
+		"username": event.Tweet.Username,
+		"url":      event.URL(),
+		"source":   event.Source,
+	}
+	if a := event.Analysis; a != nil {
+		details["score"] = fmt.Sprint(a.Score)
+		details["category"] = a.Category
+		details["tickers"] = strings.Join(a.Tickers, ",")
+	}
+	tags := append([]string{"x-crawler"}, o.tags...)
+	for _, t := range analysisTickers(event) {
+		tags = append(tags, "$"+t)
+	}
+	payload := map[string]interface{}{
+		"message":     truncate(incidentSummary(event), incidentMaxSummary),
+		"alias":       incidentKey(event),
+		"description": plainText(event, 0),
+		"priority":    priority,
+		"source":      "x-crawler",
+		"entity":      "@" + event.Tweet.Username,
+		"tags":        tags,
+		"details":     details,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{"Authorization": "GenieKey " + o.apiKey}
+	return postIncident(ctx, o.httpClient, o.endpoint, headers, body, "Opsgenie")
+}
+
+// postIncident はアラートをJSONでPOSTし、2xx以外をエラーとして返す
+func postIncident(ctx context.Context, client *http.Client, url string, headers map[string]string, body []byte, service string) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s API returned status %d: %s", service, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// incidentKey はポストごとのアラートの重複排除キー（更新・再通知で同じアラートにまとめる）
+func incidentKey(e Event) string {
+	return "x-crawler:" + e.Tweet.ID
+}
+
+// incidentSummary はアラートの件名（見出しと要約の先頭）
+func incidentSummary(e Event) string {
+	summary := title(e)
+	if a := e.Analysis; a != nil && a.Summary != "" {
+		summary += " " + strings.TrimSpace(a.Summary)
+	}
+	return summary
+}
+
+// analysisTickers はAI分析の銘柄（分析なしの場合はnil）
+func analysisTickers(e Event) []string {
+	if e.Analysis == nil {
+		return nil
+	}
+	return e.Analysis.Tickers
+}
+
+// analysisCategory はAI分析のカテゴリ（分析なしの場合は空）
+func analysisCategory(e Event) string {
+	if e.Analysis == nil {
+		return ""
+	}
+	return e.Analysis.Category
+}
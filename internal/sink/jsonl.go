@@ -0,0 +1,168 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JSONLのローテーション方式
+const (
+	RotateNone  = "none"  // ローテーションしない
+	RotateDaily = "daily" // 日付が変わったらローテーション
+	RotateSize  = "size"  // ファイルサイズが上限を超えたらローテーション
+)
+
+// JSONL は通知をJSON (Record) で1行ずつファイルに追記するSink
+// 書き込み中のファイルは常に同じパスで、ローテーションしたファイルは "名前-日時.jsonl" に改名する
+type JSONL struct {
+	path     string
+	rotate   string
+	maxSize  int64 // RotateSizeの上限（バイト）
+	maxFiles int   // 残すローテーション済みファイルの数（0の場合は全て残す）
+	filter   Filter
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+	day  string // 書き込み中のファイルの日付 (YYYY-MM-DD)
+}
+
+// NewJSONL は新しいJSONLを作成
+func NewJSONL(path, rotate string, maxSize int64, maxFiles int, filter Filter) (*JSONL, error) {
+	switch rotate {
+	case RotateNone, RotateDaily, RotateSize:
+	default:
+		return nil, fmt.Errorf("invalid JSONL rotation %q", rotate)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create archive directory: %w", err)
+		}
+	}
+	return &JSONL{path: path, rotate: rotate, maxSize: maxSize, maxFiles: maxFiles, filter: filter}, nil
+}
+
+// Name はログ用の名前
+func (j *JSONL) Name() string {
+	return "jsonl"
+}
+
+// Send は配信条件を満たす通知を1行追記する
+func (j *JSONL) Send(ctx context.Context, event Event) error {
+	if !j.filter.Matches(event) {
+		return nil
+	}
+	line, err := json.Marshal(NewRecord(event))
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.open(); err != nil {
+		return err
+	}
+	if suffix := j.rotateSuffix(event.At, int64(len(line))); suffix != "" {
+		if err := j.rotateTo(suffix); err != nil {
+			return err
+		}
+		if err := j.open(); err != nil {
+			return err
+		}
+	}
+	n, err := j.f.Write(line)
+	j.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// open は書き込み中のファイルを開く（既に開いている場合は何もしない）
+func (j *JSONL) open() error {
+	if j.f != nil {
+		return nil
+	}
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat archive: %w", err)
+	}
+	j.f = f
+	j.size = info.Size()
+	j.day = info.ModTime().Format("2006-01-02")
+	if j.size == 0 {
+		j.day = time.Now().Format("2006-01-02")
+	}
+	return nil
+}
+
+// rotateSuffix はローテーションが必要な場合にローテーション後のファイル名に付ける日時を返す
+func (j *JSONL) rotateSuffix(now time.Time, next int64) string {
+	if j.size == 0 {
+		return ""
+	}
+	switch j.rotate {
+	case RotateDaily:
+		if day := now.Format("2006-01-02"); day != j.day {
+			return j.day
+		}
+	case RotateSize:
+		if j.maxSize > 0 && j.size+next > j.maxSize {
+			return now.Format("20060102-150405")
+		}
+	}
+	return ""
+}
+
+// rotateTo は書き込み中のファイルを閉じて "名前-suffix.jsonl" に改名し、古いファイルを削除
+func (j *JSONL) rotateTo(suffix string) error {
+	j.f.Close()
+	j.f = nil
+
+	ext := filepath.Ext(j.path)
+	base := strings.TrimSuffix(j.path, ext)
+	rotated := base + "-" + suffix + ext
+	for i := 1; fileExists(rotated); i++ {
+		rotated = fmt.Sprintf("%s-%s.%d%s", base, suffix, i, ext)
+	}
+	if err := os.Rename(j.path, rotated); err != nil {
+		return fmt.Errorf("failed to rotate archive: %w", err)
+	}
+	log.Printf("Rotated archive to %s", rotated)
+
+	if j.maxFiles > 0 {
+		matches, err := filepath.Glob(base + "-*" + ext)
+		if err != nil {
+			return nil
+		}
+		// 名前の日時は辞書順で古い順に並ぶ
+		sort.Strings(matches)
+		for len(matches) > j.maxFiles {
+			if err := os.Remove(matches[0]); err != nil {
+				log.Printf("Failed to remove old archive %s: %v", matches[0], err)
+			}
+			matches = matches[1:]
+		}
+	}
+	return nil
+}
+
+// fileExists はファイルが存在するかを返す
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
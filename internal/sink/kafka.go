@@ -0,0 +1,86 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Kafka は処理結果をJSONイベントとしてKafkaのトピックに送るSink
+// Kafka REST Proxy (v2 API) 経由で送信する
+type Kafka struct {
+	restURL    string // REST ProxyのベースURL
+	topic      string // トピック名のテンプレート（expandTopicのプレースホルダーを使用可能）
+	headers    map[string]string
+	filter     Filter
+	httpClient *http.Client
+}
+
+// NewKafka は新しいKafkaを作成
+func NewKafka(restURL, topic string, headers map[string]string, filter Filter) *Kafka {
+	return &Kafka{
+		restURL:    strings.TrimSuffix(restURL, "/"),
+		topic:      topic,
+		headers:    headers,
+		filter:     filter,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name はログ用の名前
+func (k *Kafka) Name() string {
+	return "kafka"
+}
+
+// Send は配信条件を満たす処理結果をツイートIDをキーとしてトピックに送る
+func (k *Kafka) Send(ctx context.Context, event Event) error {
+	if !k.filter.Matches(event) {
+		return nil
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"records": []map[string]interface{}{{"key": event.Tweet.ID, "value": NewRecord(event)}},
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := k.restURL + "/topics/" + url.PathEscape(expandTopic(k.topic, event))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	for name, v := range k.headers {
+		req.Header.Set(name, v)
+	}
+
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Offsets []struct {
+			ErrorCode *int   `json:"error_code"`
+			Error     string `json:"error"`
+		} `json:"offsets"`
+		Message string `json:"message"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Kafka REST Proxy returned status %d: %s", resp.StatusCode, result.Message)
+	}
+	for _, o := range result.Offsets {
+		if o.ErrorCode != nil {
+			return fmt.Errorf("Kafka REST Proxy failed to produce record: %s", o.Error)
+		}
+	}
+	return nil
+}
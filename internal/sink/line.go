@@ -0,0 +1,90 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// linePushURL はLINE Messaging APIのプッシュメッセージのエンドポイント
+	linePushURL = "https://api.line.me/v2/bot/message/push"
+
+	// lineMaxSummary はLINEのメッセージに含める要約・本文の最大文字数
+	lineMaxSummary = 1000
+)
+
+// LINE は通知をLINE Messaging APIのプッシュメッセージで送るSink
+type LINE struct {
+	token      string   // チャネルアクセストークン
+	to         []string // 送信先のユーザー・グループ・トークルームのID
+	filter     Filter
+	httpClient *http.Client
+	endpoint   string
+}
+
+// NewLINE は新しいLINEを作成
+func NewLINE(channelToken string, to []string, filter Filter) *LINE {
+	return &LINE{
+		token:      channelToken,
+		to:         to,
+		filter:     filter,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		endpoint:   linePushURL,
+	}
+}
+
+// Name はログ用の名前
+func (l *LINE) Name() string {
+	return "line"
+}
+
+// Send は配信条件を満たす通知を各送信先にプッシュする
+func (l *LINE) Send(ctx context.Context, event Event) error {
+	if !l.filter.Matches(event) {
+		return nil
+	}
+	text := plainText(event, lineMaxSummary)
+	for _, to := range l.to {
+		if err := l.push(ctx, to, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// push は1件の送信先にテキストメッセージを送る
+func (l *LINE) push(ctx context.Context, to, text string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"to":       to,
+		"messages": []map[string]string{{"type": "text", "text": text}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", l.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+l.token)
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("LINE API returned status %d: %s", resp.StatusCode, apiErr.Message)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mqttTimeout はMQTTブローカーとの接続・応答のタイムアウト
+const mqttTimeout = 10 * time.Second
+
+// MQTT 3.1.1のパケット種別（固定ヘッダーの上位4ビット）
+const (
+	mqttConnect = 1
+	mqttConnack = 2
+	mqttPublish = 3
+	mqttPuback  = 4
+)
+
+// MQTT は処理結果をJSONイベントとしてMQTTブローカー (3.1.1) にパブリッシュするSink
+// 接続は使い回し、エラーが起きた場合は次の送信時に再接続する
+type MQTT struct {
+	url      *url.URL // mqtt://host:1883 または mqtts://host:8883（user:pass@ で認証情報を指定可能）
+	clientID string
+	topic    string // トピックのテンプレート（expandTopicのプレースホルダーを使用可能）
+	qos      byte   // 0または1
+	retain   bool
+	filter   Filter
+
+	mu       sync.Mutex
+	conn     net.Conn
+	r        *bufio.Reader
+	packetID uint16
+}
+
+// NewMQTT は新しいMQTTを作成（clientIDが空の場合はランダムに生成）
+func NewMQTT(brokerURL, clientID, topic string, qos int, retain bool, filter Filter) (*MQTT, error) {
+	u, err := url.Parse(brokerURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MQTT URL: %w", err)
+	}
+	switch u.Scheme {
+	case "mqtt", "tcp", "mqtts", "ssl", "tls":
+	default:
+		return nil, fmt.Errorf("invalid MQTT URL scheme %q (expected mqtt or mqtts)", u.Scheme)
+	}
+	if qos != 0 && qos != 1 {
+		return nil, fmt.Errorf("unsupported MQTT QoS %d (expected 0 or 1)", qos)
+	}
+	if clientID == "" {
+		b := make([]byte, 4)
+		rand.Read(b)
+		clientID = "x-crawler-" + hex.EncodeToString(b)
+	}
+	return &MQTT{url: u, clientID: clientID, topic: topic, qos: byte(qos), retain: retain, filter: filter}, nil
+}
+
+// Name はログ用の名前
+func (m *MQTT) Name() string {
+	return "mqtt"
+}
+
+// Send は配信条件を満たす処理結果をパブリッシュする（QoS 1の場合はPUBACKを確認）
+// テンプレートに {ticker} を含む場合は銘柄ごとのトピックにそれぞれパブリッシュする
+func (m *MQTT) Send(ctx context.Context, event Event) error {
+	if !m.filter.Matches(event) {
+		return nil
+	}
+	payload, err := json.Marshal(NewRecord(event))
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, topic := range mqttTopics(m.topic, event) {
+		if m.conn == nil {
+			if err := m.connect(ctx); err != nil {
+				return err
+			}
+		}
+		if err := m.publish(ctx, topic, payload); err != nil {
+			m.close()
+			return fmt.Errorf("failed to publish to %s: %w", topic, err)
+		}
+	}
+	return nil
+}
+
+// mqttTopics はイベントのトピックを返す（{ticker} は銘柄ごとに展開）
+func mqttTopics(tmpl string, e Event) []string {
+	if !strings.Contains(tmpl, "{ticker}") || e.Analysis == nil || len(e.Analysis.Tickers) < 2 {
+		return []string{expandTopic(tmpl, e)}
+	}
+	topics := make([]string, 0, len(e.Analysis.Tickers))
+	for _, ticker := range e.Analysis.Tickers {
+		topics = append(topics, expandTopic(strings.ReplaceAll(tmpl, "{ticker}", ticker), e))
+	}
+	return topics
+}
+
+// connect はブローカーに接続してCONNECTを送り、CONNACKを確認する
+func (m *MQTT) connect(ctx context.Context) error {
+	secure := m.url.Scheme == "mqtts" || m.url.Scheme == "ssl" || m.url.Scheme == "tls"
+	host := m.url.Host
+	if m.url.Port() == "" {
+		port := "1883"
+		if secure {
+			port = "8883"
+		}
+		host = net.JoinHostPort(host, port)
+	}
+	d := &net.Dialer{Timeout: mqttTimeout}
+	conn, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return err
+	}
+	if secure {
+		conn = tls.Client(conn, &tls.Config{ServerName: m.url.Hostname()})
+	}
+	m.conn = conn
+	m.r = bufio.NewReader(conn)
+
+	// 可変ヘッダー: プロトコル名, レベル4, フラグ (Clean Session), キープアライブ0（無効）
+	var body []byte
+	body = appendMQTTString(body, "MQTT")
+	body = append(body, 4)
+	flags := byte(0x02)
+	if u := m.url.User; u != nil {
+		flags |= 0x80
+		if _, ok := u.Password(); ok {
+			flags |= 0x40
+		}
+	}
+	body = append(body, flags, 0, 0)
+	body = appendMQTTString(body, m.clientID)
+	if u := m.url.User; u != nil {
+		body = appendMQTTString(body, u.Username())
+		if p, ok := u.Password(); ok {
+			body = appendMQTTString(body, p)
+		}
+	}
+
+	m.setDeadline(ctx)
+	if _, err := conn.Write(mqttPacket(mqttConnect<<4, body)); err != nil {
+		m.close()
+		return fmt.Errorf("failed to connect to MQTT broker: %w", err)
+	}
+	typ, resp, err := m.readPacket()
+	if err != nil {
+		m.close()
+		return fmt.Errorf("failed to read MQTT CONNACK: %w", err)
+	}
+	if typ != mqttConnack || len(resp) < 2 {
+		m.close()
+		return fmt.Errorf("unexpected MQTT packet type %d", typ)
+	}
+	if code := resp[1]; code != 0 {
+		m.close()
+		return fmt.Errorf("MQTT broker refused connection (return code %d)", code)
+	}
+	return nil
+}
+
+// publish はPUBLISHを送る（QoS 1の場合は同じパケットIDのPUBACKまで待つ）
+func (m *MQTT) publish(ctx context.Context, topic string, payload []byte) error {
+	header := byte(mqttPublish<<4) | m.qos<<1
+	if m.retain {
+		header |= 0x01
+	}
+	body := appendMQTTString(nil, topic)
+	if m.qos > 0 {
+		m.packetID++
+		if m.packetID == 0 {
+			m.packetID = 1
+		}
+		body = binary.BigEndian.AppendUint16(body, m.packetID)
+	}
+	body = append(body, payload...)
+
+	m.setDeadline(ctx)
+	if _, err := m.conn.Write(mqttPacket(header, body)); err != nil {
+		return err
+	}
+	if m.qos == 0 {
+		return nil
+	}
+	for {
+		typ, resp, err := m.readPacket()
+		if err != nil {
+			return err
+		}
+		if typ == mqttPuback && len(resp) >= 2 && binary.BigEndian.Uint16(resp) == m.packetID {
+			return nil
+		}
+	}
+}
+
+// setDeadline は応答待ちの期限を設定
+func (m *MQTT) setDeadline(ctx context.Context) {
+	deadline := time.Now().Add(mqttTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	m.conn.SetDeadline(deadline)
+}
+
+// readPacket はパケットを1つ読み、種別と残りの部分を返す
+func (m *MQTT) readPacket() (byte, []byte, error) {
+	header, err := m.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var length, shift int
+	for {
+		b, err := m.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length |= int(b&0x7F) << shift
+		if b&0x80 == 0 {
+			break
+		}
+		if shift += 7; shift > 21 {
+			return 0, nil, fmt.Errorf("malformed MQTT remaining length")
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(m.r, body); err != nil {
+		return 0, nil, err
+	}
+	return header >> 4, body, nil
+}
+
+// close は接続を閉じる（次の送信時に再接続する）
+func (m *MQTT) close() {
+	if m.conn != nil {
+		m.conn.Close()
+		m.conn = nil
+	}
+}
+
+// mqttPacket は固定ヘッダー（残りの長さは可変長整数）を付けたパケットを作成
+func mqttPacket(header byte, body []byte) []byte {
+	packet := []byte{header}
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+// appendMQTTString は長さ（2バイト）付きのUTF-8文字列を追加
+func appendMQTTString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
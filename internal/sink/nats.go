@@ -0,0 +1,159 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsTimeout はNATSサーバーとの接続・応答のタイムアウト
+const natsTimeout = 10 * time.Second
+
+// NATS は処理結果をJSONイベントとしてNATSのサブジェクトにパブリッシュするSink
+// 接続は使い回し、エラーが起きた場合は次の送信時に再接続する
+type NATS struct {
+	url     *url.URL // nats://host:4222 または tls://host:4222（user:pass@ で認証情報を指定可能）
+	token   string
+	subject string // サブジェクトのテンプレート（expandTopicのプレースホルダーを使用可能）
+	filter  Filter
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewNATS は新しいNATSを作成（tokenはトークン認証の場合のみ指定）
+func NewNATS(serverURL, token, subject string, filter Filter) (*NATS, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL: %w", err)
+	}
+	if u.Scheme != "nats" && u.Scheme != "tls" {
+		return nil, fmt.Errorf("invalid NATS URL scheme %q (expected nats or tls)", u.Scheme)
+	}
+	return &NATS{url: u, token: token, subject: subject, filter: filter}, nil
+}
+
+// Name はログ用の名前
+func (n *NATS) Name() string {
+	return "nats"
+}
+
+// Send は配信条件を満たす処理結果をパブリッシュし、サーバーが受け付けたことを確認する
+func (n *NATS) Send(ctx context.Context, event Event) error {
+	if !n.filter.Matches(event) {
+		return nil
+	}
+	payload, err := json.Marshal(NewRecord(event))
+	if err != nil {
+		return err
+	}
+	subject := expandTopic(n.subject, event)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn == nil {
+		if err := n.connect(ctx); err != nil {
+			return err
+		}
+	}
+	// PUBの後にPINGを送り、PONGが返ればサーバーが処理済み（エラーなら -ERR が先に返る）
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(payload), payload)
+	if err := n.roundTrip(ctx, msg); err != nil {
+		n.close()
+		return err
+	}
+	return nil
+}
+
+// connect はサーバーに接続してCONNECTを送る
+func (n *NATS) connect(ctx context.Context) error {
+	host := n.url.Host
+	if n.url.Port() == "" {
+		host = net.JoinHostPort(host, "4222")
+	}
+	d := &net.Dialer{Timeout: natsTimeout}
+	conn, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return err
+	}
+	if n.url.Scheme == "tls" {
+		conn = tls.Client(conn, &tls.Config{ServerName: n.url.Hostname()})
+	}
+	n.conn = conn
+	n.r = bufio.NewReader(conn)
+
+	// 最初にサーバーからINFOが送られる
+	conn.SetReadDeadline(time.Now().Add(natsTimeout))
+	line, err := n.r.ReadString('\n')
+	if err != nil {
+		n.close()
+		return fmt.Errorf("failed to read NATS INFO: %w", err)
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		n.close()
+		return fmt.Errorf("unexpected NATS greeting: %s", strings.TrimSpace(line))
+	}
+
+	opts := map[string]interface{}{"verbose": false, "pedantic": false, "name": "x-crawler", "lang": "go"}
+	if n.token != "" {
+		opts["auth_token"] = n.token
+	}
+	if u := n.url.User; u != nil {
+		opts["user"] = u.Username()
+		if p, ok := u.Password(); ok {
+			opts["pass"] = p
+		}
+	}
+	connect, _ := json.Marshal(opts)
+	if err := n.roundTrip(ctx, "CONNECT "+string(connect)+"\r\nPING\r\n"); err != nil {
+		n.close()
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	return nil
+}
+
+// roundTrip はコマンドを送り、PONGが返るまで待つ（サーバーからのPINGには応答する）
+func (n *NATS) roundTrip(ctx context.Context, cmd string) error {
+	deadline := time.Now().Add(natsTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	n.conn.SetDeadline(deadline)
+	if _, err := n.conn.Write([]byte(cmd)); err != nil {
+		return err
+	}
+	for {
+		line, err := n.r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := n.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("NATS error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		// +OK やINFOの更新は無視
+	}
+}
+
+// close は接続を閉じる（次の送信時に再接続する）
+func (n *NATS) close() {
+	if n.conn != nil {
+		n.conn.Close()
+		n.conn = nil
+	}
+}
@@ -0,0 +1,91 @@
+package sink
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+)
+
+// Record は外部システム向けにJSONで送る通知の内容
+type Record struct {
+	ID          string       `json:"id"` // 通知ごとに一意なID（ツイートID:処理）
+	Decision    string       `json:"decision"`
+	Source      string       `json:"source"`
+	SourceInfo  string       `json:"source_info"`
+	ProcessedAt time.Time    `json:"processed_at"`
+	URL         string       `json:"url"`
+	Tweet       RecordTweet  `json:"tweet"`
+	Analysis    *ai.Analysis `json:"analysis,omitempty"` // AI分析なしの場合は省略
+}
+
+// RecordTweet はRecordに含めるツイートの内容
+type RecordTweet struct {
+	ID              string    `json:"id"`
+	Username        string    `json:"username"`
+	Text            string    `json:"text"`
+	CreatedAt       time.Time `json:"created_at"`
+	ConversationID  string    `json:"conversation_id,omitempty"`
+	AuthorFollowers int       `json:"author_followers,omitempty"`
+	LikeCount       int       `json:"like_count"`
+	RetweetCount    int       `json:"retweet_count"`
+	ReplyCount      int       `json:"reply_count"`
+	QuoteCount      int       `json:"quote_count"`
+	ImpressionCount int       `json:"impression_count"`
+	Links           []string  `json:"links,omitempty"`
+}
+
+// NewRecord はEventからRecordを作成
+func NewRecord(e Event) Record {
+	t := e.Tweet
+	r := Record{
+		ID:          e.Tweet.ID + ":" + e.Decision,
+		Decision:    e.Decision,
+		Source:      e.Source,
+		SourceInfo:  e.SourceInfo,
+		ProcessedAt: e.At,
+		URL:         e.URL(),
+		Tweet: RecordTweet{
+			ID:              t.ID,
+			Username:        t.Username,
+			Text:            t.Text,
+			CreatedAt:       t.CreatedAt,
+			ConversationID:  t.ConversationID,
+			AuthorFollowers: t.AuthorFollowers,
+			Links:           t.LinkURLs(),
+		},
+		Analysis: e.Analysis,
+	}
+	if m := t.Metrics; m != nil {
+		r.Tweet.LikeCount = m.LikeCount
+		r.Tweet.RetweetCount = m.RetweetCount
+		r.Tweet.ReplyCount = m.ReplyCount
+		r.Tweet.QuoteCount = m.QuoteCount
+		r.Tweet.ImpressionCount = m.ImpressionCount
+	}
+	return r
+}
+
+// expandTopic はトピック・サブジェクトのテンプレートの {decision} {urgency} {category} {ticker} {username} を置換
+// AI分析なしの場合や銘柄がない場合は "none" に置換する
+func expandTopic(tmpl string, e Event) string {
+	urgency, category, ticker := "none", "none", "none"
+	if a := e.Analysis; a != nil {
+		if a.Urgency != "" {
+			urgency = a.Urgency
+		}
+		if a.Category != "" {
+			category = a.Category
+		}
+		if len(a.Tickers) > 0 {
+			ticker = a.Tickers[0]
+		}
+	}
+	return strings.NewReplacer(
+		"{decision}", e.Decision,
+		"{urgency}", urgency,
+		"{category}", category,
+		"{ticker}", ticker,
+		"{username}", e.Tweet.Username,
+	).Replace(tmpl)
+}
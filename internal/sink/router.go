@@ -0,0 +1,135 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Rule は通知の振り分けルール（Filterに一致した通知をSinksに送る）
+type Rule struct {
+	Name   string
+	Filter Filter
+	Sinks  []string // 送り先のSink名 (Sink.Name())
+	Stop   bool     // 一致した場合に後続のルールを評価しない
+}
+
+// Router はルールに従って通知を複数のSinkに振り分けるSink
+// どのルールにも一致しない通知はdefaultsに送る
+// ルールとdefaultsのどちらにも名前のないSinkは振り分けの対象外で、全ての通知を受け取る（各Sinkの配信条件は常に適用される）
+type Router struct {
+	sinks      []Sink
+	byName     map[string]Sink
+	rules      []Rule
+	defaults   []string
+	unassigned []Sink
+}
+
+// NewRouter は新しいRouterを作成（存在しないSink名を指定した場合はエラー）
+func NewRouter(sinks []Sink, rules []Rule, defaults []string) (*Router, error) {
+	r := &Router{sinks: sinks, byName: make(map[string]Sink, len(sinks)), rules: rules, defaults: defaults}
+	for _, s := range sinks {
+		if _, ok := r.byName[s.Name()]; ok {
+			return nil, fmt.Errorf("duplicate sink name %q (give each webhook a unique name)", s.Name())
+		}
+		r.byName[s.Name()] = s
+	}
+
+	assigned := make(map[string]bool)
+	check := func(where string, names []string) error {
+		for _, name := range names {
+			if _, ok := r.byName[name]; !ok {
+				return fmt.Errorf("%s: unknown sink %q (enabled sinks: %s)", where, name, strings.Join(r.names(), ", "))
+			}
+			assigned[name] = true
+		}
+		return nil
+	}
+	for i, rule := range rules {
+		where := fmt.Sprintf("routing rule %d", i+1)
+		if rule.Name != "" {
+			where = fmt.Sprintf("routing rule %q", rule.Name)
+		}
+		if err := check(where, rule.Sinks); err != nil {
+			return nil, err
+		}
+	}
+	if err := check("routing default", defaults); err != nil {
+		return nil, err
+	}
+	for _, s := range sinks {
+		if !assigned[s.Name()] {
+			r.unassigned = append(r.unassigned, s)
+		}
+	}
+	return r, nil
+}
+
+// Name はログ用の名前
+func (r *Router) Name() string {
+	return "router"
+}
+
+// Send は通知をルールで決まったSinkと振り分け対象外のSinkに送る（失敗したSinkのエラーをまとめて返す）
+func (r *Router) Send(ctx context.Context, event Event) error {
+	targets := append([]Sink(nil), r.unassigned...)
+	for _, name := range r.route(event) {
+		targets = append(targets, r.byName[name])
+	}
+
+	var errs []error
+	for _, s := range targets {
+		if err := s.Send(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Tick は振り分けに関係なく全てのTickerに時刻を伝える
+func (r *Router) Tick(ctx context.Context, now time.Time) {
+	for _, s := range r.sinks {
+		if t, ok := s.(Ticker); ok {
+			t.Tick(ctx, now)
+		}
+	}
+}
+
+// route は通知を送るSink名を重複なしで返す
+func (r *Router) route(event Event) []string {
+	var names []string
+	seen := make(map[string]bool)
+	matched := false
+	for _, rule := range r.rules {
+		if !rule.Filter.Matches(event) {
+			continue
+		}
+		matched = true
+		for _, name := range rule.Sinks {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		if rule.Stop {
+			break
+		}
+	}
+	if !matched {
+		return r.defaults
+	}
+	return names
+}
+
+// names は有効なSink名を返す
+func (r *Router) names() []string {
+	names := make([]string, 0, len(r.byName))
+	for name := range r.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,107 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/storage"
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// Event はSlack以外の通知先に送る通知の内容
+type Event struct {
+	Tweet      twitter.Tweet
+	Analysis   *ai.Analysis // AI分析なしで通知した場合はnil
+	Decision   string       // 分析結果に対する処理 (storage.Decision*、通知しなかったものを含む)
+	Source     string       // 取得元の種類 (trader, keyword)
+	SourceInfo string       // 取得元の情報（トレーダー名やキーワード）
+	At         time.Time    // 処理した時刻
+}
+
+// URL はポストのURLを返す
+func (e Event) URL() string {
+	return fmt.Sprintf("https://x.com/%s/status/%s", e.Tweet.Username, e.Tweet.ID)
+}
+
+// Sink はSlack以外の通知先
+type Sink interface {
+	Name() string                                // ログ用の名前
+	Send(ctx context.Context, event Event) error // 通知を送る（失敗してもクロールは続行する）
+}
+
+// Ticker はクロールのサイクルごとに呼ばれる通知先（日次ダイジェストなど）
+type Ticker interface {
+	Tick(ctx context.Context, now time.Time)
+}
+
+// AllDecisions はFilter.Decisionsに指定すると通知しなかったものを含む全ての処理に一致する
+const AllDecisions = "all"
+
+// notifiedDecisions はFilter.Decisionsが空の場合に一致する処理（Slackに通知したもの）
+var notifiedDecisions = []string{storage.DecisionNotified, storage.DecisionMaybe, storage.DecisionDigest}
+
+// Filter は通知先ごとの配信条件（空でない条件を全て満たす通知のみ送る）
+type Filter struct {
+	Decisions  []string // いずれかの処理（空の場合は通知・要確認・ダイジェスト、AllDecisionsで全て）
+	MinScore   int      // 最低スコア（0の場合は条件なし）
+	MaxScore   int      // 最高スコア（0の場合は条件なし）
+	Urgencies  []string // いずれかの緊急度
+	Categories []string // いずれかのカテゴリ
+	Traders    []string // いずれかの投稿者
+	Tickers    []string // いずれかの銘柄を含む
+}
+
+// Matches は通知が配信条件を満たすかを返す
+// AI分析なしの通知はスコア・緊急度・カテゴリ・銘柄の条件を持つFilterに一致しない
+func (f Filter) Matches(e Event) bool {
+	decisions := f.Decisions
+	if len(decisions) == 0 {
+		decisions = notifiedDecisions
+	}
+	if !containsFold(decisions, AllDecisions) && !containsFold(decisions, e.Decision) {
+		return false
+	}
+	if len(f.Traders) > 0 && !containsFold(f.Traders, e.Tweet.Username) {
+		return false
+	}
+	a := e.Analysis
+	if a == nil {
+		return f.MinScore == 0 && f.MaxScore == 0 && len(f.Urgencies) == 0 && len(f.Categories) == 0 && len(f.Tickers) == 0
+	}
+	if a.Score < f.MinScore || (f.MaxScore > 0 && a.Score > f.MaxScore) {
+		return false
+	}
+	if len(f.Urgencies) > 0 && !containsFold(f.Urgencies, a.Urgency) {
+		return false
+	}
+	if len(f.Categories) > 0 && !containsFold(f.Categories, a.Category) {
+		return false
+	}
+	if len(f.Tickers) > 0 {
+		found := false
+		for _, t := range a.Tickers {
+			if containsFold(f.Tickers, t) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold はlistに大文字小文字・先頭の@と$を無視してvと一致する要素があるかを返す
+func containsFold(list []string, v string) bool {
+	v = strings.TrimLeft(v, "@$")
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimLeft(item, "@$"), v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package sink
+
+import (
+	"fmt"
+	"strings"
+)
+
+// urgencyEmojis は緊急度ごとの絵文字（Slack通知の既定と同じ）
+var urgencyEmojis = map[string]string{
+	"critical": "🚨",
+	"high":     "⚠️",
+	"normal":   "💡",
+	"low":      "ℹ️",
+}
+
+// title は通知の見出し（緊急度の絵文字、投稿者、スコア、カテゴリ）
+func title(e Event) string {
+	a := e.Analysis
+	if a == nil {
+		return fmt.Sprintf("@%s の新しい投稿", e.Tweet.Username)
+	}
+	emoji, ok := urgencyEmojis[a.Urgency]
+	if !ok {
+		emoji = urgencyEmojis["normal"]
+	}
+	return fmt.Sprintf("%s @%s %d/100 [%s]", emoji, e.Tweet.Username, a.Score, a.Category)
+}
+
+// plainText はチャット・SMSなど書式のない通知先向けの本文
+// 見出し、銘柄、要約（AI分析なしの場合はポストの本文）、ポストのURLを改行で区切り、本文はmaxRunes文字に切り詰める
+func plainText(e Event, maxRunes int) string {
+	lines := []string{title(e)}
+	body := e.Tweet.Text
+	if a := e.Analysis; a != nil {
+		if len(a.Tickers) > 0 {
+			lines = append(lines, "$"+strings.Join(a.Tickers, " $"))
+		}
+		body = a.Summary
+	}
+	if body = truncate(strings.TrimSpace(body), maxRunes); body != "" {
+		lines = append(lines, body)
+	}
+	lines = append(lines, e.URL())
+	return strings.Join(lines, "\n")
+}
+
+// truncate はsをmaxRunes文字に切り詰める（0以下の場合はそのまま）
+func truncate(s string, maxRunes int) string {
+	r := []rune(s)
+	if maxRunes <= 0 || len(r) <= maxRunes {
+		return s
+	}
+	return string(r[:maxRunes]) + "…"
+}
@@ -0,0 +1,96 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// twilioAPIURL はTwilio REST APIのベースURL
+	twilioAPIURL = "https://api.twilio.com/2010-04-01"
+
+	// smsMaxSummary はSMSに含める要約・本文の最大文字数（分割送信の課金を抑える）
+	smsMaxSummary = 200
+)
+
+// Twilio は通知をTwilioのSMSで送るSink
+type Twilio struct {
+	accountSID string
+	authToken  string
+	from       string // 送信元の電話番号（E.164）またはMessaging ServiceのSID (MG...)
+	to         []string
+	filter     Filter
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewTwilio は新しいTwilioを作成
+func NewTwilio(accountSID, authToken, from string, to []string, filter Filter) *Twilio {
+	return &Twilio{
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		to:         to,
+		filter:     filter,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		baseURL:    twilioAPIURL,
+	}
+}
+
+// Name はログ用の名前
+func (t *Twilio) Name() string {
+	return "sms"
+}
+
+// Send は配信条件を満たす通知を各送信先にSMSで送る
+func (t *Twilio) Send(ctx context.Context, event Event) error {
+	if !t.filter.Matches(event) {
+		return nil
+	}
+	body := plainText(event, smsMaxSummary)
+	for _, to := range t.to {
+		if err := t.send(ctx, to, body); err != nil {
+			return fmt.Errorf("failed to send SMS to %s: %w", to, err)
+		}
+	}
+	return nil
+}
+
+// send は1件の送信先にSMSを送る
+func (t *Twilio) send(ctx context.Context, to, body string) error {
+	form := url.Values{"To": {to}, "Body": {body}}
+	if strings.HasPrefix(t.from, "MG") {
+		form.Set("MessagingServiceSid", t.from)
+	} else {
+		form.Set("From", t.from)
+	}
+
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", t.baseURL, url.PathEscape(t.accountSID))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(t.accountSID, t.authToken)
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("Twilio API returned status %d (code %d): %s", resp.StatusCode, apiErr.Code, apiErr.Message)
+	}
+	return nil
+}
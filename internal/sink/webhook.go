@@ -0,0 +1,133 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureHeader はHMAC-SHA256署名 ("sha256=" + hex) のヘッダー
+	// 署名対象は "タイムスタンプ.本文"（受信側はタイムスタンプの古いリクエストを拒否することでリプレイを防げる）
+	SignatureHeader = "X-Crawler-Signature"
+
+	// TimestampHeader は署名に使ったUNIX時刻（秒）のヘッダー
+	TimestampHeader = "X-Crawler-Timestamp"
+
+	// DeliveryHeader は通知ごとに一意なID（再試行でも同じ値、受信側の重複排除用）のヘッダー
+	DeliveryHeader = "X-Crawler-Delivery"
+
+	// webhookBackoff は再試行の初回待機時間（試行ごとに倍増）
+	webhookBackoff = time.Second
+)
+
+// Webhook は通知をJSON (Record) で任意のURLにPOSTするSink
+type Webhook struct {
+	name        string
+	url         string
+	secret      []byte // 空の場合は署名しない
+	headers     map[string]string
+	filter      Filter
+	maxAttempts int
+	httpClient  *http.Client
+}
+
+// NewWebhook は新しいWebhookを作成（maxAttemptsは一時的なエラー時の最大試行回数）
+func NewWebhook(name, url, secret string, headers map[string]string, filter Filter, maxAttempts int, timeout time.Duration) *Webhook {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &Webhook{
+		name:        name,
+		url:         url,
+		secret:      []byte(secret),
+		headers:     headers,
+		filter:      filter,
+		maxAttempts: maxAttempts,
+		httpClient:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Name はログ用の名前
+func (w *Webhook) Name() string {
+	return "webhook:" + w.name
+}
+
+// Send は配信条件を満たす通知をPOSTする（429, 5xx, 通信エラーはバックオフしながら再試行）
+func (w *Webhook) Send(ctx context.Context, event Event) error {
+	if !w.filter.Matches(event) {
+		return nil
+	}
+	record := NewRecord(event)
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < w.maxAttempts; attempt++ {
+		retry, err := w.post(ctx, record.ID, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || ctx.Err() != nil || attempt == w.maxAttempts-1 {
+			break
+		}
+
+		wait := webhookBackoff << attempt
+		log.Printf("Webhook %s delivery failed (attempt %d/%d), retrying in %s: %v", w.name, attempt+1, w.maxAttempts, wait, err)
+		select {
+		case <-ctx.Done():
+			return lastErr
+		case <-time.After(wait):
+		}
+	}
+	return lastErr
+}
+
+// post はリクエストを1回送信し、失敗した場合は再試行すべきかを返す
+func (w *Webhook) post(ctx context.Context, deliveryID string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryHeader, deliveryID)
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+	if len(w.secret) > 0 {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, ts)
+		req.Header.Set(SignatureHeader, Sign(w.secret, ts, body))
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return false, nil
+}
+
+// Sign はタイムスタンプと本文のHMAC-SHA256署名を "sha256=" + hex の形式で返す
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,337 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// WebSocketPath はWebSocketの待ち受けパス
+	WebSocketPath = "/ws"
+
+	// websocketGUID はSec-WebSocket-Acceptの計算に使う固定値 (RFC 6455)
+	websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	// websocketQueue はクライアントごとの送信待ちの上限（超えた遅いクライアントは切断）
+	websocketQueue = 64
+
+	// websocketPingInterval は接続維持のためのPingの間隔
+	websocketPingInterval = 30 * time.Second
+
+	// websocketMaxPayload はクライアントから受け付けるフレームの最大サイズ
+	websocketMaxPayload = 64 << 10
+)
+
+// WebSocketのオペコード
+const (
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xA
+)
+
+// WebSocket は通知をJSON (Record) で接続中の全クライアントに配信するSink
+// 組み込みHTTPサーバーのハンドラーとして登録する
+type WebSocket struct {
+	token          string // 空の場合は認証しない
+	maxClients     int    // 0の場合は無制限
+	allowedOrigins map[string]bool
+	filter         Filter
+
+	mu      sync.Mutex
+	clients map[*wsClient]struct{}
+}
+
+// wsClient は接続中のクライアント
+type wsClient struct {
+	conn      net.Conn
+	send      chan []byte // 送信待ちのフレーム
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewWebSocket は新しいWebSocketを作成
+// allowedOrigins は同一ホスト以外に接続を許可するブラウザのOrigin (例: https://dashboard.example.com)
+func NewWebSocket(token string, maxClients int, allowedOrigins []string, filter Filter) *WebSocket {
+	origins := make(map[string]bool, len(allowedOrigins))
+	for _, o := range allowedOrigins {
+		origins[strings.ToLower(strings.TrimSuffix(o, "/"))] = true
+	}
+	return &WebSocket{
+		token:          token,
+		maxClients:     maxClients,
+		allowedOrigins: origins,
+		filter:         filter,
+		clients:        make(map[*wsClient]struct{}),
+	}
+}
+
+// Name はログ用の名前
+func (w *WebSocket) Name() string {
+	return "websocket"
+}
+
+// Send は配信条件を満たす通知を接続中の全クライアントに送る（送信待ちが溢れたクライアントは切断）
+func (w *WebSocket) Send(ctx context.Context, event Event) error {
+	if !w.filter.Matches(event) {
+		return nil
+	}
+	body, err := json.Marshal(NewRecord(event))
+	if err != nil {
+		return err
+	}
+	frame := wsFrame(wsOpText, body)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for c := range w.clients {
+		select {
+		case c.send <- frame:
+		default:
+			log.Printf("WebSocket client %s is too slow, disconnecting", c.conn.RemoteAddr())
+			w.remove(c)
+		}
+	}
+	return nil
+}
+
+// ServeHTTP はOriginとトークンを検証してWebSocketにアップグレードする
+// トークンは "Authorization: Bearer <token>" またはクエリの ?token= で指定（ブラウザは後者）
+func (w *WebSocket) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if !w.originAllowed(r) {
+		http.Error(rw, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	if w.token != "" && !w.authorized(r) {
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet ||
+		!headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(rw, "websocket upgrade required", http.StatusBadRequest)
+		return
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		rw.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(rw, "unsupported websocket version", http.StatusUpgradeRequired)
+		return
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(rw, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return
+	}
+
+	w.mu.Lock()
+	full := w.maxClients > 0 && len(w.clients) >= w.maxClients
+	w.mu.Unlock()
+	if full {
+		http.Error(rw, "too many clients", http.StatusServiceUnavailable)
+		return
+	}
+
+	hijacker, ok := rw.(http.Hijacker)
+	if !ok {
+		http.Error(rw, "websocket not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		log.Printf("WebSocket hijack failed: %v", err)
+		return
+	}
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	handshake := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n"
+	if _, err := conn.Write([]byte(handshake)); err != nil {
+		conn.Close()
+		return
+	}
+
+	c := &wsClient{conn: conn, send: make(chan []byte, websocketQueue), done: make(chan struct{})}
+	w.mu.Lock()
+	w.clients[c] = struct{}{}
+	count := len(w.clients)
+	w.mu.Unlock()
+	log.Printf("WebSocket client connected from %s (%d clients)", conn.RemoteAddr(), count)
+
+	go w.writeLoop(c)
+	w.readLoop(c, buf.Reader)
+
+	w.mu.Lock()
+	w.remove(c)
+	w.mu.Unlock()
+	log.Printf("WebSocket client disconnected from %s", conn.RemoteAddr())
+}
+
+// authorized はリクエストのトークンが正しいかを返す
+func (w *WebSocket) authorized(r *http.Request) bool {
+	token := r.URL.Query().Get("token")
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(w.token)) == 1
+}
+
+// originAllowed はブラウザからの接続 (Originあり) が同一ホストか許可されたOriginかを返す
+// 他サイトのページから閲覧者のブラウザ経由で接続されるのを防ぐ (Originを送らないクライアントは対象外)
+func (w *WebSocket) originAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if w.allowedOrigins[strings.ToLower(strings.TrimSuffix(origin, "/"))] {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// remove はクライアントを登録から外して切断する（w.muを保持して呼ぶ）
+func (w *WebSocket) remove(c *wsClient) {
+	delete(w.clients, c)
+	c.close()
+}
+
+// close は接続を閉じる（複数回呼んでもよい）
+func (c *wsClient) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// writeLoop は送信待ちのフレームと定期的なPingを書き込む
+func (w *WebSocket) writeLoop(c *wsClient) {
+	ticker := time.NewTicker(websocketPingInterval)
+	defer ticker.Stop()
+	for {
+		var frame []byte
+		select {
+		case <-c.done:
+			return
+		case frame = <-c.send:
+		case <-ticker.C:
+			frame = wsFrame(wsOpPing, nil)
+		}
+		c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		if _, err := c.conn.Write(frame); err != nil {
+			c.close()
+			return
+		}
+	}
+}
+
+// readLoop はクライアントからのフレームを読み、Pingへの応答とCloseを処理する（本文は無視）
+func (w *WebSocket) readLoop(c *wsClient, r *bufio.Reader) {
+	for {
+		// Pingの応答が途絶えたクライアントは切断
+		c.conn.SetReadDeadline(time.Now().Add(2 * websocketPingInterval))
+		op, payload, err := readWSFrame(r)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.Printf("WebSocket read from %s failed: %v", c.conn.RemoteAddr(), err)
+			}
+			return
+		}
+		switch op {
+		case wsOpPing:
+			select {
+			case c.send <- wsFrame(wsOpPong, payload):
+			default:
+			}
+		case wsOpClose:
+			c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+			c.conn.Write(wsFrame(wsOpClose, nil))
+			return
+		}
+	}
+}
+
+// wsFrame はサーバーから送るフレーム（FIN付き・マスクなし）を作成
+func wsFrame(op byte, payload []byte) []byte {
+	frame := []byte{0x80 | op}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	return append(frame, payload...)
+}
+
+// readWSFrame はクライアントからのフレームを1つ読む（クライアントのフレームは必ずマスクされている）
+func readWSFrame(r *bufio.Reader) (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	op := header[0] & 0x0F
+	if header[1]&0x80 == 0 {
+		return 0, nil, fmt.Errorf("unmasked client frame")
+	}
+	n := uint64(header[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > websocketMaxPayload {
+		return 0, nil, fmt.Errorf("client frame too large (%d bytes)", n)
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(r, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return op, payload, nil
+}
+
+// headerContains はカンマ区切りのヘッダー値にトークンが含まれるかを返す（大文字小文字を区別しない）
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
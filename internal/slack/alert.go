@@ -0,0 +1,86 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// alerter は運用アラートを種類ごとに間引く
+type alerter struct {
+	interval time.Duration // 同じ種類のアラートを再送するまでの最短間隔
+
+	mu     sync.Mutex
+	states map[string]*alertState
+}
+
+// alertState は種類ごとのアラートの送信状況
+type alertState struct {
+	lastSent   time.Time
+	suppressed int // 前回の送信以降に間引いた件数
+}
+
+// WithAlerts は運用上の障害（X APIの認証エラー、AIプロバイダーの障害、保存の失敗など）を
+// 運用向けの投稿先に通知する。同じ種類のアラートはintervalに1回まで送る
+func WithAlerts(interval time.Duration) Option {
+	return func(s *Notifier) {
+		s.alerts = &alerter{
+			interval: interval,
+			states:   make(map[string]*alertState),
+		}
+	}
+}
+
+// allow は種類のアラートを今送るべきかを返し、送る場合は前回以降に間引いた件数を返す
+func (a *alerter) allow(kind string, now time.Time) (bool, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	st, ok := a.states[kind]
+	if !ok {
+		st = &alertState{}
+		a.states[kind] = st
+	}
+	if !st.lastSent.IsZero() && now.Sub(st.lastSent) < a.interval {
+		st.suppressed++
+		return false, 0
+	}
+	suppressed := st.suppressed
+	st.lastSent = now
+	st.suppressed = 0
+	return true, suppressed
+}
+
+// Alert は運用アラートを通知（アラートが無効、または同じ種類を最近送った場合は何もしない）
+// kindはアラートの種類（間引きの単位）。送信の失敗はログに出力する
+func (s *Notifier) Alert(ctx context.Context, kind, text string) {
+	if s.alerts == nil {
+		return
+	}
+	ok, suppressed := s.alerts.allow(kind, time.Now())
+	if !ok {
+		return
+	}
+	if suppressed > 0 {
+		text += "\n" + s.msg.text(MsgAlertSuppressed, suppressed)
+	}
+
+	message := map[string]interface{}{
+		"username":   s.username,
+		"icon_emoji": s.iconEmoji,
+		"attachments": []map[string]interface{}{
+			{
+				"color":  s.urgencyStyle("critical").Color,
+				"title":  s.msg.text(MsgAlertTitle, kind),
+				"text":   text,
+				"footer": s.msg.text(MsgFooter),
+				"ts":     time.Now().Unix(),
+			},
+		},
+	}
+	if err := s.postOps(ctx, "", message); err != nil && !errors.Is(err, ErrQueued) {
+		log.Printf("Failed to send ops alert (%s): %v", kind, err)
+	}
+}
@@ -0,0 +1,234 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+const (
+	apiBaseURL = "https://slack.com/api/"
+
+	// sentRetention は投稿済みメッセージの参照を保持する期間
+	sentRetention = 24 * time.Hour
+)
+
+// MessageRef は投稿済みメッセージの参照（botモードのみ）
+type MessageRef struct {
+	Channel string // チャンネルID
+	TS      string // メッセージのタイムスタンプ
+}
+
+// sentMessage はツイートごとの投稿済みメッセージ
+type sentMessage struct {
+	ref      MessageRef
+	maybe    bool // 「要確認」として投稿したか
+	compact  bool // 簡潔な形式で投稿したか
+	postedAt time.Time
+}
+
+// botClient はBotトークンでSlack Web APIを呼び出す
+type botClient struct {
+	token      string
+	channel    string
+	httpClient *http.Client
+
+	mu   sync.Mutex
+	sent map[string]sentMessage // 最初の投稿のID (Tweet.RootID) -> 投稿済みメッセージ
+
+	threadDay string                // threadsの対象日 (YYYY-MM-DD)
+	threads   map[string]MessageRef // 投稿先+ティッカー -> その日の最初の通知
+}
+
+// apiResponse はSlack Web APIの共通レスポンス
+type apiResponse struct {
+	OK      bool   `json:"ok"`
+	Error   string `json:"error"`
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+}
+
+// WithBotToken はWebhookの代わりにBotトークンとWeb API (chat.postMessage) で投稿する
+// channelは既定の投稿先（名前またはID）。スレッド返信やメッセージの更新が可能になる
+func WithBotToken(token, channel string) Option {
+	return func(s *Notifier) {
+		s.bot = &botClient{
+			token:      token,
+			channel:    channel,
+			httpClient: s.httpClient,
+			sent:       make(map[string]sentMessage),
+		}
+	}
+}
+
+// WithMaybeChannel はbotモードで確信度の低い通知の投稿先チャンネルを指定
+func WithMaybeChannel(channel string) Option {
+	return func(s *Notifier) {
+		s.maybeChannel = channel
+	}
+}
+
+// WithTickerThreads は同じ日に同じ銘柄の通知が続いた場合、最初の通知のスレッドに返信として投稿する（botモードのみ）
+func WithTickerThreads() Option {
+	return func(s *Notifier) {
+		s.tickerThreads = true
+	}
+}
+
+// BotMode はbotモード（Web API）で動作しているかを返す
+func (s *Notifier) BotMode() bool {
+	return s.bot != nil
+}
+
+// UpdateTweet は以前に通知したツイート（編集前の投稿やスレッドの起点を含む）のメッセージを
+// 新しい分析結果で更新（botモードのみ）。投稿済みのメッセージが見つからない場合はfalseを返す
+func (s *Notifier) UpdateTweet(ctx context.Context, tweet twitter.Tweet, analysis *ai.Analysis) (bool, error) {
+	if s.bot == nil {
+		return false, nil
+	}
+	sent, ok := s.bot.lookup(tweet.RootID())
+	if !ok {
+		return false, nil
+	}
+
+	var message map[string]interface{}
+	switch {
+	case sent.compact:
+		message = s.compactMessage(tweet, analysis, sent.maybe)
+	case sent.maybe:
+		message = s.buildMaybeMessage(tweet, analysis, s.fetchQuotes(ctx, analysis.Tickers))
+	default:
+		message = s.buildMessage(tweet, analysis, s.fetchQuotes(ctx, analysis.Tickers))
+	}
+	if err := s.bot.update(ctx, sent.ref, message); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// call はWeb APIのメソッドを呼び出す
+func (b *botClient) call(ctx context.Context, method string, payload map[string]interface{}) (*apiResponse, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", apiBaseURL+method, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+b.token)
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp, fmt.Sprintf("Slack API %s returned status %d", method, resp.StatusCode))
+	}
+
+	var result apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode Slack API %s response: %w", method, err)
+	}
+	if !result.OK {
+		status := resp.StatusCode
+		if result.Error == "ratelimited" {
+			status = http.StatusTooManyRequests
+		}
+		return nil, &statusError{status: status, msg: fmt.Sprintf("Slack API %s error: %s", method, result.Error)}
+	}
+	return &result, nil
+}
+
+// postMessage はchat.postMessageでメッセージを投稿
+// メッセージにchannelがなければ既定のチャンネルに投稿する
+func (b *botClient) postMessage(ctx context.Context, message map[string]interface{}) (*MessageRef, error) {
+	payload := make(map[string]interface{}, len(message)+1)
+	for k, v := range message {
+		payload[k] = v
+	}
+	if ch, _ := payload["channel"].(string); ch == "" {
+		payload["channel"] = b.channel
+	}
+
+	result, err := b.call(ctx, "chat.postMessage", payload)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageRef{Channel: result.Channel, TS: result.TS}, nil
+}
+
+// update はchat.updateで投稿済みメッセージを書き換える
+func (b *botClient) update(ctx context.Context, ref MessageRef, message map[string]interface{}) error {
+	payload := make(map[string]interface{}, len(message)+2)
+	for k, v := range message {
+		payload[k] = v
+	}
+	payload["channel"] = ref.Channel
+	payload["ts"] = ref.TS
+
+	_, err := b.call(ctx, "chat.update", payload)
+	return err
+}
+
+// remember はツイートの投稿済みメッセージを記録
+func (b *botClient) remember(tweet twitter.Tweet, sent sentMessage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for id, m := range b.sent {
+		if now.Sub(m.postedAt) > sentRetention {
+			delete(b.sent, id)
+		}
+	}
+	sent.postedAt = now
+	b.sent[tweet.RootID()] = sent
+}
+
+// threadKey は銘柄スレッドのキー（投稿先チャンネルごとに分ける）
+func threadKey(message map[string]interface{}, ticker string) string {
+	ch, _ := message["channel"].(string)
+	return ch + "|" + ticker
+}
+
+// thread は当日の銘柄スレッドを返す
+func (b *botClient) thread(key string) (MessageRef, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.threadDay != time.Now().Format("2006-01-02") {
+		return MessageRef{}, false
+	}
+	ref, ok := b.threads[key]
+	return ref, ok
+}
+
+// startThread は銘柄スレッドの起点となるメッセージを記録（日付が変わったらリセット）
+func (b *botClient) startThread(key string, ref MessageRef) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if today := time.Now().Format("2006-01-02"); b.threadDay != today || b.threads == nil {
+		b.threadDay = today
+		b.threads = make(map[string]MessageRef)
+	}
+	b.threads[key] = ref
+}
+
+// lookup は最初の投稿のIDから投稿済みメッセージを返す
+func (b *botClient) lookup(rootID string) (sentMessage, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m, ok := b.sent[rootID]
+	return m, ok
+}
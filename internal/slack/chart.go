@@ -0,0 +1,25 @@
+package slack
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultChartURL は銘柄のチャート画像URLの既定テンプレート（{ticker} を銘柄に置換）
+const DefaultChartURL = "https://finviz.com/chart.ashx?t={ticker}&ty=c&ta=0&p=d&s=l"
+
+// WithChartImage は最初の銘柄のチャート画像を通知に添付する
+// urlTemplateの {ticker} は銘柄に置換される（画像はSlackが取得する）
+func WithChartImage(urlTemplate string) Option {
+	return func(s *Notifier) {
+		s.chartURL = urlTemplate
+	}
+}
+
+// chartImageURL は銘柄のチャート画像URLを返す（無効の場合は空）
+func (s *Notifier) chartImageURL(ticker string) string {
+	if s.chartURL == "" || ticker == "" {
+		return ""
+	}
+	return strings.ReplaceAll(s.chartURL, "{ticker}", url.QueryEscape(ticker))
+}
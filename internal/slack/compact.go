@@ -0,0 +1,64 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// WithCompact は全ての通知を1行の簡潔な形式で投稿する（振り分けルールや追加のWebhookごとにも指定可能）
+func WithCompact() Option {
+	return func(s *Notifier) {
+		s.compact = true
+	}
+}
+
+// compactFor は通知を簡潔な形式で投稿するかを返す（一致した振り分けルールの指定を優先）
+func (s *Notifier) compactFor(tweet twitter.Tweet, analysis *ai.Analysis) bool {
+	if r := s.matchRoute(tweet, analysis); r != nil && r.Compact {
+		return true
+	}
+	return s.compact
+}
+
+// compactMessage は1行の簡潔な形式（緊急度の絵文字、投稿者、スコア、銘柄、要約、リンク）でメッセージを構築
+func (s *Notifier) compactMessage(tweet twitter.Tweet, analysis *ai.Analysis, maybe bool) map[string]interface{} {
+	urgency := analysis.Urgency
+	if maybe {
+		urgency = "low"
+	}
+
+	var parts []string
+	if len(analysis.Tickers) > 0 {
+		parts = append(parts, "$"+strings.Join(analysis.Tickers, " $"))
+	}
+	if summary := strings.Join(strings.Fields(analysis.Summary), " "); summary != "" {
+		parts = append(parts, summary)
+	}
+
+	line := s.msg.text(MsgCompact,
+		s.getEmojiByUrgency(urgency),
+		tweet.Username,
+		analysis.Score,
+		strings.Join(parts, " "),
+		fmt.Sprintf("https://x.com/%s/status/%s", tweet.Username, tweet.ID),
+	)
+	if maybe {
+		line = s.msg.text(MsgMaybeTitle, analysis.Confidence, line)
+	} else if mention := s.mentions[analysis.Urgency]; mention != "" {
+		line = mention + " " + line
+	}
+
+	message := map[string]interface{}{
+		"username":     s.username,
+		"icon_emoji":   s.iconEmoji,
+		"text":         line,
+		"unfurl_links": false,
+	}
+	if channel := s.categories[analysis.Category].Channel; channel != "" {
+		message["channel"] = channel
+	}
+	return message
+}
@@ -0,0 +1,94 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// Destination は通知を複製して送る追加のWebhookと、その配信条件
+// 空でない条件を全て満たす通知のみ送る
+type Destination struct {
+	Name       string // ログ用の名前
+	WebhookURL string
+	MinScore   int      // 最低スコア（0の場合は条件なし）
+	Categories []string // いずれかのカテゴリ
+	Traders    []string // いずれかの投稿者
+	Tickers    []string // いずれかの銘柄を含む
+	Compact    bool     // 1行の簡潔な形式で送る
+}
+
+// WithDestinations は通知を条件に応じて追加のWebhookにも送る（通常の投稿先への通知とは独立）
+func WithDestinations(destinations []Destination) Option {
+	return func(s *Notifier) {
+		s.destinations = destinations
+	}
+}
+
+// matches は通知が配信条件を満たすかを返す
+func (d Destination) matches(tweet twitter.Tweet, analysis *ai.Analysis) bool {
+	if analysis.Score < d.MinScore {
+		return false
+	}
+	if len(d.Categories) > 0 && !containsFold(d.Categories, analysis.Category) {
+		return false
+	}
+	if len(d.Traders) > 0 && !containsFold(d.Traders, tweet.Username) {
+		return false
+	}
+	if len(d.Tickers) > 0 {
+		found := false
+		for _, t := range analysis.Tickers {
+			if containsFold(d.Tickers, t) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold はlistに大文字小文字・先頭の@と$を無視してvと一致する要素があるかを返す
+func containsFold(list []string, v string) bool {
+	v = strings.TrimLeft(v, "@$")
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimLeft(item, "@$"), v) {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyDestinations は条件を満たす追加の配信先にメッセージを送る
+// 配信先ごとの失敗はログに出力し、通常の通知の結果には影響させない
+func (s *Notifier) notifyDestinations(ctx context.Context, tweet twitter.Tweet, analysis *ai.Analysis, message map[string]interface{}) {
+	for _, d := range s.destinations {
+		if !d.matches(tweet, analysis) {
+			continue
+		}
+		source := message
+		if d.Compact {
+			source = s.compactMessage(tweet, analysis, false)
+		}
+		copied := make(map[string]interface{}, len(source))
+		for k, v := range source {
+			switch k {
+			case "channel", "thread_ts", "reply_broadcast":
+				// 投稿先固有の指定は引き継がない
+			default:
+				copied[k] = v
+			}
+		}
+		_, err := s.sendQueued(ctx, pendingMessage{key: "tweet:" + tweet.ID + ">" + d.Name, webhookURL: d.WebhookURL, message: copied, webhook: true})
+		if err != nil && !errors.Is(err, ErrQueued) {
+			log.Printf("Failed to notify Slack destination %s: %v", d.Name, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/digest"
+)
+
+// maxDigestGroups は1回のダイジェストに含める最大銘柄グループ数
+const maxDigestGroups = 20
+
+// NotifyDigest は溜めておいた通知を銘柄・カテゴリごとにまとめて1件のメッセージとして投稿
+func (s *Notifier) NotifyDigest(ctx context.Context, items []digest.Item, since time.Time) error {
+	groups := digest.Groups(items)
+	omitted := 0
+	if len(groups) > maxDigestGroups {
+		for _, g := range groups[maxDigestGroups:] {
+			omitted += len(g.Items)
+		}
+		groups = groups[:maxDigestGroups]
+	}
+
+	attachments := make([]map[string]interface{}, 0, len(groups))
+	for _, g := range groups {
+		attachment := map[string]interface{}{
+			"color": "#439FE0",
+			"title": s.msg.text(MsgDigestNoTicker, len(g.Items)),
+		}
+		if g.Ticker != digest.NoTicker {
+			attachment["title"] = s.msg.text(MsgDigestGroup, g.Ticker, len(g.Items))
+			attachment["title_link"] = fmt.Sprintf("https://finance.yahoo.com/quote/%s", g.Ticker)
+		}
+
+		lines := make([]string, len(g.Items))
+		for i, item := range g.Items {
+			a := item.Analysis
+			label := fmt.Sprintf("[%s]", a.Category)
+			if style := s.categories[a.Category]; style.Emoji != "" {
+				label = style.Emoji + " " + label
+			}
+			lines[i] = fmt.Sprintf("• %s %s (%d) <https://x.com/%s/status/%s|@%s> %s",
+				s.getEmojiByUrgency(a.Urgency), label, a.Score,
+				item.Tweet.Username, item.Tweet.ID, item.Tweet.Username, a.Summary)
+		}
+
+		attachment["text"] = strings.Join(lines, "\n")
+		attachments = append(attachments, attachment)
+	}
+
+	text := s.msg.text(MsgDigestHeader, since.Format("01/02 15:04"), len(items))
+	if omitted > 0 {
+		text += "\n" + s.msg.text(MsgDigestOmitted, omitted)
+	}
+
+	message := map[string]interface{}{
+		"username":    s.username,
+		"icon_emoji":  s.iconEmoji,
+		"text":        text,
+		"attachments": attachments,
+	}
+	return s.post(ctx, "digest:"+since.Format(time.RFC3339), s.webhookURL, message)
+}
@@ -0,0 +1,43 @@
+package slack
+
+import (
+	"fmt"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// maxMediaImages は1件の通知に含める添付画像の上限
+const maxMediaImages = 4
+
+// WithMedia はポストの添付画像（チャートや開示資料のスクリーンショットなど）を通知に含める
+func WithMedia() Option {
+	return func(s *Notifier) {
+		s.media = true
+	}
+}
+
+// mediaAttachments はポストの添付画像を画像ブロックのアタッチメントとして返す
+func (s *Notifier) mediaAttachments(tweet twitter.Tweet, color string) []map[string]interface{} {
+	if !s.media {
+		return nil
+	}
+	urls := tweet.ImageURLs()
+	if len(urls) > maxMediaImages {
+		urls = urls[:maxMediaImages]
+	}
+
+	attachments := make([]map[string]interface{}, 0, len(urls))
+	for i, url := range urls {
+		attachments = append(attachments, map[string]interface{}{
+			"color": color,
+			"blocks": []map[string]interface{}{
+				{
+					"type":      "image",
+					"image_url": url,
+					"alt_text":  fmt.Sprintf("@%s image %d", tweet.Username, i+1),
+				},
+			},
+		})
+	}
+	return attachments
+}
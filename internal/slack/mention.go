@@ -0,0 +1,40 @@
+package slack
+
+import "strings"
+
+// WithMentions は指定した緊急度の通知にメンションを付ける
+// targetsは @here, @channel, ユーザーグループID (S...), ユーザーID (U... / W...) またはSlackの書式 (<!subteam^S...>)
+func WithMentions(urgencies, targets []string) Option {
+	return func(s *Notifier) {
+		formatted := make([]string, 0, len(targets))
+		for _, t := range targets {
+			if m := formatMention(t); m != "" {
+				formatted = append(formatted, m)
+			}
+		}
+		if len(formatted) == 0 {
+			return
+		}
+		s.mentions = make(map[string]string, len(urgencies))
+		for _, u := range urgencies {
+			s.mentions[u] = strings.Join(formatted, " ")
+		}
+	}
+}
+
+// formatMention はメンション先をSlackの書式に変換
+func formatMention(target string) string {
+	target = strings.TrimPrefix(strings.TrimSpace(target), "@")
+	switch lower := strings.ToLower(target); {
+	case target == "":
+		return ""
+	case lower == "here" || lower == "channel" || lower == "everyone":
+		return "<!" + lower + ">"
+	case strings.HasPrefix(target, "<"):
+		return target
+	case strings.HasPrefix(target, "S"):
+		return "<!subteam^" + target + ">"
+	default:
+		return "<@" + target + ">"
+	}
+}
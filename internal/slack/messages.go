@@ -0,0 +1,212 @@
+package slack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage は表示言語が未指定の場合の言語
+const DefaultLanguage = "ja"
+
+// Messages はSlack通知の表示文言（キー -> fmtの書式文字列）
+// 引数の順序が言語で異なる場合は %[2]d のような明示的な添字を使う
+type Messages map[string]string
+
+// 表示文言のキー（括弧内は書式の引数）
+const (
+	MsgTitle                = "title"       // (緊急度の絵文字, カテゴリ, スコア)
+	MsgMaybeTitle           = "maybe_title" // (確信度, 元のタイトル)
+	MsgFieldSummary         = "field_summary"
+	MsgFieldSentiment       = "field_sentiment"
+	MsgFieldTickers         = "field_tickers"
+	MsgFieldKeyPoints       = "field_key_points"
+	MsgButtonViewPost       = "button_view_post"
+	MsgButtonChart          = "button_chart"
+	MsgButtonUseful         = "button_useful"
+	MsgButtonNoise          = "button_noise"
+	MsgFooter               = "footer"
+	MsgFooterVariant        = "footer_variant" // (バリアント名)
+	MsgSimple               = "simple"         // (ユーザー名, 本文, ポストのURL)
+	MsgBullish              = "bullish"        // センチメントの表示名（絵文字は別途付加）
+	MsgBearish              = "bearish"
+	MsgNeutral              = "neutral"
+	MsgUnknown              = "unknown"
+	MsgDigestHeader         = "digest_header"    // (開始時刻, 件数)
+	MsgDigestOmitted        = "digest_omitted"   // (省略した件数)
+	MsgDigestGroup          = "digest_group"     // (銘柄, 件数)
+	MsgDigestNoTicker       = "digest_no_ticker" // (件数)
+	MsgSentimentHeader      = "sentiment_header" // (日付)
+	MsgSentimentTitle       = "sentiment_title"  // (銘柄, 言及数, 平均スコア)
+	MsgSentimentCounts      = "sentiment_counts" // (強気, 弱気, 中立の件数)
+	MsgStatsHeader          = "stats_header"     // (日付)
+	MsgStatsPosts           = "stats_posts"
+	MsgStatsPostsValue      = "stats_posts_value" // (取得, 処理の件数)
+	MsgStatsNotified        = "stats_notified"
+	MsgStatsNotifiedValue   = "stats_notified_value" // (通知, 要確認, ダイジェスト, 更新の件数)
+	MsgStatsSuppressed      = "stats_suppressed"
+	MsgStatsSuppressedValue = "stats_suppressed_value" // (スコア不足, 重複の件数)
+	MsgStatsTopTickers      = "stats_top_tickers"
+	MsgStatsTopTraders      = "stats_top_traders"
+	MsgStatsCategories      = "stats_categories"
+	MsgStatsRollupValue     = "stats_rollup_value" // (名前, 通知, 処理の件数, 平均スコア)
+	MsgStatsUsage           = "stats_usage"
+	MsgStatsUsageValue      = "stats_usage_value" // (AI呼び出し回数, AIコスト, X APIリクエスト数)
+	MsgStatsErrors          = "stats_errors"
+	MsgStatsErrorsValue     = "stats_errors_value" // (取得, AI, 通知のエラー件数)
+	MsgAlertTitle           = "alert_title"        // (アラートの種類)
+	MsgAlertSuppressed      = "alert_suppressed"   // (間引いた件数)
+	MsgMetrics              = "metrics"            // (いいね, リポスト, 表示回数)
+	MsgFollowers            = "followers"          // (フォロワー数)
+	MsgCompact              = "compact"            // (緊急度の絵文字, ユーザー名, スコア, 銘柄と要約, ポストのURL)
+)
+
+// bundles は組み込みの言語ごとの表示文言
+var bundles = map[string]Messages{
+	"ja": {
+		MsgTitle:                "%s %s スコア: %d/100",
+		MsgMaybeTitle:           "🤔 [要確認 確信度: %d/100] %s",
+		MsgFieldSummary:         "📝 AI分析サマリー",
+		MsgFieldSentiment:       "💹 センチメント",
+		MsgFieldTickers:         "🎯 関連銘柄",
+		MsgFieldKeyPoints:       "📌 重要ポイント",
+		MsgButtonViewPost:       "🔗 ポストを見る",
+		MsgButtonChart:          "📊 チャート",
+		MsgButtonUseful:         "👍 有用",
+		MsgButtonNoise:          "👎 ノイズ",
+		MsgFooter:               "X Trading Crawler",
+		MsgFooterVariant:        " | variant: %s",
+		MsgSimple:               "*@%s* さんの新しい投稿:\n%s\n\n🔗 <%s|ポストを見る>",
+		MsgBullish:              "強気",
+		MsgBearish:              "弱気",
+		MsgNeutral:              "中立",
+		MsgUnknown:              "不明",
+		MsgDigestHeader:         "🗞️ *ダイジェスト: %s 以降の通知 %d件*",
+		MsgDigestOmitted:        "（他 %d件は省略）",
+		MsgDigestGroup:          "$%s (%d件)",
+		MsgDigestNoTicker:       "銘柄なし (%d件)",
+		MsgSentimentHeader:      "📊 *%s の銘柄別センチメント*",
+		MsgSentimentTitle:       "$%s  言及: %d件 / 平均スコア: %.1f",
+		MsgSentimentCounts:      "📈 強気 %d / 📉 弱気 %d / ➡️ 中立 %d",
+		MsgStatsHeader:          "📋 *%s のクロール統計*",
+		MsgStatsPosts:           "📥 ポスト",
+		MsgStatsPostsValue:      "取得 %d / 未読 %d",
+		MsgStatsNotified:        "🔔 通知",
+		MsgStatsNotifiedValue:   "通知 %d / 要確認 %d / ダイジェスト %d / 更新 %d",
+		MsgStatsSuppressed:      "🔕 通知せず",
+		MsgStatsSuppressedValue: "スコア不足 %d / 重複 %d",
+		MsgStatsTopTickers:      "🎯 通知の多い銘柄",
+		MsgStatsTopTraders:      "👤 通知の多いトレーダー",
+		MsgStatsCategories:      "🗂 カテゴリ別",
+		MsgStatsRollupValue:     "%s %d/%d件 (平均 %.0f点)",
+		MsgStatsUsage:           "💸 API使用量",
+		MsgStatsUsageValue:      "AI %d回 ($%.2f) / X API %dリクエスト",
+		MsgStatsErrors:          "⚠️ エラー",
+		MsgStatsErrorsValue:     "取得 %d / AI分析 %d / 通知 %d",
+		MsgAlertTitle:           "🚨 運用アラート: %s",
+		MsgAlertSuppressed:      "（前回のアラート以降、同じ種類の %d件を省略）",
+		MsgMetrics:              "❤️ %s  🔁 %s  👁️ %s",
+		MsgFollowers:            "👥 フォロワー %s",
+		MsgCompact:              "%s *@%s* %d/100 %s <%s|ポストを見る>",
+	},
+	"en": {
+		MsgTitle:                "%s %s Score: %d/100",
+		MsgMaybeTitle:           "🤔 [Needs review, confidence: %d/100] %s",
+		MsgFieldSummary:         "📝 AI Summary",
+		MsgFieldSentiment:       "💹 Sentiment",
+		MsgFieldTickers:         "🎯 Tickers",
+		MsgFieldKeyPoints:       "📌 Key Points",
+		MsgButtonViewPost:       "🔗 View post",
+		MsgButtonChart:          "📊 Chart",
+		MsgButtonUseful:         "👍 Useful",
+		MsgButtonNoise:          "👎 Noise",
+		MsgFooter:               "X Trading Crawler",
+		MsgFooterVariant:        " | variant: %s",
+		MsgSimple:               "New post from *@%s*:\n%s\n\n🔗 <%s|View post>",
+		MsgBullish:              "Bullish",
+		MsgBearish:              "Bearish",
+		MsgNeutral:              "Neutral",
+		MsgUnknown:              "Unknown",
+		MsgDigestHeader:         "🗞️ *Digest: %[2]d notifications since %[1]s*",
+		MsgDigestOmitted:        "(%d more omitted)",
+		MsgDigestGroup:          "$%s (%d)",
+		MsgDigestNoTicker:       "No ticker (%d)",
+		MsgSentimentHeader:      "📊 *Ticker sentiment for %s*",
+		MsgSentimentTitle:       "$%s  Mentions: %d / Avg score: %.1f",
+		MsgSentimentCounts:      "📈 Bullish %d / 📉 Bearish %d / ➡️ Neutral %d",
+		MsgStatsHeader:          "📋 *Crawl statistics for %s*",
+		MsgStatsPosts:           "📥 Posts",
+		MsgStatsPostsValue:      "Fetched %d / New %d",
+		MsgStatsNotified:        "🔔 Notifications",
+		MsgStatsNotifiedValue:   "Notified %d / Maybe %d / Digest %d / Updated %d",
+		MsgStatsSuppressed:      "🔕 Suppressed",
+		MsgStatsSuppressedValue: "Low score %d / Duplicate %d",
+		MsgStatsTopTickers:      "🎯 Most notified tickers",
+		MsgStatsTopTraders:      "👤 Most notified traders",
+		MsgStatsCategories:      "🗂 By category",
+		MsgStatsRollupValue:     "%s %d/%d (avg %.0f)",
+		MsgStatsUsage:           "💸 API usage",
+		MsgStatsUsageValue:      "AI %d calls ($%.2f) / X API %d requests",
+		MsgStatsErrors:          "⚠️ Errors",
+		MsgStatsErrorsValue:     "Fetch %d / AI analysis %d / Notify %d",
+		MsgAlertTitle:           "🚨 Ops alert: %s",
+		MsgAlertSuppressed:      "(%d similar alerts suppressed since the last one)",
+		MsgMetrics:              "❤️ %s  🔁 %s  👁️ %s",
+		MsgFollowers:            "👥 %s followers",
+		MsgCompact:              "%s *@%s* %d/100 %s <%s|View post>",
+	},
+}
+
+// HasLanguage は組み込みの表示文言がある言語かを返す
+func HasLanguage(lang string) bool {
+	_, ok := bundles[strings.ToLower(lang)]
+	return ok
+}
+
+// LoadMessages は言語の表示文言に個別の上書きを適用して返す（langが空の場合はDefaultLanguage）
+func LoadMessages(lang string, overrides map[string]string) (Messages, error) {
+	if lang == "" {
+		lang = DefaultLanguage
+	}
+	base, ok := bundles[strings.ToLower(lang)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported language %q (available: %s)", lang, strings.Join(languages(), ", "))
+	}
+
+	m := make(Messages, len(base))
+	for k, v := range base {
+		m[k] = v
+	}
+	for k, v := range overrides {
+		if _, ok := base[k]; !ok {
+			return nil, fmt.Errorf("unknown message key %q", k)
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+// WithMessages は通知の表示文言を指定
+func WithMessages(m Messages) Option {
+	return func(s *Notifier) {
+		s.msg = m
+	}
+}
+
+// text は表示文言を書式に従って返す
+func (m Messages) text(key string, args ...interface{}) string {
+	if len(args) == 0 {
+		return m[key]
+	}
+	return fmt.Sprintf(m[key], args...)
+}
+
+// languages は組み込みの言語コードを返す
+func languages() []string {
+	langs := make([]string, 0, len(bundles))
+	for lang := range bundles {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
@@ -0,0 +1,44 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// WithMetrics は通知にポストのエンゲージメント（いいね・リポスト・表示回数）と
+// 投稿者のフォロワー数（キーワード検索の場合）を表示する
+func WithMetrics() Option {
+	return func(s *Notifier) {
+		s.metrics = true
+	}
+}
+
+// metricsLine はエンゲージメントの1行表示を返す（無効または指標がない場合は空）
+func (s *Notifier) metricsLine(tweet twitter.Tweet) string {
+	if !s.metrics {
+		return ""
+	}
+	var parts []string
+	if m := tweet.Metrics; m != nil {
+		parts = append(parts, s.msg.text(MsgMetrics,
+			compactNumber(m.LikeCount), compactNumber(m.RetweetCount), compactNumber(m.ImpressionCount)))
+	}
+	if tweet.AuthorFollowers > 0 {
+		parts = append(parts, s.msg.text(MsgFollowers, compactNumber(tweet.AuthorFollowers)))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// compactNumber は数値を「1.2K」「3.4M」のような短い表記で返す
+func compactNumber(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1_000_000), ".0") + "M"
+	case n >= 1_000:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1_000), ".0") + "K"
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
@@ -7,9 +7,12 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/quote"
 	"github.com/Minatonton/x-crawler/internal/twitter"
 )
 
@@ -18,31 +21,212 @@ type Notifier struct {
 	webhookURL string
 	username   string
 	iconEmoji  string
+	categories map[string]CategoryStyle
 	httpClient *http.Client
+
+	maybeWebhookURL string
+	feedbackURL     func(tweetID, label string) string
+	feedbackAction  string // インタラクティブなフィードバックボタンのアクション名
+
+	bot          *botClient // botモードの場合のみ設定
+	maybeChannel string
+	routesMu     sync.RWMutex // routes と traders（設定の再読み込みで置き換える）
+	routes       []Route
+	destinations []Destination // 条件に応じて複製を送る追加のWebhook
+
+	opsWebhookURL string // 運用向けの通知の投稿先（空の場合は通常の投稿先）
+	opsChannel    string
+	alerts        *alerter // nilの場合は運用アラートを送らない
+
+	tickerThreads bool
+	msg           Messages // 表示文言
+
+	mentions map[string]string // 緊急度 -> メンション（Slackの書式）
+	quotes   quote.Provider    // 関連銘柄の株価（nilの場合は表示しない）
+	chartURL string            // チャート画像URLのテンプレート（空の場合は添付しない）
+	media    bool              // ポストの添付画像を含める
+	metrics  bool              // エンゲージメントを表示する
+	compact  bool              // 1行の簡潔な形式で投稿する
+
+	template *template.Template        // ペイロード全体のテンプレート（nilの場合は組み込みの形式）
+	traders  map[string]TemplateTrader // ユーザー名（小文字） -> テンプレートに渡すトレーダー
+
+	urgencyStyles   map[string]Style // 緊急度ごとの絵文字・色
+	sentimentStyles map[string]Style // センチメントごとの絵文字・色
+
+	pendingMu sync.Mutex
+	pending   []pendingMessage          // 一時的なエラーで送れなかったメッセージ
+	queue     *QueueStore               // nilの場合は送信待ちを保存しない
+	inflight  map[string]pendingMessage // 送信中のメッセージ（queueがある場合のみ）
+	delivered map[string]time.Time      // 送信済みの冪等キー
+	seq       int
+}
+
+// CategoryStyle はカテゴリごとの表示・配信設定
+type CategoryStyle struct {
+	Emoji   string // タイトルに付ける絵文字
+	Color   string // アタッチメントの色（緊急度の色より優先）
+	Channel string // 投稿先チャンネル（botモード、またはWebhookが上書きを許可している場合のみ有効）
+}
+
+// Option はNotifierの任意設定
+type Option func(*Notifier)
+
+// WithCategoryStyles はカテゴリごとの表示設定を指定
+func WithCategoryStyles(styles map[string]CategoryStyle) Option {
+	return func(s *Notifier) {
+		s.categories = styles
+	}
+}
+
+// WithMaybeWebhook は確信度の低い通知の投稿先Webhookを指定
+func WithMaybeWebhook(webhookURL string) Option {
+	return func(s *Notifier) {
+		s.maybeWebhookURL = webhookURL
+	}
+}
+
+// WithFeedbackLinks は通知に「有用/ノイズ」のフィードバックボタンを追加
+// urlFuncはツイートIDとラベル (useful, noise) からフィードバックURLを返す
+func WithFeedbackLinks(urlFunc func(tweetID, label string) string) Option {
+	return func(s *Notifier) {
+		s.feedbackURL = urlFunc
+	}
+}
+
+// WithInteractiveFeedback は通知にSlackのインタラクティブな「有用/ノイズ」ボタンを追加
+// ボタンの操作はSlackアプリのInteractivity Request URLに送られる（valueは "ラベル:ツイートID"）
+func WithInteractiveFeedback(actionName string) Option {
+	return func(s *Notifier) {
+		s.feedbackAction = actionName
+	}
 }
 
 // NewNotifier は新しいSlackNotifierを作成
-func NewNotifier(webhookURL, username, iconEmoji string) *Notifier {
-	return &Notifier{
-		webhookURL: webhookURL,
-		username:   username,
-		iconEmoji:  iconEmoji,
+func NewNotifier(webhookURL, username, iconEmoji string, opts ...Option) *Notifier {
+	s := &Notifier{
+		msg:             bundles[DefaultLanguage],
+		urgencyStyles:   defaultUrgencyStyles,
+		sentimentStyles: defaultSentimentStyles,
+		webhookURL:      webhookURL,
+		username:        username,
+		iconEmoji:       iconEmoji,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // NotifyTweet はツイートをSlackに通知
 func (s *Notifier) NotifyTweet(ctx context.Context, tweet twitter.Tweet, analysis *ai.Analysis) error {
-	message := s.buildMessage(tweet, analysis)
+	message := s.buildMessage(tweet, analysis, s.fetchQuotes(ctx, analysis.Tickers))
+	s.notifyDestinations(ctx, tweet, analysis, message)
+	compact := s.compactFor(tweet, analysis)
+	if compact {
+		message = s.compactMessage(tweet, analysis, false)
+	}
+	webhookURL := s.route(tweet, analysis, message)
+
+	// 同じ銘柄の当日の通知があればそのスレッドに返信
+	var key string
+	threaded := false
+	if s.bot != nil && s.tickerThreads && len(analysis.Tickers) > 0 {
+		key = threadKey(message, analysis.Tickers[0])
+		if parent, ok := s.bot.thread(key); ok {
+			message["channel"] = parent.Channel
+			message["thread_ts"] = parent.TS
+			if analysis.Urgency == "critical" {
+				message["reply_broadcast"] = true // 緊急の続報はチャンネルにも表示
+			}
+			threaded = true
+		}
+	}
+
+	ref, err := s.sendQueued(ctx, pendingMessage{key: "tweet:" + tweet.ID, webhookURL: webhookURL, message: message, tweet: &tweet})
+	if err != nil {
+		return err
+	}
+	if ref != nil {
+		s.bot.remember(tweet, sentMessage{ref: *ref, compact: compact})
+		if key != "" && !threaded {
+			s.bot.startThread(key, *ref)
+		}
+	}
+	return nil
+}
+
+// NotifyMaybe は確信度の低い分析結果を「要確認」として通知
+// maybe用の投稿先が設定されていればそちらに、なければ通常の振り分けに従って低緊急度で投稿する
+func (s *Notifier) NotifyMaybe(ctx context.Context, tweet twitter.Tweet, analysis *ai.Analysis) error {
+	compact := s.compactFor(tweet, analysis)
+	var message map[string]interface{}
+	if compact {
+		message = s.compactMessage(tweet, analysis, true)
+	} else {
+		message = s.buildMaybeMessage(tweet, analysis, s.fetchQuotes(ctx, analysis.Tickers))
+	}
+
+	webhookURL := s.route(tweet, analysis, message)
+	if s.maybeWebhookURL != "" {
+		webhookURL = s.maybeWebhookURL
+		delete(message, "channel")
+	}
+	if s.bot != nil && s.maybeChannel != "" {
+		message["channel"] = s.maybeChannel
+	}
+
+	ref, err := s.sendQueued(ctx, pendingMessage{key: "maybe:" + tweet.ID, webhookURL: webhookURL, message: message, tweet: &tweet, maybe: true})
+	if err != nil {
+		return err
+	}
+	if ref != nil {
+		s.bot.remember(tweet, sentMessage{ref: *ref, maybe: true, compact: compact})
+	}
+	return nil
+}
+
+// buildMaybeMessage は「要確認」通知のメッセージを構築
+func (s *Notifier) buildMaybeMessage(tweet twitter.Tweet, analysis *ai.Analysis, quotes map[string]*quote.Quote) map[string]interface{} {
+	demoted := *analysis
+	demoted.Urgency = "low"
+	if message := s.renderTemplate(tweet, &demoted, quotes, true); message != nil {
+		return message
+	}
+	message := s.defaultMessage(tweet, &demoted, quotes)
+
+	attachment := message["attachments"].([]map[string]interface{})[0]
+	attachment["title"] = s.msg.text(MsgMaybeTitle, analysis.Confidence, attachment["title"])
+	return message
+}
 
+// post はメッセージを送信（一時的なエラーの場合は再送キューに入れてErrQueuedを返す）
+// keyは冪等キー（空の場合は重複を確認しない）
+func (s *Notifier) post(ctx context.Context, key, webhookURL string, message map[string]interface{}) error {
+	_, err := s.sendQueued(ctx, pendingMessage{key: key, webhookURL: webhookURL, message: message})
+	return err
+}
+
+// sendOnce はbotモードではWeb API、それ以外ではWebhookでメッセージを1回送信
+// botモードの場合のみ投稿したメッセージの参照を返す
+func (s *Notifier) sendOnce(ctx context.Context, p pendingMessage) (*MessageRef, error) {
+	if s.bot != nil && !p.webhook {
+		return s.bot.postMessage(ctx, p.message)
+	}
+	return nil, s.postWebhook(ctx, p.webhookURL, p.message)
+}
+
+// postWebhook はWebhookにメッセージを送信
+func (s *Notifier) postWebhook(ctx context.Context, webhookURL string, message map[string]interface{}) error {
 	jsonData, err := json.Marshal(message)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", s.webhookURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return err
 	}
@@ -56,28 +240,48 @@ func (s *Notifier) NotifyTweet(ctx context.Context, tweet twitter.Tweet, analysi
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Slack webhook returned status %d", resp.StatusCode)
+		return newStatusError(resp, fmt.Sprintf("Slack webhook returned status %d", resp.StatusCode))
 	}
 
 	return nil
 }
 
-// buildMessage はSlackメッセージを構築
-func (s *Notifier) buildMessage(tweet twitter.Tweet, analysis *ai.Analysis) map[string]interface{} {
+// buildMessage はSlackメッセージを構築（quotesは銘柄ごとの株価、nil可）
+func (s *Notifier) buildMessage(tweet twitter.Tweet, analysis *ai.Analysis, quotes map[string]*quote.Quote) map[string]interface{} {
+	if message := s.renderTemplate(tweet, analysis, quotes, false); message != nil {
+		return message
+	}
+	return s.defaultMessage(tweet, analysis, quotes)
+}
+
+// defaultMessage は組み込みの形式でSlackメッセージを構築
+func (s *Notifier) defaultMessage(tweet twitter.Tweet, analysis *ai.Analysis, quotes map[string]*quote.Quote) map[string]interface{} {
 	emoji := s.getEmojiByUrgency(analysis.Urgency)
 	color := s.getColorByUrgency(analysis.Urgency)
 	sentimentEmoji := s.getSentimentEmoji(analysis.Sentiment)
+	style := s.categories[analysis.Category]
+
+	categoryLabel := fmt.Sprintf("[%s]", analysis.Category)
+	if style.Emoji != "" {
+		categoryLabel = style.Emoji + " " + categoryLabel
+	}
+	if style.Color != "" {
+		color = style.Color
+	}
 
 	// ティッカーリンクを生成
 	tickerLinks := make([]string, len(analysis.Tickers))
 	for i, ticker := range analysis.Tickers {
 		tickerLinks[i] = fmt.Sprintf("<https://finance.yahoo.com/quote/%s|$%s>", ticker, ticker)
+		if q := quotes[ticker]; q != nil {
+			tickerLinks[i] += " " + formatQuote(q)
+		}
 	}
 
 	// フィールドを構築
 	fields := []map[string]interface{}{
 		{
-			"title": "📝 AI分析サマリー",
+			"title": s.msg.text(MsgFieldSummary),
 			"value": analysis.Summary,
 			"short": false,
 		},
@@ -85,16 +289,20 @@ func (s *Notifier) buildMessage(tweet twitter.Tweet, analysis *ai.Analysis) map[
 
 	if analysis.Sentiment != "" {
 		fields = append(fields, map[string]interface{}{
-			"title": "💹 センチメント",
+			"title": s.msg.text(MsgFieldSentiment),
 			"value": sentimentEmoji,
 			"short": true,
 		})
 	}
 
 	if len(tickerLinks) > 0 {
+		sep := ", "
+		if len(quotes) > 0 {
+			sep = "\n" // 株価付きの場合は1行に1銘柄
+		}
 		fields = append(fields, map[string]interface{}{
-			"title": "🎯 関連銘柄",
-			"value": strings.Join(tickerLinks, ", "),
+			"title": s.msg.text(MsgFieldTickers),
+			"value": strings.Join(tickerLinks, sep),
 			"short": true,
 		})
 	}
@@ -102,26 +310,34 @@ func (s *Notifier) buildMessage(tweet twitter.Tweet, analysis *ai.Analysis) map[
 	if len(analysis.KeyPoints) > 0 {
 		points := "• " + strings.Join(analysis.KeyPoints, "\n• ")
 		fields = append(fields, map[string]interface{}{
-			"title": "📌 重要ポイント",
+			"title": s.msg.text(MsgFieldKeyPoints),
 			"value": points,
 			"short": false,
 		})
 	}
 
+	footer := s.msg.text(MsgFooter)
+	if analysis.Variant != "" {
+		footer += s.msg.text(MsgFooterVariant, analysis.Variant)
+	}
+	if line := s.metricsLine(tweet); line != "" {
+		footer = line + " | " + footer
+	}
+
 	// アタッチメントを構築
 	attachment := map[string]interface{}{
 		"color":       color,
 		"author_name": fmt.Sprintf("@%s", tweet.Username),
-		"title":       fmt.Sprintf("%s [%s] スコア: %d/100", emoji, analysis.Category, analysis.Score),
+		"title":       s.msg.text(MsgTitle, emoji, categoryLabel, analysis.Score),
 		"text":        tweet.Text,
 		"fields":      fields,
-		"footer":      "X Trading Crawler",
+		"footer":      footer,
 		"footer_icon": "https://abs.twimg.com/icons/apple-touch-icon-192x192.png",
 		"ts":          tweet.CreatedAt.Unix(),
 		"actions": []map[string]interface{}{
 			{
 				"type":  "button",
-				"text":  "🔗 ポストを見る",
+				"text":  s.msg.text(MsgButtonViewPost),
 				"url":   fmt.Sprintf("https://x.com/%s/status/%s", tweet.Username, tweet.ID),
 				"style": "primary",
 			},
@@ -132,21 +348,65 @@ func (s *Notifier) buildMessage(tweet twitter.Tweet, analysis *ai.Analysis) map[
 	if len(analysis.Tickers) > 0 {
 		attachment["actions"] = append(attachment["actions"].([]map[string]interface{}), map[string]interface{}{
 			"type": "button",
-			"text": "📊 チャート",
+			"text": s.msg.text(MsgButtonChart),
 			"url":  fmt.Sprintf("https://www.tradingview.com/chart/?symbol=%s", analysis.Tickers[0]),
 		})
+		if imageURL := s.chartImageURL(analysis.Tickers[0]); imageURL != "" {
+			attachment["image_url"] = imageURL
+		}
+	}
+
+	// フィードバックボタンを追加
+	if s.feedbackAction != "" {
+		attachment["callback_id"] = s.feedbackAction
+		attachment["actions"] = append(attachment["actions"].([]map[string]interface{}),
+			map[string]interface{}{
+				"type":  "button",
+				"name":  s.feedbackAction,
+				"text":  s.msg.text(MsgButtonUseful),
+				"value": "useful:" + tweet.ID,
+			},
+			map[string]interface{}{
+				"type":  "button",
+				"name":  s.feedbackAction,
+				"text":  s.msg.text(MsgButtonNoise),
+				"value": "noise:" + tweet.ID,
+			},
+		)
+	} else if s.feedbackURL != nil {
+		attachment["actions"] = append(attachment["actions"].([]map[string]interface{}),
+			map[string]interface{}{
+				"type": "button",
+				"text": s.msg.text(MsgButtonUseful),
+				"url":  s.feedbackURL(tweet.ID, "useful"),
+			},
+			map[string]interface{}{
+				"type": "button",
+				"text": s.msg.text(MsgButtonNoise),
+				"url":  s.feedbackURL(tweet.ID, "noise"),
+			},
+		)
 	}
 
-	return map[string]interface{}{
+	message := map[string]interface{}{
 		"username":    s.username,
 		"icon_emoji":  s.iconEmoji,
-		"attachments": []map[string]interface{}{attachment},
+		"attachments": append([]map[string]interface{}{attachment}, s.mediaAttachments(tweet, color)...),
+	}
+	if style.Channel != "" {
+		message["channel"] = style.Channel
+	}
+	// 緊急の通知はメンションで知らせる（アタッチメント内のメンションは通知されないため本文に付ける）
+	if mention := s.mentions[analysis.Urgency]; mention != "" {
+		message["text"] = mention
 	}
+
+	return message
 }
 
 // NotifySimple はシンプルな通知（AI分析なし）
 func (s *Notifier) NotifySimple(ctx context.Context, tweet twitter.Tweet, traderInfo string) error {
-	text := fmt.Sprintf("*@%s* さんの新しい投稿:\n%s\n\n🔗 <%s|ポストを見る>",
+	text := s.msg.text(MsgSimple,
 		tweet.Username,
 		tweet.Text,
 		fmt.Sprintf("https://x.com/%s/status/%s", tweet.Username, tweet.ID),
@@ -158,73 +418,29 @@ func (s *Notifier) NotifySimple(ctx context.Context, tweet twitter.Tweet, trader
 		"text":       text,
 	}
 
-	jsonData, err := json.Marshal(message)
-	if err != nil {
-		return err
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", s.webhookURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Slack webhook returned status %d", resp.StatusCode)
-	}
-
-	return nil
+	return s.post(ctx, "simple:"+tweet.ID, s.route(tweet, nil, message), message)
 }
 
 // getEmojiByUrgency は緊急度に応じた絵文字を返す
 func (s *Notifier) getEmojiByUrgency(urgency string) string {
-	switch urgency {
-	case "critical":
-		return "🚨"
-	case "high":
-		return "⚠️"
-	case "normal":
-		return "💡"
-	case "low":
-		return "ℹ️"
-	default:
-		return "💡"
-	}
+	return s.urgencyStyle(urgency).Emoji
 }
 
 // getColorByUrgency は緊急度に応じた色を返す
 func (s *Notifier) getColorByUrgency(urgency string) string {
-	switch urgency {
-	case "critical":
-		return "#FF0000" // 赤
-	case "high":
-		return "#FF9900" // オレンジ
-	case "normal":
-		return "#36A64F" // 緑
-	case "low":
-		return "#808080" // グレー
-	default:
-		return "#36A64F"
-	}
+	return s.urgencyStyle(urgency).Color
 }
 
-// getSentimentEmoji はセンチメントに応じた絵文字を返す
+// getSentimentEmoji はセンチメントに応じた絵文字と表示名を返す
 func (s *Notifier) getSentimentEmoji(sentiment string) string {
+	key := MsgUnknown
 	switch sentiment {
 	case "bullish":
-		return "📈 強気"
+		key = MsgBullish
 	case "bearish":
-		return "📉 弱気"
+		key = MsgBearish
 	case "neutral":
-		return "➡️ 中立"
-	default:
-		return "❓ 不明"
+		key = MsgNeutral
 	}
+	return s.sentimentStyle(sentiment).Emoji + " " + s.msg.text(key)
 }
@@ -0,0 +1,171 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/storage"
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// deliveredRetention は送信済みの冪等キーを保持する期間
+const deliveredRetention = 24 * time.Hour
+
+// queueEntry は保存された送信待ちのメッセージ
+type queueEntry struct {
+	ID         string                 `json:"id"`
+	Key        string                 `json:"key,omitempty"`
+	WebhookURL string                 `json:"webhook_url,omitempty"`
+	Message    map[string]interface{} `json:"message"`
+	Tweet      *twitter.Tweet         `json:"tweet,omitempty"`
+	Maybe      bool                   `json:"maybe,omitempty"`
+	Webhook    bool                   `json:"webhook,omitempty"`
+	QueuedAt   time.Time              `json:"queued_at"`
+}
+
+// queueFile はQueueStoreの保存形式
+type queueFile struct {
+	Pending   []queueEntry         `json:"pending"`   // 一時的なエラーで送れなかったメッセージ
+	InFlight  []queueEntry         `json:"in_flight"` // 送信中だったメッセージ（異常終了した場合に残る）
+	Delivered map[string]time.Time `json:"delivered"` // 冪等キー -> 送信した時刻
+}
+
+// QueueBackend は送信待ちのメッセージ（JSON）の保存先
+type QueueBackend interface {
+	ReadQueue() ([]byte, error) // 保存されていない場合は nil, nil
+	WriteQueue(data []byte) error
+}
+
+// QueueStore は送信待ちのメッセージを保存し、再起動後も再送できるようにする
+type QueueStore struct {
+	backend QueueBackend
+	loaded  queueFile
+}
+
+// NewQueueStore は新しいQueueStoreを作成（ファイルがあれば前回の送信待ちを読み込む）
+func NewQueueStore(filePath string) (*QueueStore, error) {
+	return NewQueueStoreWith(queueFilePath(filePath))
+}
+
+// NewQueueStoreWith は任意の保存先（Redisなど）を使うQueueStoreを作成（前回の送信待ちを読み込む）
+func NewQueueStoreWith(backend QueueBackend) (*QueueStore, error) {
+	q := &QueueStore{backend: backend}
+
+	data, err := backend.ReadQueue()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification queue: %w", err)
+	}
+	if data == nil {
+		return q, nil
+	}
+	// 本文・メッセージ・Webhook URLを含むため、storage.encryption の場合は暗号化して保存する
+	if data, err = storage.DecryptFile(data); err != nil {
+		return nil, fmt.Errorf("failed to decrypt notification queue: %w", err)
+	}
+	if err := json.Unmarshal(data, &q.loaded); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal notification queue: %w", err)
+	}
+	return q, nil
+}
+
+// WithQueueStore は送信待ちのメッセージをファイルに保存する
+// 送信前に記録し、送信できたものから取り除くため、異常終了やSlackの障害でも通知が失われない
+// 前回の送信待ちは次のRetryPendingで（新しい通知より先に）再送される
+func WithQueueStore(q *QueueStore) Option {
+	return func(s *Notifier) {
+		s.queue = q
+		s.inflight = make(map[string]pendingMessage)
+		s.delivered = make(map[string]time.Time)
+
+		entries := append(append([]queueEntry(nil), q.loaded.InFlight...), q.loaded.Pending...)
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].QueuedAt.Before(entries[j].QueuedAt)
+		})
+		for _, e := range entries {
+			s.pending = append(s.pending, e.pendingMessage())
+		}
+		for key, at := range q.loaded.Delivered {
+			s.delivered[key] = at
+		}
+		q.loaded = queueFile{}
+	}
+}
+
+// pendingMessage は保存形式から送信待ちのメッセージに戻す
+func (e queueEntry) pendingMessage() pendingMessage {
+	return pendingMessage{
+		id:         e.ID,
+		key:        e.Key,
+		webhookURL: e.WebhookURL,
+		message:    e.Message,
+		tweet:      e.Tweet,
+		maybe:      e.Maybe,
+		webhook:    e.Webhook,
+		queuedAt:   e.QueuedAt,
+	}
+}
+
+// entry は送信待ちのメッセージを保存形式に変換
+func (p pendingMessage) entry() queueEntry {
+	return queueEntry{
+		ID:         p.id,
+		Key:        p.key,
+		WebhookURL: p.webhookURL,
+		Message:    p.message,
+		Tweet:      p.tweet,
+		Maybe:      p.maybe,
+		Webhook:    p.webhook,
+		QueuedAt:   p.queuedAt,
+	}
+}
+
+// save は送信待ちのメッセージを保存先に書き込む
+func (q *QueueStore) save(file queueFile) error {
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification queue: %w", err)
+	}
+	if data, err = storage.EncryptFile(data); err != nil {
+		return fmt.Errorf("failed to encrypt notification queue: %w", err)
+	}
+	return q.backend.WriteQueue(data)
+}
+
+// queueFilePath は送信待ちのメッセージをJSONファイルに保存するQueueBackend
+type queueFilePath string
+
+// ReadQueue はファイルを読み込む（存在しない場合は nil, nil）
+func (path queueFilePath) ReadQueue() ([]byte, error) {
+	data, err := os.ReadFile(string(path))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// WriteQueue はファイルに書き込む（一時ファイルに書いてから置き換える）
+func (path queueFilePath) WriteQueue(data []byte) error {
+	filePath := string(path)
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write notification queue: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write notification queue: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write notification queue: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write notification queue: %w", err)
+	}
+	return nil
+}
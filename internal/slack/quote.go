@@ -0,0 +1,39 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Minatonton/x-crawler/internal/quote"
+)
+
+// maxQuotes は1件の通知で株価を取得する最大銘柄数
+const maxQuotes = 5
+
+// WithQuotes は通知の関連銘柄に現在値と当日の騰落率を表示する
+func WithQuotes(provider quote.Provider) Option {
+	return func(s *Notifier) {
+		s.quotes = provider
+	}
+}
+
+// fetchQuotes は銘柄の株価を取得（取得できなかった銘柄はログに出力して省略）
+func (s *Notifier) fetchQuotes(ctx context.Context, tickers []string) map[string]*quote.Quote {
+	if s.quotes == nil || len(tickers) == 0 {
+		return nil
+	}
+	if len(tickers) > maxQuotes {
+		tickers = tickers[:maxQuotes]
+	}
+	quotes, errs := quote.Fetch(ctx, s.quotes, tickers)
+	for _, err := range errs {
+		log.Printf("Failed to fetch quote: %v", err)
+	}
+	return quotes
+}
+
+// formatQuote は株価を「189.20 (+1.23%)」の形式で返す
+func formatQuote(q *quote.Quote) string {
+	return fmt.Sprintf("%.2f (%+.2f%%)", q.Price, q.ChangePercent)
+}
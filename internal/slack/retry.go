@@ -0,0 +1,264 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+const (
+	// maxSendAttempts は1回の送信での最大試行回数
+	maxSendAttempts = 3
+
+	// baseBackoff は一時的なエラー後の初回待機時間（試行ごとに倍増）
+	baseBackoff = time.Second
+
+	// maxRetryAfter はRetry-Afterで待機する上限（超える場合はキューに入れる）
+	maxRetryAfter = 30 * time.Second
+
+	// maxPending は再送キューに保持するメッセージの上限
+	maxPending = 200
+)
+
+// ErrQueued は送信に失敗したメッセージが再送キューに入ったことを示す
+// 呼び出し側は通知済みとして扱ってよい（次回のRetryPendingで再送される）
+var ErrQueued = errors.New("slack message queued for retry")
+
+// statusError はSlackが200以外を返したエラー
+type statusError struct {
+	status     int
+	retryAfter time.Duration // 429の場合のRetry-After
+	msg        string
+}
+
+func (e *statusError) Error() string {
+	return e.msg
+}
+
+// newStatusError はレスポンスからstatusErrorを作成
+func newStatusError(resp *http.Response, msg string) *statusError {
+	err := &statusError{status: resp.StatusCode, msg: msg}
+	if sec, perr := strconv.Atoi(resp.Header.Get("Retry-After")); perr == nil && sec > 0 {
+		err.retryAfter = time.Duration(sec) * time.Second
+	}
+	return err
+}
+
+// pendingMessage は再送待ちのメッセージ
+type pendingMessage struct {
+	id         string // キュー内で一意なID
+	key        string // 冪等キー（同じキーのメッセージは一度しか送らない、空の場合は区別しない）
+	webhookURL string
+	message    map[string]interface{}
+	tweet      *twitter.Tweet // 投稿後にメッセージ参照を記録するツイート（botモード）
+	maybe      bool
+	webhook    bool // botモードでもWebhookで送信する（追加の配信先）
+	queuedAt   time.Time
+}
+
+// retryable は再試行で回復し得るエラーかを返す
+func retryable(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.status == http.StatusTooManyRequests || se.status >= 500
+	}
+	// ネットワークエラーなどSlackから応答を得られなかった場合は一時的なものとして扱う
+	return true
+}
+
+// deliver は一時的なエラー（429, 5xx, 通信エラー）をバックオフしながら再試行して送信
+func (s *Notifier) deliver(ctx context.Context, p pendingMessage) (*MessageRef, error) {
+	var lastErr error
+	for attempt := 0; attempt < maxSendAttempts; attempt++ {
+		ref, err := s.sendOnce(ctx, p)
+		if err == nil {
+			return ref, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil || !retryable(err) || attempt == maxSendAttempts-1 {
+			break
+		}
+
+		wait := baseBackoff << attempt
+		var se *statusError
+		if errors.As(err, &se) && se.retryAfter > 0 {
+			if se.retryAfter > maxRetryAfter {
+				break
+			}
+			wait = se.retryAfter
+		}
+		log.Printf("Slack delivery failed (attempt %d/%d), retrying in %s: %v", attempt+1, maxSendAttempts, wait, err)
+
+		select {
+		case <-ctx.Done():
+			return nil, lastErr
+		case <-time.After(wait):
+		}
+	}
+	return nil, lastErr
+}
+
+// enqueue は再送キューにメッセージを追加（上限を超えた場合は古いものから破棄）
+func (s *Notifier) enqueue(p pendingMessage) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	delete(s.inflight, p.id)
+	s.pending = append(s.pending, p)
+	log.Printf("Slack message queued for retry (%d pending)", len(s.pending))
+	if over := len(s.pending) - maxPending; over > 0 {
+		log.Printf("Slack retry queue is full, dropping %d oldest messages", over)
+		s.pending = append([]pendingMessage(nil), s.pending[over:]...)
+	}
+	s.persistLocked()
+}
+
+// begin は送信を始めるメッセージを記録し、同じ冪等キーのメッセージが送信済み・送信待ちであればfalseを返す
+func (s *Notifier) begin(p *pendingMessage) bool {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	if p.key != "" {
+		if _, ok := s.delivered[p.key]; ok {
+			return false
+		}
+		for _, q := range s.pending {
+			if q.key == p.key {
+				return false
+			}
+		}
+		for _, q := range s.inflight {
+			if q.key == p.key {
+				return false
+			}
+		}
+	}
+
+	s.seq++
+	p.id = fmt.Sprintf("%d-%d", time.Now().UnixNano(), s.seq)
+	p.queuedAt = time.Now()
+	if s.queue != nil {
+		s.inflight[p.id] = *p
+		s.persistLocked()
+	}
+	return true
+}
+
+// finish は送信を終えた（または諦めた）メッセージを取り除き、送信できた場合は冪等キーを記録
+func (s *Notifier) finish(p pendingMessage, delivered bool) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	if s.queue == nil {
+		return
+	}
+	delete(s.inflight, p.id)
+	if delivered && p.key != "" {
+		s.delivered[p.key] = time.Now()
+	}
+	s.persistLocked()
+}
+
+// persistLocked は送信待ちのメッセージをファイルに保存（pendingMuを取得済みで呼ぶ）
+func (s *Notifier) persistLocked() {
+	if s.queue == nil {
+		return
+	}
+
+	file := queueFile{Delivered: make(map[string]time.Time, len(s.delivered))}
+	now := time.Now()
+	for key, at := range s.delivered {
+		if now.Sub(at) > deliveredRetention {
+			delete(s.delivered, key)
+			continue
+		}
+		file.Delivered[key] = at
+	}
+	for _, p := range s.pending {
+		file.Pending = append(file.Pending, p.entry())
+	}
+	for _, p := range s.inflight {
+		file.InFlight = append(file.InFlight, p.entry())
+	}
+	if err := s.queue.save(file); err != nil {
+		log.Printf("Failed to persist Slack queue: %v", err)
+	}
+}
+
+// PendingCount は再送キューのメッセージ数を返す
+func (s *Notifier) PendingCount() int {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	return len(s.pending)
+}
+
+// RetryPending は再送キューのメッセージを古い順に再送し、送信できた件数を返す
+// 一時的なエラーが続く場合は残りを次回に持ち越す
+func (s *Notifier) RetryPending(ctx context.Context) int {
+	s.pendingMu.Lock()
+	queue := s.pending
+	s.pending = nil
+	for _, p := range queue {
+		if s.queue != nil {
+			s.inflight[p.id] = p
+		}
+	}
+	s.pendingMu.Unlock()
+
+	sent := 0
+	for i, p := range queue {
+		ref, err := s.deliver(ctx, p)
+		if err != nil {
+			if retryable(err) {
+				// 残りは順序を保ってキューに戻す
+				s.pendingMu.Lock()
+				for _, q := range queue[i:] {
+					delete(s.inflight, q.id)
+				}
+				s.pending = append(append([]pendingMessage(nil), queue[i:]...), s.pending...)
+				s.persistLocked()
+				s.pendingMu.Unlock()
+				log.Printf("Slack still unavailable, %d messages remain queued: %v", len(queue)-i, err)
+				return sent
+			}
+			log.Printf("Dropping queued Slack message (queued at %s): %v", p.queuedAt.Format(time.RFC3339), err)
+			s.finish(p, false)
+			continue
+		}
+		s.finish(p, true)
+		if ref != nil && p.tweet != nil {
+			s.bot.remember(*p.tweet, sentMessage{ref: *ref, maybe: p.maybe})
+		}
+		sent++
+	}
+	if sent > 0 {
+		log.Printf("Delivered %d queued Slack messages", sent)
+	}
+	return sent
+}
+
+// sendQueued は送信し、一時的なエラーで送れなかった場合は再送キューに入れてErrQueuedを返す
+// 同じ冪等キーのメッセージが送信済み・送信待ちの場合は送らずにnilを返す
+func (s *Notifier) sendQueued(ctx context.Context, p pendingMessage) (*MessageRef, error) {
+	if !s.begin(&p) {
+		log.Printf("Skipping duplicate Slack message (%s)", p.key)
+		return nil, nil
+	}
+	ref, err := s.deliver(ctx, p)
+	if err == nil {
+		s.finish(p, true)
+		return ref, nil
+	}
+	if !retryable(err) {
+		s.finish(p, false)
+		return nil, err
+	}
+	s.enqueue(p)
+	return nil, fmt.Errorf("%w: %v", ErrQueued, err)
+}
@@ -0,0 +1,79 @@
+package slack
+
+import (
+	"strings"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// Route は通知の振り分けルール
+// 空でない条件 (Category, Urgency, Trader) を全て満たす通知をChannel/WebhookURLに送る
+type Route struct {
+	Category   string
+	Urgency    string
+	Trader     string
+	Channel    string // 投稿先チャンネル（botモード、またはWebhookが上書きを許可している場合）
+	WebhookURL string // 投稿先Webhook（webhookモードのみ）
+	Compact    bool   // 1行の簡潔な形式で投稿する
+}
+
+// WithRoutes は通知の振り分けルールを指定（上から順に評価し最初に一致したものを使用）
+func WithRoutes(routes []Route) Option {
+	return func(s *Notifier) {
+		s.routes = routes
+	}
+}
+
+// SetRoutes は振り分けルールを置き換える（設定の再読み込みでトレーダー個別の投稿先が変わった場合）
+func (s *Notifier) SetRoutes(routes []Route) {
+	s.routesMu.Lock()
+	s.routes = routes
+	s.routesMu.Unlock()
+}
+
+// matches は通知がルールの条件を満たすかを返す
+// analysisがnil（AI分析なしの通知）の場合はカテゴリ・緊急度の条件を持つルールに一致しない
+func (r Route) matches(tweet twitter.Tweet, analysis *ai.Analysis) bool {
+	if r.Trader != "" && !strings.EqualFold(strings.TrimPrefix(r.Trader, "@"), tweet.Username) {
+		return false
+	}
+	if r.Category != "" && (analysis == nil || analysis.Category != r.Category) {
+		return false
+	}
+	if r.Urgency != "" && (analysis == nil || analysis.Urgency != r.Urgency) {
+		return false
+	}
+	return true
+}
+
+// route は一致するルールに従ってメッセージの投稿先を決め、投稿先のWebhookを返す
+// 一致するルールがなければ既定のWebhookを返す
+func (s *Notifier) route(tweet twitter.Tweet, analysis *ai.Analysis, message map[string]interface{}) string {
+	r := s.matchRoute(tweet, analysis)
+	if r == nil {
+		return s.webhookURL
+	}
+	webhookURL := s.webhookURL
+	if r.WebhookURL != "" {
+		webhookURL = r.WebhookURL
+		delete(message, "channel")
+	}
+	if r.Channel != "" {
+		message["channel"] = r.Channel
+	}
+	return webhookURL
+}
+
+// matchRoute は最初に一致した振り分けルールを返す（一致しなければnil）
+func (s *Notifier) matchRoute(tweet twitter.Tweet, analysis *ai.Analysis) *Route {
+	s.routesMu.RLock()
+	routes := s.routes
+	s.routesMu.RUnlock()
+	for i := range routes {
+		if routes[i].matches(tweet, analysis) {
+			return &routes[i]
+		}
+	}
+	return nil
+}
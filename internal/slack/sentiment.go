@@ -0,0 +1,49 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Minatonton/x-crawler/internal/sentiment"
+)
+
+// NotifySentimentReport は1日分のティッカー別センチメントサマリーを通知
+func (s *Notifier) NotifySentimentReport(ctx context.Context, report *sentiment.Report) error {
+	attachments := make([]map[string]interface{}, 0, len(report.Tickers))
+	for _, t := range report.Tickers {
+		color := s.sentimentStyle("neutral").Color
+		switch {
+		case t.Bullish > t.Bearish:
+			color = s.sentimentStyle("bullish").Color
+		case t.Bearish > t.Bullish:
+			color = s.sentimentStyle("bearish").Color
+		}
+
+		lines := make([]string, len(t.TopTweets))
+		for i, rec := range t.TopTweets {
+			summary := rec.Summary
+			if summary == "" {
+				summary = rec.Text
+			}
+			lines[i] = fmt.Sprintf("• <https://x.com/%s/status/%s|@%s> (%d) %s",
+				rec.Username, rec.TweetID, rec.Username, rec.Score, summary)
+		}
+
+		attachments = append(attachments, map[string]interface{}{
+			"color":      color,
+			"title":      s.msg.text(MsgSentimentTitle, t.Ticker, t.Mentions, t.AvgScore),
+			"title_link": fmt.Sprintf("https://finance.yahoo.com/quote/%s", t.Ticker),
+			"text": s.msg.text(MsgSentimentCounts, t.Bullish, t.Bearish, t.Neutral) +
+				"\n" + strings.Join(lines, "\n"),
+		})
+	}
+
+	message := map[string]interface{}{
+		"username":    s.username,
+		"icon_emoji":  s.iconEmoji,
+		"text":        s.msg.text(MsgSentimentHeader, report.Day),
+		"attachments": attachments,
+	}
+	return s.post(ctx, "sentiment:"+report.Day, s.webhookURL, message)
+}
@@ -0,0 +1,117 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Minatonton/x-crawler/internal/stats"
+	"github.com/Minatonton/x-crawler/internal/storage"
+)
+
+// WithOpsChannel は運用向けの通知（統計レポートなど）の投稿先を指定
+// webhookURLはwebhookモード、channelはbotモード（またはWebhookが上書きを許可している場合）で使う
+func WithOpsChannel(webhookURL, channel string) Option {
+	return func(s *Notifier) {
+		s.opsWebhookURL = webhookURL
+		s.opsChannel = channel
+	}
+}
+
+// postOps は運用向けのメッセージを投稿先に送信（未指定の場合は通常の投稿先）
+func (s *Notifier) postOps(ctx context.Context, key string, message map[string]interface{}) error {
+	webhookURL := s.webhookURL
+	if s.opsWebhookURL != "" {
+		webhookURL = s.opsWebhookURL
+	}
+	if s.opsChannel != "" {
+		message["channel"] = s.opsChannel
+	}
+	return s.post(ctx, key, webhookURL, message)
+}
+
+// NotifyStatsReport は1日分のクロール統計を運用向けの投稿先に通知
+func (s *Notifier) NotifyStatsReport(ctx context.Context, report *stats.Report) error {
+	d := report.Decisions
+	fields := []map[string]interface{}{
+		{
+			"title": s.msg.text(MsgStatsPosts),
+			"value": s.msg.text(MsgStatsPostsValue, report.Fetched, report.Processed),
+			"short": true,
+		},
+		{
+			"title": s.msg.text(MsgStatsNotified),
+			"value": s.msg.text(MsgStatsNotifiedValue, d[storage.DecisionNotified], d[storage.DecisionMaybe],
+				d[storage.DecisionDigest], d[storage.DecisionUpdated]),
+			"short": true,
+		},
+		{
+			"title": s.msg.text(MsgStatsSuppressed),
+			"value": s.msg.text(MsgStatsSuppressedValue, d[storage.DecisionLowScore], d[storage.DecisionDuplicate]),
+			"short": true,
+		},
+		{
+			"title": s.msg.text(MsgStatsUsage),
+			"value": s.msg.text(MsgStatsUsageValue, report.AICalls, report.AICostUSD, report.XRequests),
+			"short": true,
+		},
+		{
+			"title": s.msg.text(MsgStatsErrors),
+			"value": s.msg.text(MsgStatsErrorsValue, report.Errors[stats.ErrorFetch], report.Errors[stats.ErrorAI],
+				report.Errors[stats.ErrorNotify]),
+			"short": true,
+		},
+	}
+	if len(report.TopTickers) > 0 {
+		tickers := make([]string, len(report.TopTickers))
+		for i, t := range report.TopTickers {
+			tickers[i] = fmt.Sprintf("$%s (%d)", t.Ticker, t.Count)
+		}
+		fields = append(fields, map[string]interface{}{
+			"title": s.msg.text(MsgStatsTopTickers),
+			"value": strings.Join(tickers, ", "),
+			"short": false,
+		})
+	}
+	if len(report.TopTraders) > 0 {
+		fields = append(fields, map[string]interface{}{
+			"title": s.msg.text(MsgStatsTopTraders),
+			"value": s.rollupsValue(report.TopTraders, "@"),
+			"short": false,
+		})
+	}
+	if len(report.Categories) > 0 {
+		fields = append(fields, map[string]interface{}{
+			"title": s.msg.text(MsgStatsCategories),
+			"value": s.rollupsValue(report.Categories, ""),
+			"short": false,
+		})
+	}
+
+	color := "#36A64F"
+	if report.TotalErrors() > 0 {
+		color = s.urgencyStyle("high").Color
+	}
+	message := map[string]interface{}{
+		"username":   s.username,
+		"icon_emoji": s.iconEmoji,
+		"text":       s.msg.text(MsgStatsHeader, report.Day),
+		"attachments": []map[string]interface{}{
+			{
+				"color":  color,
+				"fields": fields,
+				"footer": s.msg.text(MsgFooter),
+			},
+		},
+	}
+	return s.postOps(ctx, "stats:"+report.Day, message)
+}
+
+// rollupsValue は集計を「名前 通知/処理件数 (平均スコア)」の一覧にする
+func (s *Notifier) rollupsValue(rollups []storage.Rollup, prefix string) string {
+	lines := make([]string, len(rollups))
+	for i, r := range rollups {
+		lines[i] = s.msg.text(MsgStatsRollupValue, prefix+r.Key, r.Notified, r.Processed, r.AvgScore())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,72 @@
+package slack
+
+// Style は通知に使う絵文字と色
+type Style struct {
+	Emoji string
+	Color string // アタッチメントの色 (例: #FF0000)
+}
+
+// defaultUrgencyStyles は緊急度ごとの既定の絵文字・色
+var defaultUrgencyStyles = map[string]Style{
+	"critical": {Emoji: "🚨", Color: "#FF0000"},  // 赤
+	"high":     {Emoji: "⚠️", Color: "#FF9900"}, // オレンジ
+	"normal":   {Emoji: "💡", Color: "#36A64F"},  // 緑
+	"low":      {Emoji: "ℹ️", Color: "#808080"}, // グレー
+}
+
+// defaultSentimentStyles はセンチメントごとの既定の絵文字・色（色はセンチメントサマリーで使用）
+var defaultSentimentStyles = map[string]Style{
+	"bullish": {Emoji: "📈", Color: "#36A64F"},
+	"bearish": {Emoji: "📉", Color: "#FF0000"},
+	"neutral": {Emoji: "➡️", Color: "#808080"},
+	"unknown": {Emoji: "❓", Color: "#808080"},
+}
+
+// WithUrgencyStyles は緊急度ごとの絵文字・色を上書き（空の項目は既定値のまま）
+func WithUrgencyStyles(styles map[string]Style) Option {
+	return func(s *Notifier) {
+		s.urgencyStyles = mergeStyles(s.urgencyStyles, styles)
+	}
+}
+
+// WithSentimentStyles はセンチメントごとの絵文字・色を上書き（空の項目は既定値のまま）
+func WithSentimentStyles(styles map[string]Style) Option {
+	return func(s *Notifier) {
+		s.sentimentStyles = mergeStyles(s.sentimentStyles, styles)
+	}
+}
+
+// mergeStyles はbaseにoverridesの空でない項目を重ねた新しいmapを返す
+func mergeStyles(base, overrides map[string]Style) map[string]Style {
+	merged := make(map[string]Style, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		style := merged[k]
+		if v.Emoji != "" {
+			style.Emoji = v.Emoji
+		}
+		if v.Color != "" {
+			style.Color = v.Color
+		}
+		merged[k] = style
+	}
+	return merged
+}
+
+// urgencyStyle は緊急度の絵文字・色を返す（未知の緊急度はnormal）
+func (s *Notifier) urgencyStyle(urgency string) Style {
+	if style, ok := s.urgencyStyles[urgency]; ok {
+		return style
+	}
+	return s.urgencyStyles["normal"]
+}
+
+// sentimentStyle はセンチメントの絵文字・色を返す（未知のセンチメントはunknown）
+func (s *Notifier) sentimentStyle(sentiment string) Style {
+	if style, ok := s.sentimentStyles[sentiment]; ok {
+		return style
+	}
+	return s.sentimentStyles["unknown"]
+}
@@ -0,0 +1,126 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/quote"
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// TemplateTrader はテンプレートに渡す投稿者の設定
+type TemplateTrader struct {
+	Username    string
+	DisplayName string
+	Priority    string
+	Context     string
+}
+
+// TemplateData はメッセージテンプレートに渡す変数
+type TemplateData struct {
+	Tweet     twitter.Tweet
+	Analysis  *ai.Analysis
+	Trader    *TemplateTrader         // 監視対象のトレーダーでない場合（キーワード検索など）はnil
+	Quotes    map[string]*quote.Quote // 銘柄ごとの株価（quotes が無効の場合は空）
+	Maybe     bool                    // 確信度の低い「要確認」通知か
+	URL       string                  // ポストのURL
+	Emoji     string                  // 緊急度の絵文字
+	Color     string                  // 緊急度（カテゴリの指定があればカテゴリ）の色
+	Sentiment string                  // センチメントの絵文字と表示名
+	ChartURL  string                  // 最初の銘柄のチャート画像URL（chart が無効の場合は空）
+	Images    []string                // ポストの添付画像のURL
+	Metrics   string                  // エンゲージメントの1行表示（metrics が無効の場合は空）
+	Mention   string                  // 緊急度に応じたメンション（なければ空）
+	Username  string                  // 投稿に使う表示名
+	IconEmoji string
+}
+
+// ParseTemplate はSlackのペイロード (JSON) を出力するGoテンプレートを解析
+// 文字列をJSONに埋め込む場合は {{json .Tweet.Text}} のようにエスケープする
+func ParseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("slack").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"join":  strings.Join,
+		"quote": formatQuote,
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse slack template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// WithTemplate は通知のペイロード全体をテンプレートで生成する
+// tradersは {{.Trader}} に渡す監視対象のトレーダーの設定
+func WithTemplate(tmpl *template.Template, traders []TemplateTrader) Option {
+	return func(s *Notifier) {
+		s.template = tmpl
+		s.SetTemplateTraders(traders)
+	}
+}
+
+// SetTemplateTraders は {{.Trader}} に渡すトレーダーの設定を置き換える（設定の再読み込みでトレーダーが変わった場合）
+func (s *Notifier) SetTemplateTraders(traders []TemplateTrader) {
+	m := make(map[string]TemplateTrader, len(traders))
+	for _, t := range traders {
+		m[strings.ToLower(t.Username)] = t
+	}
+	s.routesMu.Lock()
+	s.traders = m
+	s.routesMu.Unlock()
+}
+
+// renderTemplate はテンプレートからメッセージを生成（テンプレート未設定または失敗時はnil）
+func (s *Notifier) renderTemplate(tweet twitter.Tweet, analysis *ai.Analysis, quotes map[string]*quote.Quote, maybe bool) map[string]interface{} {
+	if s.template == nil {
+		return nil
+	}
+
+	color := s.getColorByUrgency(analysis.Urgency)
+	if c := s.categories[analysis.Category].Color; c != "" {
+		color = c
+	}
+	data := TemplateData{
+		Tweet:     tweet,
+		Analysis:  analysis,
+		Quotes:    quotes,
+		Maybe:     maybe,
+		URL:       fmt.Sprintf("https://x.com/%s/status/%s", tweet.Username, tweet.ID),
+		Emoji:     s.getEmojiByUrgency(analysis.Urgency),
+		Color:     color,
+		Sentiment: s.getSentimentEmoji(analysis.Sentiment),
+		Images:    tweet.ImageURLs(),
+		Metrics:   s.metricsLine(tweet),
+		Mention:   s.mentions[analysis.Urgency],
+		Username:  s.username,
+		IconEmoji: s.iconEmoji,
+	}
+	s.routesMu.RLock()
+	t, ok := s.traders[strings.ToLower(tweet.Username)]
+	s.routesMu.RUnlock()
+	if ok {
+		data.Trader = &t
+	}
+	if len(analysis.Tickers) > 0 {
+		data.ChartURL = s.chartImageURL(analysis.Tickers[0])
+	}
+
+	var buf bytes.Buffer
+	if err := s.template.Execute(&buf, data); err != nil {
+		log.Printf("Failed to render slack template, using default message: %v", err)
+		return nil
+	}
+	var message map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &message); err != nil {
+		log.Printf("Slack template did not produce valid JSON, using default message: %v", err)
+		return nil
+	}
+	return message
+}
@@ -0,0 +1,247 @@
+package stats
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/storage"
+)
+
+// dayFormat は集計日の表記
+const dayFormat = "2006-01-02"
+
+// エラーの種類
+const (
+	ErrorFetch  = "fetch"  // X APIからの取得
+	ErrorAI     = "ai"     // AI分析
+	ErrorNotify = "notify" // Slack通知
+)
+
+// TickerCount は銘柄ごとの通知件数
+type TickerCount struct {
+	Ticker string
+	Count  int
+}
+
+// Report は1日分のクロール統計レポート
+type Report struct {
+	storage.DailyStats
+	TopTickers []TickerCount    // 通知件数の多い順
+	TopTraders []storage.Rollup // 通知件数の多いトレーダー
+	Categories []storage.Rollup // カテゴリごとの集計（通知件数の多い順）
+}
+
+// TotalErrors はエラーの総数を返す
+func (r *Report) TotalErrors() int {
+	total := 0
+	for _, n := range r.Errors {
+		total += n
+	}
+	return total
+}
+
+// Collector はクロールの統計を日ごとに集計する
+type Collector struct {
+	store      *storage.StatsStore
+	rollups    storage.RollupStore // トレーダー・銘柄・カテゴリごとの集計の保存先
+	reportAt   time.Duration       // 前日分を投稿する時刻（0時からの経過時間）
+	topTickers int                 // レポートに含める銘柄数
+	retention  int                 // 統計を保持する日数
+	usage      *ai.UsageTracker    // nilの場合はAI使用量を記録しない
+	now        func() time.Time
+
+	lastXRequests int64 // 前回記録したX APIのリクエスト数の累計
+}
+
+// NewCollector は新しいCollectorを作成（usageはnil可、rollupsがnilの場合はstoreに集計する）
+func NewCollector(store *storage.StatsStore, rollups storage.RollupStore, usage *ai.UsageTracker, reportAt time.Duration, topTickers, retentionDays int) *Collector {
+	if rollups == nil {
+		rollups = store
+	}
+	return &Collector{
+		store:      store,
+		rollups:    rollups,
+		reportAt:   reportAt,
+		topTickers: topTickers,
+		retention:  retentionDays,
+		usage:      usage,
+		now:        time.Now,
+	}
+}
+
+// today は当日の集計を更新
+func (c *Collector) today(fn func(*storage.DailyStats)) {
+	c.store.Update(c.now().Format(dayFormat), fn)
+}
+
+// AddFetched は取得したポスト数と、そのうち未読として処理した件数を記録
+func (c *Collector) AddFetched(fetched, processed int) {
+	c.today(func(s *storage.DailyStats) {
+		s.Fetched += fetched
+		s.Processed += processed
+	})
+}
+
+// AddDecision は分析結果の処理を記録（通知した場合は銘柄も集計）
+// トレーダー・銘柄・カテゴリごとの集計も更新する
+func (c *Collector) AddDecision(username, decision string, analysis *ai.Analysis) {
+	c.addRollups(username, decision, analysis)
+	c.today(func(s *storage.DailyStats) {
+		s.Decisions[decision]++
+		if analysis == nil {
+			return
+		}
+		switch decision {
+		case storage.DecisionNotified, storage.DecisionMaybe, storage.DecisionDigest:
+			for _, t := range analysis.Tickers {
+				s.Tickers[t]++
+			}
+		}
+	})
+}
+
+// addRollups はトレーダー・銘柄・カテゴリごとの集計に1件を足す
+func (c *Collector) addRollups(username, decision string, analysis *ai.Analysis) {
+	delta := storage.Rollup{Day: c.now().Format(dayFormat), Processed: 1}
+	switch decision {
+	case storage.DecisionNotified, storage.DecisionMaybe, storage.DecisionDigest:
+		delta.Notified = 1
+	}
+	if analysis != nil {
+		delta.Scored = 1
+		delta.ScoreSum = analysis.Score
+		delta.AICostUSD = analysis.CostUSD
+	}
+
+	var rows []storage.Rollup
+	add := func(dimension, key string) {
+		if key == "" {
+			return
+		}
+		r := delta
+		r.Dimension, r.Key = dimension, key
+		rows = append(rows, r)
+	}
+	add(storage.RollupTrader, username)
+	if analysis != nil {
+		add(storage.RollupCategory, analysis.Category)
+		for _, t := range analysis.Tickers {
+			add(storage.RollupTicker, strings.ToUpper(strings.TrimPrefix(t, "$")))
+		}
+	}
+	if err := c.rollups.AddRollups(rows); err != nil {
+		log.Printf("Failed to record stats rollup: %v", err)
+	}
+}
+
+// Rollups は[since, until]の日（YYYY-MM-DD）のdimensionの集計を返す
+func (c *Collector) Rollups(dimension, since, until string) ([]storage.Rollup, error) {
+	return c.rollups.Rollups(dimension, since, until)
+}
+
+// AICost は当日のAIの推定コストの累計を返す（使用量を記録しない場合は0）
+func (c *Collector) AICost() float64 {
+	if c.usage == nil {
+		return 0
+	}
+	return c.usage.Stats().CostUSD
+}
+
+// AddError はエラーを記録
+func (c *Collector) AddError(kind string) {
+	c.today(func(s *storage.DailyStats) {
+		s.Errors[kind]++
+	})
+}
+
+// RecordUsage は当日のAI使用量と、X APIのリクエスト数（起動してからの累計）を記録
+func (c *Collector) RecordUsage(xRequests int64) {
+	delta := xRequests - c.lastXRequests
+	c.lastXRequests = xRequests
+	c.today(func(s *storage.DailyStats) {
+		if c.usage != nil {
+			if usage := c.usage.Stats(); usage.Day == s.Day {
+				s.AICalls = usage.Calls
+				s.AICostUSD = usage.CostUSD
+			}
+		}
+		s.XRequests += int(delta)
+	})
+}
+
+// Due は投稿すべき前日分のレポートがあれば返す（投稿後はMarkReportedを呼ぶ）
+func (c *Collector) Due(now time.Time) (*Report, bool) {
+	today := startOfDay(now)
+	if now.Sub(today) < c.reportAt {
+		return nil, false
+	}
+	day := today.AddDate(0, 0, -1).Format(dayFormat)
+	if c.store.LastReport() >= day {
+		return nil, false
+	}
+	return c.Summarize(day), true
+}
+
+// MarkReported はレポートの投稿を記録し、保持期間を過ぎた統計を削除
+func (c *Collector) MarkReported(report *Report) {
+	c.store.SetLastReport(report.Day)
+	if c.retention > 0 {
+		before := startOfDay(c.now()).AddDate(0, 0, -c.retention).Format(dayFormat)
+		c.store.Prune(before)
+		if err := c.rollups.PruneRollups(before); err != nil {
+			log.Printf("Failed to prune stats rollups: %v", err)
+		}
+	}
+}
+
+// Summarize は指定日 (YYYY-MM-DD) のレポートを作成
+func (c *Collector) Summarize(day string) *Report {
+	s, _ := c.store.Get(day)
+	report := &Report{DailyStats: s}
+	for ticker, n := range s.Tickers {
+		report.TopTickers = append(report.TopTickers, TickerCount{Ticker: ticker, Count: n})
+	}
+	sort.Slice(report.TopTickers, func(i, j int) bool {
+		ti, tj := report.TopTickers[i], report.TopTickers[j]
+		if ti.Count != tj.Count {
+			return ti.Count > tj.Count
+		}
+		return ti.Ticker < tj.Ticker
+	})
+	if c.topTickers > 0 && len(report.TopTickers) > c.topTickers {
+		report.TopTickers = report.TopTickers[:c.topTickers]
+	}
+
+	rollups, err := c.rollups.Rollups("", day, day)
+	if err != nil {
+		log.Printf("Failed to read stats rollups for %s: %v", day, err)
+	}
+	for _, r := range storage.MergeRollups(rollups) {
+		switch r.Dimension {
+		case storage.RollupTrader:
+			if r.Notified > 0 {
+				report.TopTraders = append(report.TopTraders, r)
+			}
+		case storage.RollupCategory:
+			report.Categories = append(report.Categories, r)
+		}
+	}
+	if c.topTickers > 0 && len(report.TopTraders) > c.topTickers {
+		report.TopTraders = report.TopTraders[:c.topTickers]
+	}
+	return report
+}
+
+// Save は統計を保存（集計の保存先がStatsStore以外の場合は書き込み済み）
+func (c *Collector) Save() error {
+	return c.store.Save()
+}
+
+// startOfDay はローカル時刻での日付の始まりを返す
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+)
+
+// UsageLedger はAI API呼び出しの記録（トークン数・コスト・レイテンシ・結果）の保存先（Archive, SQLite）
+type UsageLedger interface {
+	RecordAICall(call ai.Call) error
+	// AICalls は[since, until)の呼び出しを古い順に返す（untilがゼロ値の場合は現在まで）
+	AICalls(since, until time.Time) ([]ai.Call, error)
+}
+
+// RecordAICall はAI API呼び出しを {dir}/ai_calls-YYYY-MM-DD.jsonl に追記（UsageLedger）
+func (a *Archive) RecordAICall(call ai.Call) error {
+	return a.append("ai_calls", call.At, call)
+}
+
+// AICalls は[since, until)の呼び出しを古い順に返す（UsageLedger）
+func (a *Archive) AICalls(since, until time.Time) ([]ai.Call, error) {
+	var calls []ai.Call
+	err := a.read("ai_calls", since, until, func(line []byte) error {
+		var c ai.Call
+		if err := json.Unmarshal(line, &c); err != nil {
+			log.Printf("Skipping invalid AI call record: %v", err)
+			return nil
+		}
+		if inRange(c.At, since, until) {
+			calls = append(calls, c)
+		}
+		return nil
+	})
+	return calls, err
+}
+
+// RecordAICall はAI API呼び出しを保存（UsageLedger）
+func (s *SQLite) RecordAICall(call ai.Call) error {
+	data, err := json.Marshal(call)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO ai_calls
+		(called_at, source, model, input_tokens, output_tokens, cost_usd, latency_ms, outcome, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		call.At.UnixMilli(), call.Source, call.Model, call.InputTokens, call.OutputTokens, call.CostUSD, call.LatencyMS, call.Outcome, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to record AI call: %w", err)
+	}
+	return nil
+}
+
+// AICalls は[since, until)の呼び出しを古い順に返す（UsageLedger）
+func (s *SQLite) AICalls(since, until time.Time) ([]ai.Call, error) {
+	if until.IsZero() {
+		until = time.Now().Add(time.Second)
+	}
+	rows, err := s.db.Query(`SELECT data FROM ai_calls WHERE called_at >= ? AND called_at < ? ORDER BY called_at, id`,
+		since.UnixMilli(), until.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var calls []ai.Call
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var c ai.Call
+		if err := json.Unmarshal([]byte(data), &c); err != nil {
+			return nil, fmt.Errorf("failed to decode AI call: %w", err)
+		}
+		calls = append(calls, c)
+	}
+	return calls, rows.Err()
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// 分析結果に対する処理
+const (
+	DecisionNotified  = "notified"  // 通常通知
+	DecisionMaybe     = "maybe"     // 確信度不足で「要確認」通知
+	DecisionLowScore  = "low_score" // スコア不足で通知せず
+	DecisionDuplicate = "duplicate" // 通知済みツイートと意味的に重複するため通知せず
+	DecisionDigest    = "digest"    // ダイジェストにまとめて通知
+	DecisionUpdated   = "updated"   // 通知済みメッセージを新しい分析結果で更新
+	DecisionMuted     = "muted"     // ミュート中の銘柄を含むため通知せず
+)
+
+// AnalysisRecord は保存されたAI分析結果
+type AnalysisRecord struct {
+	TweetID    string      `json:"tweet_id"`
+	Username   string      `json:"username"`
+	Source     string      `json:"source"`
+	Text       string      `json:"text"`
+	CreatedAt  time.Time   `json:"created_at"`
+	Analysis   ai.Analysis `json:"analysis"`
+	Decision   string      `json:"decision"`
+	AnalyzedAt time.Time   `json:"analyzed_at"`
+}
+
+// AnalysisStore はツイートIDごとのAI分析結果を管理
+type AnalysisStore struct {
+	mu         sync.RWMutex
+	records    map[string]*AnalysisRecord
+	maxRecords int
+	filePath   string
+}
+
+// NewAnalysisStore は新しいAnalysisStoreを作成（maxRecordsが0以下の場合は無制限）
+func NewAnalysisStore(filePath string, maxRecords int) (*AnalysisStore, error) {
+	as := &AnalysisStore{
+		records:    make(map[string]*AnalysisRecord),
+		maxRecords: maxRecords,
+		filePath:   filePath,
+	}
+
+	// ファイルが存在する場合は読み込み
+	if _, err := os.Stat(filePath); err == nil {
+		if err := as.Load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return as, nil
+}
+
+// Record は分析結果を記録（同じツイートは上書き）
+func (as *AnalysisStore) Record(rec AnalysisRecord) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	as.records[rec.TweetID] = &rec
+	as.pruneLocked()
+}
+
+// RecordAnalysis は分析結果を記録（ArchiveStore）
+func (as *AnalysisStore) RecordAnalysis(rec AnalysisRecord) error {
+	as.Record(rec)
+	return nil
+}
+
+// RecordTweet は何もしない（ArchiveStore、ツイートは分析結果と一緒に保存する）
+func (as *AnalysisStore) RecordTweet(tweet twitter.Tweet, source string) error {
+	return nil
+}
+
+// Get はツイートの分析結果を返す
+func (as *AnalysisStore) Get(tweetID string) (AnalysisRecord, bool) {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	rec, ok := as.records[tweetID]
+	if !ok {
+		return AnalysisRecord{}, false
+	}
+	return *rec, true
+}
+
+// All は全ての分析結果を分析日時の古い順に返す
+func (as *AnalysisStore) All() []AnalysisRecord {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	records := make([]AnalysisRecord, 0, len(as.records))
+	for _, rec := range as.records {
+		records = append(records, *rec)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].AnalyzedAt.Before(records[j].AnalyzedAt)
+	})
+	return records
+}
+
+// Count は保存されている分析結果の件数を返す
+func (as *AnalysisStore) Count() int {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	return len(as.records)
+}
+
+// Save は分析結果をファイルに保存
+func (as *AnalysisStore) Save() error {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	data, err := json.MarshalIndent(as.records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal analyses: %w", err)
+	}
+
+	if err := writeFileAtomic(as.filePath, data); err != nil {
+		return fmt.Errorf("failed to write analyses file: %w", err)
+	}
+
+	return nil
+}
+
+// Load は分析結果をファイルから読み込み
+func (as *AnalysisStore) Load() error {
+	data, err := readFile(as.filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read analyses file: %w", err)
+	}
+
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	if err := json.Unmarshal(data, &as.records); err != nil {
+		return fmt.Errorf("failed to unmarshal analyses: %w", err)
+	}
+
+	return nil
+}
+
+// pruneLocked は上限を超えた古い記録を削除（ロック取得済みで呼ぶ）
+func (as *AnalysisStore) pruneLocked() {
+	if as.maxRecords <= 0 || len(as.records) <= as.maxRecords {
+		return
+	}
+
+	ids := make([]string, 0, len(as.records))
+	for id := range as.records {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return as.records[ids[i]].AnalyzedAt.Before(as.records[ids[j]].AnalyzedAt)
+	})
+	for _, id := range ids[:len(ids)-as.maxRecords] {
+		delete(as.records, id)
+	}
+}
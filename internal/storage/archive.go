@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// archiveDateLayout はアーカイブのファイルを分ける日付の形式
+const archiveDateLayout = "2006-01-02"
+
+// ArchivedTweet は保存された取得済みのツイート
+type ArchivedTweet struct {
+	Tweet     twitter.Tweet `json:"tweet"`
+	Username  string        `json:"username"` // 投稿者（エクスポートや絞り込み用）
+	Source    string        `json:"source"`
+	FetchedAt time.Time     `json:"fetched_at"`
+}
+
+// ArchiveReader は保存済みのツイート・AI分析結果を期間で読み出す（Archive, SQLite）
+type ArchiveReader interface {
+	Tweets(since, until time.Time) ([]ArchivedTweet, error)
+	Analyses(since, until time.Time) ([]AnalysisRecord, error)
+}
+
+// Archive は取得した全てのツイートとAI分析結果（通知しなかったものも含む）を日ごとのJSONLファイルに追記する
+// 保存先のバックエンドに関係なく使え、保持期間を過ぎた日のファイルはSaveで削除する
+//
+// ファイル:
+//   - {dir}/tweets-YYYY-MM-DD.jsonl    取得したツイート（ArchivedTweet）
+//   - {dir}/analyses-YYYY-MM-DD.jsonl  AI分析結果と処理（AnalysisRecord、再分析された場合は複数行）
+//   - {dir}/ai_calls-YYYY-MM-DD.jsonl  AI API呼び出しの記録（ai.Call、UsageLedgerとして使う場合）
+type Archive struct {
+	mu        sync.Mutex
+	dir       string
+	retention time.Duration // 0の場合は削除しない
+}
+
+// NewArchive はアーカイブを作成（ディレクトリがない場合は作成）
+func NewArchive(dir string, retention time.Duration) (*Archive, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	return &Archive{dir: dir, retention: retention}, nil
+}
+
+// RecordTweet は取得したツイートを追記（ArchiveStore）
+func (a *Archive) RecordTweet(tweet twitter.Tweet, source string) error {
+	now := time.Now()
+	return a.append("tweets", now, ArchivedTweet{Tweet: tweet, Username: tweet.Username, Source: source, FetchedAt: now})
+}
+
+// RecordAnalysis はAI分析結果を追記（ArchiveStore）
+func (a *Archive) RecordAnalysis(rec AnalysisRecord) error {
+	return a.append("analyses", rec.AnalyzedAt, rec)
+}
+
+// append はkindの当日のファイルに1行追記
+func (a *Archive) append(kind string, at time.Time, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if data, err = encryptLine(data); err != nil {
+		return fmt.Errorf("failed to encrypt archive: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, err := os.OpenFile(a.path(kind, at), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return f.Close()
+}
+
+// path はkindのatの日のファイルのパスを返す
+func (a *Archive) path(kind string, at time.Time) string {
+	return filepath.Join(a.dir, kind+"-"+at.Format(archiveDateLayout)+".jsonl")
+}
+
+// Save は保持期間を過ぎた日のファイルを削除する（書き込みはその場で反映済み）
+func (a *Archive) Save() error {
+	if a.retention <= 0 {
+		return nil
+	}
+	cutoff := time.Now().Add(-a.retention)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, kind := range []string{"tweets", "analyses", "ai_calls"} {
+		files, err := a.files(kind)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			// その日の終わりが保持期間より前のファイルだけを削除
+			if f.day.AddDate(0, 0, 1).Before(cutoff) {
+				if err := os.Remove(f.path); err != nil {
+					return fmt.Errorf("failed to prune archive: %w", err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// archiveFile は日ごとのアーカイブファイル
+type archiveFile struct {
+	path string
+	day  time.Time
+}
+
+// files はkindのファイルを日付の古い順に返す
+func (a *Archive) files(kind string) ([]archiveFile, error) {
+	matches, err := filepath.Glob(filepath.Join(a.dir, kind+"-*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	var files []archiveFile
+	for _, m := range matches {
+		date := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(m), kind+"-"), ".jsonl")
+		day, err := time.ParseInLocation(archiveDateLayout, date, time.Local)
+		if err != nil {
+			continue
+		}
+		files = append(files, archiveFile{path: m, day: day})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].day.Before(files[j].day) })
+	return files, nil
+}
+
+// Tweets は[since, until)に取得したツイートを古い順に返す（untilがゼロ値の場合は現在まで）
+func (a *Archive) Tweets(since, until time.Time) ([]ArchivedTweet, error) {
+	var tweets []ArchivedTweet
+	err := a.read("tweets", since, until, func(line []byte) error {
+		var t ArchivedTweet
+		if err := json.Unmarshal(line, &t); err != nil {
+			log.Printf("Skipping invalid archived tweet: %v", err)
+			return nil
+		}
+		if inRange(t.FetchedAt, since, until) {
+			t.Tweet.Username = t.Username
+			tweets = append(tweets, t)
+		}
+		return nil
+	})
+	return tweets, err
+}
+
+// Analyses は[since, until)に分析した結果を古い順に返す（untilがゼロ値の場合は現在まで）
+func (a *Archive) Analyses(since, until time.Time) ([]AnalysisRecord, error) {
+	var records []AnalysisRecord
+	err := a.read("analyses", since, until, func(line []byte) error {
+		var rec AnalysisRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			log.Printf("Skipping invalid archived analysis: %v", err)
+			return nil
+		}
+		if inRange(rec.AnalyzedAt, since, until) {
+			records = append(records, rec)
+		}
+		return nil
+	})
+	return records, err
+}
+
+// read は期間に含まれる日のkindのファイルを1行ずつ読む
+func (a *Archive) read(kind string, since, until time.Time, fn func(line []byte) error) error {
+	a.mu.Lock()
+	files, err := a.files(kind)
+	a.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if !since.IsZero() && f.day.AddDate(0, 0, 1).Before(since) {
+			continue
+		}
+		if !until.IsZero() && !f.day.Before(until) {
+			break
+		}
+		if err := readLines(f.path, fn); err != nil {
+			return fmt.Errorf("failed to read archive %s: %w", filepath.Base(f.path), err)
+		}
+	}
+	return nil
+}
+
+// readLines はファイルの空でない行ごとにfnを呼ぶ（暗号化した行は復号する）
+func readLines(path string, fn func(line []byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		line, err := decryptLine(line)
+		if err != nil {
+			return err
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// inRange はtが[since, until)に含まれるかを返す（ゼロ値は制限なし）
+func inRange(t, since, until time.Time) bool {
+	if !since.IsZero() && t.Before(since) {
+		return false
+	}
+	return until.IsZero() || t.Before(until)
+}
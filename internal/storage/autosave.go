@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// AutoSave は既読IDの追加がevery件に達するか、intervalが経過するたびにバックグラウンドで保存するSeenStore
+// クロールの途中で異常終了しても、それまでに処理したツイートを再起動後に再び通知しない
+type AutoSave struct {
+	SeenStore
+	every    int64         // 0の場合は件数では保存しない
+	interval time.Duration // 0の場合は時間では保存しない
+	pending  atomic.Int64  // 前回の保存から追加した件数
+	kick     chan struct{}
+}
+
+// NewAutoSave はstoreを定期的に保存するAutoSaveを作成（保存はRunで開始する）
+func NewAutoSave(store SeenStore, every int, interval time.Duration) *AutoSave {
+	return &AutoSave{
+		SeenStore: store,
+		every:     int64(every),
+		interval:  interval,
+		kick:      make(chan struct{}, 1),
+	}
+}
+
+// Add はツイートIDを既読にし、追加した件数がeveryに達した場合は保存を要求する
+func (a *AutoSave) Add(tweetID string) {
+	a.SeenStore.Add(tweetID)
+	if n := a.pending.Add(1); a.every > 0 && n >= a.every {
+		select {
+		case a.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Save は保存する（前回の保存から追加した件数をリセット）
+func (a *AutoSave) Save() error {
+	a.pending.Store(0)
+	return a.SeenStore.Save()
+}
+
+// Run はctxが終了するまでバックグラウンドで保存する
+func (a *AutoSave) Run(ctx context.Context) {
+	var tick <-chan time.Time
+	if a.interval > 0 {
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		case <-a.kick:
+		}
+		if a.pending.Load() == 0 {
+			continue
+		}
+		if err := a.Save(); err != nil {
+			log.Printf("Failed to save seen tweets: %v", err)
+		}
+	}
+}
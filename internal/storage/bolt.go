@@ -0,0 +1,351 @@
+package storage
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	boltSeenBucket       = []byte("seen")
+	boltCheckpointBucket = []byte("checkpoints")
+	boltContentBucket    = []byte("content")
+	boltRollupBucket     = []byte("rollups")
+)
+
+// Bolt は既読ツイートIDと取得元ごとのチェックポイントを単一ファイルのbboltに保存
+// 外部サービスが不要で、書き込みはトランザクションごとに反映されるため、JSONのように毎回全体を書き直さない
+//
+// バケット:
+//   - seen         ツイートID -> 既読にしたUNIX時刻（8バイト、ビッグエンディアン）
+//   - checkpoints  取得元 -> {"tweet_id", "updated_at"}
+//   - content      本文のハッシュ -> {"tweet_id", "at"}（同じ本文を最初に投稿したツイート）
+//   - rollups      日|軸|値 -> Rollup（日ごとの集計）
+type Bolt struct {
+	db        *bolt.DB
+	retention time.Duration // 0の場合は既読IDを削除しない
+}
+
+// OpenBolt はbboltのファイルを開く（なければ作成、retentionより古い既読IDはSaveで削除）
+func OpenBolt(path string, retention time.Duration) (*Bolt, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bbolt database: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltSeenBucket, boltCheckpointBucket, boltContentBucket, boltRollupBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize bbolt buckets: %w", err)
+	}
+	return &Bolt{db: db, retention: retention}, nil
+}
+
+// Close はファイルを閉じる
+func (b *Bolt) Close() error {
+	return b.db.Close()
+}
+
+// Has は指定されたツイートIDが既読かチェック
+func (b *Bolt) Has(tweetID string) bool {
+	var found bool
+	b.db.View(func(tx *bolt.Tx) error {
+		found = tx.Bucket(boltSeenBucket).Get([]byte(tweetID)) != nil
+		return nil
+	})
+	return found
+}
+
+// Add は新しいツイートIDを既読にする（既読の場合は時刻を更新しない）
+func (b *Bolt) Add(tweetID string) {
+	if err := b.ImportSeen([]SeenEntry{{TweetID: tweetID, SeenAt: time.Now()}}); err != nil {
+		log.Printf("Failed to add seen tweet %s: %v", tweetID, err)
+	}
+}
+
+// ImportSeen は既読にした時刻を指定してツイートIDを1つのトランザクションで既読にする（SeenMigrator）
+func (b *Bolt) ImportSeen(entries []SeenEntry) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltSeenBucket)
+		for _, e := range entries {
+			if bucket.Get([]byte(e.TweetID)) != nil {
+				continue
+			}
+			var v [8]byte
+			binary.BigEndian.PutUint64(v[:], uint64(e.SeenAt.Unix()))
+			if err := bucket.Put([]byte(e.TweetID), v[:]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SeenEntries は既読ツイートIDと既読にした時刻を返す（SeenMigrator）
+func (b *Bolt) SeenEntries() ([]SeenEntry, error) {
+	var entries []SeenEntry
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltSeenBucket).ForEach(func(k, v []byte) error {
+			var seenAt time.Time
+			if len(v) == 8 {
+				seenAt = time.Unix(int64(binary.BigEndian.Uint64(v)), 0)
+			}
+			entries = append(entries, SeenEntry{TweetID: string(k), SeenAt: seenAt})
+			return nil
+		})
+	})
+	return entries, err
+}
+
+// Count は既読ツイート数を返す
+func (b *Bolt) Count() int {
+	var n int
+	b.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(boltSeenBucket).Stats().KeyN
+		return nil
+	})
+	return n
+}
+
+// Save は保持期間を過ぎた既読IDを削除する（書き込みはその場で反映済み）
+func (b *Bolt) Save() error {
+	if b.retention <= 0 {
+		return nil
+	}
+	cutoff := uint64(time.Now().Add(-b.retention).Unix())
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltSeenBucket)
+		var expired [][]byte
+		bucket.ForEach(func(k, v []byte) error {
+			if len(v) == 8 && binary.BigEndian.Uint64(v) < cutoff {
+				expired = append(expired, k)
+			}
+			return nil
+		})
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prune seen tweets in bbolt: %w", err)
+	}
+	return nil
+}
+
+// boltCheckpoint はcheckpointsバケットに保存する値
+type boltCheckpoint struct {
+	TweetID   string `json:"tweet_id"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// SetCheckpoint は取得元の最新のツイートIDを記録（記録済みより古いIDの場合は更新しない）
+func (b *Bolt) SetCheckpoint(source, tweetID string) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltCheckpointBucket)
+		if data := bucket.Get([]byte(source)); data != nil {
+			var cur boltCheckpoint
+			if json.Unmarshal(data, &cur) == nil && !NewerTweetID(tweetID, cur.TweetID) {
+				return nil
+			}
+		}
+		data, err := json.Marshal(boltCheckpoint{TweetID: tweetID, UpdatedAt: time.Now().Unix()})
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(source), data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record checkpoint of %s: %w", source, err)
+	}
+	return nil
+}
+
+// Checkpoint は取得元のチェックポイントを返す
+func (b *Bolt) Checkpoint(source string) (Checkpoint, bool, error) {
+	var v boltCheckpoint
+	var found bool
+	err := b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(boltCheckpointBucket).Get([]byte(source))
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, &v)
+	})
+	if err != nil {
+		return Checkpoint{}, false, fmt.Errorf("failed to decode checkpoint of %s: %w", source, err)
+	}
+	if !found {
+		return Checkpoint{}, false, nil
+	}
+	return Checkpoint{Source: source, TweetID: v.TweetID, UpdatedAt: time.Unix(v.UpdatedAt, 0)}, true, nil
+}
+
+// Checkpoints は全ての取得元のチェックポイントを返す（CheckpointLister）
+func (b *Bolt) Checkpoints() ([]Checkpoint, error) {
+	var checkpoints []Checkpoint
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltCheckpointBucket).ForEach(func(k, data []byte) error {
+			var v boltCheckpoint
+			if err := json.Unmarshal(data, &v); err != nil {
+				return fmt.Errorf("failed to decode checkpoint of %s: %w", k, err)
+			}
+			checkpoints = append(checkpoints, Checkpoint{Source: string(k), TweetID: v.TweetID, UpdatedAt: time.Unix(v.UpdatedAt, 0)})
+			return nil
+		})
+	})
+	return checkpoints, err
+}
+
+// ContentStore は本文のハッシュをcontentバケットで管理するContentStoreを返す（ContentStorer）
+func (b *Bolt) ContentStore(ttl time.Duration) ContentStore {
+	return &boltContent{db: b.db, ttl: ttl}
+}
+
+// boltContent はbboltのContentStore
+type boltContent struct {
+	db  *bolt.DB
+	ttl time.Duration
+}
+
+// MarkContent は本文のハッシュを記録し、ttl以内に別のツイートが記録されていればそのIDを返す
+func (c *boltContent) MarkContent(hash, tweetID string) (string, error) {
+	var first string
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltContentBucket)
+		now := time.Now()
+		if data := bucket.Get([]byte(hash)); data != nil {
+			var e contentEntry
+			if json.Unmarshal(data, &e) == nil && now.Sub(time.Unix(e.At, 0)) < c.ttl {
+				if e.TweetID != tweetID {
+					first = e.TweetID
+				}
+				return nil
+			}
+		}
+		data, err := json.Marshal(contentEntry{TweetID: tweetID, At: now.Unix()})
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(hash), data)
+	})
+	return first, err
+}
+
+// Save は期限切れのハッシュを削除する
+func (c *boltContent) Save() error {
+	cutoff := time.Now().Add(-c.ttl).Unix()
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltContentBucket)
+		var expired [][]byte
+		bucket.ForEach(func(k, data []byte) error {
+			var e contentEntry
+			if json.Unmarshal(data, &e) != nil || e.At < cutoff {
+				expired = append(expired, k)
+			}
+			return nil
+		})
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prune content hashes in bbolt: %w", err)
+	}
+	return nil
+}
+
+// AddRollups は日・軸・値ごとの集計に件数を足す（RollupStore）
+func (b *Bolt) AddRollups(rows []Rollup) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltRollupBucket)
+		for _, d := range rows {
+			k := []byte(rollupKey(d.Day, d.Dimension, d.Key))
+			r := Rollup{Day: d.Day, Dimension: d.Dimension, Key: d.Key}
+			if data := bucket.Get(k); data != nil {
+				if err := json.Unmarshal(data, &r); err != nil {
+					return err
+				}
+			}
+			r.add(d)
+			data, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(k, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record rollup: %w", err)
+	}
+	return nil
+}
+
+// Rollups は[since, until]の日のdimensionの集計を返す（RollupStore）
+func (b *Bolt) Rollups(dimension, since, until string) ([]Rollup, error) {
+	var rollups []Rollup
+	err := b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltRollupBucket).Cursor()
+		// キーは日付で始まるため、sinceの位置から順に読む
+		for k, data := c.Seek([]byte(since)); k != nil; k, data = c.Next() {
+			day, dim, _, ok := parseRollupKey(string(k))
+			if !ok {
+				continue
+			}
+			if until != "" && day > until {
+				break
+			}
+			if dimension != "" && dim != dimension {
+				continue
+			}
+			var r Rollup
+			if err := json.Unmarshal(data, &r); err != nil {
+				return fmt.Errorf("failed to decode rollup %s: %w", k, err)
+			}
+			rollups = append(rollups, r)
+		}
+		return nil
+	})
+	return rollups, err
+}
+
+// PruneRollups はbeforeより前の日の集計を削除する（RollupStore）
+func (b *Bolt) PruneRollups(before string) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltRollupBucket)
+		var expired [][]byte
+		c := bucket.Cursor()
+		for k, _ := c.First(); k != nil && string(k) < before; k, _ = c.Next() {
+			expired = append(expired, k)
+		}
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prune rollups in bbolt: %w", err)
+	}
+	return nil
+}
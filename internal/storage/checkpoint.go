@@ -0,0 +1,24 @@
+package storage
+
+import "time"
+
+// Checkpoint は取得元ごとの最後に取得した最新のツイート
+type Checkpoint struct {
+	Source    string
+	TweetID   string
+	UpdatedAt time.Time
+}
+
+// CheckpointStore は取得元ごとのチェックポイントの保存先（SQLite, Redis, bbolt）
+type CheckpointStore interface {
+	SetCheckpoint(source, tweetID string) error // 記録済みより古いIDの場合は更新しない
+	Checkpoint(source string) (Checkpoint, bool, error)
+}
+
+// NewerTweetID はaがbより新しいツイートIDかを返す（IDは桁数が同じなら辞書順で時系列に並ぶ）
+func NewerTweetID(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) > len(b)
+	}
+	return a > b
+}
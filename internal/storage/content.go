@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ContentStore は正規化した本文のハッシュの保存先
+// 同じ本文を別のツイートIDで投稿し直す転載ボットを、ttlの間は重複として扱う
+type ContentStore interface {
+	// MarkContent は本文のハッシュを記録し、ttl以内に別のツイートが同じ本文で記録されていればそのIDを返す
+	MarkContent(hash, tweetID string) (string, error)
+	// Save は期限切れのハッシュを削除する
+	Save() error
+}
+
+// contentEntry は記録したハッシュの最初のツイート
+type contentEntry struct {
+	TweetID string `json:"tweet_id"`
+	At      int64  `json:"at"`
+}
+
+// ContentHashes は本文のハッシュをJSONファイルで管理するContentStore（storage.backend: json の場合）
+type ContentHashes struct {
+	mu       sync.Mutex
+	hashes   map[string]contentEntry
+	filePath string
+	ttl      time.Duration
+}
+
+// NewContentHashes は新しいContentHashesを作成（ファイルが存在する場合は読み込む）
+func NewContentHashes(filePath string, ttl time.Duration) (*ContentHashes, error) {
+	ch := &ContentHashes{hashes: make(map[string]contentEntry), filePath: filePath, ttl: ttl}
+	data, err := readFile(filePath)
+	if os.IsNotExist(err) {
+		return ch, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content hashes file: %w", err)
+	}
+	if err := json.Unmarshal(data, &ch.hashes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal content hashes: %w", err)
+	}
+	return ch, nil
+}
+
+// MarkContent は本文のハッシュを記録し、ttl以内に別のツイートが記録されていればそのIDを返す
+func (ch *ContentHashes) MarkContent(hash, tweetID string) (string, error) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	now := time.Now()
+	if e, ok := ch.hashes[hash]; ok && now.Sub(time.Unix(e.At, 0)) < ch.ttl {
+		if e.TweetID == tweetID {
+			return "", nil
+		}
+		return e.TweetID, nil
+	}
+	ch.hashes[hash] = contentEntry{TweetID: tweetID, At: now.Unix()}
+	return "", nil
+}
+
+// Save は期限切れのハッシュを削除してファイルに保存
+func (ch *ContentHashes) Save() error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	cutoff := time.Now().Add(-ch.ttl).Unix()
+	for hash, e := range ch.hashes {
+		if e.At < cutoff {
+			delete(ch.hashes, hash)
+		}
+	}
+	data, err := json.Marshal(ch.hashes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal content hashes: %w", err)
+	}
+	if err := writeFileAtomic(ch.filePath, data); err != nil {
+		return fmt.Errorf("failed to write content hashes file: %w", err)
+	}
+	return nil
+}
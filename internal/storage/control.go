@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// controlFile はSlackコマンドで変更した実行時の設定の保存形式
+type controlFile struct {
+	Traders []string             `json:"traders"` // 追加した監視対象のユーザー名
+	Mutes   map[string]time.Time `json:"mutes"`   // 銘柄 -> ミュートの期限
+}
+
+// ControlStore はSlackコマンドで変更した監視対象やミュートを保持する
+type ControlStore struct {
+	mu       sync.RWMutex
+	data     controlFile
+	filePath string
+}
+
+// NewControlStore は新しいControlStoreを作成
+func NewControlStore(filePath string) (*ControlStore, error) {
+	cs := &ControlStore{
+		data:     controlFile{Mutes: make(map[string]time.Time)},
+		filePath: filePath,
+	}
+
+	// ファイルが存在する場合は読み込み
+	if _, err := os.Stat(filePath); err == nil {
+		if err := cs.Load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return cs, nil
+}
+
+// AddTrader は監視対象のユーザーを追加（既に追加済みの場合はfalseを返す）
+func (cs *ControlStore) AddTrader(username string) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	for _, u := range cs.data.Traders {
+		if strings.EqualFold(u, username) {
+			return false
+		}
+	}
+	cs.data.Traders = append(cs.data.Traders, username)
+	return true
+}
+
+// Traders は追加した監視対象のユーザー名を返す
+func (cs *ControlStore) Traders() []string {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return append([]string(nil), cs.data.Traders...)
+}
+
+// Mute は銘柄の通知をuntilまでミュート
+func (cs *ControlStore) Mute(ticker string, until time.Time) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.data.Mutes[strings.ToUpper(ticker)] = until
+}
+
+// Unmute は銘柄のミュートを解除（ミュートしていなかった場合はfalseを返す）
+func (cs *ControlStore) Unmute(ticker string) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	ticker = strings.ToUpper(ticker)
+	if _, ok := cs.data.Mutes[ticker]; !ok {
+		return false
+	}
+	delete(cs.data.Mutes, ticker)
+	return true
+}
+
+// Muted は銘柄がnowの時点でミュート中かを返す
+func (cs *ControlStore) Muted(ticker string, now time.Time) bool {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	until, ok := cs.data.Mutes[strings.ToUpper(ticker)]
+	return ok && now.Before(until)
+}
+
+// MuteEntry はミュート中の銘柄と期限
+type MuteEntry struct {
+	Ticker string
+	Until  time.Time
+}
+
+// Mutes はnowの時点でミュート中の銘柄を期限の早い順に返す（期限切れのものは削除）
+func (cs *ControlStore) Mutes(now time.Time) []MuteEntry {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	var entries []MuteEntry
+	for ticker, until := range cs.data.Mutes {
+		if !now.Before(until) {
+			delete(cs.data.Mutes, ticker)
+			continue
+		}
+		entries = append(entries, MuteEntry{Ticker: ticker, Until: until})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Until.Before(entries[j].Until)
+	})
+	return entries
+}
+
+// Save は実行時の設定をファイルに保存
+func (cs *ControlStore) Save() error {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	data, err := json.MarshalIndent(cs.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal control state: %w", err)
+	}
+
+	if err := writeFileAtomic(cs.filePath, data); err != nil {
+		return fmt.Errorf("failed to write control file: %w", err)
+	}
+
+	return nil
+}
+
+// Load は実行時の設定をファイルから読み込み
+func (cs *ControlStore) Load() error {
+	data, err := readFile(cs.filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read control file: %w", err)
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	var f controlFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("failed to unmarshal control state: %w", err)
+	}
+	if f.Mutes == nil {
+		f.Mutes = make(map[string]time.Time)
+	}
+	cs.data = f
+
+	return nil
+}
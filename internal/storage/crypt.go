@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// encryptedMagic は暗号化した状態ファイルの先頭に付ける識別子（平文のファイルと区別する）
+var encryptedMagic = []byte("XCENC1\n")
+
+// encryptedLinePrefix は暗号化したアーカイブの行の先頭に付ける識別子
+var encryptedLinePrefix = []byte("enc:")
+
+var (
+	cipherMu   sync.RWMutex
+	fileCipher cipher.AEAD // nilの場合は暗号化しない
+)
+
+// SetEncryptionKey は状態ファイルとアーカイブをAES-256-GCMで暗号化する鍵を設定する（32バイト）
+// 設定後に保存したファイルは暗号化され、暗号化していない既存のファイルもそのまま読める
+func SetEncryptionKey(key []byte) error {
+	if len(key) != 32 {
+		return fmt.Errorf("encryption key must be 32 bytes (got %d)", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	cipherMu.Lock()
+	fileCipher = aead
+	cipherMu.Unlock()
+	return nil
+}
+
+// ParseEncryptionKey は鍵の文字列（base64 または16進数で32バイト）を解釈する
+func ParseEncryptionKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := hex.DecodeString(s); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	return nil, errors.New("encryption key must be 32 bytes encoded in base64 or hex")
+}
+
+// currentCipher は設定された鍵を返す
+func currentCipher() cipher.AEAD {
+	cipherMu.RLock()
+	defer cipherMu.RUnlock()
+	return fileCipher
+}
+
+// seal はdataを暗号化する（nonce + 暗号文）
+func seal(aead cipher.AEAD, data []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// unseal はsealしたデータを復号する
+func unseal(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("encrypted data is too short")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt (wrong encryption key?): %w", err)
+	}
+	return plain, nil
+}
+
+// EncryptFile は状態ファイルに書き込む内容を暗号化する（鍵が未設定の場合はそのまま）
+func EncryptFile(data []byte) ([]byte, error) {
+	aead := currentCipher()
+	if aead == nil {
+		return data, nil
+	}
+	sealed, err := seal(aead, data)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, encryptedMagic...), sealed...), nil
+}
+
+// DecryptFile は暗号化した状態ファイルの内容を復号する（平文の場合はそのまま）
+func DecryptFile(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, encryptedMagic) {
+		return data, nil
+	}
+	aead := currentCipher()
+	if aead == nil {
+		return nil, errors.New("file is encrypted but no encryption key is configured")
+	}
+	return unseal(aead, data[len(encryptedMagic):])
+}
+
+// readFile は状態ファイルを読み込んで復号する
+func readFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptFile(data)
+}
+
+// encryptLine はアーカイブの1行を暗号化する（鍵が未設定の場合はそのまま）
+func encryptLine(line []byte) ([]byte, error) {
+	aead := currentCipher()
+	if aead == nil {
+		return line, nil
+	}
+	sealed, err := seal(aead, line)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(encryptedLinePrefix)+base64.StdEncoding.EncodedLen(len(sealed)))
+	copy(out, encryptedLinePrefix)
+	base64.StdEncoding.Encode(out[len(encryptedLinePrefix):], sealed)
+	return out, nil
+}
+
+// decryptLine はアーカイブの暗号化した行を復号する（平文の行はそのまま）
+func decryptLine(line []byte) ([]byte, error) {
+	if !bytes.HasPrefix(line, encryptedLinePrefix) {
+		return line, nil
+	}
+	aead := currentCipher()
+	if aead == nil {
+		return nil, errors.New("archive is encrypted but no encryption key is configured")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(line[len(encryptedLinePrefix):]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted line: %w", err)
+	}
+	return unseal(aead, sealed)
+}
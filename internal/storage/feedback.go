@@ -0,0 +1,353 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// フィードバックのラベル
+const (
+	LabelUseful = "useful"
+	LabelNoise  = "noise"
+)
+
+// maxFeedbackRecords は保持する通知記録の上限
+const maxFeedbackRecords = 2000
+
+// NotificationRecord は通知したツイートとそのフィードバック
+type NotificationRecord struct {
+	TweetID    string    `json:"tweet_id"`
+	Username   string    `json:"username"`
+	Source     string    `json:"source"`
+	Text       string    `json:"text"`
+	Score      int       `json:"score"`
+	Category   string    `json:"category"`
+	NotifiedAt time.Time `json:"notified_at"`
+	Label      string    `json:"label,omitempty"`
+	LabeledAt  time.Time `json:"labeled_at,omitempty"`
+	LabeledBy  string    `json:"labeled_by,omitempty"` // 評価したSlackユーザー（インタラクティブボタンの場合）
+
+	// 事後の値動き（feedback.outcomes が有効な場合）
+	Tickers    []string           `json:"tickers,omitempty"`
+	BasePrices map[string]float64 `json:"base_prices,omitempty"` // 通知直後の銘柄ごとの株価
+	BasePriced time.Time          `json:"base_priced,omitempty"`
+	Outcomes   []Outcome          `json:"outcomes,omitempty"`
+}
+
+// Outcome は通知からN時間後の銘柄の値動き
+type Outcome struct {
+	Ticker     string    `json:"ticker"`
+	Hours      int       `json:"hours"` // 通知からの経過時間
+	BasePrice  float64   `json:"base_price"`
+	Price      float64   `json:"price"`
+	ChangePct  float64   `json:"change_pct"` // 通知直後の株価からの変化率 (%)
+	MeasuredAt time.Time `json:"measured_at"`
+}
+
+// HasOutcome はtickerのhours時間後の値動きが記録済みかを返す
+func (r *NotificationRecord) HasOutcome(ticker string, hours int) bool {
+	for _, o := range r.Outcomes {
+		if o.Ticker == ticker && o.Hours == hours {
+			return true
+		}
+	}
+	return false
+}
+
+// FeedbackBackend は通知記録・フィードバック・事後の値動きの保存先（SQLite）
+// 保存先が対応している場合はファイルの代わりに使い、件数の上限なしに全ての記録を残す
+type FeedbackBackend interface {
+	// SaveNotifications は記録を保存（同じツイートは上書き、値動きは追加）
+	SaveNotifications(recs []NotificationRecord) error
+	// Notifications は[since, until)に通知した記録を古い順に返す（untilがゼロ値の場合は現在まで）
+	Notifications(since, until time.Time) ([]NotificationRecord, error)
+}
+
+// FeedbackStore は通知記録とユーザーフィードバックを管理
+type FeedbackStore struct {
+	mu       sync.RWMutex
+	records  map[string]*NotificationRecord
+	filePath string
+	backend  FeedbackBackend     // nilの場合はfilePathに保存
+	dirty    map[string]struct{} // backendに未保存の記録
+}
+
+// NewFeedbackStore は新しいFeedbackStoreを作成
+func NewFeedbackStore(filePath string) (*FeedbackStore, error) {
+	fs := &FeedbackStore{
+		records:  make(map[string]*NotificationRecord),
+		filePath: filePath,
+	}
+
+	// ファイルが存在する場合は読み込み
+	if _, err := os.Stat(filePath); err == nil {
+		if err := fs.Load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return fs, nil
+}
+
+// NewFeedbackStoreWith はbackendに保存するFeedbackStoreを作成（直近の記録をメモリに読み込む）
+func NewFeedbackStoreWith(backend FeedbackBackend) (*FeedbackStore, error) {
+	recs, err := backend.Notifications(time.Time{}, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feedback: %w", err)
+	}
+	fs := &FeedbackStore{
+		records: make(map[string]*NotificationRecord, len(recs)),
+		backend: backend,
+		dirty:   make(map[string]struct{}),
+	}
+	for i := range recs {
+		fs.records[recs[i].TweetID] = &recs[i]
+	}
+	fs.pruneLocked()
+	return fs, nil
+}
+
+// RecordNotification は通知したツイートを記録
+func (fs *FeedbackStore) RecordNotification(rec NotificationRecord) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if existing, ok := fs.records[rec.TweetID]; ok {
+		rec.Label = existing.Label
+		rec.LabeledAt = existing.LabeledAt
+		rec.LabeledBy = existing.LabeledBy
+		rec.BasePrices = existing.BasePrices
+		rec.BasePriced = existing.BasePriced
+		rec.Outcomes = existing.Outcomes
+	}
+	fs.records[rec.TweetID] = &rec
+	fs.markDirty(rec.TweetID)
+	fs.pruneLocked()
+}
+
+// SetLabel はツイートにフィードバックを記録（byは評価したユーザー、不明な場合は空）
+func (fs *FeedbackStore) SetLabel(tweetID, label, by string) error {
+	if label != LabelUseful && label != LabelNoise {
+		return fmt.Errorf("unknown feedback label: %s", label)
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	rec, ok := fs.records[tweetID]
+	if !ok {
+		return fmt.Errorf("no notification record for tweet %s", tweetID)
+	}
+	rec.Label = label
+	rec.LabeledAt = time.Now()
+	rec.LabeledBy = by
+	fs.markDirty(tweetID)
+	return nil
+}
+
+// SetBasePrices は通知直後の銘柄ごとの株価を記録
+func (fs *FeedbackStore) SetBasePrices(tweetID string, prices map[string]float64, at time.Time) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	rec, ok := fs.records[tweetID]
+	if !ok {
+		return fmt.Errorf("no notification record for tweet %s", tweetID)
+	}
+	rec.BasePrices = prices
+	rec.BasePriced = at
+	fs.markDirty(tweetID)
+	return nil
+}
+
+// AddOutcome は通知からN時間後の値動きを記録（記録済みの場合は何もしない）
+func (fs *FeedbackStore) AddOutcome(tweetID string, o Outcome) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	rec, ok := fs.records[tweetID]
+	if !ok {
+		return fmt.Errorf("no notification record for tweet %s", tweetID)
+	}
+	if rec.HasOutcome(o.Ticker, o.Hours) {
+		return nil
+	}
+	rec.Outcomes = append(rec.Outcomes, o)
+	fs.markDirty(tweetID)
+	return nil
+}
+
+// WithTickers は銘柄を含む記録を通知の古い順に返す（事後の値動きの記録用）
+func (fs *FeedbackStore) WithTickers() []NotificationRecord {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	var recs []NotificationRecord
+	for _, rec := range fs.records {
+		if len(rec.Tickers) > 0 {
+			recs = append(recs, *rec)
+		}
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		return recs[i].NotifiedAt.Before(recs[j].NotifiedAt)
+	})
+	return recs
+}
+
+// Records は[since, until)に通知した記録を古い順に返す（untilがゼロ値の場合は現在まで）
+// 保存先がbackendの場合はメモリにない古い記録も含む
+func (fs *FeedbackStore) Records(since, until time.Time) ([]NotificationRecord, error) {
+	if fs.backend != nil {
+		if err := fs.Save(); err != nil {
+			return nil, err
+		}
+		return fs.backend.Notifications(since, until)
+	}
+
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	var recs []NotificationRecord
+	for _, rec := range fs.records {
+		if inRange(rec.NotifiedAt, since, until) {
+			recs = append(recs, *rec)
+		}
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		return recs[i].NotifiedAt.Before(recs[j].NotifiedAt)
+	})
+	return recs, nil
+}
+
+// markDirty はbackendに未保存の記録として印を付ける（ロック取得済みで呼ぶ）
+func (fs *FeedbackStore) markDirty(tweetID string) {
+	if fs.backend != nil {
+		fs.dirty[tweetID] = struct{}{}
+	}
+}
+
+// RecentLabeled はフィードバック済みの記録を新しい順に最大n件返す
+func (fs *FeedbackStore) RecentLabeled(n int) []NotificationRecord {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	var labeled []NotificationRecord
+	for _, rec := range fs.records {
+		if rec.Label != "" {
+			labeled = append(labeled, *rec)
+		}
+	}
+	sort.Slice(labeled, func(i, j int) bool {
+		return labeled[i].LabeledAt.After(labeled[j].LabeledAt)
+	})
+	if len(labeled) > n {
+		labeled = labeled[:n]
+	}
+	return labeled
+}
+
+// LabelCounts は投稿者ごとのフィードバック件数 (useful, noise) を返す
+func (fs *FeedbackStore) LabelCounts() map[string][2]int {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	counts := make(map[string][2]int)
+	for _, rec := range fs.records {
+		c := counts[rec.Username]
+		switch rec.Label {
+		case LabelUseful:
+			c[0]++
+		case LabelNoise:
+			c[1]++
+		default:
+			continue
+		}
+		counts[rec.Username] = c
+	}
+	return counts
+}
+
+// Save はフィードバックをファイルに保存（backendの場合は変更した記録だけを保存）
+func (fs *FeedbackStore) Save() error {
+	if fs.backend != nil {
+		return fs.saveBackend()
+	}
+
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	data, err := json.MarshalIndent(fs.records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal feedback: %w", err)
+	}
+
+	if err := writeFileAtomic(fs.filePath, data); err != nil {
+		return fmt.Errorf("failed to write feedback file: %w", err)
+	}
+
+	return nil
+}
+
+// saveBackend は変更した記録をbackendに保存
+func (fs *FeedbackStore) saveBackend() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if len(fs.dirty) == 0 {
+		return nil
+	}
+	recs := make([]NotificationRecord, 0, len(fs.dirty))
+	for id := range fs.dirty {
+		if rec, ok := fs.records[id]; ok {
+			recs = append(recs, *rec)
+		}
+	}
+	if err := fs.backend.SaveNotifications(recs); err != nil {
+		return fmt.Errorf("failed to save feedback: %w", err)
+	}
+	fs.dirty = make(map[string]struct{})
+	return nil
+}
+
+// Load はフィードバックをファイルから読み込み
+func (fs *FeedbackStore) Load() error {
+	data, err := readFile(fs.filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read feedback file: %w", err)
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if err := json.Unmarshal(data, &fs.records); err != nil {
+		return fmt.Errorf("failed to unmarshal feedback: %w", err)
+	}
+
+	return nil
+}
+
+// pruneLocked は上限を超えた古い記録を削除（ロック取得済みで呼ぶ）
+func (fs *FeedbackStore) pruneLocked() {
+	if len(fs.records) <= maxFeedbackRecords {
+		return
+	}
+
+	ids := make([]string, 0, len(fs.records))
+	for id := range fs.records {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return fs.records[ids[i]].NotifiedAt.Before(fs.records[ids[j]].NotifiedAt)
+	})
+	for _, id := range ids[:len(ids)-maxFeedbackRecords] {
+		// backendに未保存の記録は保存するまで残す
+		if _, ok := fs.dirty[id]; ok {
+			continue
+		}
+		delete(fs.records, id)
+	}
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// backupSuffix は1つ前に保存した状態ファイルのバックアップの拡張子
+const backupSuffix = ".bak"
+
+// writeFileAtomic は同じディレクトリの一時ファイルに書き込んでから置き換える
+// 書き込み中に異常終了しても元のファイルは壊れない（暗号化の鍵が設定されていれば暗号化する）
+func writeFileAtomic(path string, data []byte) error {
+	data, err := EncryptFile(data)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt %s: %w", filepath.Base(path), err)
+	}
+	return writeRawFileAtomic(path, data)
+}
+
+// writeRawFileAtomic はdataをそのまま（暗号化せずに）writeFileAtomicと同じ手順で書き込む
+func writeRawFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	// 置き換える前にディスクに書き出す（電源断で中身が空のファイルに置き換わらないようにする）
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// writeFileWithBackup は現在のファイルを path.bak に残してから置き換える
+func writeFileWithBackup(path string, data []byte) error {
+	if err := backupFile(path); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// backupFile は現在のファイルを path.bak にする（ファイルがない場合は何もしない）
+func backupFile(path string) error {
+	backup := path + backupSuffix
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	// ハードリンクできればコピーしない（置き換え後もバックアップは前の中身を指す）
+	tmp := backup + ".tmp"
+	os.Remove(tmp)
+	if err := os.Link(path, tmp); err == nil {
+		return os.Rename(tmp, backup)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return writeRawFileAtomic(backup, data)
+}
+
+// loadWithRecovery はpathを読み込んでparseで検証し、壊れている場合は path.bak から復元する
+// 壊れたファイルは調査できるよう path.corrupt-日時 に残す（バックアップも壊れている場合はエラー）
+func loadWithRecovery(path string, parse func(data []byte) error) error {
+	data, err := readFile(path)
+	if err == nil {
+		if err = parse(data); err == nil {
+			return nil
+		}
+	}
+
+	backup := path + backupSuffix
+	log.Printf("%s is corrupted (%v), recovering from %s", path, err, backup)
+	backupData, backupErr := readFile(backup)
+	if backupErr == nil {
+		backupErr = parse(backupData)
+	}
+	if backupErr != nil {
+		return fmt.Errorf("%s is corrupted (%v) and could not be recovered from %s: %w", path, err, backup, backupErr)
+	}
+
+	corrupt := path + ".corrupt-" + time.Now().Format("20060102-150405")
+	if err := os.Rename(path, corrupt); err != nil {
+		return fmt.Errorf("failed to move corrupted %s aside: %w", path, err)
+	}
+	raw, err := os.ReadFile(backup)
+	if err != nil {
+		return err
+	}
+	if err := writeRawFileAtomic(path, raw); err != nil {
+		return fmt.Errorf("failed to restore %s from %s: %w", path, backup, err)
+	}
+	log.Printf("Recovered %s from %s (corrupted file kept as %s)", path, backup, corrupt)
+	return nil
+}
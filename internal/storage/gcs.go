@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gcsMetadataTokenURL はCloud Run / GCEのサービスアカウントのアクセストークンを返すメタデータサーバーのURL
+const gcsMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// gcsObject はGoogle Cloud Storageの1つのオブジェクト
+// アクセストークンは GOOGLE_OAUTH_ACCESS_TOKEN、なければメタデータサーバー（Cloud Run / GCE のサービスアカウント）から取得する
+type gcsObject struct {
+	httpClient *http.Client
+	bucket     string
+	key        string
+	endpoint   string // 空の場合は https://storage.googleapis.com
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func newGCSObject(httpClient *http.Client, bucket, key, endpoint string) *gcsObject {
+	if endpoint == "" {
+		endpoint = "https://storage.googleapis.com"
+	}
+	return &gcsObject{httpClient: httpClient, bucket: bucket, key: key, endpoint: strings.TrimSuffix(endpoint, "/")}
+}
+
+// String はオブジェクトのURLを返す
+func (o *gcsObject) String() string {
+	return "gs://" + o.bucket + "/" + o.key
+}
+
+// Get はオブジェクトの内容とgenerationを返す
+func (o *gcsObject) Get(ctx context.Context) ([]byte, string, error) {
+	reqURL := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", o.endpoint, url.PathEscape(o.bucket), url.PathEscape(o.key))
+	resp, err := o.do(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", o, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, "", ErrObjectNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, "", fmt.Errorf("failed to read %s: status %d: %s", o, resp.StatusCode, truncateBody(body))
+	}
+	return body, resp.Header.Get("X-Goog-Generation"), nil
+}
+
+// Put はgenerationがversionの場合だけ書き込む（versionが空の場合は ifGenerationMatch=0 で新規作成のみ）
+func (o *gcsObject) Put(ctx context.Context, data []byte, version string) (string, error) {
+	if version == "" {
+		version = "0"
+	}
+	q := url.Values{}
+	q.Set("uploadType", "media")
+	q.Set("name", o.key)
+	q.Set("ifGenerationMatch", version)
+	reqURL := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?%s", o.endpoint, url.PathEscape(o.bucket), q.Encode())
+	resp, err := o.do(ctx, http.MethodPost, reqURL, data)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed:
+		return "", ErrObjectConflict
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("failed to write %s: status %d: %s", o, resp.StatusCode, truncateBody(body))
+	}
+	var obj struct {
+		Generation string `json:"generation"`
+	}
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return "", fmt.Errorf("failed to decode response of %s: %w", o, err)
+	}
+	return obj.Generation, nil
+}
+
+// do は認証付きのリクエストを送る
+func (o *gcsObject) do(ctx context.Context, method, reqURL string, body []byte) (*http.Response, error) {
+	token, err := o.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request %s: %w", o, err)
+	}
+	return resp, nil
+}
+
+// accessToken は環境変数またはメタデータサーバーのアクセストークンを返す（期限の1分前まで使い回す）
+// endpointを指定した場合（エミュレーター）でトークンがなければ認証しない
+func (o *gcsObject) accessToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+	if o.endpoint != "https://storage.googleapis.com" {
+		return "", nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.token != "" && time.Until(o.expires) > time.Minute {
+		return o.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcsMetadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get GCS access token from metadata server (set GOOGLE_OAUTH_ACCESS_TOKEN outside Google Cloud): %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get GCS access token from metadata server: status %d", resp.StatusCode)
+	}
+	var t struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return "", fmt.Errorf("failed to decode GCS access token: %w", err)
+	}
+	o.token = t.AccessToken
+	o.expires = time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	return o.token, nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SaveNotifications は通知記録とフィードバックを保存し、事後の値動きを outcomes に追加（FeedbackBackend）
+func (s *SQLite) SaveNotifications(recs []NotificationRecord) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, rec := range recs {
+		outcomes := rec.Outcomes
+		rec.Outcomes = nil
+		data, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		var labeledAt int64
+		if !rec.LabeledAt.IsZero() {
+			labeledAt = rec.LabeledAt.Unix()
+		}
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO notifications
+			(tweet_id, username, source, score, category, notified_at, label, labeled_at, labeled_by, data)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			rec.TweetID, rec.Username, rec.Source, rec.Score, rec.Category, rec.NotifiedAt.Unix(),
+			rec.Label, labeledAt, rec.LabeledBy, string(data)); err != nil {
+			return fmt.Errorf("failed to save notification %s: %w", rec.TweetID, err)
+		}
+		for _, o := range outcomes {
+			if _, err := tx.Exec(`INSERT OR IGNORE INTO outcomes
+				(tweet_id, ticker, hours, base_price, price, change_pct, measured_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				rec.TweetID, o.Ticker, o.Hours, o.BasePrice, o.Price, o.ChangePct, o.MeasuredAt.Unix()); err != nil {
+				return fmt.Errorf("failed to save outcome of %s: %w", rec.TweetID, err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// Notifications は[since, until)に通知した記録を事後の値動きとともに古い順に返す（FeedbackBackend）
+func (s *SQLite) Notifications(since, until time.Time) ([]NotificationRecord, error) {
+	if until.IsZero() {
+		until = time.Now().Add(time.Second)
+	}
+	rows, err := s.db.Query(`SELECT data FROM notifications WHERE notified_at >= ? AND notified_at < ? ORDER BY notified_at`,
+		since.Unix(), until.Unix())
+	if err != nil {
+		return nil, err
+	}
+	var recs []NotificationRecord
+	index := make(map[string]int)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		var rec NotificationRecord
+		if err := json.Unmarshal([]byte(data), &rec); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to decode notification: %w", err)
+		}
+		index[rec.TweetID] = len(recs)
+		recs = append(recs, rec)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(`SELECT o.tweet_id, o.ticker, o.hours, o.base_price, o.price, o.change_pct, o.measured_at
+		FROM outcomes o JOIN notifications n ON n.tweet_id = o.tweet_id
+		WHERE n.notified_at >= ? AND n.notified_at < ? ORDER BY o.ticker, o.hours`,
+		since.Unix(), until.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var o Outcome
+		var measuredAt int64
+		if err := rows.Scan(&id, &o.Ticker, &o.Hours, &o.BasePrice, &o.Price, &o.ChangePct, &measuredAt); err != nil {
+			return nil, err
+		}
+		o.MeasuredAt = time.Unix(measuredAt, 0)
+		if i, ok := index[id]; ok {
+			recs[i].Outcomes = append(recs[i].Outcomes, o)
+		}
+	}
+	return recs, rows.Err()
+}
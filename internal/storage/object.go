@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// objectTimeout は1回のオブジェクトの読み書きのタイムアウト
+const objectTimeout = 30 * time.Second
+
+var (
+	// ErrObjectNotFound はオブジェクトがまだ存在しないことを表す
+	ErrObjectNotFound = errors.New("object not found")
+	// ErrObjectConflict は読み込んだ後に別のインスタンスがオブジェクトを更新したことを表す
+	ErrObjectConflict = errors.New("object was modified concurrently")
+)
+
+// ObjectStore はバージョン（S3のETag、GCSのgeneration）による楽観的ロック付きで1つのオブジェクトを読み書きする
+type ObjectStore interface {
+	// Get はオブジェクトの内容とバージョンを返す（存在しない場合はErrObjectNotFound）
+	Get(ctx context.Context) ([]byte, string, error)
+	// Put はオブジェクトのバージョンがversionの場合だけ書き込み、新しいバージョンを返す
+	// versionが空の場合はオブジェクトが存在しない場合だけ書き込む（一致しない場合はErrObjectConflict）
+	Put(ctx context.Context, data []byte, version string) (string, error)
+	// String はログに表示するオブジェクトのURL
+	String() string
+}
+
+// OpenObjectStore は s3://bucket/key または gs://bucket/key のオブジェクトを開く
+// regionはS3のリージョン（空の場合は AWS_REGION）、endpointはS3互換のサービスやエミュレーターのURL（空の場合は本番のAPI）
+func OpenObjectStore(rawURL, region, endpoint string) (ObjectStore, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object URL: %w", err)
+	}
+	bucket, key := u.Host, strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("invalid object URL %q (expected s3://bucket/key or gs://bucket/key)", rawURL)
+	}
+	httpClient := &http.Client{Timeout: objectTimeout}
+	switch u.Scheme {
+	case "s3":
+		return newS3Object(httpClient, bucket, key, region, endpoint), nil
+	case "gs":
+		return newGCSObject(httpClient, bucket, key, endpoint), nil
+	default:
+		return nil, fmt.Errorf("unsupported object URL scheme %q (expected s3 or gs)", u.Scheme)
+	}
+}
@@ -0,0 +1,364 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTimeout は1回のRedisコマンドのタイムアウト
+const redisTimeout = 5 * time.Second
+
+// setCheckpointScript は記録済みより新しいIDの場合だけチェックポイントを更新する（複数のインスタンスから同時に呼ばれても安全）
+var setCheckpointScript = redis.NewScript(`
+local cur = redis.call('HGET', KEYS[1], ARGV[1])
+if cur then
+	cur = cjson.decode(cur).tweet_id
+	if #ARGV[2] < #cur or (#ARGV[2] == #cur and ARGV[2] <= cur) then
+		return 0
+	end
+end
+redis.call('HSET', KEYS[1], ARGV[1], cjson.encode({tweet_id = ARGV[2], updated_at = tonumber(ARGV[3])}))
+return 1
+`)
+
+// Redis は既読ツイートID・取得元ごとのチェックポイント・Slackの送信待ちをRedisで管理
+// 複数のインスタンスや再起動で消えるコンテナから同じ状態を共有できる
+//
+// キー（prefixは設定で変更可能）:
+//   - {prefix}:seen          既読ツイートID（ソート済みセット、スコアは既読にしたUNIX時刻）
+//   - {prefix}:checkpoints   取得元 -> {"tweet_id", "updated_at"}（ハッシュ）
+//   - {prefix}:queue:{name}  Slackの送信待ちのメッセージ（インスタンスごと）
+//   - {prefix}:content:{hash}  同じ本文を最初に投稿したツイートID（期限付き）
+//   - {prefix}:rollups:{day}   軸|値|項目 -> 件数（日ごとの集計、ハッシュ）
+//   - {prefix}:rollup_days     集計のある日（セット）
+type Redis struct {
+	client    *redis.Client
+	prefix    string
+	retention time.Duration // 0の場合は既読IDを削除しない
+	queueName string
+}
+
+// OpenRedis はRedisに接続する（redis://[:password@]host:port/db 形式のURL、retentionより古い既読IDはSaveで削除）
+func OpenRedis(url, prefix string, retention time.Duration, queueName string) (*Redis, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	return &Redis{client: client, prefix: prefix, retention: retention, queueName: queueName}, nil
+}
+
+// Close は接続を閉じる
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
+
+// key はprefixを付けたキーを返す
+func (r *Redis) key(name string) string {
+	return r.prefix + ":" + name
+}
+
+// Has は指定されたツイートIDが既読かチェック（読み込みに失敗した場合は未読として扱う）
+func (r *Redis) Has(tweetID string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	err := r.client.ZScore(ctx, r.key("seen"), tweetID).Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Printf("Failed to query seen tweet %s: %v", tweetID, err)
+	}
+	return err == nil
+}
+
+// Add は新しいツイートIDを既読にする
+func (r *Redis) Add(tweetID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	err := r.client.ZAddNX(ctx, r.key("seen"), redis.Z{Score: float64(time.Now().Unix()), Member: tweetID}).Err()
+	if err != nil {
+		log.Printf("Failed to add seen tweet %s: %v", tweetID, err)
+	}
+}
+
+// ImportSeen は既読にした時刻を指定してツイートIDを既読にする（SeenMigrator、1000件ずつ送る）
+func (r *Redis) ImportSeen(entries []SeenEntry) error {
+	for start := 0; start < len(entries); start += 1000 {
+		end := start + 1000
+		if end > len(entries) {
+			end = len(entries)
+		}
+		members := make([]redis.Z, 0, end-start)
+		for _, e := range entries[start:end] {
+			members = append(members, redis.Z{Score: float64(e.SeenAt.Unix()), Member: e.TweetID})
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+		err := r.client.ZAddNX(ctx, r.key("seen"), members...).Err()
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SeenEntries は既読ツイートIDと既読にした時刻を返す（SeenMigrator）
+func (r *Redis) SeenEntries() ([]SeenEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	members, err := r.client.ZRangeWithScores(ctx, r.key("seen"), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]SeenEntry, len(members))
+	for i, m := range members {
+		id, _ := m.Member.(string)
+		entries[i] = SeenEntry{TweetID: id, SeenAt: time.Unix(int64(m.Score), 0)}
+	}
+	return entries, nil
+}
+
+// Count は既読ツイート数を返す
+func (r *Redis) Count() int {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	n, err := r.client.ZCard(ctx, r.key("seen")).Result()
+	if err != nil {
+		log.Printf("Failed to count seen tweets: %v", err)
+	}
+	return int(n)
+}
+
+// Save は保持期間を過ぎた既読IDを削除する（書き込みはその場で反映済み）
+func (r *Redis) Save() error {
+	if r.retention <= 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	cutoff := strconv.FormatInt(time.Now().Add(-r.retention).Unix(), 10)
+	if err := r.client.ZRemRangeByScore(ctx, r.key("seen"), "-inf", "("+cutoff).Err(); err != nil {
+		return fmt.Errorf("failed to prune seen tweets in Redis: %w", err)
+	}
+	return nil
+}
+
+// SetCheckpoint は取得元の最新のツイートIDを記録（記録済みより古いIDの場合は更新しない）
+func (r *Redis) SetCheckpoint(source, tweetID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	err := setCheckpointScript.Run(ctx, r.client, []string{r.key("checkpoints")}, source, tweetID, time.Now().Unix()).Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to record checkpoint of %s: %w", source, err)
+	}
+	return nil
+}
+
+// Checkpoint は取得元のチェックポイントを返す
+func (r *Redis) Checkpoint(source string) (Checkpoint, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	data, err := r.client.HGet(ctx, r.key("checkpoints"), source).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return Checkpoint{}, false, nil
+	}
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+	var v struct {
+		TweetID   string `json:"tweet_id"`
+		UpdatedAt int64  `json:"updated_at"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return Checkpoint{}, false, fmt.Errorf("failed to decode checkpoint of %s: %w", source, err)
+	}
+	return Checkpoint{Source: source, TweetID: v.TweetID, UpdatedAt: time.Unix(v.UpdatedAt, 0)}, true, nil
+}
+
+// Checkpoints は全ての取得元のチェックポイントを返す（CheckpointLister）
+func (r *Redis) Checkpoints() ([]Checkpoint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	sources, err := r.client.HKeys(ctx, r.key("checkpoints")).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(sources)
+	checkpoints := make([]Checkpoint, 0, len(sources))
+	for _, source := range sources {
+		cp, ok, err := r.Checkpoint(source)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			checkpoints = append(checkpoints, cp)
+		}
+	}
+	return checkpoints, nil
+}
+
+// ReadQueue はSlackの送信待ちのメッセージを読み込む（slack.QueueBackend）
+func (r *Redis) ReadQueue() ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	data, err := r.client.Get(ctx, r.key("queue:"+r.queueName)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// WriteQueue はSlackの送信待ちのメッセージを書き込む（slack.QueueBackend）
+func (r *Redis) WriteQueue(data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := r.client.Set(ctx, r.key("queue:"+r.queueName), data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to write notification queue to Redis: %w", err)
+	}
+	return nil
+}
+
+// ContentStore は本文のハッシュを期限付きのキーで管理するContentStoreを返す（ContentStorer）
+func (r *Redis) ContentStore(ttl time.Duration) ContentStore {
+	return &redisContent{r: r, ttl: ttl}
+}
+
+// redisContent はRedisのContentStore（期限切れのキーはRedisが削除する）
+type redisContent struct {
+	r   *Redis
+	ttl time.Duration
+}
+
+// MarkContent は本文のハッシュを記録し、ttl以内に別のツイートが記録されていればそのIDを返す
+func (c *redisContent) MarkContent(hash, tweetID string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	key := c.r.key("content:" + hash)
+	ok, err := c.r.client.SetNX(ctx, key, tweetID, c.ttl).Result()
+	if err != nil || ok {
+		return "", err
+	}
+	first, err := c.r.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) || first == tweetID {
+		return "", nil
+	}
+	return first, err
+}
+
+// Save は何もしない（期限切れのキーはRedisが削除する）
+func (c *redisContent) Save() error {
+	return nil
+}
+
+// AddRollups は日・軸・値ごとの集計に件数を足す（複数のインスタンスから同時に呼ばれても安全、RollupStore）
+func (r *Redis) AddRollups(rows []Rollup) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	pipe := r.client.TxPipeline()
+	for _, d := range rows {
+		key := r.key("rollups:" + d.Day)
+		field := d.Dimension + "|" + d.Key + "|"
+		pipe.SAdd(ctx, r.key("rollup_days"), d.Day)
+		pipe.HIncrBy(ctx, key, field+"processed", int64(d.Processed))
+		pipe.HIncrBy(ctx, key, field+"notified", int64(d.Notified))
+		pipe.HIncrBy(ctx, key, field+"scored", int64(d.Scored))
+		pipe.HIncrBy(ctx, key, field+"score_sum", int64(d.ScoreSum))
+		pipe.HIncrByFloat(ctx, key, field+"ai_cost_usd", d.AICostUSD)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record rollup: %w", err)
+	}
+	return nil
+}
+
+// Rollups は[since, until]の日のdimensionの集計を返す（RollupStore）
+func (r *Redis) Rollups(dimension, since, until string) ([]Rollup, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	days, err := r.client.SMembers(ctx, r.key("rollup_days")).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(days)
+
+	var rollups []Rollup
+	for _, day := range days {
+		if !inDayRange(day, since, until) {
+			continue
+		}
+		fields, err := r.client.HGetAll(ctx, r.key("rollups:"+day)).Result()
+		if err != nil {
+			return nil, err
+		}
+		byKey := make(map[string]*Rollup)
+		var keys []string
+		for field, value := range fields {
+			_, dim, rest, ok := parseRollupKey("|" + field)
+			if !ok || (dimension != "" && dim != dimension) {
+				continue
+			}
+			i := strings.LastIndex(rest, "|")
+			if i < 0 {
+				continue
+			}
+			key, name := rest[:i], rest[i+1:]
+			ro, ok := byKey[dim+"|"+key]
+			if !ok {
+				ro = &Rollup{Day: day, Dimension: dim, Key: key}
+				byKey[dim+"|"+key] = ro
+				keys = append(keys, dim+"|"+key)
+			}
+			switch name {
+			case "processed":
+				ro.Processed, _ = strconv.Atoi(value)
+			case "notified":
+				ro.Notified, _ = strconv.Atoi(value)
+			case "scored":
+				ro.Scored, _ = strconv.Atoi(value)
+			case "score_sum":
+				ro.ScoreSum, _ = strconv.Atoi(value)
+			case "ai_cost_usd":
+				ro.AICostUSD, _ = strconv.ParseFloat(value, 64)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			rollups = append(rollups, *byKey[k])
+		}
+	}
+	return rollups, nil
+}
+
+// PruneRollups はbeforeより前の日の集計を削除する（RollupStore）
+func (r *Redis) PruneRollups(before string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	days, err := r.client.SMembers(ctx, r.key("rollup_days")).Result()
+	if err != nil {
+		return fmt.Errorf("failed to prune rollups: %w", err)
+	}
+	for _, day := range days {
+		if day >= before {
+			continue
+		}
+		if err := r.client.Del(ctx, r.key("rollups:"+day)).Err(); err != nil {
+			return fmt.Errorf("failed to prune rollups: %w", err)
+		}
+		r.client.SRem(ctx, r.key("rollup_days"), day)
+	}
+	return nil
+}
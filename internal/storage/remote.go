@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// remoteSaveRetries は別のインスタンスと同時に保存した場合に読み込み直して保存し直す回数
+const remoteSaveRetries = 5
+
+// remoteSnapshot はオブジェクトに保存する状態
+type remoteSnapshot struct {
+	Seen        map[string]int64          `json:"seen"` // ツイートID -> 既読にしたUNIX時刻
+	Checkpoints map[string]boltCheckpoint `json:"checkpoints"`
+}
+
+// Remote は既読ツイートIDと取得元ごとのチェックポイントをS3/GCSの1つのオブジェクトに保存する
+// 永続ボリュームのないFargateやCloud Runで使え、起動時に読み込み、Saveで書き込む
+// 書き込みは読み込んだバージョンの場合だけ行い、別のインスタンスが更新していた場合は読み込み直して統合する
+type Remote struct {
+	mu          sync.Mutex
+	object      ObjectStore
+	version     string // 最後に読み書きしたオブジェクトのバージョン（空の場合は未作成）
+	seen        map[string]int64
+	checkpoints map[string]boltCheckpoint
+	dirty       bool
+	retention   time.Duration // 0の場合は既読IDを削除しない
+}
+
+// OpenRemote はオブジェクトから状態を読み込む（存在しない場合は空の状態から始める）
+func OpenRemote(object ObjectStore, retention time.Duration) (*Remote, error) {
+	r := &Remote{
+		object:      object,
+		seen:        make(map[string]int64),
+		checkpoints: make(map[string]boltCheckpoint),
+		retention:   retention,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), objectTimeout)
+	defer cancel()
+	snap, version, err := r.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.version = version
+	r.merge(snap)
+	return r, nil
+}
+
+// fetch はオブジェクトの状態を読み込む（存在しない場合は空の状態）
+func (r *Remote) fetch(ctx context.Context) (remoteSnapshot, string, error) {
+	data, version, err := r.object.Get(ctx)
+	if errors.Is(err, ErrObjectNotFound) {
+		return remoteSnapshot{}, "", nil
+	}
+	if err != nil {
+		return remoteSnapshot{}, "", err
+	}
+	if data, err = DecryptFile(data); err != nil {
+		return remoteSnapshot{}, "", fmt.Errorf("failed to decrypt %s: %w", r.object, err)
+	}
+	var snap remoteSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return remoteSnapshot{}, "", fmt.Errorf("failed to parse %s: %w", r.object, err)
+	}
+	return snap, version, nil
+}
+
+// merge は読み込んだ状態を統合する（既読IDは和集合、チェックポイントは新しい方）
+func (r *Remote) merge(snap remoteSnapshot) {
+	for id, at := range snap.Seen {
+		if cur, ok := r.seen[id]; !ok || at < cur {
+			r.seen[id] = at
+		}
+	}
+	for source, cp := range snap.Checkpoints {
+		if cur, ok := r.checkpoints[source]; !ok || NewerTweetID(cp.TweetID, cur.TweetID) {
+			r.checkpoints[source] = cp
+		}
+	}
+}
+
+// Close は何もしない（保存はSaveで行う）
+func (r *Remote) Close() error {
+	return nil
+}
+
+// Has は指定されたツイートIDが既読かチェック
+func (r *Remote) Has(tweetID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.seen[tweetID]
+	return ok
+}
+
+// Add は新しいツイートIDを既読にする（既読の場合は時刻を更新しない）
+func (r *Remote) Add(tweetID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.seen[tweetID]; !ok {
+		r.seen[tweetID] = time.Now().Unix()
+		r.dirty = true
+	}
+}
+
+// Count は既読ツイート数を返す
+func (r *Remote) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.seen)
+}
+
+// ImportSeen は既読にした時刻を指定してツイートIDを既読にする（SeenMigrator）
+func (r *Remote) ImportSeen(entries []SeenEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range entries {
+		if _, ok := r.seen[e.TweetID]; !ok {
+			r.seen[e.TweetID] = e.SeenAt.Unix()
+			r.dirty = true
+		}
+	}
+	return nil
+}
+
+// SeenEntries は既読ツイートIDと既読にした時刻を返す（SeenMigrator）
+func (r *Remote) SeenEntries() ([]SeenEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := make([]SeenEntry, 0, len(r.seen))
+	for id, at := range r.seen {
+		entries = append(entries, SeenEntry{TweetID: id, SeenAt: time.Unix(at, 0)})
+	}
+	return entries, nil
+}
+
+// SetCheckpoint は取得元の最新のツイートIDを記録（記録済みより古いIDの場合は更新しない、保存はSaveで行う）
+func (r *Remote) SetCheckpoint(source, tweetID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cur, ok := r.checkpoints[source]; ok && !NewerTweetID(tweetID, cur.TweetID) {
+		return nil
+	}
+	r.checkpoints[source] = boltCheckpoint{TweetID: tweetID, UpdatedAt: time.Now().Unix()}
+	r.dirty = true
+	return nil
+}
+
+// Checkpoint は取得元のチェックポイントを返す
+func (r *Remote) Checkpoint(source string) (Checkpoint, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp, ok := r.checkpoints[source]
+	if !ok {
+		return Checkpoint{}, false, nil
+	}
+	return Checkpoint{Source: source, TweetID: cp.TweetID, UpdatedAt: time.Unix(cp.UpdatedAt, 0)}, true, nil
+}
+
+// Checkpoints は全ての取得元のチェックポイントを返す（CheckpointLister）
+func (r *Remote) Checkpoints() ([]Checkpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	checkpoints := make([]Checkpoint, 0, len(r.checkpoints))
+	for source, cp := range r.checkpoints {
+		checkpoints = append(checkpoints, Checkpoint{Source: source, TweetID: cp.TweetID, UpdatedAt: time.Unix(cp.UpdatedAt, 0)})
+	}
+	return checkpoints, nil
+}
+
+// Save は変更があればオブジェクトに書き込む
+// 別のインスタンスが先に更新していた場合は読み込み直して統合し、書き込み直す
+func (r *Remote) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), objectTimeout*remoteSaveRetries)
+	defer cancel()
+
+	pruned := r.prune()
+	if !r.dirty && pruned == 0 {
+		return nil
+	}
+	for attempt := 0; ; attempt++ {
+		data, err := json.Marshal(remoteSnapshot{Seen: r.seen, Checkpoints: r.checkpoints})
+		if err != nil {
+			return err
+		}
+		if data, err = EncryptFile(data); err != nil {
+			return fmt.Errorf("failed to encrypt state: %w", err)
+		}
+		version, err := r.object.Put(ctx, data, r.version)
+		if err == nil {
+			r.version = version
+			r.dirty = false
+			return nil
+		}
+		if !errors.Is(err, ErrObjectConflict) || attempt+1 >= remoteSaveRetries {
+			return fmt.Errorf("failed to save state to %s: %w", r.object, err)
+		}
+
+		log.Printf("State in %s was updated by another instance, merging and retrying", r.object)
+		snap, version, err := r.fetch(ctx)
+		if err != nil {
+			return err
+		}
+		r.version = version
+		r.merge(snap)
+		r.prune()
+	}
+}
+
+// prune は保持期間を過ぎた既読IDを削除し、削除した数を返す
+func (r *Remote) prune() int {
+	if r.retention <= 0 {
+		return 0
+	}
+	cutoff := time.Now().Add(-r.retention).Unix()
+	var n int
+	for id, at := range r.seen {
+		if at < cutoff {
+			delete(r.seen, id)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+)
+
+// 集計の軸
+const (
+	RollupTrader   = "trader"   // 投稿者（@なしのユーザー名）
+	RollupTicker   = "ticker"   // 銘柄（$なし、大文字）
+	RollupCategory = "category" // AI分析のカテゴリ
+)
+
+// Rollup は1日・1つの軸の値（トレーダー・銘柄・カテゴリ）ごとの集計
+type Rollup struct {
+	Day       string  `json:"day"` // YYYY-MM-DD
+	Dimension string  `json:"dimension"`
+	Key       string  `json:"key"`
+	Processed int     `json:"processed"`   // 処理したツイート数
+	Notified  int     `json:"notified"`    // 通知した数（ダイジェストを含む）
+	Scored    int     `json:"scored"`      // AI分析したツイート数
+	ScoreSum  int     `json:"score_sum"`   // AI分析のスコアの合計
+	AICostUSD float64 `json:"ai_cost_usd"` // AIの推定コスト（バッチの場合は件数で按分）
+}
+
+// AvgScore は平均スコアを返す（AI分析していない場合は0）
+func (r Rollup) AvgScore() float64 {
+	if r.Scored == 0 {
+		return 0
+	}
+	return float64(r.ScoreSum) / float64(r.Scored)
+}
+
+// add はdの件数を足す
+func (r *Rollup) add(d Rollup) {
+	r.Processed += d.Processed
+	r.Notified += d.Notified
+	r.Scored += d.Scored
+	r.ScoreSum += d.ScoreSum
+	r.AICostUSD += d.AICostUSD
+}
+
+// RollupStore は日ごとの集計の保存先（StatsStore, SQLite, Redis, bbolt）
+type RollupStore interface {
+	// AddRollups はDay・Dimension・Keyごとに件数を足す
+	AddRollups(rows []Rollup) error
+	// Rollups は[since, until]の日（YYYY-MM-DD、空の場合は制限なし）のdimensionの集計を返す（dimensionが空の場合は全て）
+	Rollups(dimension, since, until string) ([]Rollup, error)
+	// PruneRollups はbefore（YYYY-MM-DD）より前の日の集計を削除する
+	PruneRollups(before string) error
+}
+
+// MergeRollups は日をまたいでDimension・Keyごとに合計し、通知数・処理数の多い順に返す（Dayは空）
+func MergeRollups(rows []Rollup) []Rollup {
+	merged := make(map[string]*Rollup)
+	var keys []string
+	for _, r := range rows {
+		k := rollupKey("", r.Dimension, r.Key)
+		m, ok := merged[k]
+		if !ok {
+			m = &Rollup{Dimension: r.Dimension, Key: r.Key}
+			merged[k] = m
+			keys = append(keys, k)
+		}
+		m.add(r)
+	}
+	out := make([]Rollup, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, *merged[k])
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Notified != out[j].Notified {
+			return out[i].Notified > out[j].Notified
+		}
+		if out[i].Processed != out[j].Processed {
+			return out[i].Processed > out[j].Processed
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+// rollupKey は集計を一意に表すキー（day|dimension|key）
+func rollupKey(day, dimension, key string) string {
+	return day + "|" + dimension + "|" + key
+}
+
+// parseRollupKey はrollupKeyを分解する
+func parseRollupKey(k string) (day, dimension, key string, ok bool) {
+	parts := strings.SplitN(k, "|", 3)
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// inDayRange はdayが[since, until]に含まれるかを返す（空の場合は制限なし）
+func inDayRange(day, since, until string) bool {
+	return (since == "" || day >= since) && (until == "" || day <= until)
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/awsauth"
+)
+
+// s3Object はS3（またはS3互換のサービス）の1つのオブジェクト
+// 認証情報はAWS SDKの標準と同じ順序で探す（環境変数・共有認証情報ファイル・ECSのタスクロール・EC2のIAMロール）
+type s3Object struct {
+	httpClient *http.Client
+	bucket     string
+	key        string
+	region     string
+	endpoint   string // 空の場合は https://{bucket}.s3.{region}.amazonaws.com
+	creds      *awsauth.CredentialChain
+}
+
+func newS3Object(httpClient *http.Client, bucket, key, region, endpoint string) *s3Object {
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &s3Object{httpClient: httpClient, bucket: bucket, key: key, region: region, endpoint: strings.TrimSuffix(endpoint, "/"),
+		creds: awsauth.NewCredentialChain()}
+}
+
+// String はオブジェクトのURLを返す
+func (o *s3Object) String() string {
+	return "s3://" + o.bucket + "/" + o.key
+}
+
+// Get はオブジェクトの内容とETagを返す
+func (o *s3Object) Get(ctx context.Context) ([]byte, string, error) {
+	resp, err := o.do(ctx, http.MethodGet, nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", o, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, "", ErrObjectNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, "", fmt.Errorf("failed to read %s: status %d: %s", o, resp.StatusCode, truncateBody(body))
+	}
+	return body, resp.Header.Get("ETag"), nil
+}
+
+// Put はETagがversionの場合だけ書き込む（S3の条件付き書き込み）
+func (o *s3Object) Put(ctx context.Context, data []byte, version string) (string, error) {
+	header := http.Header{}
+	if version == "" {
+		header.Set("If-None-Match", "*")
+	} else {
+		header.Set("If-Match", version)
+	}
+	resp, err := o.do(ctx, http.MethodPut, data, header)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed, resp.StatusCode == http.StatusConflict:
+		return "", ErrObjectConflict
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("failed to write %s: status %d: %s", o, resp.StatusCode, truncateBody(body))
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+// do は署名付きのリクエストを送る
+func (o *s3Object) do(ctx context.Context, method string, body []byte, header http.Header) (*http.Response, error) {
+	creds, err := o.creds.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var reqURL string
+	if o.endpoint != "" {
+		// S3互換のサービスはパス形式
+		reqURL = o.endpoint + "/" + o.bucket + "/" + s3EscapePath(o.key)
+	} else {
+		reqURL = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", o.bucket, o.region, s3EscapePath(o.key))
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	// S3は本文のハッシュのヘッダーも必要
+	req.Header.Set("X-Amz-Content-Sha256", sha256Hex(body))
+	awsauth.SignV4(req, body, creds, o.region, "s3", time.Now())
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request %s: %w", o, err)
+	}
+	return resp, nil
+}
+
+// s3EscapePath はオブジェクトのキーをURLのパスとしてエスケープする（"/" はそのまま）
+func s3EscapePath(key string) string {
+	var b strings.Builder
+	for _, c := range []byte(key) {
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// truncateBody はエラーメッセージに含めるレスポンスを短くする
+func truncateBody(body []byte) string {
+	s := strings.TrimSpace(string(body))
+	if len(s) > 200 {
+		s = s[:200] + "..."
+	}
+	return s
+}
@@ -4,21 +4,44 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"sync"
+	"time"
 )
 
+// SeenStore は既読ツイートIDの保存先（JSONファイルのSeenTweets、SQLite, Redis, bbolt）
+type SeenStore interface {
+	Has(tweetID string) bool
+	Add(tweetID string)
+	Count() int
+	Save() error
+}
+
+// seenFile はSeenTweetsの保存形式（checksumは seen をJSONにしたもののSHA-256）
+type seenFile struct {
+	Checksum string           `json:"checksum"`
+	Seen     map[string]int64 `json:"seen"`
+}
+
 // SeenTweets は既に通知済みのツイートIDを管理
+// ファイルにはツイートIDごとに最後に見たUNIX時刻を保存し、保持期間を過ぎたIDや上限を超えた分はSaveで削除する
+// 読み込み時にチェックサムとJSONを検証し、壊れている場合は前回保存したバックアップ（.bak）から復元する
 type SeenTweets struct {
-	mu       sync.RWMutex
-	tweets   map[string]bool
-	filePath string
+	mu        sync.Mutex
+	tweets    map[string]int64
+	filePath  string
+	retention time.Duration // 0の場合は削除しない
+	maxIDs    int           // 0の場合は上限なし
 }
 
 // NewSeenTweets は新しいSeenTweetsを作成
-func NewSeenTweets(filePath string) (*SeenTweets, error) {
+// retentionより古い既読IDと、maxIDsを超えた分の最後に見たのが古いIDはSaveで削除
+func NewSeenTweets(filePath string, retention time.Duration, maxIDs int) (*SeenTweets, error) {
 	st := &SeenTweets{
-		tweets:   make(map[string]bool),
-		filePath: filePath,
+		tweets:    make(map[string]int64),
+		filePath:  filePath,
+		retention: retention,
+		maxIDs:    maxIDs,
 	}
 
 	// ファイルが存在する場合は読み込み
@@ -31,57 +54,168 @@ func NewSeenTweets(filePath string) (*SeenTweets, error) {
 	return st, nil
 }
 
-// Has は指定されたツイートIDが既に通知済みかチェック
+// Has は指定されたツイートIDが既に通知済みかチェック（既読の場合は最後に見た時刻を更新）
 func (st *SeenTweets) Has(tweetID string) bool {
-	st.mu.RLock()
-	defer st.mu.RUnlock()
-	return st.tweets[tweetID]
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	_, ok := st.tweets[tweetID]
+	if ok {
+		st.tweets[tweetID] = time.Now().Unix()
+	}
+	return ok
 }
 
-// Add は新しいツイートIDを追加
+// Add は新しいツイートIDを追加（既読の場合は時刻を更新しない）
 func (st *SeenTweets) Add(tweetID string) {
 	st.mu.Lock()
 	defer st.mu.Unlock()
-	st.tweets[tweetID] = true
+	if _, ok := st.tweets[tweetID]; !ok {
+		st.tweets[tweetID] = time.Now().Unix()
+	}
 }
 
-// Save は既読ツイートをファイルに保存
+// Save は保持期間を過ぎた既読IDを削除し、既読ツイートをファイルに保存
 func (st *SeenTweets) Save() error {
-	st.mu.RLock()
-	defer st.mu.RUnlock()
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.retention > 0 {
+		cutoff := time.Now().Add(-st.retention).Unix()
+		for id, seenAt := range st.tweets {
+			if seenAt < cutoff {
+				delete(st.tweets, id)
+			}
+		}
+	}
+	st.evictLocked()
 
-	data, err := json.MarshalIndent(st.tweets, "", "  ")
+	seen, err := json.Marshal(st.tweets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal seen tweets: %w", err)
+	}
+	data, err := json.MarshalIndent(seenFile{Checksum: sha256Hex(seen), Seen: st.tweets}, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal seen tweets: %w", err)
 	}
 
-	if err := os.WriteFile(st.filePath, data, 0644); err != nil {
+	if err := writeFileWithBackup(st.filePath, data); err != nil {
 		return fmt.Errorf("failed to write seen tweets file: %w", err)
 	}
 
 	return nil
 }
 
-// Load は既読ツイートをファイルから読み込み
+// Load は既読ツイートをファイルから読み込み（壊れている場合はバックアップから復元）
 func (st *SeenTweets) Load() error {
-	data, err := os.ReadFile(st.filePath)
+	var tweets map[string]int64
+	err := loadWithRecovery(st.filePath, func(data []byte) error {
+		var err error
+		tweets, err = parseSeenTweets(data)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to read seen tweets file: %w", err)
+		return fmt.Errorf("failed to load seen tweets: %w", err)
 	}
 
 	st.mu.Lock()
 	defer st.mu.Unlock()
+	for id, seenAt := range tweets {
+		st.tweets[id] = seenAt
+	}
+	return nil
+}
 
-	if err := json.Unmarshal(data, &st.tweets); err != nil {
-		return fmt.Errorf("failed to unmarshal seen tweets: %w", err)
+// parseSeenTweets は既読ツイートのファイルを解釈し、チェックサムを検証する
+// 以前の形式（ツイートID -> UNIX時刻、ツイートID -> true）も読み込む
+func parseSeenTweets(data []byte) (map[string]int64, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if _, ok := raw["seen"]; ok {
+		var file seenFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("invalid seen tweets: %w", err)
+		}
+		if file.Seen == nil {
+			file.Seen = make(map[string]int64)
+		}
+		seen, err := json.Marshal(file.Seen)
+		if err != nil {
+			return nil, err
+		}
+		if sum := sha256Hex(seen); sum != file.Checksum {
+			return nil, fmt.Errorf("checksum mismatch (expected %s, got %s)", file.Checksum, sum)
+		}
+		return file.Seen, nil
 	}
 
-	return nil
+	// 以前の形式（ツイートID -> true）は読み込んだ時刻に既読にしたものとして扱う
+	tweets := make(map[string]int64, len(raw))
+	now := time.Now().Unix()
+	for id, v := range raw {
+		var seenAt int64
+		if err := json.Unmarshal(v, &seenAt); err != nil {
+			seenAt = now
+		}
+		tweets[id] = seenAt
+	}
+	return tweets, nil
 }
 
 // Count は既読ツイート数を返す
 func (st *SeenTweets) Count() int {
-	st.mu.RLock()
-	defer st.mu.RUnlock()
+	st.mu.Lock()
+	defer st.mu.Unlock()
 	return len(st.tweets)
 }
+
+// SeenEntries は既読ツイートIDと最後に見た時刻を返す（SeenMigrator）
+func (st *SeenTweets) SeenEntries() ([]SeenEntry, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	entries := make([]SeenEntry, 0, len(st.tweets))
+	for id, seenAt := range st.tweets {
+		entries = append(entries, SeenEntry{TweetID: id, SeenAt: time.Unix(seenAt, 0)})
+	}
+	return entries, nil
+}
+
+// ImportSeen は既読にした時刻を指定してツイートIDを追加（SeenMigrator）
+func (st *SeenTweets) ImportSeen(entries []SeenEntry) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for _, e := range entries {
+		if _, ok := st.tweets[e.TweetID]; !ok {
+			st.tweets[e.TweetID] = e.SeenAt.Unix()
+		}
+	}
+	return nil
+}
+
+// evictLocked は上限を超えた分を最後に見た時刻が古い順に削除
+func (st *SeenTweets) evictLocked() {
+	if st.maxIDs <= 0 || len(st.tweets) <= st.maxIDs {
+		return
+	}
+	ids := make([]string, 0, len(st.tweets))
+	for id := range st.tweets {
+		ids = append(ids, id)
+	}
+	// 同じ時刻の場合は古いツイート（IDが小さい方）から削除
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := st.tweets[ids[i]], st.tweets[ids[j]]
+		if a != b {
+			return a < b
+		}
+		return NewerTweetID(ids[j], ids[i])
+	})
+	for _, id := range ids[:len(ids)-st.maxIDs] {
+		delete(st.tweets, id)
+	}
+}
+
+// Close は何もしない（ファイルはSaveのたびに書き込んで閉じる）
+func (st *SeenTweets) Close() error {
+	return nil
+}
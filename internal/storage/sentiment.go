@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// SentimentRecord はティッカー別集計用の分析結果
+type SentimentRecord struct {
+	TweetID    string    `json:"tweet_id"`
+	Username   string    `json:"username"`
+	Text       string    `json:"text"`
+	Tickers    []string  `json:"tickers"`
+	Score      int       `json:"score"`
+	Sentiment  string    `json:"sentiment"`
+	Summary    string    `json:"summary"`
+	AnalyzedAt time.Time `json:"analyzed_at"`
+}
+
+// sentimentFile はSentimentStoreの保存形式
+type sentimentFile struct {
+	LastReport string                      `json:"last_report"`
+	Records    map[string]*SentimentRecord `json:"records"`
+}
+
+// SentimentStore はティッカー別センチメント集計用の分析結果を管理
+type SentimentStore struct {
+	mu         sync.RWMutex
+	records    map[string]*SentimentRecord
+	lastReport string
+	filePath   string
+}
+
+// NewSentimentStore は新しいSentimentStoreを作成
+func NewSentimentStore(filePath string) (*SentimentStore, error) {
+	ss := &SentimentStore{
+		records:  make(map[string]*SentimentRecord),
+		filePath: filePath,
+	}
+
+	// ファイルが存在する場合は読み込み
+	if _, err := os.Stat(filePath); err == nil {
+		if err := ss.Load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, nil
+}
+
+// Record は分析結果を記録（同じツイートは上書き）
+func (ss *SentimentStore) Record(rec SentimentRecord) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.records[rec.TweetID] = &rec
+}
+
+// Between は指定期間 [from, to) に分析された記録を返す
+func (ss *SentimentStore) Between(from, to time.Time) []SentimentRecord {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	var records []SentimentRecord
+	for _, rec := range ss.records {
+		if !rec.AnalyzedAt.Before(from) && rec.AnalyzedAt.Before(to) {
+			records = append(records, *rec)
+		}
+	}
+	return records
+}
+
+// Prune は指定時刻より前の記録を削除
+func (ss *SentimentStore) Prune(before time.Time) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	for id, rec := range ss.records {
+		if rec.AnalyzedAt.Before(before) {
+			delete(ss.records, id)
+		}
+	}
+}
+
+// LastReport は最後にサマリーを投稿した日付 (YYYY-MM-DD) を返す
+func (ss *SentimentStore) LastReport() string {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	return ss.lastReport
+}
+
+// SetLastReport はサマリーを投稿した日付を記録
+func (ss *SentimentStore) SetLastReport(day string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.lastReport = day
+}
+
+// Save は集計データをファイルに保存
+func (ss *SentimentStore) Save() error {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	data, err := json.MarshalIndent(sentimentFile{
+		LastReport: ss.lastReport,
+		Records:    ss.records,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal sentiment records: %w", err)
+	}
+
+	if err := writeFileAtomic(ss.filePath, data); err != nil {
+		return fmt.Errorf("failed to write sentiment file: %w", err)
+	}
+
+	return nil
+}
+
+// Load は集計データをファイルから読み込み
+func (ss *SentimentStore) Load() error {
+	data, err := readFile(ss.filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read sentiment file: %w", err)
+	}
+
+	var file sentimentFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to unmarshal sentiment records: %w", err)
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.lastReport = file.LastReport
+	if file.Records != nil {
+		ss.records = file.Records
+	}
+
+	return nil
+}
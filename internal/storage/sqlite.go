@@ -0,0 +1,521 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	// SQLiteドライバー（cgoが必要。CGO_ENABLED=0 でビルドした場合は開く時にエラーになる）
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// sqliteSchema はSQLiteのテーブル定義（起動のたびに実行するため冪等にする）
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS seen (
+	tweet_id TEXT PRIMARY KEY,
+	seen_at  INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS seen_seen_at ON seen(seen_at);
+
+CREATE TABLE IF NOT EXISTS tweets (
+	id         TEXT PRIMARY KEY,
+	username   TEXT NOT NULL,
+	source     TEXT NOT NULL,
+	text       TEXT NOT NULL,
+	created_at INTEGER NOT NULL,
+	fetched_at INTEGER NOT NULL,
+	data       TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS tweets_username ON tweets(username);
+CREATE INDEX IF NOT EXISTS tweets_fetched_at ON tweets(fetched_at);
+
+CREATE TABLE IF NOT EXISTS analyses (
+	tweet_id    TEXT PRIMARY KEY,
+	username    TEXT NOT NULL,
+	source      TEXT NOT NULL,
+	score       INTEGER NOT NULL,
+	category    TEXT NOT NULL,
+	urgency     TEXT NOT NULL,
+	decision    TEXT NOT NULL,
+	analyzed_at INTEGER NOT NULL,
+	data        TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS analyses_analyzed_at ON analyses(analyzed_at);
+
+CREATE TABLE IF NOT EXISTS checkpoints (
+	source     TEXT PRIMARY KEY,
+	tweet_id   TEXT NOT NULL,
+	updated_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS content_hashes (
+	hash     TEXT PRIMARY KEY,
+	tweet_id TEXT NOT NULL,
+	seen_at  INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS rollups (
+	day         TEXT NOT NULL,
+	dimension   TEXT NOT NULL,
+	key         TEXT NOT NULL,
+	processed   INTEGER NOT NULL DEFAULT 0,
+	notified    INTEGER NOT NULL DEFAULT 0,
+	scored      INTEGER NOT NULL DEFAULT 0,
+	score_sum   INTEGER NOT NULL DEFAULT 0,
+	ai_cost_usd REAL NOT NULL DEFAULT 0,
+	PRIMARY KEY (day, dimension, key)
+);
+
+CREATE TABLE IF NOT EXISTS ai_calls (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	called_at     INTEGER NOT NULL,
+	source        TEXT NOT NULL,
+	model         TEXT NOT NULL,
+	input_tokens  INTEGER NOT NULL,
+	output_tokens INTEGER NOT NULL,
+	cost_usd      REAL NOT NULL,
+	latency_ms    INTEGER NOT NULL,
+	outcome       TEXT NOT NULL,
+	data          TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS ai_calls_called_at ON ai_calls(called_at);
+
+CREATE TABLE IF NOT EXISTS notifications (
+	tweet_id    TEXT PRIMARY KEY,
+	username    TEXT NOT NULL,
+	source      TEXT NOT NULL,
+	score       INTEGER NOT NULL,
+	category    TEXT NOT NULL,
+	notified_at INTEGER NOT NULL,
+	label       TEXT NOT NULL DEFAULT '',
+	labeled_at  INTEGER NOT NULL DEFAULT 0,
+	labeled_by  TEXT NOT NULL DEFAULT '',
+	data        TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS notifications_notified_at ON notifications(notified_at);
+
+CREATE TABLE IF NOT EXISTS outcomes (
+	tweet_id    TEXT NOT NULL,
+	ticker      TEXT NOT NULL,
+	hours       INTEGER NOT NULL,
+	base_price  REAL NOT NULL,
+	price       REAL NOT NULL,
+	change_pct  REAL NOT NULL,
+	measured_at INTEGER NOT NULL,
+	PRIMARY KEY (tweet_id, ticker, hours)
+);
+`
+
+// SQLite は既読ツイートID・取得したツイート・AI分析結果・取得元ごとのチェックポイントをSQLiteで管理
+// 書き込みはその場で反映されるため、JSONファイルのように全件をメモリに読み込んだり保存し直したりしない
+type SQLite struct {
+	db        *sql.DB
+	path      string
+	retention time.Duration // 0の場合は削除しない
+}
+
+// OpenSQLite はSQLiteのデータベースを開く（存在しない場合は作成、retentionより古い記録はSaveで削除）
+func OpenSQLite(path string, retention time.Duration) (*SQLite, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	// 書き込みを直列化する（SQLiteは同時に1つの書き込みしか扱えない）
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize SQLite database %s: %w", path, err)
+	}
+	return &SQLite{db: db, path: path, retention: retention}, nil
+}
+
+// Close はデータベースを閉じる
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+// Has は指定されたツイートIDが既読かチェック（読み込みに失敗した場合は未読として扱う）
+func (s *SQLite) Has(tweetID string) bool {
+	var one int
+	err := s.db.QueryRow(`SELECT 1 FROM seen WHERE tweet_id = ?`, tweetID).Scan(&one)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Failed to query seen tweet %s: %v", tweetID, err)
+	}
+	return err == nil
+}
+
+// Add は新しいツイートIDを既読にする
+func (s *SQLite) Add(tweetID string) {
+	_, err := s.db.Exec(`INSERT OR IGNORE INTO seen (tweet_id, seen_at) VALUES (?, ?)`, tweetID, time.Now().Unix())
+	if err != nil {
+		log.Printf("Failed to add seen tweet %s: %v", tweetID, err)
+	}
+}
+
+// ImportSeen は既読にした時刻を指定してツイートIDを1つのトランザクションで既読にする（SeenMigrator）
+func (s *SQLite) ImportSeen(entries []SeenEntry) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO seen (tweet_id, seen_at) VALUES (?, ?)`, e.TweetID, e.SeenAt.Unix()); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SeenEntries は既読ツイートIDと既読にした時刻を返す（SeenMigrator）
+func (s *SQLite) SeenEntries() ([]SeenEntry, error) {
+	rows, err := s.db.Query(`SELECT tweet_id, seen_at FROM seen`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []SeenEntry
+	for rows.Next() {
+		var e SeenEntry
+		var seenAt int64
+		if err := rows.Scan(&e.TweetID, &seenAt); err != nil {
+			return nil, err
+		}
+		e.SeenAt = time.Unix(seenAt, 0)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Count は既読ツイート数を返す
+func (s *SQLite) Count() int {
+	var n int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM seen`).Scan(&n); err != nil {
+		log.Printf("Failed to count seen tweets: %v", err)
+	}
+	return n
+}
+
+// Save は保持期間を過ぎた記録を削除する（書き込みはその場で反映済み）
+func (s *SQLite) Save() error {
+	if s.retention <= 0 {
+		return nil
+	}
+	_, err := s.Prune(time.Now().Add(-s.retention))
+	return err
+}
+
+// Prune はbeforeより前の既読ID・ツイート・分析結果・AI呼び出しの記録を削除し、削除した件数を返す
+func (s *SQLite) Prune(before time.Time) (int64, error) {
+	var total int64
+	for _, q := range []struct {
+		query  string
+		cutoff int64
+	}{
+		{`DELETE FROM seen WHERE seen_at < ?`, before.Unix()},
+		{`DELETE FROM tweets WHERE fetched_at < ?`, before.Unix()},
+		{`DELETE FROM analyses WHERE analyzed_at < ?`, before.Unix()},
+		{`DELETE FROM ai_calls WHERE called_at < ?`, before.UnixMilli()},
+	} {
+		res, err := s.db.Exec(q.query, q.cutoff)
+		if err != nil {
+			return total, fmt.Errorf("failed to prune SQLite database: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		total += n
+	}
+	return total, nil
+}
+
+// RecordTweet は取得したツイートを保存（同じツイートは上書き）
+func (s *SQLite) RecordTweet(tweet twitter.Tweet, source string) error {
+	data, err := json.Marshal(tweet)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO tweets (id, username, source, text, created_at, fetched_at, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		tweet.ID, tweet.Username, source, tweet.Text, tweet.CreatedAt.Unix(), time.Now().Unix(), string(data))
+	if err != nil {
+		return fmt.Errorf("failed to record tweet %s: %w", tweet.ID, err)
+	}
+	return nil
+}
+
+// Tweet は保存されたツイートを返す
+func (s *SQLite) Tweet(id string) (twitter.Tweet, bool, error) {
+	var username, data string
+	err := s.db.QueryRow(`SELECT username, data FROM tweets WHERE id = ?`, id).Scan(&username, &data)
+	if err == sql.ErrNoRows {
+		return twitter.Tweet{}, false, nil
+	}
+	if err != nil {
+		return twitter.Tweet{}, false, err
+	}
+	var tweet twitter.Tweet
+	if err := json.Unmarshal([]byte(data), &tweet); err != nil {
+		return twitter.Tweet{}, false, fmt.Errorf("failed to decode tweet %s: %w", id, err)
+	}
+	// Usernameはjsonタグがないため列から復元
+	tweet.Username = username
+	return tweet, true, nil
+}
+
+// Tweets は[since, until)に取得したツイートを古い順に返す（untilがゼロ値の場合は現在まで）
+func (s *SQLite) Tweets(since, until time.Time) ([]ArchivedTweet, error) {
+	if until.IsZero() {
+		until = time.Now().Add(time.Second)
+	}
+	rows, err := s.db.Query(`SELECT username, source, fetched_at, data FROM tweets WHERE fetched_at >= ? AND fetched_at < ? ORDER BY fetched_at`,
+		since.Unix(), until.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tweets []ArchivedTweet
+	for rows.Next() {
+		var t ArchivedTweet
+		var fetchedAt int64
+		var data string
+		if err := rows.Scan(&t.Username, &t.Source, &fetchedAt, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &t.Tweet); err != nil {
+			return nil, fmt.Errorf("failed to decode tweet: %w", err)
+		}
+		t.Tweet.Username = t.Username
+		t.FetchedAt = time.Unix(fetchedAt, 0)
+		tweets = append(tweets, t)
+	}
+	return tweets, rows.Err()
+}
+
+// RecordAnalysis はAI分析結果を保存（同じツイートは上書き）
+func (s *SQLite) RecordAnalysis(rec AnalysisRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	a := rec.Analysis
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO analyses
+		(tweet_id, username, source, score, category, urgency, decision, analyzed_at, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rec.TweetID, rec.Username, rec.Source, a.Score, a.Category, a.Urgency, rec.Decision, rec.AnalyzedAt.Unix(), string(data))
+	if err != nil {
+		return fmt.Errorf("failed to record analysis of %s: %w", rec.TweetID, err)
+	}
+	return nil
+}
+
+// Analyses は[since, until)に分析した結果を古い順に返す（untilがゼロ値の場合は現在まで）
+func (s *SQLite) Analyses(since, until time.Time) ([]AnalysisRecord, error) {
+	if until.IsZero() {
+		until = time.Now().Add(time.Second)
+	}
+	rows, err := s.db.Query(`SELECT data FROM analyses WHERE analyzed_at >= ? AND analyzed_at < ? ORDER BY analyzed_at`,
+		since.Unix(), until.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []AnalysisRecord
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var rec AnalysisRecord
+		if err := json.Unmarshal([]byte(data), &rec); err != nil {
+			return nil, fmt.Errorf("failed to decode analysis: %w", err)
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+// DecisionCounts はsince以降の分析結果を処理 (Decision*) ごとに数える
+func (s *SQLite) DecisionCounts(since time.Time) (map[string]int, error) {
+	rows, err := s.db.Query(`SELECT decision, COUNT(*) FROM analyses WHERE analyzed_at >= ? GROUP BY decision`, since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var decision string
+		var n int
+		if err := rows.Scan(&decision, &n); err != nil {
+			return nil, err
+		}
+		counts[decision] = n
+	}
+	return counts, rows.Err()
+}
+
+// SetCheckpoint は取得元の最新のツイートIDを記録（記録済みより古いIDの場合は更新しない）
+func (s *SQLite) SetCheckpoint(source, tweetID string) error {
+	current, ok, err := s.Checkpoint(source)
+	if err != nil {
+		return err
+	}
+	if ok && !NewerTweetID(tweetID, current.TweetID) {
+		return nil
+	}
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO checkpoints (source, tweet_id, updated_at) VALUES (?, ?, ?)`,
+		source, tweetID, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("failed to record checkpoint of %s: %w", source, err)
+	}
+	return nil
+}
+
+// Checkpoint は取得元のチェックポイントを返す
+func (s *SQLite) Checkpoint(source string) (Checkpoint, bool, error) {
+	var cp Checkpoint
+	var updated int64
+	err := s.db.QueryRow(`SELECT source, tweet_id, updated_at FROM checkpoints WHERE source = ?`, source).
+		Scan(&cp.Source, &cp.TweetID, &updated)
+	if err == sql.ErrNoRows {
+		return Checkpoint{}, false, nil
+	}
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+	cp.UpdatedAt = time.Unix(updated, 0)
+	return cp, true, nil
+}
+
+// Checkpoints は全ての取得元のチェックポイントを返す（CheckpointLister）
+func (s *SQLite) Checkpoints() ([]Checkpoint, error) {
+	rows, err := s.db.Query(`SELECT source, tweet_id, updated_at FROM checkpoints ORDER BY source`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var checkpoints []Checkpoint
+	for rows.Next() {
+		var cp Checkpoint
+		var updated int64
+		if err := rows.Scan(&cp.Source, &cp.TweetID, &updated); err != nil {
+			return nil, err
+		}
+		cp.UpdatedAt = time.Unix(updated, 0)
+		checkpoints = append(checkpoints, cp)
+	}
+	return checkpoints, rows.Err()
+}
+
+// ContentStore は本文のハッシュをcontent_hashesテーブルで管理するContentStoreを返す（ContentStorer）
+func (s *SQLite) ContentStore(ttl time.Duration) ContentStore {
+	return &sqliteContent{db: s.db, ttl: ttl}
+}
+
+// sqliteContent はSQLiteのContentStore
+type sqliteContent struct {
+	db  *sql.DB
+	ttl time.Duration
+}
+
+// MarkContent は本文のハッシュを記録し、ttl以内に別のツイートが記録されていればそのIDを返す
+func (c *sqliteContent) MarkContent(hash, tweetID string) (string, error) {
+	now := time.Now()
+	var first string
+	var seenAt int64
+	err := c.db.QueryRow(`SELECT tweet_id, seen_at FROM content_hashes WHERE hash = ?`, hash).Scan(&first, &seenAt)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+	if err == nil && now.Sub(time.Unix(seenAt, 0)) < c.ttl {
+		if first == tweetID {
+			return "", nil
+		}
+		return first, nil
+	}
+	_, err = c.db.Exec(`INSERT OR REPLACE INTO content_hashes (hash, tweet_id, seen_at) VALUES (?, ?, ?)`, hash, tweetID, now.Unix())
+	return "", err
+}
+
+// Save は期限切れのハッシュを削除する
+func (c *sqliteContent) Save() error {
+	if _, err := c.db.Exec(`DELETE FROM content_hashes WHERE seen_at < ?`, time.Now().Add(-c.ttl).Unix()); err != nil {
+		return fmt.Errorf("failed to prune content hashes: %w", err)
+	}
+	return nil
+}
+
+// AddRollups は日・軸・値ごとの集計に件数を足す（RollupStore）
+func (s *SQLite) AddRollups(rows []Rollup) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(`INSERT INTO rollups (day, dimension, key, processed, notified, scored, score_sum, ai_cost_usd)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (day, dimension, key) DO UPDATE SET
+	processed = processed + excluded.processed,
+	notified = notified + excluded.notified,
+	scored = scored + excluded.scored,
+	score_sum = score_sum + excluded.score_sum,
+	ai_cost_usd = ai_cost_usd + excluded.ai_cost_usd`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, r := range rows {
+		if _, err := stmt.Exec(r.Day, r.Dimension, r.Key, r.Processed, r.Notified, r.Scored, r.ScoreSum, r.AICostUSD); err != nil {
+			return fmt.Errorf("failed to record rollup: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Rollups は[since, until]の日のdimensionの集計を返す（RollupStore）
+func (s *SQLite) Rollups(dimension, since, until string) ([]Rollup, error) {
+	query := `SELECT day, dimension, key, processed, notified, scored, score_sum, ai_cost_usd FROM rollups WHERE 1 = 1`
+	var args []interface{}
+	if dimension != "" {
+		query += ` AND dimension = ?`
+		args = append(args, dimension)
+	}
+	if since != "" {
+		query += ` AND day >= ?`
+		args = append(args, since)
+	}
+	if until != "" {
+		query += ` AND day <= ?`
+		args = append(args, until)
+	}
+	rows, err := s.db.Query(query+` ORDER BY day, dimension, key`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rollups []Rollup
+	for rows.Next() {
+		var r Rollup
+		if err := rows.Scan(&r.Day, &r.Dimension, &r.Key, &r.Processed, &r.Notified, &r.Scored, &r.ScoreSum, &r.AICostUSD); err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, r)
+	}
+	return rollups, rows.Err()
+}
+
+// PruneRollups はbeforeより前の日の集計を削除する（RollupStore）
+func (s *SQLite) PruneRollups(before string) error {
+	if _, err := s.db.Exec(`DELETE FROM rollups WHERE day < ?`, before); err != nil {
+		return fmt.Errorf("failed to prune rollups: %w", err)
+	}
+	return nil
+}
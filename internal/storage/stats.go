@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// DailyStats は1日分のクロール統計
+type DailyStats struct {
+	Day       string         `json:"day"`         // YYYY-MM-DD
+	Fetched   int            `json:"fetched"`     // X APIから取得したポスト数
+	Processed int            `json:"processed"`   // 未読として処理したポスト数
+	Decisions map[string]int `json:"decisions"`   // 分析結果の処理 (Decision*) ごとの件数
+	Tickers   map[string]int `json:"tickers"`     // 通知した銘柄ごとの件数
+	Errors    map[string]int `json:"errors"`      // 種類 (fetch, ai, notify) ごとのエラー件数
+	AICalls   int            `json:"ai_calls"`    // AI APIの呼び出し回数
+	AICostUSD float64        `json:"ai_cost_usd"` // AIの推定コスト
+	XRequests int            `json:"x_requests"`  // X APIのリクエスト数
+}
+
+// statsFile はStatsStoreの保存形式
+type statsFile struct {
+	LastReport string                 `json:"last_report"`
+	Days       map[string]*DailyStats `json:"days"`
+	Rollups    map[string]*Rollup     `json:"rollups,omitempty"` // day|dimension|key -> 集計
+}
+
+// StatsStore は日ごとのクロール統計を管理
+// 保存先のバックエンドが集計に対応していない場合（json）は、トレーダー・銘柄・カテゴリごとの集計も保存する（RollupStore）
+type StatsStore struct {
+	mu         sync.RWMutex
+	days       map[string]*DailyStats
+	rollups    map[string]*Rollup
+	lastReport string
+	filePath   string
+}
+
+// NewStatsStore は新しいStatsStoreを作成
+func NewStatsStore(filePath string) (*StatsStore, error) {
+	ss := &StatsStore{
+		days:     make(map[string]*DailyStats),
+		rollups:  make(map[string]*Rollup),
+		filePath: filePath,
+	}
+
+	// ファイルが存在する場合は読み込み
+	if _, err := os.Stat(filePath); err == nil {
+		if err := ss.Load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, nil
+}
+
+// Update は指定日の統計を更新
+func (ss *StatsStore) Update(day string, fn func(*DailyStats)) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	s, ok := ss.days[day]
+	if !ok {
+		s = &DailyStats{Day: day}
+		ss.days[day] = s
+	}
+	if s.Decisions == nil {
+		s.Decisions = make(map[string]int)
+	}
+	if s.Tickers == nil {
+		s.Tickers = make(map[string]int)
+	}
+	if s.Errors == nil {
+		s.Errors = make(map[string]int)
+	}
+	fn(s)
+}
+
+// Get は指定日の統計を返す
+func (ss *StatsStore) Get(day string) (DailyStats, bool) {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	s, ok := ss.days[day]
+	if !ok {
+		return DailyStats{Day: day}, false
+	}
+	return *s, true
+}
+
+// Prune は指定日 (YYYY-MM-DD) より前の統計を削除
+func (ss *StatsStore) Prune(before string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	for day := range ss.days {
+		if day < before {
+			delete(ss.days, day)
+		}
+	}
+}
+
+// AddRollups は日・軸・値ごとの集計に件数を足す（RollupStore）
+func (ss *StatsStore) AddRollups(rows []Rollup) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	for _, d := range rows {
+		k := rollupKey(d.Day, d.Dimension, d.Key)
+		r, ok := ss.rollups[k]
+		if !ok {
+			r = &Rollup{Day: d.Day, Dimension: d.Dimension, Key: d.Key}
+			ss.rollups[k] = r
+		}
+		r.add(d)
+	}
+	return nil
+}
+
+// Rollups は[since, until]の日のdimensionの集計を日付順に返す（RollupStore）
+func (ss *StatsStore) Rollups(dimension, since, until string) ([]Rollup, error) {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	var rollups []Rollup
+	for _, r := range ss.rollups {
+		if (dimension == "" || r.Dimension == dimension) && inDayRange(r.Day, since, until) {
+			rollups = append(rollups, *r)
+		}
+	}
+	sort.Slice(rollups, func(i, j int) bool {
+		return rollupKey(rollups[i].Day, rollups[i].Dimension, rollups[i].Key) < rollupKey(rollups[j].Day, rollups[j].Dimension, rollups[j].Key)
+	})
+	return rollups, nil
+}
+
+// PruneRollups はbeforeより前の日の集計を削除する（RollupStore）
+func (ss *StatsStore) PruneRollups(before string) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	for k, r := range ss.rollups {
+		if r.Day < before {
+			delete(ss.rollups, k)
+		}
+	}
+	return nil
+}
+
+// LastReport は最後にレポートを投稿した日付 (YYYY-MM-DD) を返す
+func (ss *StatsStore) LastReport() string {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	return ss.lastReport
+}
+
+// SetLastReport はレポートを投稿した日付を記録
+func (ss *StatsStore) SetLastReport(day string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.lastReport = day
+}
+
+// Save は統計をファイルに保存
+func (ss *StatsStore) Save() error {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	data, err := json.MarshalIndent(statsFile{
+		LastReport: ss.lastReport,
+		Days:       ss.days,
+		Rollups:    ss.rollups,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal stats: %w", err)
+	}
+
+	if err := writeFileAtomic(ss.filePath, data); err != nil {
+		return fmt.Errorf("failed to write stats file: %w", err)
+	}
+
+	return nil
+}
+
+// Load は統計をファイルから読み込み
+func (ss *StatsStore) Load() error {
+	data, err := readFile(ss.filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read stats file: %w", err)
+	}
+
+	var file statsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to unmarshal stats: %w", err)
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.lastReport = file.LastReport
+	if file.Days != nil {
+		ss.days = file.Days
+	}
+	if file.Rollups != nil {
+		ss.rollups = file.Rollups
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// Storage は状態の保存先のバックエンド（storage.backend で切り替える）
+// 既読IDの保存は必須で、ArchiveStore・CheckpointStoreは対応しているバックエンドだけが実装する
+type Storage interface {
+	SeenStore
+	Close() error
+}
+
+// ArchiveStore は取得したツイートとAI分析結果の保存先（JSONファイルのAnalysisStore、SQLite、Archive）
+type ArchiveStore interface {
+	RecordTweet(tweet twitter.Tweet, source string) error
+	RecordAnalysis(rec AnalysisRecord) error
+	Save() error
+}
+
+// SeenEntry は既読ツイートIDと既読にした時刻
+type SeenEntry struct {
+	TweetID string
+	SeenAt  time.Time
+}
+
+// SeenMigrator は既読IDを既読にした時刻ごと読み書きできる保存先（migrate で別の保存先に移す時に使う）
+type SeenMigrator interface {
+	SeenEntries() ([]SeenEntry, error)
+	ImportSeen(entries []SeenEntry) error // 既読のIDは時刻を更新しない
+}
+
+// CheckpointLister は全ての取得元のチェックポイントを返せる保存先
+type CheckpointLister interface {
+	Checkpoints() ([]Checkpoint, error)
+}
+
+// ContentStorer は本文のハッシュも保存できるバックエンド（SQLite, Redis, bbolt）
+type ContentStorer interface {
+	ContentStore(ttl time.Duration) ContentStore
+}
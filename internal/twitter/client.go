@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,15 +16,126 @@ import (
 type Client struct {
 	bearerToken string
 	httpClient  *http.Client
+	selfReplies bool // 投稿者自身へのリプライ（スレッドの続き）を取得する
+	requests    atomic.Int64
+}
+
+// Option はClientの任意設定
+type Option func(*Client)
+
+// WithSelfReplies はユーザーのツイート取得時に自分自身へのリプライ（スレッドの続き）も含める
+func WithSelfReplies() Option {
+	return func(c *Client) {
+		c.selfReplies = true
+	}
 }
 
 // Tweet はツイート情報
 type Tweet struct {
-	ID        string    `json:"id"`
-	Text      string    `json:"text"`
-	AuthorID  string    `json:"author_id"`
-	CreatedAt time.Time `json:"created_at"`
-	Username  string    // APIレスポンスには含まれないが後で設定
+	ID              string       `json:"id"`
+	Text            string       `json:"text"`
+	AuthorID        string       `json:"author_id"`
+	CreatedAt       time.Time    `json:"created_at"`
+	Attachments     *Attachments `json:"attachments,omitempty"`
+	Entities        *Entities    `json:"entities,omitempty"`
+	Metrics         *Metrics     `json:"public_metrics,omitempty"`
+	ConversationID  string       `json:"conversation_id"`        // スレッドの起点となったツイートのID
+	InReplyToUserID string       `json:"in_reply_to_user_id"`    // リプライ先のユーザーID
+	EditHistoryIDs  []string     `json:"edit_history_tweet_ids"` // 編集履歴（先頭が元のツイート）
+	Username        string       // APIレスポンスには含まれないが後で設定
+	AuthorFollowers int          // 投稿者のフォロワー数（キーワード検索の場合のみ後で設定）
+	Media           []Media      // APIレスポンスのincludesから後で設定
+	Articles        []Article    // リンク先ページの本文（クローラーが後で設定）
+}
+
+// Metrics はツイートのエンゲージメント指標
+type Metrics struct {
+	RetweetCount    int `json:"retweet_count"`
+	ReplyCount      int `json:"reply_count"`
+	LikeCount       int `json:"like_count"`
+	QuoteCount      int `json:"quote_count"`
+	ImpressionCount int `json:"impression_count"`
+}
+
+// Entities はツイート本文中のエンティティ
+type Entities struct {
+	URLs []EntityURL `json:"urls"`
+}
+
+// EntityURL は本文中のURL
+type EntityURL struct {
+	URL         string `json:"url"`
+	ExpandedURL string `json:"expanded_url"`
+}
+
+// Article はリンク先ページから抽出した本文
+type Article struct {
+	URL   string
+	Title string
+	Text  string
+}
+
+// LinkURLs は本文中の外部リンク（X内のリンクを除く）の展開後URLを返す
+func (t *Tweet) LinkURLs() []string {
+	if t.Entities == nil {
+		return nil
+	}
+	var urls []string
+	for _, u := range t.Entities.URLs {
+		link := u.ExpandedURL
+		if link == "" {
+			link = u.URL
+		}
+		if strings.HasPrefix(link, "https://twitter.com/") || strings.HasPrefix(link, "https://x.com/") {
+			continue
+		}
+		urls = append(urls, link)
+	}
+	return urls
+}
+
+// Engagement はいいね・リポスト・リプライ・引用の合計を返す（指標がない場合は0）
+func (t *Tweet) Engagement() int {
+	if t.Metrics == nil {
+		return 0
+	}
+	return t.Metrics.LikeCount + t.Metrics.RetweetCount + t.Metrics.ReplyCount + t.Metrics.QuoteCount
+}
+
+// RootID は編集やスレッドの続きでも変わらない、最初の投稿のIDを返す
+// 自分自身へのリプライ（スレッドの続き）は会話の起点、編集されたツイートは編集前のIDになる
+func (t *Tweet) RootID() string {
+	if t.ConversationID != "" && t.InReplyToUserID != "" && t.InReplyToUserID == t.AuthorID {
+		return t.ConversationID
+	}
+	if len(t.EditHistoryIDs) > 0 {
+		return t.EditHistoryIDs[0]
+	}
+	return t.ID
+}
+
+// Attachments はツイートの添付情報
+type Attachments struct {
+	MediaKeys []string `json:"media_keys"`
+}
+
+// Media は添付メディア
+type Media struct {
+	MediaKey        string `json:"media_key"`
+	Type            string `json:"type"` // photo, video, animated_gif
+	URL             string `json:"url"`
+	PreviewImageURL string `json:"preview_image_url"`
+}
+
+// ImageURLs は添付画像 (photo) のURLを返す
+func (t *Tweet) ImageURLs() []string {
+	var urls []string
+	for _, m := range t.Media {
+		if m.Type == "photo" && m.URL != "" {
+			urls = append(urls, m.URL)
+		}
+	}
+	return urls
 }
 
 // Response はTwitter API v2のレスポンス
@@ -35,14 +147,21 @@ type Response struct {
 
 // ResponseIncludes はユーザー情報など
 type ResponseIncludes struct {
-	Users []User `json:"users"`
+	Users []User  `json:"users"`
+	Media []Media `json:"media"`
 }
 
 // User はユーザー情報
 type User struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
-	Name     string `json:"name"`
+	ID       string       `json:"id"`
+	Username string       `json:"username"`
+	Name     string       `json:"name"`
+	Metrics  *UserMetrics `json:"public_metrics,omitempty"`
+}
+
+// UserMetrics はユーザーの公開指標
+type UserMetrics struct {
+	FollowersCount int `json:"followers_count"`
 }
 
 // ResponseMeta はメタ情報
@@ -53,17 +172,22 @@ type ResponseMeta struct {
 }
 
 // NewClient は新しいTwitterクライアントを作成
-func NewClient(bearerToken string) *Client {
-	return &Client{
+func NewClient(bearerToken string, opts ...Option) *Client {
+	c := &Client{
 		bearerToken: bearerToken,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // GetUserTweets は指定されたユーザーの最新ツイートを取得
-func (c *Client) GetUserTweets(ctx context.Context, username string, maxResults int) ([]Tweet, error) {
+// includeRepliesがtrueの場合は他人へのリプライも含める
+func (c *Client) GetUserTweets(ctx context.Context, username string, maxResults int, includeReplies bool) ([]Tweet, error) {
 	// まずユーザーIDを取得
 	userID, err := c.getUserIDByUsername(ctx, username)
 	if err != nil {
@@ -74,14 +198,30 @@ func (c *Client) GetUserTweets(ctx context.Context, username string, maxResults
 	endpoint := fmt.Sprintf("https://api.twitter.com/2/users/%s/tweets", userID)
 	params := url.Values{}
 	params.Set("max_results", fmt.Sprintf("%d", maxResults))
-	params.Set("tweet.fields", "created_at,author_id")
+	params.Set("tweet.fields", "created_at,author_id,attachments,entities,public_metrics,conversation_id,in_reply_to_user_id,edit_history_tweet_ids")
+	params.Set("expansions", "attachments.media_keys")
+	params.Set("media.fields", "type,url,preview_image_url")
 	params.Set("exclude", "retweets,replies") // リツイートとリプライを除外
+	if c.selfReplies || includeReplies {
+		// スレッドの続きを取得するためリプライを含め、他人へのリプライは後で除外
+		params.Set("exclude", "retweets")
+	}
 
 	tweets, err := c.makeRequest(ctx, endpoint, params)
 	if err != nil {
 		return nil, err
 	}
 
+	if c.selfReplies && !includeReplies {
+		filtered := tweets[:0]
+		for _, t := range tweets {
+			if t.InReplyToUserID == "" || t.InReplyToUserID == userID {
+				filtered = append(filtered, t)
+			}
+		}
+		tweets = filtered
+	}
+
 	// ユーザー名を設定
 	for i := range tweets {
 		tweets[i].Username = username
@@ -90,15 +230,25 @@ func (c *Client) GetUserTweets(ctx context.Context, username string, maxResults
 	return tweets, nil
 }
 
-// SearchTweets はキーワードでツイートを検索
-func (c *Client) SearchTweets(ctx context.Context, query string, maxResults int) ([]Tweet, error) {
+// 検索結果の並び順
+const (
+	SortRecency   = "recency"   // 新しい順（デフォルト）
+	SortRelevancy = "relevancy" // 関連度・反応の多い順
+)
+
+// SearchTweets はキーワードでツイートを検索（sortOrderが空の場合は新しい順）
+func (c *Client) SearchTweets(ctx context.Context, query string, maxResults int, sortOrder string) ([]Tweet, error) {
 	endpoint := "https://api.twitter.com/2/tweets/search/recent"
 	params := url.Values{}
 	params.Set("query", query)
 	params.Set("max_results", fmt.Sprintf("%d", maxResults))
-	params.Set("tweet.fields", "created_at,author_id")
-	params.Set("expansions", "author_id")
-	params.Set("user.fields", "username")
+	if sortOrder != "" {
+		params.Set("sort_order", sortOrder)
+	}
+	params.Set("tweet.fields", "created_at,author_id,attachments,entities,public_metrics,conversation_id,in_reply_to_user_id,edit_history_tweet_ids")
+	params.Set("expansions", "author_id,attachments.media_keys")
+	params.Set("user.fields", "username,public_metrics")
+	params.Set("media.fields", "type,url,preview_image_url")
 
 	resp, err := c.makeRequestWithUsers(ctx, endpoint, params)
 	if err != nil {
@@ -122,7 +272,7 @@ func (c *Client) getUserIDByUsername(ctx context.Context, username string) (stri
 
 	req.Header.Set("Authorization", "Bearer "+c.bearerToken)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", err
 	}
@@ -144,6 +294,17 @@ func (c *Client) getUserIDByUsername(ctx context.Context, username string) (stri
 	return result.Data.ID, nil
 }
 
+// do はリクエストを送信し、リクエスト数を数える
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	c.requests.Add(1)
+	return c.httpClient.Do(req)
+}
+
+// RequestCount は起動してからのX APIのリクエスト数を返す
+func (c *Client) RequestCount() int64 {
+	return c.requests.Load()
+}
+
 // makeRequest は共通のリクエスト処理
 func (c *Client) makeRequest(ctx context.Context, endpoint string, params url.Values) ([]Tweet, error) {
 	urlStr := endpoint
@@ -158,7 +319,7 @@ func (c *Client) makeRequest(ctx context.Context, endpoint string, params url.Va
 
 	req.Header.Set("Authorization", "Bearer "+c.bearerToken)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -178,6 +339,8 @@ func (c *Client) makeRequest(ctx context.Context, endpoint string, params url.Va
 		return []Tweet{}, nil
 	}
 
+	attachMedia(result.Data, result.Includes)
+
 	return result.Data, nil
 }
 
@@ -195,7 +358,7 @@ func (c *Client) makeRequestWithUsers(ctx context.Context, endpoint string, para
 
 	req.Header.Set("Authorization", "Bearer "+c.bearerToken)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -215,20 +378,48 @@ func (c *Client) makeRequestWithUsers(ctx context.Context, endpoint string, para
 		return []Tweet{}, nil
 	}
 
-	// ユーザー名をマッピング
-	userMap := make(map[string]string)
+	// ユーザー名とフォロワー数をマッピング
+	userMap := make(map[string]User)
 	if result.Includes != nil && result.Includes.Users != nil {
 		for _, user := range result.Includes.Users {
-			userMap[user.ID] = user.Username
+			userMap[user.ID] = user
 		}
 	}
 
 	tweets := result.Data
 	for i := range tweets {
-		if username, ok := userMap[tweets[i].AuthorID]; ok {
-			tweets[i].Username = username
+		if user, ok := userMap[tweets[i].AuthorID]; ok {
+			tweets[i].Username = user.Username
+			if user.Metrics != nil {
+				tweets[i].AuthorFollowers = user.Metrics.FollowersCount
+			}
 		}
 	}
 
+	attachMedia(tweets, result.Includes)
+
 	return tweets, nil
 }
+
+// attachMedia はincludesのメディア情報を各ツイートに設定
+func attachMedia(tweets []Tweet, includes *ResponseIncludes) {
+	if includes == nil || len(includes.Media) == 0 {
+		return
+	}
+
+	mediaMap := make(map[string]Media, len(includes.Media))
+	for _, m := range includes.Media {
+		mediaMap[m.MediaKey] = m
+	}
+
+	for i := range tweets {
+		if tweets[i].Attachments == nil {
+			continue
+		}
+		for _, key := range tweets[i].Attachments.MediaKeys {
+			if m, ok := mediaMap[key]; ok {
+				tweets[i].Media = append(tweets[i].Media, m)
+			}
+		}
+	}
+}
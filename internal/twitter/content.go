@@ -0,0 +1,30 @@
+package twitter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// minContentLength は本文で同じ内容かを判定する最短の長さ（正規化後、短い定型文やURLだけの本文を同じ内容として扱わない）
+const minContentLength = 30
+
+var (
+	contentURLPattern     = regexp.MustCompile(`https?://\S+`)
+	contentMentionPattern = regexp.MustCompile(`^(rt\s+)?(@\w+:?\s*)+`)
+)
+
+// ContentHash は本文を正規化したハッシュを返す（短すぎて判定できない場合は空文字）
+// 大文字小文字・URL（t.coの短縮URLは転載ごとに変わる）・先頭のRTとメンション・空白の違いは無視する
+func ContentHash(text string) string {
+	text = strings.ToLower(text)
+	text = contentURLPattern.ReplaceAllString(text, "")
+	text = strings.Join(strings.Fields(text), " ")
+	text = contentMentionPattern.ReplaceAllString(text, "")
+	if len([]rune(text)) < minContentLength {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,76 @@
+package twitter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Thread は同じ投稿者による一連のツイート（投稿順）
+type Thread struct {
+	Tweets []Tweet
+}
+
+// GroupThreads は同じ会話・同じ投稿者のツイートをスレッドにまとめる
+// スレッドは元の並び順で最初に現れた位置に並ぶ
+func GroupThreads(tweets []Tweet) []Thread {
+	var threads []Thread
+	index := make(map[string]int)
+	for _, t := range tweets {
+		if t.ConversationID == "" {
+			threads = append(threads, Thread{Tweets: []Tweet{t}})
+			continue
+		}
+		key := t.ConversationID + "/" + t.AuthorID
+		if i, ok := index[key]; ok {
+			threads[i].Tweets = append(threads[i].Tweets, t)
+			continue
+		}
+		index[key] = len(threads)
+		threads = append(threads, Thread{Tweets: []Tweet{t}})
+	}
+
+	for _, th := range threads {
+		sort.SliceStable(th.Tweets, func(i, j int) bool {
+			return th.Tweets[i].CreatedAt.Before(th.Tweets[j].CreatedAt)
+		})
+	}
+	return threads
+}
+
+// IDs はスレッドに含まれるツイートIDを返す
+func (th Thread) IDs() []string {
+	ids := make([]string, len(th.Tweets))
+	for i, t := range th.Tweets {
+		ids[i] = t.ID
+	}
+	return ids
+}
+
+// Combined はスレッド全体を1つのツイートとしてまとめる
+// IDと投稿時刻は先頭のツイート、本文は (1/n) 形式で連結する
+func (th Thread) Combined() Tweet {
+	if len(th.Tweets) == 1 {
+		return th.Tweets[0]
+	}
+
+	combined := th.Tweets[0]
+	combined.Entities = nil
+	combined.Media = nil
+	combined.Articles = nil
+
+	parts := make([]string, len(th.Tweets))
+	var urls []EntityURL
+	for i, t := range th.Tweets {
+		parts[i] = fmt.Sprintf("(%d/%d) %s", i+1, len(th.Tweets), t.Text)
+		if t.Entities != nil {
+			urls = append(urls, t.Entities.URLs...)
+		}
+		combined.Media = append(combined.Media, t.Media...)
+	}
+	combined.Text = strings.Join(parts, "\n\n")
+	if len(urls) > 0 {
+		combined.Entities = &Entities{URLs: urls}
+	}
+	return combined
+}
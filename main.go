@@ -3,18 +3,30 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
 
 	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/article"
+	"github.com/Minatonton/x-crawler/internal/command"
 	"github.com/Minatonton/x-crawler/internal/config"
 	"github.com/Minatonton/x-crawler/internal/crawler"
+	"github.com/Minatonton/x-crawler/internal/digest"
+	"github.com/Minatonton/x-crawler/internal/embedding"
+	"github.com/Minatonton/x-crawler/internal/feedback"
+	"github.com/Minatonton/x-crawler/internal/quote"
+	"github.com/Minatonton/x-crawler/internal/sentiment"
+	"github.com/Minatonton/x-crawler/internal/server"
+	"github.com/Minatonton/x-crawler/internal/sink"
 	"github.com/Minatonton/x-crawler/internal/slack"
+	"github.com/Minatonton/x-crawler/internal/stats"
 	"github.com/Minatonton/x-crawler/internal/storage"
 	"github.com/Minatonton/x-crawler/internal/twitter"
 )
@@ -25,25 +37,102 @@ const (
 )
 
 func main() {
+	// サブコマンド
+	if len(os.Args) > 1 && os.Args[1] == "evaluate" {
+		if err := runEvaluate(os.Args[2:]); err != nil {
+			log.Fatalf("Evaluation failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "notify-test" {
+		if err := runNotifyTest(os.Args[2:]); err != nil {
+			log.Fatalf("Notification test failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "backup" {
+		if err := runBackup(os.Args[2:]); err != nil {
+			log.Fatalf("Backup failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "restore" {
+		if err := runRestore(os.Args[2:]); err != nil {
+			log.Fatalf("Restore failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := runExport(os.Args[2:]); err != nil {
+			log.Fatalf("Export failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "stats" {
+		if err := runStats(os.Args[2:]); err != nil {
+			log.Fatalf("Stats failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "ai-usage" {
+		if err := runAIUsage(os.Args[2:]); err != nil {
+			log.Fatalf("AI usage failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		if err := runValidate(os.Args[2:]); err != nil {
+			log.Fatalf("Validation failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "schema" {
+		if err := runSchema(os.Args[2:]); err != nil {
+			log.Fatalf("Schema failed: %v", err)
+		}
+		return
+	}
+
 	// フラグ解析
-	configPath := flag.String("config", defaultConfigPath, "設定ファイルのパス")
+	configPath := flag.String("config", defaultConfigPath, "設定ファイルのパス（http(s)・s3・gs・consul のURLも可）")
 	seenTweetsPath := flag.String("seen", defaultSeenTweetsPath, "既読ツイートファイルのパス")
+	profile := flag.String("profile", "", "適用する設定ファイルのプロファイル（未指定時は環境変数 "+config.ProfileEnv+"）")
 	flag.Parse()
 
 	// .envファイルを読み込み（存在する場合）
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using environment variables")
 	}
+	if *profile != "" {
+		// 設定の再読み込みでも同じプロファイルを使う
+		os.Setenv(config.ProfileEnv, *profile)
+	}
 
-	// 設定を読み込み
-	cfg, err := config.Load(*configPath)
+	// 設定を読み込み（リモートのURLの場合は取得してキャッシュファイルから読み込む）
+	configFile, remoteConfig, err := openConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	cfg, err := config.Load(configFile)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	if err := setupEncryption(cfg); err != nil {
+		log.Fatal(err)
+	}
 
 	// ログレベルを設定
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	log.Printf("Starting X-Crawler for Trading (interval: %s)", cfg.Interval)
+	if cfg.Profile != "" {
+		log.Printf("Using config profile: %s", cfg.Profile)
+	}
 
 	// 環境変数をチェック
 	xAPIToken := os.Getenv("X_API_BEARER_TOKEN")
@@ -51,38 +140,327 @@ func main() {
 		log.Fatal("X_API_BEARER_TOKEN environment variable is required")
 	}
 
-	slackWebhookURL := cfg.Slack.WebhookURL
-	if slackWebhookURL == "" {
-		slackWebhookURL = os.Getenv("SLACK_WEBHOOK_URL")
-	}
-	if slackWebhookURL == "" {
-		log.Fatal("SLACK_WEBHOOK_URL is required (in config or environment variable)")
+	slackWebhookURL, slackBotToken, err := slackCredentials(cfg)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	// 既読ツイート管理を初期化
-	seenTweets, err := storage.NewSeenTweets(*seenTweetsPath)
+	// 状態の保存先を初期化
+	state, err := openStorage(cfg, *seenTweetsPath)
 	if err != nil {
-		log.Fatalf("Failed to initialize seen tweets: %v", err)
+		log.Fatal(err)
 	}
-	log.Printf("Loaded %d seen tweets from %s", seenTweets.Count(), *seenTweetsPath)
+	defer state.Close()
 
 	// クライアントを初期化
-	twitterClient := twitter.NewClient(xAPIToken)
-	slackNotifier := slack.NewNotifier(slackWebhookURL, cfg.Slack.Username, cfg.Slack.IconEmoji)
+	var twitterOpts []twitter.Option
+	if cfg.AI.ThreadAnalysis {
+		twitterOpts = append(twitterOpts, twitter.WithSelfReplies())
+	}
+	twitterClient := twitter.NewClient(xAPIToken, twitterOpts...)
+	slackOpts, err := slackOptions(cfg, slackBotToken)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	var aiFilter *ai.Filter
-	if cfg.AI.Enabled {
-		apiKey := os.Getenv("ANTHROPIC_API_KEY")
-		if apiKey == "" {
-			log.Println("Warning: AI filter is enabled but ANTHROPIC_API_KEY is not set. AI analysis will be skipped.")
+	var opts []crawler.Option
+	if archive, ok := state.(storage.ArchiveStore); ok {
+		opts = append(opts, crawler.WithArchive(archive))
+	}
+	if checkpoints, ok := state.(storage.CheckpointStore); ok {
+		opts = append(opts, crawler.WithCheckpoints(checkpoints))
+	}
+	if cfg.Storage.Content.Enabled {
+		ttl, _ := time.ParseDuration(cfg.Storage.Content.TTL)
+		var contents storage.ContentStore
+		if cs, ok := state.(storage.ContentStorer); ok {
+			contents = cs.ContentStore(ttl)
+		} else {
+			contents, err = storage.NewContentHashes(cfg.Storage.Content.File, ttl)
+			if err != nil {
+				log.Fatalf("Failed to initialize content hashes: %v", err)
+			}
+		}
+		opts = append(opts, crawler.WithContentDedupe(contents))
+		log.Printf("Content hash dedupe enabled (ttl: %s)", cfg.Storage.Content.TTL)
+	}
+	if cfg.Storage.Archive.Enabled {
+		archive, err := storage.NewArchive(cfg.Storage.Archive.Dir, time.Duration(cfg.Storage.Archive.RetentionDays)*24*time.Hour)
+		if err != nil {
+			log.Fatalf("Failed to initialize tweet archive: %v", err)
+		}
+		opts = append(opts, crawler.WithArchive(archive))
+		log.Printf("Tweet archive enabled (%s, retention: %d days)", cfg.Storage.Archive.Dir, cfg.Storage.Archive.RetentionDays)
+	}
+	var httpServer *server.Server
+	var exampleSource ai.ExampleSource
+
+	// フィードバック（通知の有用/ノイズ評価、保存先が対応している場合（SQLite）は保存先に保存）
+	if cfg.Feedback.Enabled {
+		var feedbackStore *storage.FeedbackStore
+		if backend, ok := state.(storage.FeedbackBackend); ok {
+			feedbackStore, err = storage.NewFeedbackStoreWith(backend)
+		} else {
+			feedbackStore, err = storage.NewFeedbackStore(cfg.Feedback.File)
+		}
+		if err != nil {
+			log.Fatalf("Failed to initialize feedback store: %v", err)
+		}
+		calibrator := feedback.NewCalibrator(feedbackStore,
+			cfg.Feedback.Examples, cfg.Feedback.MaxOffset, cfg.Feedback.MinSamples)
+		opts = append(opts, crawler.WithFeedback(calibrator))
+		exampleSource = calibrator
+
+		// 通知した銘柄の事後の値動き
+		if cfg.Feedback.Outcomes.Enabled {
+			if provider := newQuoteProvider(cfg); provider != nil {
+				ttl, _ := time.ParseDuration(cfg.Quotes.CacheTTL)
+				opts = append(opts, crawler.WithOutcomes(feedback.NewOutcomeTracker(feedbackStore,
+					quote.NewCache(provider, ttl), cfg.Feedback.Outcomes.Hours)))
+				log.Printf("Price outcomes enabled (provider: %s, hours: %v)", cfg.Quotes.Provider, cfg.Feedback.Outcomes.Hours)
+			}
+		}
+
+		httpServer = server.New(cfg.Server.Listen)
+		if cfg.Feedback.Secret != "" {
+			// 署名付きリンク（空の秘密鍵の署名は誰でも作れるため、secret がない場合は受け付けない）
+			httpServer.Handle(feedback.Path, feedback.NewHandler(feedbackStore, cfg.Feedback.Secret))
+		}
+		if cfg.Feedback.SlackSigningSecret != "" {
+			// Slackアプリのインタラクティブボタン（Request URL: <public_url>/slack/interactions）
+			httpServer.Handle(feedback.InteractionPath, feedback.NewInteractionHandler(feedbackStore, cfg.Feedback.SlackSigningSecret))
+			slackOpts = append(slackOpts, slack.WithInteractiveFeedback(feedback.ActionName))
+		} else if cfg.Server.PublicURL != "" && cfg.Feedback.Secret != "" {
+			links := feedback.NewLinks(cfg.Server.PublicURL, cfg.Feedback.Secret)
+			slackOpts = append(slackOpts, slack.WithFeedbackLinks(links.URL))
+		}
+		log.Printf("Feedback enabled (examples: %d, max_offset: %d)", cfg.Feedback.Examples, cfg.Feedback.MaxOffset)
+	}
+
+	// 銘柄別センチメントの日次サマリー
+	if cfg.Sentiment.Enabled {
+		sentimentStore, err := storage.NewSentimentStore(cfg.Sentiment.File)
+		if err != nil {
+			log.Fatalf("Failed to initialize sentiment store: %v", err)
+		}
+		reportAt, _ := cfg.Sentiment.GetReportTime()
+		opts = append(opts, crawler.WithSentiment(sentiment.NewAggregator(sentimentStore,
+			reportAt, cfg.Sentiment.MaxTickers, cfg.Sentiment.TopTweets, cfg.Sentiment.RetentionDays)))
+		log.Printf("Daily sentiment report enabled (report_time: %s)", cfg.Sentiment.ReportTime)
+	}
+
+	// 埋め込みによる意味的な重複抑制・戦略との関連度評価
+	if cfg.Dedupe.Enabled || cfg.Relevance.Enabled {
+		if embedder := newEmbedder(cfg); embedder != nil {
+			opts = append(opts, crawler.WithEmbedder(embedder))
+			if cfg.Dedupe.Enabled {
+				window, _ := time.ParseDuration(cfg.Dedupe.Window)
+				opts = append(opts, crawler.WithDeduper(embedding.NewDeduper(cfg.Dedupe.Threshold, window)))
+				log.Printf("Semantic dedupe enabled (threshold: %.2f, window: %s)", cfg.Dedupe.Threshold, window)
+			}
+			if cfg.Relevance.Enabled {
+				opts = append(opts, crawler.WithRelevance(embedding.NewRelevance(embedder,
+					cfg.Relevance.Profile, cfg.Relevance.MinSimilarity, cfg.Relevance.MaxSimilarity)))
+				log.Printf("Relevance scoring enabled (weight: %.2f)", cfg.Relevance.Weight)
+			}
+		}
+	}
+
+	// 緊急でない通知のダイジェスト
+	if cfg.Slack.Digest.Enabled {
+		interval, _ := time.ParseDuration(cfg.Slack.Digest.Interval)
+		opts = append(opts, crawler.WithDigest(digest.NewDigest(interval, cfg.Slack.Digest.Immediate)))
+		log.Printf("Slack digest enabled (interval: %s, immediate: %s)", interval, strings.Join(cfg.Slack.Digest.Immediate, ", "))
+	}
+
+	// 緊急度ごとの即時通知の上限（超えた分はダイジェストに回す）
+	if cfg.Slack.Throttle.Enabled {
+		window, _ := time.ParseDuration(cfg.Slack.Throttle.Window)
+		opts = append(opts, crawler.WithThrottle(digest.NewThrottle(cfg.Slack.Throttle.Limits, window)))
+		if !cfg.Slack.Digest.Enabled {
+			// ダイジェストが無効の場合は上限を超えた通知だけをまとめる
+			interval, _ := time.ParseDuration(cfg.Slack.Digest.Interval)
+			opts = append(opts, crawler.WithDigest(digest.NewDigest(interval, []string{"critical", "high", "normal", "low"})))
+		}
+		log.Printf("Slack throttle enabled (window: %s, limits: %v)", window, cfg.Slack.Throttle.Limits)
+	}
+
+	// AI分析結果の保存
+	if cfg.Analyses.Enabled {
+		analysisStore, err := storage.NewAnalysisStore(cfg.Analyses.File, cfg.Analyses.MaxRecords)
+		if err != nil {
+			log.Fatalf("Failed to initialize analysis store: %v", err)
+		}
+		opts = append(opts, crawler.WithArchive(analysisStore))
+		log.Printf("Analysis store enabled (%d records)", analysisStore.Count())
+	}
+
+	// 送信待ちの通知を保存し、再起動後のクロール開始前に再送する（保存先が対応している場合（Redis）は常に保存先に保存）
+	queueFrom := cfg.Slack.QueueFile
+	queueBackend, stateQueue := state.(slack.QueueBackend)
+	if stateQueue {
+		queueFrom = cfg.Storage.Backend
+	}
+	if queueFrom != "" {
+		var queueStore *slack.QueueStore
+		if stateQueue {
+			queueStore, err = slack.NewQueueStoreWith(queueBackend)
 		} else {
-			aiFilter = ai.NewFilter(apiKey, cfg.AI.Model)
-			log.Printf("AI filter enabled (model: %s, min_score: %d)", cfg.AI.Model, cfg.AI.MinScore)
+			queueStore, err = slack.NewQueueStore(cfg.Slack.QueueFile)
 		}
+		if err != nil {
+			log.Fatalf("Failed to initialize Slack queue: %v", err)
+		}
+		slackOpts = append(slackOpts, slack.WithQueueStore(queueStore))
+	}
+
+	slackNotifier := slack.NewNotifier(slackWebhookURL, cfg.Slack.Username, cfg.Slack.IconEmoji, slackOpts...)
+	// 設定の再読み込みではトレーダー個別の投稿先とテンプレートのトレーダーだけを作り直す（slack.routes の変更は再起動が必要）
+	slackRouteConfig := cfg.Slack.Routes
+	onTradersReload(func(next *config.Config) {
+		slackNotifier.SetRoutes(slackRoutes(slackRouteConfig, next.Traders))
+		slackNotifier.SetTemplateTraders(templateTraders(next.Traders))
+	})
+	if n := slackNotifier.PendingCount(); n > 0 {
+		log.Printf("Loaded %d undelivered Slack messages from %s", n, queueFrom)
+	}
+
+	var analyzer ai.Analyzer
+	var usage *ai.UsageTracker
+	var ledger storage.UsageLedger
+	if cfg.AI.Enabled {
+		if err := resolveModels(cfg); err != nil {
+			log.Fatalf("Invalid AI model: %v", err)
+		}
+		usage = newUsageTracker(cfg)
+		var closeLedger func()
+		ledger, closeLedger, err = openUsageLedger(cfg, state)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer closeLedger()
+		if ledger != nil {
+			recordUsage(usage, ledger)
+			pruneUsageLedger(ledger)
+			log.Printf("AI usage ledger enabled")
+		}
+		analyzer, err = newAnalyzer(cfg, usage, exampleSource)
+		if err != nil {
+			log.Fatalf("Failed to initialize AI analyzer: %v", err)
+		}
+	}
+	if analyzer == nil && cfg.AI.RuleFallback {
+		log.Printf("Using rule-based scorer (min_score: %d)", cfg.AI.MinScore)
+		analyzer = newRuleScorer(cfg)
+	}
+	if analyzer != nil {
+		var symbols ai.SymbolSet
+		if cfg.AI.SymbolsFile != "" {
+			symbols, err = ai.LoadSymbols(cfg.AI.SymbolsFile)
+			if err != nil {
+				log.Fatalf("Failed to load symbols: %v", err)
+			}
+			log.Printf("Loaded %d symbols for ticker validation", len(symbols))
+		}
+		analyzer = ai.NewTickerMerger(analyzer, symbols)
+	}
+
+	if analyzer != nil && cfg.AI.Articles.Enabled {
+		timeout, err := time.ParseDuration(cfg.AI.Articles.Timeout)
+		if err != nil {
+			log.Fatalf("Invalid ai.articles.timeout: %v", err)
+		}
+		opts = append(opts, crawler.WithArticleFetcher(
+			article.NewFetcher(cfg.AI.Articles.MaxChars, cfg.AI.Articles.MaxLinks, timeout)))
+		log.Printf("Linked article fetching enabled (max_chars: %d)", cfg.AI.Articles.MaxChars)
+	}
+
+	// Slackのスラッシュコマンドで追加した監視対象・ミュート
+	var controlStore *storage.ControlStore
+	if cfg.Commands.Enabled {
+		controlStore, err = storage.NewControlStore(cfg.Commands.File)
+		if err != nil {
+			log.Fatalf("Failed to initialize control store: %v", err)
+		}
+		opts = append(opts, crawler.WithControl(controlStore))
+	}
+
+	// Slack以外の通知先
+	sinks, err := newSinks(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize notification sinks: %v", err)
+	}
+	if cfg.WebSocket.Enabled {
+		ws := sink.NewWebSocket(cfg.WebSocket.Token, cfg.WebSocket.MaxClients, cfg.WebSocket.AllowedOrigins, sinkFilter(cfg.WebSocket.SinkFilter))
+		sinks = append(sinks, ws)
+		if httpServer == nil {
+			httpServer = server.New(cfg.Server.Listen)
+		}
+		httpServer.Handle(sink.WebSocketPath, ws)
+		if cfg.WebSocket.Token == "" {
+			log.Printf("Warning: websocket.token is empty, any local client can connect to %s", sink.WebSocketPath)
+		}
+		log.Printf("WebSocket broadcast enabled (path: %s)", sink.WebSocketPath)
+	}
+	if cfg.Feed.Enabled {
+		feed := sink.NewFeed(cfg.Feed.Title, cfg.Server.PublicURL, cfg.Feed.Token, cfg.Feed.MaxItems, sinkFilter(cfg.Feed.SinkFilter))
+		sinks = append(sinks, feed)
+		if httpServer == nil {
+			httpServer = server.New(cfg.Server.Listen)
+		}
+		httpServer.Handle(sink.FeedAtomPath, feed.AtomHandler())
+		httpServer.Handle(sink.FeedRSSPath, feed.RSSHandler())
+		log.Printf("RSS/Atom feed enabled (paths: %s, %s)", sink.FeedRSSPath, sink.FeedAtomPath)
+	}
+	sinks, err = routeSinks(cfg, sinks)
+	if err != nil {
+		log.Fatalf("Invalid notification routing: %v", err)
+	}
+	if len(sinks) > 0 {
+		opts = append(opts, crawler.WithSinks(sinks...))
 	}
 
 	// クローラーを作成
-	crawlerInstance := crawler.New(cfg, twitterClient, aiFilter, slackNotifier, seenTweets)
+	if cfg.Stats.Enabled {
+		statsStore, err := storage.NewStatsStore(cfg.Stats.File)
+		if err != nil {
+			log.Fatalf("Failed to initialize stats store: %v", err)
+		}
+		reportAt, _ := cfg.Stats.GetReportTime()
+		// 保存先が集計に対応していればトレーダー・銘柄・カテゴリごとの集計もそこに保存する
+		rollups, _ := state.(storage.RollupStore)
+		opts = append(opts, crawler.WithStats(stats.NewCollector(statsStore, rollups, usage,
+			reportAt, cfg.Stats.TopTickers, cfg.Stats.RetentionDays)))
+		log.Printf("Daily stats report enabled (report_time: %s)", cfg.Stats.ReportTime)
+	}
+
+	// JSONファイル・S3/GCSの場合はクロールの途中でも既読IDを保存する（他のバックエンドは書き込みがその場で反映される）
+	var seenTweets storage.SeenStore = state
+	var autoSave *storage.AutoSave
+	switch cfg.Storage.Backend {
+	case "json":
+		saveInterval, _ := time.ParseDuration(cfg.Storage.JSON.SaveInterval)
+		if cfg.Storage.JSON.SaveEvery > 0 || saveInterval > 0 {
+			autoSave = storage.NewAutoSave(state, cfg.Storage.JSON.SaveEvery, saveInterval)
+			seenTweets = autoSave
+		}
+	case "remote":
+		// 1件ごとにアップロードしないよう間隔だけで保存する
+		if saveInterval, _ := time.ParseDuration(cfg.Storage.Remote.SaveInterval); saveInterval > 0 {
+			autoSave = storage.NewAutoSave(state, 0, saveInterval)
+			seenTweets = autoSave
+		}
+	}
+
+	crawlerInstance := crawler.New(cfg, twitterClient, analyzer, slackNotifier, seenTweets, opts...)
+
+	if controlStore != nil {
+		if httpServer == nil {
+			httpServer = server.New(cfg.Server.Listen)
+		}
+		httpServer.Handle(command.Path, command.NewHandler(controlStore, crawlerInstance.Status, cfg.Commands.SigningSecret))
+		log.Printf("Slack commands enabled (%d added traders)", len(controlStore.Traders()))
+	}
 
 	// 実行間隔を取得
 	interval, err := cfg.GetInterval()
@@ -90,40 +468,505 @@ func main() {
 		log.Fatalf("Invalid interval: %v", err)
 	}
 
+	if httpServer != nil {
+		httpServer.Start()
+	}
+
+	// シグナルハンドリング（実行中のクロールやAPI呼び出しも中断する）
+	rootCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if autoSave != nil {
+		go autoSave.Run(rootCtx)
+	}
+
+	// SIGHUP・設定ファイルの更新で監視対象としきい値を読み込み直す
+	reload := make(chan struct{}, 1)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	if cfg.Reload.Watch {
+		watchInterval, _ := time.ParseDuration(cfg.Reload.WatchInterval)
+		files := cfg.Files
+		if remoteConfig != nil {
+			// キャッシュファイルはリモートの取得元の更新時だけ書き換える
+			files = files[1:]
+			go remoteConfig.Watch(rootCtx, watchInterval, reload)
+			log.Printf("Refreshing config from %s (every %s)", remoteConfig, watchInterval)
+		}
+		if len(files) > 0 {
+			go watchConfig(rootCtx, files, watchInterval, reload)
+			log.Printf("Watching %s for changes (every %s)", strings.Join(files, ", "), watchInterval)
+		}
+	}
+
 	// 初回実行
 	log.Println("Running initial crawl...")
-	if err := crawlerInstance.Run(context.Background()); err != nil {
+	if err := crawlerInstance.Run(rootCtx); err != nil {
 		log.Printf("Error during initial crawl: %v", err)
 	}
+	logUsage(usage)
+
+	// 設定の読み込み（シークレットの参照の解決を含む）はクロールとは別のgoroutineで行い、読み込めた設定だけを受け取る
+	reloaded := make(chan *config.Config)
+	go loadConfigs(rootCtx, configFile, remoteConfig, cfg, hup, reload, reloaded)
 
 	// 定期実行
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	// シグナルハンドリング
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
 	log.Printf("Crawler started. Press Ctrl+C to stop.")
 
 	for {
 		select {
 		case <-ticker.C:
 			log.Println("Running scheduled crawl...")
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			ctx, cancel := context.WithTimeout(rootCtx, 5*time.Minute)
 			if err := crawlerInstance.Run(ctx); err != nil {
 				log.Printf("Error during crawl: %v", err)
 			}
 			cancel()
+			logUsage(usage)
+			pruneUsageLedger(ledger)
+
+		case next := <-reloaded:
+			crawlerInstance.Reload(next)
+			reloadTraders(next)
+			if nextInterval, _ := next.GetInterval(); nextInterval != interval {
+				interval = nextInterval
+				ticker.Reset(interval)
+				log.Printf("Crawl interval changed to %s", interval)
+			}
 
-		case sig := <-sigChan:
-			log.Printf("Received signal %v, shutting down...", sig)
+		case <-rootCtx.Done():
+			log.Println("Received signal, shutting down...")
+			// 溜まっているダイジェストを投稿
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			crawlerInstance.FlushDigest(flushCtx, true)
+			cancel()
 			// 既読ツイートを保存
 			if err := seenTweets.Save(); err != nil {
 				log.Printf("Failed to save seen tweets: %v", err)
 			}
+			if httpServer != nil {
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				if err := httpServer.Shutdown(shutdownCtx); err != nil {
+					log.Printf("Failed to shut down HTTP server: %v", err)
+				}
+				cancel()
+			}
 			log.Println("Shutdown complete")
 			return
 		}
 	}
 }
+
+// newAnalyzer は設定されたプロバイダーチェーンのAnalyzerを作成
+// 利用可能なプロバイダーがない場合はnilを返す（AI分析なしで動作）
+// feedbackExamplesにはフィードバック由来の参考例を渡す（nil可）
+func newAnalyzer(cfg *config.Config, usage *ai.UsageTracker, feedbackExamples ai.ExampleSource) (ai.Analyzer, error) {
+	timeout, err := cfg.AI.GetProviderTimeout()
+	if err != nil {
+		return nil, fmt.Errorf("invalid ai.provider_timeout: %w", err)
+	}
+
+	promptText, err := cfg.AI.LoadPromptTemplate()
+	if err != nil {
+		return nil, err
+	}
+	examples := make([]ai.Example, len(cfg.AI.Examples))
+	for i, ex := range cfg.AI.Examples {
+		examples[i] = ai.Example(ex)
+	}
+	categories := make([]ai.Category, len(cfg.Categories))
+	for i, c := range cfg.Categories {
+		categories[i] = ai.Category{Name: c.Name, Description: c.Description}
+	}
+	pc := ai.PromptContext{
+		Watchlist:      cfg.AI.Watchlist,
+		Examples:       examples,
+		OutputLanguage: ai.LanguageName(cfg.AI.OutputLanguage),
+		Categories:     categories,
+		Feedback:       feedbackExamples,
+	}
+	prompt, err := ai.NewPrompt(promptText, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ai.ChainEntry
+	for _, name := range cfg.AI.ProviderChain() {
+		analyzer, err := newProvider(cfg, name, prompt, usage)
+		if err != nil {
+			return nil, err
+		}
+		if analyzer == nil {
+			continue
+		}
+		entries = append(entries, ai.ChainEntry{Name: name, Analyzer: analyzer})
+	}
+
+	var analyzer ai.Analyzer
+	switch {
+	case len(entries) == 0:
+		log.Println("Warning: AI filter is enabled but no provider is available. AI analysis will be skipped.")
+		return nil, nil
+	case len(entries) == 1 && timeout == 0:
+		analyzer = entries[0].Analyzer
+	default:
+		names := make([]string, len(entries))
+		for i, e := range entries {
+			names[i] = e.Name
+		}
+		log.Printf("AI provider chain: %s", strings.Join(names, " -> "))
+		analyzer = ai.NewChain(entries, timeout)
+	}
+
+	if cfg.AI.Experiment.Enabled {
+		variant, err := newExperimentVariant(cfg, promptText, pc, usage)
+		if err != nil {
+			return nil, err
+		}
+		if variant != nil {
+			ec := cfg.AI.Experiment
+			log.Printf("AI experiment enabled (variant: %s, percent: %d%%)", ec.Name, ec.Percent)
+			analyzer = ai.NewExperiment(analyzer, variant, ec.Name, ec.Percent)
+		}
+	}
+
+	if cfg.AI.Triage.Enabled {
+		triager, err := newTriager(cfg, prompt, usage)
+		if err != nil {
+			return nil, err
+		}
+		if triager != nil {
+			log.Printf("AI triage enabled (model: %s, threshold: %d)", cfg.AI.Triage.Model, cfg.AI.Triage.Threshold)
+			analyzer = ai.NewTriage(triager, analyzer, cfg.AI.Triage.Threshold)
+		}
+	}
+
+	if cfg.AI.Redaction.Enabled {
+		log.Printf("PII redaction enabled (mode: %s)", cfg.AI.Redaction.Mode)
+		redactor := ai.NewRedactor(analyzer, redactionAllowList(cfg.AI.Redaction.AllowHandles, cfg.Traders), cfg.AI.Redaction.Mode)
+		allowHandles := cfg.AI.Redaction.AllowHandles
+		onTradersReload(func(next *config.Config) {
+			redactor.SetAllowedHandles(redactionAllowList(allowHandles, next.Traders))
+		})
+		analyzer = redactor
+	}
+
+	cacheTTL, err := cfg.AI.GetCacheTTL()
+	if err != nil {
+		return nil, fmt.Errorf("invalid ai.cache_ttl: %w", err)
+	}
+	if cacheTTL > 0 {
+		log.Printf("AI analysis cache enabled (ttl: %s)", cacheTTL)
+		analyzer = ai.NewCache(analyzer, cacheTTL)
+	}
+
+	return analyzer, nil
+}
+
+// newExperimentVariant はA/Bテストの代替グループ用のAnalyzerを作成
+// プロバイダーはチェーンの先頭、プロンプトとモデルは未指定の場合は通常と同じ
+func newExperimentVariant(cfg *config.Config, controlPrompt string, pc ai.PromptContext, usage *ai.UsageTracker) (ai.Analyzer, error) {
+	ec := cfg.AI.Experiment
+	promptText, err := ec.LoadPromptTemplate()
+	if err != nil {
+		return nil, err
+	}
+	if promptText == "" {
+		promptText = controlPrompt
+	}
+	prompt, err := ai.NewPrompt(promptText, pc)
+	if err != nil {
+		return nil, fmt.Errorf("ai.experiment: %w", err)
+	}
+
+	provider := cfg.AI.ProviderChain()[0]
+	vcfg := *cfg
+	if ec.Model != "" {
+		model, err := ai.ResolveModel(provider, ec.Model, nil)
+		if err != nil {
+			return nil, fmt.Errorf("ai.experiment: %w", err)
+		}
+		if provider == ai.ProviderOpenAI {
+			vcfg.AI.OpenAI.Model = model
+		} else {
+			vcfg.AI.Model = model
+		}
+	}
+	return newProvider(&vcfg, provider, prompt, usage)
+}
+
+// newProvider は指定されたプロバイダーのAnalyzerを作成
+// APIキーが未設定の場合は警告を出してnilを返す
+func newProvider(cfg *config.Config, name string, prompt *ai.Prompt, usage *ai.UsageTracker) (ai.Analyzer, error) {
+	switch name {
+	case ai.ProviderAnthropic:
+		apiKey := os.Getenv("ANTHROPIC_API_KEY")
+		if apiKey == "" {
+			log.Println("Warning: ANTHROPIC_API_KEY is not set. Skipping anthropic provider.")
+			return nil, nil
+		}
+		log.Printf("AI filter enabled (provider: anthropic, model: %s, min_score: %d)", cfg.AI.Model, cfg.AI.MinScore)
+		retry, err := retryPolicy(cfg.AI.Retry)
+		if err != nil {
+			return nil, err
+		}
+		maxImages := 0
+		if cfg.AI.Vision.Enabled {
+			maxImages = cfg.AI.Vision.MaxImages
+		}
+		idleTimeout, _ := time.ParseDuration(cfg.AI.Stream.IdleTimeout)
+		return ai.NewFilter(ai.FilterConfig{
+			APIKey:      apiKey,
+			Model:       cfg.AI.Model,
+			Retry:       retry,
+			Prompt:      prompt,
+			Usage:       usage,
+			Limiter:     rateLimiter(cfg, ai.ProviderAnthropic),
+			MaxImages:   maxImages,
+			Stream:      cfg.AI.Stream.Enabled,
+			IdleTimeout: idleTimeout,
+		}), nil
+
+	case ai.ProviderOpenAI:
+		apiKey := os.Getenv("OPENAI_API_KEY")
+		if apiKey == "" {
+			log.Println("Warning: OPENAI_API_KEY is not set. Skipping openai provider.")
+			return nil, nil
+		}
+		oc := cfg.AI.OpenAI
+		log.Printf("AI filter enabled (provider: openai, model: %s, min_score: %d)", oc.Model, cfg.AI.MinScore)
+		return ai.NewOpenAIAnalyzer(apiKey, oc.Model, oc.BaseURL, oc.APIVersion, prompt, usage,
+			rateLimiter(cfg, ai.ProviderOpenAI)), nil
+
+	case ai.ProviderRules:
+		log.Printf("AI filter enabled (provider: rules, min_score: %d)", cfg.AI.MinScore)
+		return newRuleScorer(cfg), nil
+
+	default:
+		return nil, fmt.Errorf("unknown AI provider: %s", name)
+	}
+}
+
+// redactionAllowList は個人情報の伏せ字から除く@ハンドル（設定の allow_handles と監視対象のトレーダー）を返す
+func redactionAllowList(allowHandles []string, traders []config.Trader) []string {
+	allowed := append([]string(nil), allowHandles...)
+	for _, t := range traders {
+		allowed = append(allowed, t.Username)
+	}
+	return allowed
+}
+
+// newRuleScorer はトレーダーの優先度とカテゴリ体系からルールベースのスコアラーを作成
+// 設定の再読み込みではトレーダーの優先度だけを作り直す
+func newRuleScorer(cfg *config.Config) *ai.RuleScorer {
+	categories := make([]ai.Category, len(cfg.Categories))
+	for i, c := range cfg.Categories {
+		categories[i] = ai.Category{Name: c.Name, Description: c.Description}
+	}
+	scorer := ai.NewRuleScorer(traderPriorities(cfg.Traders), categories)
+	onTradersReload(func(next *config.Config) {
+		scorer.SetPriorities(traderPriorities(next.Traders))
+	})
+	return scorer
+}
+
+// traderPriorities はトレーダーごとの優先度スコアを返す
+func traderPriorities(traders []config.Trader) map[string]int {
+	priorities := make(map[string]int, len(traders))
+	for i := range traders {
+		priorities[traders[i].Username] = traders[i].GetPriorityScore()
+	}
+	return priorities
+}
+
+// newTriager は一次選別用の安価なClaudeモデルのクライアントを作成
+func newTriager(cfg *config.Config, prompt *ai.Prompt, usage *ai.UsageTracker) (ai.Triager, error) {
+	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+	if apiKey == "" {
+		log.Println("Warning: ANTHROPIC_API_KEY is not set. AI triage will be skipped.")
+		return nil, nil
+	}
+	retry, err := retryPolicy(cfg.AI.Retry)
+	if err != nil {
+		return nil, err
+	}
+	return ai.NewFilter(ai.FilterConfig{
+		APIKey:  apiKey,
+		Model:   cfg.AI.Triage.Model,
+		Retry:   retry,
+		Prompt:  prompt,
+		Usage:   usage,
+		Limiter: rateLimiter(cfg, ai.ProviderAnthropic),
+	}), nil
+}
+
+// resolveModels は設定のモデル名のエイリアスを解決し、各プロバイダーのモデル一覧で検証する
+// モデル一覧を取得できない場合は警告のみで続行する
+func resolveModels(cfg *config.Config) error {
+	uses := func(name string) bool {
+		for _, p := range cfg.AI.ProviderChain() {
+			if p == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" && (uses(ai.ProviderAnthropic) || cfg.AI.Triage.Enabled) {
+		available := listModels(ai.ProviderAnthropic, ai.AnthropicModels(key))
+		if uses(ai.ProviderAnthropic) {
+			if err := resolveModel(ai.ProviderAnthropic, &cfg.AI.Model, available); err != nil {
+				return err
+			}
+		}
+		if cfg.AI.Triage.Enabled {
+			if err := resolveModel(ai.ProviderAnthropic, &cfg.AI.Triage.Model, available); err != nil {
+				return err
+			}
+		}
+	}
+
+	if key := os.Getenv("OPENAI_API_KEY"); key != "" && uses(ai.ProviderOpenAI) {
+		var available []string
+		// Azure OpenAIはデプロイ名を指定するため一覧での検証はしない
+		if cfg.AI.OpenAI.APIVersion == "" {
+			available = listModels(ai.ProviderOpenAI, ai.OpenAIModels(key, cfg.AI.OpenAI.BaseURL))
+		}
+		if err := resolveModel(ai.ProviderOpenAI, &cfg.AI.OpenAI.Model, available); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listModels はプロバイダーのモデル一覧を取得（失敗した場合はnil）
+func listModels(provider string, list ai.ModelLister) []string {
+	available, err := list(context.Background())
+	if err != nil {
+		log.Printf("Warning: failed to list %s models, skipping model validation: %v", provider, err)
+		return nil
+	}
+	return available
+}
+
+// resolveModel はモデル名を解決して置き換える
+func resolveModel(provider string, model *string, available []string) error {
+	resolved, err := ai.ResolveModel(provider, *model, available)
+	if err != nil {
+		return err
+	}
+	if resolved != *model {
+		log.Printf("Resolved model alias %s -> %s", *model, resolved)
+		*model = resolved
+	}
+	return nil
+}
+
+// newEmbedder は設定から埋め込みプロバイダーを作成（APIキーがない場合はnil）
+func newEmbedder(cfg *config.Config) embedding.Embedder {
+	ec := cfg.Embeddings
+	switch ec.Provider {
+	case embedding.ProviderVoyage:
+		apiKey := os.Getenv("VOYAGE_API_KEY")
+		if apiKey == "" {
+			log.Println("Warning: VOYAGE_API_KEY is not set. Embedding features will be skipped.")
+			return nil
+		}
+		return embedding.NewVoyageEmbedder(apiKey, ec.Model, ec.BaseURL)
+	default:
+		apiKey := os.Getenv("OPENAI_API_KEY")
+		if apiKey == "" {
+			log.Println("Warning: OPENAI_API_KEY is not set. Embedding features will be skipped.")
+			return nil
+		}
+		return embedding.NewOpenAIEmbedder(apiKey, ec.Model, ec.BaseURL)
+	}
+}
+
+// rateLimiters はプロバイダーごとのAI APIリクエスト制限（分析・一次選別・A/Bテストで共用）
+var rateLimiters = make(map[string]*ai.RateLimiter)
+
+// rateLimiter はプロバイダーのリクエスト制限を返す（未設定の場合はnil）
+func rateLimiter(cfg *config.Config, provider string) *ai.RateLimiter {
+	lc := cfg.AI.Limits
+	if lc.MaxConcurrent <= 0 && lc.RequestsPerMinute <= 0 {
+		return nil
+	}
+	if l, ok := rateLimiters[provider]; ok {
+		return l
+	}
+	log.Printf("AI rate limit for %s: max_concurrent=%d, requests_per_minute=%d", provider, lc.MaxConcurrent, lc.RequestsPerMinute)
+	l := ai.NewRateLimiter(lc.MaxConcurrent, lc.RequestsPerMinute)
+	rateLimiters[provider] = l
+	return l
+}
+
+// newUsageTracker は設定からトークン使用量の集計を作成
+func newUsageTracker(cfg *config.Config) *ai.UsageTracker {
+	prices := make(map[string]ai.Price, len(cfg.AI.Pricing))
+	for model, p := range cfg.AI.Pricing {
+		prices[model] = ai.Price(p)
+	}
+	if cfg.AI.DailyBudgetUSD > 0 {
+		log.Printf("AI daily budget: $%.2f", cfg.AI.DailyBudgetUSD)
+	}
+	return ai.NewUsageTracker(cfg.AI.DailyBudgetUSD, prices)
+}
+
+// recordUsage はAI呼び出しごとにledgerへ記録し、起動前に記録された当日の使用量を予算の集計に戻す
+func recordUsage(usage *ai.UsageTracker, ledger storage.UsageLedger) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	calls, err := ledger.AICalls(today, time.Time{})
+	if err != nil {
+		log.Printf("Failed to read AI usage ledger: %v", err)
+	} else if len(calls) > 0 {
+		usage.Restore(calls)
+		log.Printf("Restored today's AI usage from ledger: %s", usage.Stats())
+	}
+	usage.SetRecorder(func(call ai.Call) {
+		if err := ledger.RecordAICall(call); err != nil {
+			log.Printf("Failed to record AI call: %v", err)
+		}
+	})
+}
+
+// pruneUsageLedger は保持期間を過ぎたAI呼び出しの記録を削除する（JSONLファイルの場合、SQLiteは状態の保存時に削除される）
+func pruneUsageLedger(ledger storage.UsageLedger) {
+	if archive, ok := ledger.(*storage.Archive); ok {
+		if err := archive.Save(); err != nil {
+			log.Printf("Failed to prune AI usage ledger: %v", err)
+		}
+	}
+}
+
+// logUsage は当日のAI使用量をログに出力
+func logUsage(usage *ai.UsageTracker) {
+	if usage == nil {
+		return
+	}
+	log.Printf("AI usage: %s", usage.Stats())
+}
+
+// retryPolicy は設定からリトライポリシーを作成
+func retryPolicy(rc config.RetryConfig) (ai.RetryPolicy, error) {
+	baseDelay, err := time.ParseDuration(rc.BaseDelay)
+	if err != nil {
+		return ai.RetryPolicy{}, fmt.Errorf("invalid ai.retry.base_delay: %w", err)
+	}
+	maxDelay, err := time.ParseDuration(rc.MaxDelay)
+	if err != nil {
+		return ai.RetryPolicy{}, fmt.Errorf("invalid ai.retry.max_delay: %w", err)
+	}
+	return ai.RetryPolicy{
+		MaxRetries:     rc.MaxRetries,
+		BudgetPerCycle: rc.BudgetPerCycle,
+		BaseDelay:      baseDelay,
+		MaxDelay:       maxDelay,
+	}, nil
+}
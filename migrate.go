@@ -0,0 +1,201 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/Minatonton/x-crawler/internal/storage"
+)
+
+// runMigrate は別の保存先（デフォルトは以前のJSONファイル）の状態を設定の storage.backend に移す
+// 既読IDを移さずに保存先を切り替えると、既読のツイートを再び通知してしまう
+//
+//	x-crawler migrate [-from json|sqlite|redis|bbolt|remote] [-seen seen_tweets.json] [-analyses analyses.json]
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	configPath := fs.String("config", defaultConfigPath, "設定ファイルのパス")
+	from := fs.String("from", "json", "移行元の保存先: json, sqlite, redis, bbolt, remote（設定は config の storage を使う）")
+	seenPath := fs.String("seen", defaultSeenTweetsPath, "既読ツイートファイルのパス（json の場合）")
+	analysesPath := fs.String("analyses", "", "移行するAI分析結果のファイル（省略時は analyses.file が存在する場合に移行）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := setupEncryption(cfg); err != nil {
+		return err
+	}
+	switch *from {
+	case "json", "sqlite", "redis", "bbolt", "remote":
+	default:
+		return fmt.Errorf("invalid -from %q (expected json, sqlite, redis, bbolt or remote)", *from)
+	}
+	if *from == cfg.Storage.Backend {
+		return fmt.Errorf("-from %s is the same as storage.backend", *from)
+	}
+
+	srcCfg := *cfg
+	srcCfg.Storage.Backend = *from
+	src, err := openStorage(&srcCfg, *seenPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := openStorage(cfg, *seenPath)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	// 既読ID（既読にした時刻ごと移すため、保持期間も引き継がれる）
+	srcSeen, ok := src.(storage.SeenMigrator)
+	if !ok {
+		return fmt.Errorf("storage backend %s does not support migration", *from)
+	}
+	dstSeen, ok := dst.(storage.SeenMigrator)
+	if !ok {
+		return fmt.Errorf("storage backend %s does not support migration", cfg.Storage.Backend)
+	}
+	entries, err := srcSeen.SeenEntries()
+	if err != nil {
+		return fmt.Errorf("failed to read seen tweets: %w", err)
+	}
+	if err := dstSeen.ImportSeen(entries); err != nil {
+		return fmt.Errorf("failed to write seen tweets: %w", err)
+	}
+	log.Printf("Migrated %d seen tweets", len(entries))
+
+	// 取得元ごとのチェックポイント
+	if lister, ok := src.(storage.CheckpointLister); ok {
+		checkpoints, err := lister.Checkpoints()
+		if err != nil {
+			return fmt.Errorf("failed to read checkpoints: %w", err)
+		}
+		if cs, ok := dst.(storage.CheckpointStore); ok {
+			for _, cp := range checkpoints {
+				if err := cs.SetCheckpoint(cp.Source, cp.TweetID); err != nil {
+					return err
+				}
+			}
+			log.Printf("Migrated %d checkpoints", len(checkpoints))
+		} else if len(checkpoints) > 0 {
+			log.Printf("Skipping %d checkpoints (storage backend %s does not store checkpoints)", len(checkpoints), cfg.Storage.Backend)
+		}
+	}
+
+	// ツイート・AI分析結果（移行先の保存先と storage.archive に書き込む）
+	var archives []storage.ArchiveStore
+	if a, ok := dst.(storage.ArchiveStore); ok {
+		archives = append(archives, a)
+	}
+	if cfg.Storage.Archive.Enabled {
+		a, err := storage.NewArchive(cfg.Storage.Archive.Dir, 0)
+		if err != nil {
+			return err
+		}
+		archives = append(archives, a)
+	}
+	if len(archives) > 0 {
+		if err := migrateArchives(src, archives, *analysesPath, cfg.Analyses.File); err != nil {
+			return err
+		}
+	}
+
+	// 通知記録・フィードバック・事後の値動き（移行先が対応している場合）
+	if backend, ok := dst.(storage.FeedbackBackend); ok {
+		if err := migrateFeedback(src, backend, cfg.Feedback.File); err != nil {
+			return err
+		}
+	}
+
+	if err := dst.Save(); err != nil {
+		return fmt.Errorf("failed to save %s storage: %w", cfg.Storage.Backend, err)
+	}
+	log.Printf("Migration from %s to %s completed", *from, cfg.Storage.Backend)
+	return nil
+}
+
+// migrateArchives は移行元の保存先と以前のAI分析結果のファイルのツイート・分析結果をarchivesに書き込む
+// analysesPathが空の場合はdefaultAnalysesPathが存在すれば移行する
+func migrateArchives(src storage.Storage, archives []storage.ArchiveStore, analysesPath, defaultAnalysesPath string) error {
+	var tweets []storage.ArchivedTweet
+	var records []storage.AnalysisRecord
+	if reader, ok := src.(storage.ArchiveReader); ok {
+		var err error
+		if tweets, err = reader.Tweets(time.Time{}, time.Time{}); err != nil {
+			return fmt.Errorf("failed to read tweets: %w", err)
+		}
+		if records, err = reader.Analyses(time.Time{}, time.Time{}); err != nil {
+			return fmt.Errorf("failed to read analyses: %w", err)
+		}
+	}
+	if analysesPath == "" {
+		if _, err := os.Stat(defaultAnalysesPath); err == nil {
+			analysesPath = defaultAnalysesPath
+		}
+	}
+	if analysesPath != "" {
+		store, err := storage.NewAnalysisStore(analysesPath, 0)
+		if err != nil {
+			return err
+		}
+		records = append(records, store.All()...)
+	}
+
+	for _, a := range archives {
+		for _, t := range tweets {
+			if err := a.RecordTweet(t.Tweet, t.Source); err != nil {
+				return err
+			}
+		}
+		for _, rec := range records {
+			if err := a.RecordAnalysis(rec); err != nil {
+				return err
+			}
+		}
+		if err := a.Save(); err != nil {
+			return err
+		}
+	}
+	log.Printf("Migrated %d tweets and %d analyses", len(tweets), len(records))
+	return nil
+}
+
+// migrateFeedback は移行元の保存先、なければ feedback.file の通知記録をdstに書き込む
+func migrateFeedback(src storage.Storage, dst storage.FeedbackBackend, feedbackPath string) error {
+	var recs []storage.NotificationRecord
+	if backend, ok := src.(storage.FeedbackBackend); ok {
+		var err error
+		if recs, err = backend.Notifications(time.Time{}, time.Time{}); err != nil {
+			return fmt.Errorf("failed to read feedback: %w", err)
+		}
+	} else if _, err := os.Stat(feedbackPath); err == nil {
+		store, err := storage.NewFeedbackStore(feedbackPath)
+		if err != nil {
+			return err
+		}
+		if recs, err = store.Records(time.Time{}, time.Time{}); err != nil {
+			return err
+		}
+	}
+	if len(recs) == 0 {
+		return nil
+	}
+	if err := dst.SaveNotifications(recs); err != nil {
+		return err
+	}
+	log.Printf("Migrated %d notification records with feedback", len(recs))
+	return nil
+}
@@ -0,0 +1,170 @@
+// Package notifier はSlack以外の通知先をクローラー本体に手を入れずに追加するための公開インターフェースと登録の仕組み
+//
+// 独自の通知先は init で Register を呼ぶパッケージとして実装し、main パッケージに
+// ブランクインポートだけのファイル（例: plugins_mysink.go）を追加してビルドに組み込む:
+//
+//	package main
+//
+//	import _ "example.com/xcrawler-mysink"
+//
+// 設定ファイルの plugins に登録した種類を書くと有効になる:
+//
+//	plugins:
+//	  - type: "mysink"
+//	    name: "mysink:desk"      # ルーティング (routing.rules[].sinks) で使う名前（省略時は Notifier.Name()）
+//	    min_score: 80            # 配信条件（他の通知先と同じ。Send の前に適用される）
+//	    options:                 # Options.Decode で独自の構造体に読み込む
+//	      endpoint: "https://example.com/hook"
+//
+// Notifier の規約:
+//   - Send はクロールのたびに同じゴルーチンから順に呼ばれる。並行して呼ばれることはないが、
+//     HTTPハンドラーなど別のゴルーチンと状態を共有する場合は自身で排他する
+//   - Send は ctx のキャンセル（シャットダウン）に従い、長時間ブロックしない
+//   - 失敗はエラーとして返す（クローラーがログに記録して統計のエラー件数に数え、クロールは続行する）。
+//     再試行が必要な場合は Send の中で行う
+//   - Event とその Analysis は他の通知先と共有されるため変更しない
+//   - Event.Analysis はAI分析なしで通知した場合 nil になる
+//   - 配信条件は Send の前に適用されるため、Send に届くのは条件に一致したイベントだけ
+//     （decisions の既定は notified, maybe, digest。["all"] で通知しなかったものも届く）
+//   - 日次の集計などクロールのサイクルごとの処理が必要な場合は Ticker も実装する
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/sink"
+	"github.com/Minatonton/x-crawler/internal/storage"
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// Event は通知の内容（ポスト、AI分析の結果、処理）
+type Event = sink.Event
+
+// Tweet は通知したポスト
+type Tweet = twitter.Tweet
+
+// Analysis はAI分析の結果
+type Analysis = ai.Analysis
+
+// Filter は通知先ごとの配信条件
+type Filter = sink.Filter
+
+// Notifier は通知先（組み込みの通知先と同じインターフェース）
+type Notifier = sink.Sink
+
+// Ticker はクロールのサイクルごとに呼ばれる通知先
+type Ticker = sink.Ticker
+
+// Event.Decision の値
+const (
+	DecisionNotified  = storage.DecisionNotified
+	DecisionMaybe     = storage.DecisionMaybe
+	DecisionDigest    = storage.DecisionDigest
+	DecisionUpdated   = storage.DecisionUpdated
+	DecisionLowScore  = storage.DecisionLowScore
+	DecisionDuplicate = storage.DecisionDuplicate
+	DecisionMuted     = storage.DecisionMuted
+)
+
+// Options は設定ファイルの plugins[] の1件分
+type Options struct {
+	Name   string                    // plugins[].name（空の場合あり）
+	decode func(v interface{}) error // plugins[].options を読み込む
+}
+
+// NewOptions は新しいOptionsを作成（decodeはoptionsを構造体に読み込む関数、nilの場合は何も読み込まない）
+func NewOptions(name string, decode func(v interface{}) error) Options {
+	return Options{Name: name, decode: decode}
+}
+
+// Decode は plugins[].options をvに読み込む（フィールドはyamlタグで対応付ける）
+func (o Options) Decode(v interface{}) error {
+	if o.decode == nil {
+		return nil
+	}
+	return o.decode(v)
+}
+
+// Factory は設定から通知先を作成する関数
+type Factory func(opts Options) (Notifier, error)
+
+var (
+	mu        sync.RWMutex
+	factories = make(map[string]Factory)
+)
+
+// Register は通知先の種類を登録する（init から呼ぶ。同じ種類を二重に登録した場合はpanic）
+func Register(kind string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	if factory == nil {
+		panic("notifier: Register factory is nil for " + kind)
+	}
+	if _, dup := factories[kind]; dup {
+		panic("notifier: Register called twice for " + kind)
+	}
+	factories[kind] = factory
+}
+
+// Kinds は登録済みの種類を返す
+func Kinds() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	kinds := make([]string, 0, len(factories))
+	for kind := range factories {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// New は登録済みの種類の通知先を作成し、配信条件を適用するラッパーで包む
+func New(kind string, opts Options, filter Filter) (Notifier, error) {
+	mu.RLock()
+	factory, ok := factories[kind]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown notifier plugin %q (registered: %v)", kind, Kinds())
+	}
+	n, err := factory(opts)
+	if err != nil {
+		return nil, fmt.Errorf("notifier plugin %q: %w", kind, err)
+	}
+	name := opts.Name
+	if name == "" {
+		name = n.Name()
+	}
+	return &filtered{notifier: n, name: name, filter: filter}, nil
+}
+
+// filtered は配信条件に一致したイベントだけを通知先に渡す
+type filtered struct {
+	notifier Notifier
+	name     string
+	filter   Filter
+}
+
+// Name はログ・ルーティング用の名前
+func (f *filtered) Name() string {
+	return f.name
+}
+
+// Send は配信条件に一致したイベントを通知先に渡す
+func (f *filtered) Send(ctx context.Context, event Event) error {
+	if !f.filter.Matches(event) {
+		return nil
+	}
+	return f.notifier.Send(ctx, event)
+}
+
+// Tick は通知先がTickerの場合に時刻を伝える
+func (f *filtered) Tick(ctx context.Context, now time.Time) {
+	if t, ok := f.notifier.(Ticker); ok {
+		t.Tick(ctx, now)
+	}
+}
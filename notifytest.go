@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/slack"
+	"github.com/Minatonton/x-crawler/internal/twitter"
+)
+
+// runNotifyTest は設定されたSlackの投稿先にサンプル通知を送り、URL・表示・振り分けを確認する
+//
+//	x-crawler notify-test [-variant all|simple|full] [-category earnings] [-urgency high] [-trader someone] [-ticker AAPL]
+func runNotifyTest(args []string) error {
+	fs := flag.NewFlagSet("notify-test", flag.ExitOnError)
+	configPath := fs.String("config", defaultConfigPath, "設定ファイルのパス")
+	variant := fs.String("variant", "all", "送信する通知: all, simple (AI分析なし), full (AI分析あり)")
+	category := fs.String("category", "", "サンプル分析のカテゴリ（省略時は最初のカテゴリ）")
+	urgency := fs.String("urgency", "high", "サンプル分析の緊急度 (critical, high, normal, low)")
+	trader := fs.String("trader", "x_crawler_test", "サンプルポストの投稿者（振り分けの確認用）")
+	ticker := fs.String("ticker", "AAPL", "サンプル分析の銘柄（空の場合は銘柄なし）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	switch *variant {
+	case "all", "simple", "full":
+	default:
+		return fmt.Errorf("invalid -variant %q (expected all, simple or full)", *variant)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	webhookURL, botToken, err := slackCredentials(cfg)
+	if err != nil {
+		return err
+	}
+	opts, err := slackOptions(cfg, botToken)
+	if err != nil {
+		return err
+	}
+	notifier := slack.NewNotifier(webhookURL, cfg.Slack.Username, cfg.Slack.IconEmoji, opts...)
+
+	if *category == "" {
+		*category = "other"
+		if len(cfg.Categories) > 0 {
+			*category = cfg.Categories[0].Name
+		}
+	}
+	tweet := twitter.Tweet{
+		ID:        fmt.Sprintf("test-%d", time.Now().Unix()),
+		Username:  *trader,
+		Text:      "This is a test notification from x-crawler notify-test.",
+		CreatedAt: time.Now(),
+	}
+	analysis := &ai.Analysis{
+		Score:      85,
+		Confidence: 90,
+		Category:   *category,
+		Sentiment:  "bullish",
+		Summary:    "Sample analysis sent by notify-test to verify the Slack destination and formatting.",
+		KeyPoints:  []string{"Sample key point 1", "Sample key point 2"},
+		Urgency:    *urgency,
+		Reasoning:  "notify-test",
+	}
+	if *ticker != "" {
+		analysis.Tickers = []string{*ticker}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var failed []string
+	if *variant == "all" || *variant == "simple" {
+		err := notifier.NotifySimple(ctx, tweet, "notify-test")
+		failed = append(failed, reportNotifyTest("simple", err)...)
+	}
+	if *variant == "all" || *variant == "full" {
+		full := tweet
+		full.ID += "-full"
+		err := notifier.NotifyTweet(ctx, full, analysis)
+		failed = append(failed, reportNotifyTest("full", err)...)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to send: %v", failed)
+	}
+	return nil
+}
+
+// reportNotifyTest は送信結果を出力し、失敗した場合は通知の種類を返す
+// 再送キューに入った場合もSlackには届いていないため失敗として扱う
+func reportNotifyTest(name string, err error) []string {
+	if err != nil {
+		log.Printf("✗ %s notification: %v", name, err)
+		return []string{name}
+	}
+	log.Printf("✓ %s notification sent", name)
+	return nil
+}
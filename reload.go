@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/config"
+	"github.com/Minatonton/x-crawler/internal/remoteconfig"
+)
+
+// watchConfig は設定ファイル（include したファイルを含む）の更新時刻を間隔ごとに確認し、更新されていればchに通知する
+// 監視するのは起動時に読み込んだファイルだけで、再読み込みで include に追加したファイルは監視しない
+func watchConfig(ctx context.Context, paths []string, interval time.Duration, ch chan<- struct{}) {
+	last := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			log.Printf("Failed to watch config %s: %v", path, err)
+			continue
+		}
+		last[path] = info.ModTime()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed := false
+			for path, modTime := range last {
+				info, err := os.Stat(path)
+				if err != nil || info.ModTime().Equal(modTime) {
+					continue
+				}
+				last[path] = info.ModTime()
+				changed = true
+			}
+			if !changed {
+				continue
+			}
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// traderReloaders は起動時にトレーダーの設定から作った部品を、再読み込みした設定で作り直す関数
+var traderReloaders []func(cfg *config.Config)
+
+// onTradersReload は設定の再読み込みのたびに呼ぶ関数を登録する（トレーダーの追加・変更を反映するため）
+func onTradersReload(f func(cfg *config.Config)) {
+	traderReloaders = append(traderReloaders, f)
+}
+
+// reloadTraders は登録した関数に再読み込みした設定を渡す
+func reloadTraders(cfg *config.Config) {
+	for _, f := range traderReloaders {
+		f(cfg)
+	}
+}
+
+// loadConfigs はSIGHUP・設定ファイルの更新のたびに設定を読み込み直し、読み込めた設定をoutに送る
+// シークレットの参照の解決やリモートの取得元が遅くてもクロールを止めないよう、クロールとは別のgoroutineで実行する
+func loadConfigs(ctx context.Context, path string, remote *remoteconfig.Source, current *config.Config,
+	hup <-chan os.Signal, reload <-chan struct{}, out chan<- *config.Config) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Println("Received SIGHUP, reloading config...")
+			if remote != nil {
+				if _, err := remote.Fetch(ctx); err != nil {
+					log.Printf("Failed to refresh config from %s, reloading cached copy: %v", remote, err)
+				}
+			}
+		case <-reload:
+		}
+
+		next := reloadConfig(path, current)
+		if next == nil {
+			continue
+		}
+		select {
+		case out <- next:
+			current = next
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reloadConfig は設定ファイルを読み込み直す（不正な場合はエラーを記録してnilを返し、現在の設定を使い続ける）
+func reloadConfig(path string, current *config.Config) *config.Config {
+	cfg, err := config.Load(path)
+	if err != nil {
+		log.Printf("Failed to reload config, keeping current config: %v", err)
+		return nil
+	}
+	if _, err := cfg.GetInterval(); err != nil {
+		log.Printf("Failed to reload config, keeping current config: invalid interval: %v", err)
+		return nil
+	}
+	log.Printf("Reloaded config from %s: %d traders, %d keywords, interval %s, min_score %d (%d -> %d traders, %d -> %d keywords)",
+		path, len(cfg.Traders), len(cfg.Keywords), cfg.Interval, cfg.AI.MinScore,
+		len(current.Traders), len(cfg.Traders), len(current.Keywords), len(cfg.Keywords))
+	return cfg
+}
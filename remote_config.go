@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Minatonton/x-crawler/internal/config"
+	"github.com/Minatonton/x-crawler/internal/remoteconfig"
+)
+
+// openConfig は設定ファイルがリモートのURLの場合は取得してキャッシュファイルのパスと取得元を返す（ローカルのファイルの場合はそのまま）
+// 取得できない場合は前回のキャッシュファイルがあればそれを使う
+// 先に環境変数のシークレットの参照を解決する（設定ファイルの ${VAR} や取得元のトークンにも使えるように）
+func openConfig(configPath string) (string, *remoteconfig.Source, error) {
+	if err := resolveSecretEnv(); err != nil {
+		return "", nil, fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+	if !remoteconfig.IsRemote(configPath) {
+		return configPath, nil, nil
+	}
+	var opts []remoteconfig.Option
+	if secret := os.Getenv("X_CRAWLER_CONFIG_SECRET"); secret != "" {
+		opts = append(opts, remoteconfig.WithSecret(secret))
+	}
+	if token := os.Getenv("X_CRAWLER_CONFIG_TOKEN"); token != "" {
+		opts = append(opts, remoteconfig.WithToken(token))
+	}
+	src, err := remoteconfig.New(configPath, os.Getenv("X_CRAWLER_CONFIG_CACHE"), opts...)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := src.Fetch(context.Background()); err != nil {
+		if _, statErr := os.Stat(src.Path()); statErr != nil {
+			return "", nil, fmt.Errorf("failed to fetch config from %s: %w", configPath, err)
+		}
+		log.Printf("Warning: failed to fetch config from %s, using cached copy %s: %v", configPath, src.Path(), err)
+	} else {
+		log.Printf("Fetched config from %s (cached at %s)", configPath, src.Path())
+	}
+	return src.Path(), src, nil
+}
+
+// loadConfig は設定ファイル（リモートのURLも可）を読み込む
+func loadConfig(configPath string) (*config.Config, error) {
+	path, _, err := openConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return config.Load(path)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Minatonton/x-crawler/internal/config"
+)
+
+// runSchema は設定ファイルのJSON Schemaを出力する（エディタの補完やCIでの検証に使う）
+//
+//	x-crawler schema [-out config.schema.json]
+func runSchema(args []string) error {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	outPath := fs.String("out", "", "出力先のファイル（省略時は標準出力）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := config.JSONSchema()
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if *outPath == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*outPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	fmt.Printf("Wrote JSON Schema to %s\n", *outPath)
+	return nil
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/secrets"
+)
+
+// resolveSecretEnv は値がシークレットの参照 (vault://, awssm://, ssm://) の環境変数を取得した値に置き換える
+// X_API_BEARER_TOKEN や ANTHROPIC_API_KEY などを平文で置かずにシークレットマネージャーから読み込める
+func resolveSecretEnv() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	names, err := secrets.NewResolver().ResolveEnv(ctx)
+	for _, name := range names {
+		log.Printf("Resolved %s from secrets manager", name)
+	}
+	return err
+}
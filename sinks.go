@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/config"
+	"github.com/Minatonton/x-crawler/internal/sink"
+	"github.com/Minatonton/x-crawler/notifier"
+)
+
+// newSinks は設定されたSlack以外の通知先を作成
+func newSinks(cfg *config.Config) ([]sink.Sink, error) {
+	var sinks []sink.Sink
+
+	if cfg.Email.Enabled {
+		email, err := newEmailSink(cfg.Email)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, email)
+		log.Printf("Email notifications enabled (%d recipients, digest: %t)", len(cfg.Email.Recipients), cfg.Email.Digest.Enabled)
+	}
+
+	if cfg.PagerDuty.Enabled {
+		sinks = append(sinks, sink.NewPagerDuty(cfg.PagerDuty.RoutingKey, sinkFilter(cfg.PagerDuty.SinkFilter)))
+		log.Printf("PagerDuty escalation enabled (min_score: %d, urgencies: %v)", cfg.PagerDuty.MinScore, cfg.PagerDuty.Urgencies)
+	}
+	if cfg.Opsgenie.Enabled {
+		sinks = append(sinks, sink.NewOpsgenie(cfg.Opsgenie.APIKey, cfg.Opsgenie.APIURL, cfg.Opsgenie.Tags, sinkFilter(cfg.Opsgenie.SinkFilter)))
+		log.Printf("Opsgenie escalation enabled (min_score: %d, urgencies: %v)", cfg.Opsgenie.MinScore, cfg.Opsgenie.Urgencies)
+	}
+	if cfg.GoogleChat.Enabled {
+		sinks = append(sinks, sink.NewGoogleChat(cfg.GoogleChat.WebhookURL, sinkFilter(cfg.GoogleChat.SinkFilter)))
+		log.Println("Google Chat notifications enabled")
+	}
+	if cfg.SMS.Enabled {
+		sinks = append(sinks, sink.NewTwilio(cfg.SMS.AccountSID, cfg.SMS.AuthToken, cfg.SMS.From, cfg.SMS.To, sinkFilter(cfg.SMS.SinkFilter)))
+		log.Printf("SMS notifications enabled (%d numbers, min_score: %d, urgencies: %v)", len(cfg.SMS.To), cfg.SMS.MinScore, cfg.SMS.Urgencies)
+	}
+	if cfg.LINE.Enabled {
+		sinks = append(sinks, sink.NewLINE(cfg.LINE.ChannelToken, cfg.LINE.To, sinkFilter(cfg.LINE.SinkFilter)))
+		log.Printf("LINE notifications enabled (%d targets)", len(cfg.LINE.To))
+	}
+
+	if cfg.Desktop.Enabled {
+		desktop, err := sink.NewDesktop(sinkFilter(cfg.Desktop.SinkFilter))
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, desktop)
+		log.Printf("Desktop notifications enabled")
+	}
+
+	if cfg.Kafka.Enabled {
+		sinks = append(sinks, sink.NewKafka(cfg.Kafka.RESTURL, cfg.Kafka.Topic, cfg.Kafka.Headers, sinkFilter(cfg.Kafka.SinkFilter)))
+		log.Printf("Kafka publishing enabled (topic: %s)", cfg.Kafka.Topic)
+	}
+	if cfg.NATS.Enabled {
+		nats, err := sink.NewNATS(cfg.NATS.URL, cfg.NATS.Token, cfg.NATS.Subject, sinkFilter(cfg.NATS.SinkFilter))
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, nats)
+		log.Printf("NATS publishing enabled (subject: %s)", cfg.NATS.Subject)
+	}
+	if cfg.MQTT.Enabled {
+		mqtt, err := sink.NewMQTT(cfg.MQTT.URL, cfg.MQTT.ClientID, cfg.MQTT.Topic, cfg.MQTT.QoS, cfg.MQTT.Retain, sinkFilter(cfg.MQTT.SinkFilter))
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, mqtt)
+		log.Printf("MQTT publishing enabled (topic: %s)", cfg.MQTT.Topic)
+	}
+	if cfg.AWS.SNS.Enabled {
+		sns, err := sink.NewSNS(cfg.AWS.SNS.TopicARN, cfg.AWS.Region, sinkFilter(cfg.AWS.SNS.SinkFilter))
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sns)
+		log.Printf("Amazon SNS publishing enabled (topic: %s)", cfg.AWS.SNS.TopicARN)
+	}
+	if cfg.AWS.SQS.Enabled {
+		sqs, err := sink.NewSQS(cfg.AWS.SQS.QueueURL, cfg.AWS.Region, sinkFilter(cfg.AWS.SQS.SinkFilter))
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sqs)
+		log.Printf("Amazon SQS publishing enabled (queue: %s)", cfg.AWS.SQS.QueueURL)
+	}
+	if cfg.Archive.Enabled {
+		archive, err := sink.NewJSONL(cfg.Archive.File, cfg.Archive.Rotate,
+			int64(cfg.Archive.MaxSizeMB)<<20, cfg.Archive.MaxFiles, sinkFilter(cfg.Archive.SinkFilter))
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, archive)
+		log.Printf("JSONL archive enabled (%s, rotate: %s)", cfg.Archive.File, cfg.Archive.Rotate)
+	}
+
+	for _, w := range cfg.Webhooks {
+		timeout, _ := time.ParseDuration(w.Timeout)
+		sinks = append(sinks, sink.NewWebhook(w.Name, w.URL, w.Secret, w.Headers, sinkFilter(w.SinkFilter), w.MaxAttempts, timeout))
+	}
+	if len(cfg.Webhooks) > 0 {
+		log.Printf("Outbound webhooks enabled (%d URLs)", len(cfg.Webhooks))
+	}
+
+	for i := range cfg.Plugins {
+		p := &cfg.Plugins[i]
+		var decode func(v interface{}) error
+		if p.Options.Kind != 0 {
+			decode = p.Options.Decode
+		}
+		n, err := notifier.New(p.Type, notifier.NewOptions(p.Name, decode), sinkFilter(p.SinkFilter))
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, n)
+		log.Printf("Notifier plugin enabled (%s: %s)", p.Type, n.Name())
+	}
+
+	return sinks, nil
+}
+
+// routeSinks は振り分けルールが設定されている場合にSinkをRouterにまとめる
+func routeSinks(cfg *config.Config, sinks []sink.Sink) ([]sink.Sink, error) {
+	if len(cfg.Routing.Rules) == 0 && len(cfg.Routing.Default) == 0 {
+		return sinks, nil
+	}
+	rules := make([]sink.Rule, 0, len(cfg.Routing.Rules))
+	for _, r := range cfg.Routing.Rules {
+		rules = append(rules, sink.Rule{Name: r.Name, Filter: sinkFilter(r.SinkFilter), Sinks: r.Sinks, Stop: r.Stop})
+	}
+	router, err := sink.NewRouter(sinks, rules, cfg.Routing.Default)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Notification routing enabled (%d rules)", len(rules))
+	return []sink.Sink{router}, nil
+}
+
+// newEmailSink はメール通知のSinkを作成
+func newEmailSink(cfg config.EmailConfig) (*sink.Email, error) {
+	recipients := make([]sink.Recipient, len(cfg.Recipients))
+	for i, r := range cfg.Recipients {
+		recipients[i] = sink.Recipient{Address: r.Address, Filter: sinkFilter(r.SinkFilter)}
+	}
+
+	var opts []sink.EmailOption
+	if cfg.TemplateFile != "" {
+		data, err := os.ReadFile(cfg.TemplateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read email.template_file: %w", err)
+		}
+		opts = append(opts, sink.WithEmailTemplate(string(data)))
+	}
+	if cfg.Digest.Enabled {
+		var tmpl string
+		if cfg.Digest.TemplateFile != "" {
+			data, err := os.ReadFile(cfg.Digest.TemplateFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read email.digest.template_file: %w", err)
+			}
+			tmpl = string(data)
+		}
+		at, _ := cfg.Digest.GetReportTime()
+		opts = append(opts, sink.WithEmailDigest(cfg.Digest.To, at, tmpl))
+	}
+
+	return sink.NewEmail(sink.SMTPConfig{
+		Host:     cfg.Host,
+		Port:     cfg.Port,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		From:     cfg.From,
+	}, recipients, opts...)
+}
+
+// sinkFilter は設定の配信条件をsink.Filterに変換
+func sinkFilter(f config.SinkFilter) sink.Filter {
+	return sink.Filter(f)
+}
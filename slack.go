@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/config"
+	"github.com/Minatonton/x-crawler/internal/quote"
+	"github.com/Minatonton/x-crawler/internal/slack"
+)
+
+// slackCredentials は設定または環境変数からSlackのWebhook URLとBotトークンを返す
+func slackCredentials(cfg *config.Config) (webhookURL, botToken string, err error) {
+	webhookURL = cfg.Slack.WebhookURL
+	if webhookURL == "" {
+		webhookURL = os.Getenv("SLACK_WEBHOOK_URL")
+	}
+	botToken = cfg.Slack.BotToken
+	if botToken == "" {
+		botToken = os.Getenv("SLACK_BOT_TOKEN")
+	}
+	if cfg.Slack.Mode == config.SlackModeBot {
+		if botToken == "" {
+			return "", "", fmt.Errorf("SLACK_BOT_TOKEN is required in bot mode (in config or environment variable)")
+		}
+	} else if webhookURL == "" {
+		return "", "", fmt.Errorf("SLACK_WEBHOOK_URL is required (in config or environment variable)")
+	}
+	return webhookURL, botToken, nil
+}
+
+// slackOptions は設定からSlack通知の表示・配信のオプションを作成
+func slackOptions(cfg *config.Config, botToken string) ([]slack.Option, error) {
+	categoryStyles := make(map[string]slack.CategoryStyle, len(cfg.Categories))
+	for _, c := range cfg.Categories {
+		categoryStyles[c.Name] = slack.CategoryStyle{Emoji: c.Emoji, Color: c.Color, Channel: c.Channel}
+	}
+	for name, st := range cfg.Slack.Styles.Categories {
+		style := categoryStyles[name]
+		if st.Emoji != "" {
+			style.Emoji = st.Emoji
+		}
+		if st.Color != "" {
+			style.Color = st.Color
+		}
+		categoryStyles[name] = style
+	}
+	language := cfg.Slack.Language
+	if language == "" && slack.HasLanguage(cfg.AI.OutputLanguage) {
+		language = cfg.AI.OutputLanguage
+	}
+	messages, err := slack.LoadMessages(language, cfg.Slack.Messages)
+	if err != nil {
+		return nil, fmt.Errorf("invalid slack messages: %w", err)
+	}
+	slackOpts := []slack.Option{
+		slack.WithMessages(messages),
+		slack.WithUrgencyStyles(slackStyles(cfg.Slack.Styles.Urgency)),
+		slack.WithSentimentStyles(slackStyles(cfg.Slack.Styles.Sentiment)),
+		slack.WithCategoryStyles(categoryStyles),
+		slack.WithMaybeWebhook(cfg.Slack.MaybeWebhookURL),
+		slack.WithOpsChannel(cfg.Slack.OpsWebhookURL, cfg.Slack.OpsChannel),
+	}
+	if cfg.Quotes.Enabled {
+		if provider := newQuoteProvider(cfg); provider != nil {
+			ttl, _ := time.ParseDuration(cfg.Quotes.CacheTTL)
+			slackOpts = append(slackOpts, slack.WithQuotes(quote.NewCache(provider, ttl)))
+			log.Printf("Price quotes enabled (provider: %s)", cfg.Quotes.Provider)
+		}
+	}
+	if templateText, err := cfg.Slack.LoadTemplate(); err != nil {
+		return nil, fmt.Errorf("invalid slack template: %w", err)
+	} else if templateText != "" {
+		tmpl, err := slack.ParseTemplate(templateText)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slack template: %w", err)
+		}
+		slackOpts = append(slackOpts, slack.WithTemplate(tmpl, templateTraders(cfg.Traders)))
+		log.Println("Using custom Slack message template")
+	}
+	if cfg.Slack.Alerts.Enabled {
+		interval, _ := time.ParseDuration(cfg.Slack.Alerts.Interval)
+		slackOpts = append(slackOpts, slack.WithAlerts(interval))
+		log.Printf("Ops alerts enabled (interval: %s)", interval)
+	}
+	if cfg.Slack.ShowMetrics {
+		slackOpts = append(slackOpts, slack.WithMetrics())
+	}
+	if cfg.Slack.Compact {
+		slackOpts = append(slackOpts, slack.WithCompact())
+	}
+	if cfg.Slack.IncludeMedia {
+		slackOpts = append(slackOpts, slack.WithMedia())
+	}
+	if cfg.Slack.Chart.Enabled {
+		chartURL := cfg.Slack.Chart.URL
+		if chartURL == "" {
+			chartURL = slack.DefaultChartURL
+		}
+		slackOpts = append(slackOpts, slack.WithChartImage(chartURL))
+	}
+	if len(cfg.Slack.Mentions.Targets) > 0 {
+		slackOpts = append(slackOpts, slack.WithMentions(cfg.Slack.Mentions.Urgencies, cfg.Slack.Mentions.Targets))
+		log.Printf("Slack mentions enabled for %s: %s",
+			strings.Join(cfg.Slack.Mentions.Urgencies, ", "), strings.Join(cfg.Slack.Mentions.Targets, ", "))
+	}
+	if len(cfg.Slack.Webhooks) > 0 {
+		destinations := make([]slack.Destination, len(cfg.Slack.Webhooks))
+		for i, w := range cfg.Slack.Webhooks {
+			destinations[i] = slack.Destination{
+				Name:       w.Name,
+				WebhookURL: w.URL,
+				MinScore:   w.MinScore,
+				Categories: w.Categories,
+				Traders:    w.Traders,
+				Tickers:    w.Tickers,
+				Compact:    w.Compact,
+			}
+		}
+		slackOpts = append(slackOpts, slack.WithDestinations(destinations))
+		log.Printf("Slack notifications are also sent to %d additional webhooks", len(destinations))
+	}
+	if routes := slackRoutes(cfg.Slack.Routes, cfg.Traders); len(routes) > 0 {
+		slackOpts = append(slackOpts, slack.WithRoutes(routes))
+		log.Printf("Slack routing enabled (%d routes)", len(routes))
+	}
+	if cfg.Slack.Mode == config.SlackModeBot {
+		slackOpts = append(slackOpts,
+			slack.WithBotToken(botToken, cfg.Slack.Channel),
+			slack.WithMaybeChannel(cfg.Slack.MaybeChannel))
+		if cfg.Slack.ThreadByTicker {
+			slackOpts = append(slackOpts, slack.WithTickerThreads())
+		}
+		log.Printf("Slack bot mode enabled (channel: %s, thread_by_ticker: %t)", cfg.Slack.Channel, cfg.Slack.ThreadByTicker)
+	}
+	return slackOpts, nil
+}
+
+// slackRoutes は slack.routes とトレーダー個別の投稿先から振り分けルールを作る
+// トレーダー個別の投稿先は slack.routes に一致しなかった通知に適用
+func slackRoutes(configured []config.SlackRoute, traders []config.Trader) []slack.Route {
+	var routes []slack.Route
+	for _, r := range configured {
+		routes = append(routes, slack.Route(r))
+	}
+	for _, t := range traders {
+		if t.Channel != "" {
+			routes = append(routes, slack.Route{Trader: t.Username, Channel: t.Channel})
+		}
+	}
+	return routes
+}
+
+// templateTraders はメッセージテンプレートの {{.Trader}} に渡すトレーダーの設定を返す
+func templateTraders(traders []config.Trader) []slack.TemplateTrader {
+	result := make([]slack.TemplateTrader, len(traders))
+	for i, t := range traders {
+		result[i] = slack.TemplateTrader{Username: t.Username, DisplayName: t.DisplayName, Priority: t.Priority, Context: t.Context}
+	}
+	return result
+}
+
+// newQuoteProvider は設定された株価プロバイダーを作成（APIキーがない場合はnil）
+func newQuoteProvider(cfg *config.Config) quote.Provider {
+	switch cfg.Quotes.Provider {
+	case quote.ProviderAlphaVantage:
+		apiKey := os.Getenv("ALPHAVANTAGE_API_KEY")
+		if apiKey == "" {
+			log.Println("Warning: ALPHAVANTAGE_API_KEY is not set. Price quotes will be skipped.")
+			return nil
+		}
+		return quote.NewAlphaVantage(apiKey)
+	case quote.ProviderFinnhub:
+		apiKey := os.Getenv("FINNHUB_API_KEY")
+		if apiKey == "" {
+			log.Println("Warning: FINNHUB_API_KEY is not set. Price quotes will be skipped.")
+			return nil
+		}
+		return quote.NewFinnhub(apiKey)
+	default:
+		return quote.NewYahoo()
+	}
+}
+
+// slackStyles は設定の絵文字・色の対応をSlack通知用に変換
+func slackStyles(styles map[string]config.SlackStyle) map[string]slack.Style {
+	converted := make(map[string]slack.Style, len(styles))
+	for k, v := range styles {
+		converted[k] = slack.Style(v)
+	}
+	return converted
+}
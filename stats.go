@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/Minatonton/x-crawler/internal/config"
+	"github.com/Minatonton/x-crawler/internal/storage"
+)
+
+// runStats はトレーダー・銘柄・カテゴリごとの集計（処理数・通知数・平均スコア・AIコスト）を表示する
+//
+//	x-crawler stats [-by trader|ticker|category] [-days 7] [-since 2024-01-01] [-until 2024-01-31] [-daily] [-format table|csv|jsonl]
+func runStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	configPath := fs.String("config", defaultConfigPath, "設定ファイルのパス")
+	seenPath := fs.String("seen", defaultSeenTweetsPath, "既読ツイートファイルのパス（storage.backend: json の場合）")
+	by := fs.String("by", storage.RollupTrader, "集計の軸: trader, ticker, category")
+	days := fs.Int("days", 7, "直近の日数（-since を指定した場合は無視）")
+	sinceFlag := fs.String("since", "", "この日以降 (2006-01-02)")
+	untilFlag := fs.String("until", "", "この日まで (2006-01-02、その日を含む)")
+	daily := fs.Bool("daily", false, "日ごとに分けて表示する")
+	limit := fs.Int("limit", 20, "表示する件数（0で全て）")
+	format := fs.String("format", "table", "出力形式: table, csv, jsonl")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	switch *by {
+	case storage.RollupTrader, storage.RollupTicker, storage.RollupCategory:
+	default:
+		return fmt.Errorf("invalid -by %q (expected trader, ticker or category)", *by)
+	}
+	switch *format {
+	case "table", "csv", "jsonl":
+	default:
+		return fmt.Errorf("invalid -format %q (expected table, csv or jsonl)", *format)
+	}
+	since, until := *sinceFlag, *untilFlag
+	if since == "" && *days > 0 {
+		since = time.Now().AddDate(0, 0, -(*days - 1)).Format("2006-01-02")
+	}
+	for _, d := range []string{since, until} {
+		if _, err := time.Parse("2006-01-02", d); d != "" && err != nil {
+			return fmt.Errorf("invalid date %q (expected 2006-01-02)", d)
+		}
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := setupEncryption(cfg); err != nil {
+		return err
+	}
+	store, closeStore, err := openRollupStore(cfg, *seenPath)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	rollups, err := store.Rollups(*by, since, until)
+	if err != nil {
+		return fmt.Errorf("failed to read stats: %w", err)
+	}
+	if !*daily {
+		rollups = storage.MergeRollups(rollups)
+	}
+	if *limit > 0 && !*daily && len(rollups) > *limit {
+		rollups = rollups[:*limit]
+	}
+	return writeStats(os.Stdout, *format, rollups, *daily)
+}
+
+// openRollupStore は集計の保存先を開く（保存先のバックエンドが対応していなければ stats.file）
+func openRollupStore(cfg *config.Config, seenPath string) (storage.RollupStore, func(), error) {
+	switch cfg.Storage.Backend {
+	case "sqlite", "redis", "bbolt":
+		state, err := openStorage(cfg, seenPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		if store, ok := state.(storage.RollupStore); ok {
+			return store, func() { state.Close() }, nil
+		}
+		state.Close()
+	}
+	if _, err := os.Stat(cfg.Stats.File); err != nil {
+		return nil, nil, fmt.Errorf("no stats to show (enable stats and run the crawler first): %w", err)
+	}
+	store, err := storage.NewStatsStore(cfg.Stats.File)
+	if err != nil {
+		return nil, nil, err
+	}
+	return store, func() {}, nil
+}
+
+// writeStats は集計を表・CSV・JSONLで書き込む
+func writeStats(w io.Writer, format string, rollups []storage.Rollup, daily bool) error {
+	switch format {
+	case "jsonl":
+		return writeJSONLines(w, len(rollups), func(i int) interface{} { return rollups[i] })
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"day", "dimension", "key", "processed", "notified", "avg_score", "ai_cost_usd"})
+		for _, r := range rollups {
+			cw.Write([]string{
+				r.Day,
+				r.Dimension,
+				r.Key,
+				strconv.Itoa(r.Processed),
+				strconv.Itoa(r.Notified),
+				strconv.FormatFloat(r.AvgScore(), 'f', 1, 64),
+				strconv.FormatFloat(r.AICostUSD, 'f', 4, 64),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := "KEY\tPROCESSED\tNOTIFIED\tAVG SCORE\tAI COST\t"
+	if daily {
+		header = "DAY\t" + header
+	}
+	fmt.Fprintln(tw, header)
+	for _, r := range rollups {
+		if daily {
+			fmt.Fprintf(tw, "%s\t", r.Day)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t$%.4f\t\n", r.Key, r.Processed, r.Notified, r.AvgScore(), r.AICostUSD)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/Minatonton/x-crawler/internal/config"
+	"github.com/Minatonton/x-crawler/internal/storage"
+)
+
+// setupEncryption は storage.encryption が有効な場合に状態ファイルとアーカイブの暗号化の鍵を設定する
+// key_command はシェルで実行し、標準出力を鍵として使う（例: aws kms decrypt で復号したデータキー）
+func setupEncryption(cfg *config.Config) error {
+	enc := cfg.Storage.Encryption
+	if !enc.Enabled {
+		return nil
+	}
+	keyText := enc.Key
+	if enc.KeyCommand != "" {
+		cmd := exec.Command("sh", "-c", enc.KeyCommand)
+		cmd.Stderr = os.Stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return fmt.Errorf("failed to run storage.encryption.key_command: %w", err)
+		}
+		keyText = string(out)
+	}
+	key, err := storage.ParseEncryptionKey(keyText)
+	if err != nil {
+		return fmt.Errorf("invalid storage.encryption key: %w", err)
+	}
+	if err := storage.SetEncryptionKey(key); err != nil {
+		return fmt.Errorf("invalid storage.encryption key: %w", err)
+	}
+	log.Println("Encryption at rest enabled for state files and archive")
+	return nil
+}
+
+// openStorage は storage.backend で選択された状態の保存先を開く
+func openStorage(cfg *config.Config, seenTweetsPath string) (storage.Storage, error) {
+	switch cfg.Storage.Backend {
+	case "sqlite":
+		retention := time.Duration(cfg.Storage.SQLite.RetentionDays) * 24 * time.Hour
+		db, err := storage.OpenSQLite(cfg.Storage.SQLite.File, retention)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize SQLite storage: %w", err)
+		}
+		log.Printf("Loaded %d seen tweets from SQLite %s", db.Count(), cfg.Storage.SQLite.File)
+		return db, nil
+	case "redis":
+		r := cfg.Storage.Redis
+		rdb, err := storage.OpenRedis(r.URL, r.KeyPrefix, time.Duration(r.RetentionDays)*24*time.Hour, r.QueueName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Redis storage: %w", err)
+		}
+		log.Printf("Loaded %d seen tweets from Redis (prefix: %s)", rdb.Count(), r.KeyPrefix)
+		return rdb, nil
+	case "bbolt":
+		retention := time.Duration(cfg.Storage.Bolt.RetentionDays) * 24 * time.Hour
+		db, err := storage.OpenBolt(cfg.Storage.Bolt.File, retention)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize bbolt storage: %w", err)
+		}
+		log.Printf("Loaded %d seen tweets from bbolt %s", db.Count(), cfg.Storage.Bolt.File)
+		return db, nil
+	case "remote":
+		r := cfg.Storage.Remote
+		object, err := storage.OpenObjectStore(r.URL, r.Region, r.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		remote, err := storage.OpenRemote(object, time.Duration(r.RetentionDays)*24*time.Hour)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize remote storage: %w", err)
+		}
+		log.Printf("Loaded %d seen tweets from %s", remote.Count(), object)
+		return remote, nil
+	default:
+		retention := time.Duration(cfg.Storage.JSON.RetentionDays) * 24 * time.Hour
+		seen, err := storage.NewSeenTweets(seenTweetsPath, retention, cfg.Storage.JSON.MaxIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize seen tweets: %w", err)
+		}
+		log.Printf("Loaded %d seen tweets from %s", seen.Count(), seenTweetsPath)
+		return seen, nil
+	}
+}
+
+// openUsageLedger は storage.ai_ledger が有効な場合にAI呼び出しの記録の保存先を開く
+// backend: sqlite の場合は状態と同じデータベース（stateがnilの場合は開き直す）、それ以外は日ごとのJSONLファイル
+func openUsageLedger(cfg *config.Config, state storage.Storage) (storage.UsageLedger, func(), error) {
+	if !cfg.Storage.AILedger.Enabled {
+		return nil, func() {}, nil
+	}
+	if cfg.Storage.Backend == "sqlite" {
+		if ledger, ok := state.(storage.UsageLedger); ok {
+			return ledger, func() {}, nil
+		}
+		db, err := storage.OpenSQLite(cfg.Storage.SQLite.File, 0)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize SQLite storage: %w", err)
+		}
+		return db, func() { db.Close() }, nil
+	}
+	retention := time.Duration(cfg.Storage.AILedger.RetentionDays) * 24 * time.Hour
+	archive, err := storage.NewArchive(cfg.Storage.AILedger.Dir, retention)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize AI usage ledger: %w", err)
+	}
+	return archive, func() {}, nil
+}
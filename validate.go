@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+
+	"github.com/Minatonton/x-crawler/internal/ai"
+	"github.com/Minatonton/x-crawler/internal/config"
+)
+
+// runValidate は設定ファイルを検証し、問題を行番号付きで表示する（問題がある場合はエラー）
+//
+//	x-crawler validate [-config config.yaml] [-online]
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	configPath := fs.String("config", defaultConfigPath, "設定ファイルのパス")
+	online := fs.Bool("online", false, "APIキーがある場合はプロバイダーのモデル一覧でモデル名を確認する")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	path, _, err := openConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	issues, err := config.Validate(path, func(cfg *config.Config) []config.Issue {
+		return checkModels(cfg, *online)
+	})
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		fmt.Printf("%s: OK\n", *configPath)
+		return nil
+	}
+	for _, issue := range issues {
+		file := *configPath
+		if issue.File != "" {
+			file = issue.File
+		}
+		if issue.Line > 0 {
+			fmt.Fprintf(os.Stderr, "%s:%d: ", file, issue.Line)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s: ", file)
+		}
+		if issue.Path != "" {
+			fmt.Fprintf(os.Stderr, "%s: ", issue.Path)
+		}
+		fmt.Fprintln(os.Stderr, issue.Message)
+	}
+	return fmt.Errorf("%d issue(s) found in %s", len(issues), *configPath)
+}
+
+// checkModels は使用するプロバイダーに対してモデル名を確認する
+// online の場合はAPIキーがあればモデル一覧と照合し、そうでなければエイリアスとプロバイダーの組み合わせだけを確認する
+func checkModels(cfg *config.Config, online bool) []config.Issue {
+	uses := make(map[string]bool)
+	for _, p := range cfg.AI.ProviderChain() {
+		uses[p] = true
+	}
+
+	var issues []config.Issue
+	check := func(path, provider, model string, available []string) {
+		resolved, err := ai.ResolveModel(provider, model, available)
+		if err != nil {
+			issues = append(issues, config.Issue{Path: path, Message: err.Error()})
+			return
+		}
+		// 一覧がない場合も明らかに別のプロバイダーのモデル名は指摘する
+		// (OpenAI互換のbase_urlやAzureでは任意の名前を使えるため確認しない)
+		custom := provider == ai.ProviderOpenAI && (cfg.AI.OpenAI.BaseURL != "" || cfg.AI.OpenAI.APIVersion != "")
+		claude := strings.HasPrefix(strings.ToLower(resolved), "claude-")
+		if available == nil && !custom && claude != (provider == ai.ProviderAnthropic) {
+			issues = append(issues, config.Issue{Path: path, Message: fmt.Sprintf("model %q does not look like a %s model", model, provider)})
+		}
+	}
+
+	if uses[ai.ProviderAnthropic] || cfg.AI.Triage.Enabled {
+		var available []string
+		if key := os.Getenv("ANTHROPIC_API_KEY"); online && key != "" {
+			available = listModels(ai.ProviderAnthropic, ai.AnthropicModels(key))
+		}
+		if uses[ai.ProviderAnthropic] {
+			check("ai.model", ai.ProviderAnthropic, cfg.AI.Model, available)
+		}
+		if cfg.AI.Triage.Enabled {
+			check("ai.triage.model", ai.ProviderAnthropic, cfg.AI.Triage.Model, available)
+		}
+	}
+	if uses[ai.ProviderOpenAI] {
+		var available []string
+		// Azure OpenAIはデプロイ名を指定するため一覧での検証はしない
+		if key := os.Getenv("OPENAI_API_KEY"); online && key != "" && cfg.AI.OpenAI.APIVersion == "" {
+			available = listModels(ai.ProviderOpenAI, ai.OpenAIModels(key, cfg.AI.OpenAI.BaseURL))
+		}
+		check("ai.openai.model", ai.ProviderOpenAI, cfg.AI.OpenAI.Model, available)
+	}
+	return issues
+}